@@ -1,9 +1,10 @@
 package analyzer
 
 import (
+	"fmt"
 	"go/ast"
+	"go/token"
 	"go/types"
-	"strings"
 
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/inspect"
@@ -12,39 +13,184 @@ import (
 
 // Analyzer is the main analyzer for detecting nil assignments to non-optional protobuf message fields
 var Analyzer = &analysis.Analyzer{
-	Name:     "nonillinter",
-	Doc:      "detects nil assignments to non-optional protobuf message fields",
-	Run:      run,
-	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Name:       "nonillinter",
+	Doc:        "detects nil assignments to non-optional protobuf message fields",
+	Run:        run,
+	Requires:   []*analysis.Analyzer{inspect.Analyzer},
+	ResultType: resultType,
+	FactTypes:  []analysis.Fact{new(returnFact), new(setterFact), new(optionalTypeFact)},
 }
 
 func run(pass *analysis.Pass) (interface{}, error) {
-	// Skip generated protobuf files (.pb.go)
-	for _, file := range pass.Files {
-		filename := pass.Fset.Position(file.Pos()).Filename
-		if strings.HasSuffix(filename, ".pb.go") {
-			return nil, nil
+	// The SSA form is built on demand by the nil checks; see ssaflow.go
+	defer ssaPackages.Delete(pass.Pkg)
+
+	// Descriptor metadata and message fields are shared while the passes that see them
+	// run; see descriptor.go and typecache.go
+	defer evictDescriptors(pass)
+	holdMessageFields(pass)
+	defer releaseMessageFields(pass)
+
+	// Message types declared optional everywhere; see optionaltypes.go
+	loadOptionalTypes(pass)
+	defer releaseOptionalTypes(pass)
+
+	// Messages used by the services the package sees, under -service-roots; see roots.go
+	loadServiceRoots(pass)
+
+	// Classify message types up front so downstream analyzers get a result
+	// even for packages we don't check
+	result := classifyPackage(pass)
+
+	// Deep analysis of each function is bounded by -analysis-budget; see budget.go
+	trackBudgets(pass, result)
+	defer packageBudgets.Delete(pass.Pkg)
+	defer laterFields.Delete(pass.Pkg)
+	defer packageBuilders.Delete(pass.Pkg)
+	defer validationPaths.Delete(pass.Pkg)
+	defer packageDecls.Delete(pass.Pkg)
+	defer checkedHelpers.Delete(pass.Pkg)
+
+	// Packages outside -include-packages or matching -exclude are not checked
+	included := isPackageIncluded(pass.Pkg.Path())
+	excluded := isPackageExcluded(pass.Pkg.Path())
+	// Generated files are skipped, not the package holding them; see generated.go. So are
+	// the files matching -exclude.
+	skipped := generatedFiles(pass)
+	for tf := range excludedFiles(pass) {
+		skipped[tf] = true
+	}
+	// Test files are skipped under -skip-tests, and under -tests-strict apart from their
+	// fake servers, whose findings are kept when the rest are dropped; see testfiles.go
+	tests := testFiles(pass)
+	unchecked := make(map[*token.File]bool, len(skipped)+len(tests))
+	for tf := range skipped {
+		unchecked[tf] = true
+	}
+	for tf := range tests {
+		unchecked[tf] = true
+		if !testsStrict {
+			skipped[tf] = true
 		}
 	}
 
+	// Summarize message-returning functions for callers here and in dependent packages,
+	// including packages that aren't checked themselves
+	exportReturnFacts(pass, included && !excluded, unchecked)
+	exportSetterFacts(pass)
+
+	// Skip packages outside the configured -include-packages patterns
+	if !included {
+		log().Info("skipping package not matched by -include-packages", "package", pass.Pkg.Path())
+		return result, nil
+	}
+
+	// Skip packages matching -exclude
+	if excluded {
+		log().Info("skipping package matched by -exclude", "package", pass.Pkg.Path())
+		return result, nil
+	}
+
+	// Response sites are counted by how far they were verified; see coverage.go
+	trackCoverage(pass)
+	defer packageCoverage.Delete(pass.Pkg)
+
+	// Keep each diagnostic with its syntax for codemod tooling; see findings.go
+	recordFindings(pass, result)
+
+	// Findings accepted with //nonillinter:ignore are dropped; see suppress.go
+	suppressIgnored(pass)
+
+	// Findings in handlers registered in a map or route table name their entry; see tables.go
+	nameTableEntries(pass)
+
+	// Findings in the files cgo writes are dropped or lose their fixes; see cgo.go
+	filterCgo(pass)
+
+	// Findings in generated and excluded files are dropped; see generated.go
+	dropSkipped(pass, skipped)
+
+	// Under -tests-strict, findings in test files outside fake servers are dropped; see testfiles.go
+	keepFakeServers(pass, tests)
+
+	// Under -grpc-handlers-only, findings outside handler methods are dropped; see handlers.go
+	onlyHandlers(pass)
+
 	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
 
 	// Track analyzed composite literals to avoid duplicate checks
 	analyzedComposites := make(map[ast.Node]bool)
 
+	// Response literals bound to local variables are evaluated at the returns that
+	// hand them back instead of at the literal; see flow.go
+	deferredLiterals := make(map[*ast.CompositeLit]bool)
+	funcFilter := []ast.Node{
+		(*ast.FuncDecl)(nil),
+		(*ast.FuncLit)(nil),
+	}
+	inspect.Preorder(funcFilter, func(n ast.Node) {
+		if skipped[pass.Fset.File(n.Pos())] {
+			return
+		}
+		var body *ast.BlockStmt
+		switch fn := n.(type) {
+		case *ast.FuncDecl:
+			body = fn.Body
+		case *ast.FuncLit:
+			body = fn.Body
+		}
+		if body == nil {
+			return
+		}
+		// Over budget, response literals are checked where they are built
+		tracked := make(map[types.Object]*trackedResponse)
+		withinBudget(body.Pos(), pass, func() {
+			tracked = collectTrackedResponses(body, pass)
+			for _, t := range sortedTracked(tracked) {
+				if t.lit != nil {
+					deferredLiterals[t.lit] = true
+				}
+				// A template is completed by its clones; see protoruntime.go
+				if t.template != nil {
+					deferredLiterals[t.template] = true
+				}
+				log().Debug("evaluating response variable at its return sites",
+					"variable", t.obj.Name(), "type", t.litType.String(), "pos", pass.Fset.Position(t.init.Pos()))
+			}
+			checkTrackedResponses(body, tracked, pass)
+			// Nested fields set later, e.g. through resp.GetUser(); see getters.go
+			collectLaterFields(body, tracked, pass)
+		})
+		checkExclusiveFields(body, tracked, pass)
+		checkSharedResponses(body, pass)
+		// Responses recycled through a sync.Pool must be reset and filled in; see pool.go
+		checkPooledResponses(body, pass)
+		// Responses built by helpers are checked where they are returned; see builders.go
+		checkBuilderResults(body, pass)
+	})
+
+	// Literals returned with a non-nil error under -partial-responses=with-error; see partial.go
+	partialLiterals := make(map[*ast.CompositeLit]bool)
+
 	// Node types we care about
 	nodeFilter := []ast.Node{
 		(*ast.AssignStmt)(nil),   // Regular assignments
 		(*ast.CompositeLit)(nil), // Struct literals
 		(*ast.ReturnStmt)(nil),   // Return statements
+		(*ast.CallExpr)(nil),     // Reflective sets
 	}
 
 	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		if skipped[pass.Fset.File(n.Pos())] {
+			return
+		}
 		switch stmt := n.(type) {
 		case *ast.AssignStmt:
 			checkAssignment(stmt, pass)
 
 		case *ast.CompositeLit:
+			checkOneofWrapper(stmt, pass)
+
 			// Avoid duplicate analysis if we've already checked this composite
 			if analyzedComposites[stmt] {
 				return
@@ -57,13 +203,33 @@ func run(pass *analysis.Pass) (interface{}, error) {
 				return
 			}
 
-			if isResponseMessage(litType) {
-				checkCompositeLiteral(stmt, litType, pass)
+			if shouldCheckType(litType) {
+				checkCompositeLiteral(stmt, litType, pass, !deferredLiterals[stmt] && !partialLiterals[stmt])
+				if checkAllMessages {
+					// Nested message literals were validated recursively as part of this one
+					markNestedLiterals(stmt, analyzedComposites, pass)
+				}
 			}
 
 		case *ast.ReturnStmt:
 			// Check return statements for composite literals creating messages
+			checkNilNilReturn(stmt, pass)
+			checkStubResponse(stmt, pass)
+			partial := allowsPartialResponse(stmt, pass)
+			// Builders' responses are verified at their callers' returns instead
+			trusted := partial && !returnsToCaller(stmt, pass)
 			for _, result := range stmt.Results {
+				// Connect handlers return the message wrapped; see connect.go
+				result = unwrapResponse(result, pass)
+				if lit := messageLiteral(result, shouldCheckType, pass); lit != nil && partial {
+					partialLiterals[lit] = true
+					if trusted {
+						trustSite(lit, pass)
+					}
+				}
+				if lit, _ := builtLiteral(result, shouldCheckType, pass); lit != nil && partial {
+					partialLiterals[lit] = true
+				}
 				if comp, ok := result.(*ast.CompositeLit); ok {
 					if analyzedComposites[comp] {
 						continue
@@ -71,94 +237,134 @@ func run(pass *analysis.Pass) (interface{}, error) {
 					analyzedComposites[comp] = true
 
 					litType := pass.TypesInfo.TypeOf(comp)
-					if litType != nil && isResponseMessage(litType) {
-						checkCompositeLiteral(comp, litType, pass)
+					if litType != nil && shouldCheckType(litType) {
+						checkCompositeLiteral(comp, litType, pass, !partial)
 					}
 				}
 			}
+
+		case *ast.CallExpr:
+			// Builder literals are checked as the message they build; see opaque.go
+			if lit, msgType := builtLiteral(stmt, shouldCheckType, pass); lit != nil && !analyzedComposites[lit] {
+				analyzedComposites[lit] = true
+				checkCompositeLiteral(lit, msgType, pass, !deferredLiterals[lit] && !partialLiterals[lit])
+				if checkAllMessages {
+					markNestedLiterals(lit, analyzedComposites, pass)
+				}
+			}
+			checkReflectiveSet(stmt, pass)
+			checkSetterCall(stmt, pass)
+			checkSetExtension(stmt, pass)
 		}
 	})
 
-	return nil, nil
+	checkServiceImplementations(pass)
+	checkOptionConstructors(inspect, pass)
+	checkMessageCopies(inspect, pass)
+	checkOutputOnlySets(inspect, pass)
+	checkOptionalUsage(inspect, pass)
+	checkConverterFuncs(pass)
+	countCoverage(pass, result, skipped)
+
+	log().Info("analyzed package", "package", pass.Pkg.Path(), "files", len(pass.Files),
+		"messages", len(result.RequiredFields), "responses", len(result.ResponseTypes))
+	return result, nil
 }
 
 // checkAssignment checks an assignment statement for nil assignments to message fields
 func checkAssignment(stmt *ast.AssignStmt, pass *analysis.Pass) {
 	for i := 0; i < len(stmt.Lhs) && i < len(stmt.Rhs); i++ {
-		lhs := stmt.Lhs[i]
-		rhs := stmt.Rhs[i]
-
 		// Check if LHS is a selector expression (field access)
-		sel, ok := lhs.(*ast.SelectorExpr)
-		if !ok {
-			continue
+		if sel := storedField(stmt.Lhs[i], pass); sel != nil {
+			checkFieldStore(sel, stmt.Rhs[i], pass)
 		}
+	}
+}
 
-		// Get the type of the base expression
-		baseType := pass.TypesInfo.TypeOf(sel.X)
-		if baseType == nil {
-			continue
-		}
+// checkFieldStore checks a value stored into a message field, by an assignment or a
+// setter call
+func checkFieldStore(sel *ast.SelectorExpr, rhs ast.Expr, pass *analysis.Pass) {
+	// Get the type of the base expression
+	baseType := pass.TypesInfo.TypeOf(sel.X)
+	if baseType == nil {
+		return
+	}
 
-		// Dereference pointer types
-		if ptr, ok := baseType.(*types.Pointer); ok {
-			baseType = ptr.Elem()
-		}
+	// Dereference pointer types
+	if ptr, ok := baseType.(*types.Pointer); ok {
+		baseType = ptr.Elem()
+	}
 
-		// Check if the base is a response message type - only check response messages
-		if !isResponseMessage(baseType) {
-			continue
-		}
+	// Check if the base is a response message type (any message with -check-all-messages)
+	if !shouldCheckType(baseType) {
+		return
+	}
 
-		// Get the field being accessed
-		field := getFieldFromType(baseType, sel.Sel.Name)
-		if field == nil {
-			continue
-		}
+	// Get the field being accessed
+	field := getFieldFromType(baseType, sel.Sel.Name)
+	if field == nil {
+		return
+	}
 
-		// Check if this is a message field (not scalar)
-		if !isMessageField(field) {
-			continue
-		}
+	// Map and repeated fields hold messages in their entries and elements; see
+	// mapfields.go and repeated.go
+	checkMapValues(rhs, field, baseType, pass)
+	checkRepeatedValues(rhs, field, baseType, pass)
 
-		// Check if the field is optional
-		if isOptionalField(field) {
-			continue
-		}
+	// Check if this is a message field (not scalar)
+	if !isMessageField(field) {
+		return
+	}
 
-		// Check if RHS is nil (explicit or implicit)
-		if isNilValue(rhs, pass) {
-			pass.Reportf(rhs.Pos(),
-				"nil assignment to non-optional message field '%s' in protobuf message '%s'",
-				sel.Sel.Name, baseType.String())
-		} else {
-			// If RHS is not nil but is a message type, recursively validate it
-			rhsType := pass.TypesInfo.TypeOf(rhs)
-			if rhsType != nil && isProtobufMessageType(rhsType) {
-				validateMessageValue(rhs, rhsType, pass, sel.Sel.Name)
-			}
-		}
+	// Check if the field is optional
+	if isOptionalField(getStructType(baseType), field) {
+		return
 	}
-}
 
-// checkCompositeLiteral checks a composite literal for nil message fields
-func checkCompositeLiteral(lit *ast.CompositeLit, litType types.Type, pass *analysis.Pass) {
-	// Only check if this is a response message type
-	if !isResponseMessage(litType) {
+	// Clients leave OUTPUT_ONLY fields of requests unset
+	if isRequestMessage(baseType) && isOutputOnlyField(baseType, getStructType(baseType), field) {
 		return
 	}
 
+	// Check if RHS is nil (explicit or implicit)
+	ref := rootField(baseType, sel.Sel.Name)
+	if isNilValue(rhs, pass) {
+		reportField(pass, ref, analysis.Diagnostic{
+			Pos:      rhs.Pos(),
+			Category: nilKind(rhs, pass),
+			Message: fmt.Sprintf("nil assignment to non-optional message field '%s' in protobuf message %s%s%s",
+				sel.Sel.Name, describeType(pass, baseType), nilProvenance(rhs, pass), gatewayNote(baseType, sel.Sel.Name)),
+			SuggestedFixes: nilTimestampFix(rhs, field, pass),
+		})
+	} else if isZeroValueMessage(rhs, pass) {
+		reportZeroValueMessage(pass, rhs.Pos(), ref, baseType, pass.TypesInfo.TypeOf(rhs))
+	} else {
+		checkMapLookup(rhs, ref, baseType, pass)
+		checkContextValue(rhs, ref, baseType, pass)
+		checkTypeAssertion(rhs, ref, baseType, pass)
+		reportUnverified(rhs, ref, baseType, pass)
+
+		// If RHS is not nil but is a message type, recursively validate it
+		rhsType := pass.TypesInfo.TypeOf(rhs)
+		if rhsType != nil && isProtobufMessageType(rhsType) {
+			validateMessageValue(rhs, rhsType, pass, ref, isRequestMessage(baseType))
+		}
+	}
+}
+
+// checkCompositeLiteral checks a composite literal for nil message fields.
+// reportMissing controls whether uninitialized fields are reported at the literal.
+// Callers decide which message types are checked; see shouldCheckType.
+func checkCompositeLiteral(lit *ast.CompositeLit, litType types.Type, pass *analysis.Pass, reportMissing bool) {
 	// Get the struct type
 	structType := getStructType(litType)
 	if structType == nil {
 		return
 	}
 
-	// Get all message fields for this type
-	messageFields := getMessageFields(structType)
-	if len(messageFields) == 0 {
-		return
-	}
+	// Get all message fields for this type. A message without any still has its map
+	// fields checked below.
+	messageFields := requiredFields(structType, litType, isRequestMessage(litType))
 
 	// Track which fields are initialized
 	initialized := make(map[string]bool)
@@ -179,6 +385,10 @@ func checkCompositeLiteral(lit *ast.CompositeLit, litType types.Type, pass *anal
 
 		fieldName := fieldIdent.Name
 		initialized[fieldName] = true
+		if f := getFieldFromType(litType, fieldName); f != nil {
+			checkMapValues(kv.Value, f, litType, pass)
+			checkRepeatedValues(kv.Value, f, litType, pass)
+		}
 
 		// Find the corresponding field
 		var field *types.Var
@@ -193,27 +403,76 @@ func checkCompositeLiteral(lit *ast.CompositeLit, litType types.Type, pass *anal
 			continue
 		}
 
-		// Check if value is nil
-		if isNilValue(kv.Value, pass) {
-			pass.Reportf(kv.Value.Pos(),
-				"nil assignment to non-optional message field '%s' in protobuf message '%s'",
-				fieldName, litType.String())
+		// Check if value is nil; a recursive field ends its chain there
+		ref := rootField(litType, fieldName)
+		if isNilValue(kv.Value, pass) && isRecursiveField(litType, field, isRequestMessage(litType)) {
+			reportRecursiveField(kv.Value.Pos(), ref, litType, pass)
+		} else if isNilValue(kv.Value, pass) {
+			reportField(pass, ref, analysis.Diagnostic{
+				Pos:      kv.Value.Pos(),
+				Category: nilKind(kv.Value, pass),
+				Message: fmt.Sprintf("nil assignment to non-optional message field '%s' in protobuf message %s%s%s",
+					fieldName, describeType(pass, litType), nilProvenance(kv.Value, pass), gatewayNote(litType, fieldName)),
+				SuggestedFixes: nilTimestampFix(kv.Value, field, pass),
+			})
+		} else if isZeroValueMessage(kv.Value, pass) {
+			reportZeroValueMessage(pass, kv.Value.Pos(), ref, litType, pass.TypesInfo.TypeOf(kv.Value))
 		} else {
+			checkMapLookup(kv.Value, ref, litType, pass)
+			checkContextValue(kv.Value, ref, litType, pass)
+			checkTypeAssertion(kv.Value, ref, litType, pass)
+			reportUnverified(kv.Value, ref, litType, pass)
+
 			// Recursively validate non-nil message values
 			valueType := pass.TypesInfo.TypeOf(kv.Value)
 			if valueType != nil && isProtobufMessageType(valueType) {
-				validateMessageValue(kv.Value, valueType, pass, fieldName)
+				validateMessageValue(kv.Value, valueType, pass, ref, isRequestMessage(litType))
 			}
 		}
 	}
 
+	if !reportMissing {
+		return
+	}
+
 	// Check for uninitialized required message fields
 	for _, field := range messageFields {
-		if !initialized[field.Name()] {
-			pass.Reportf(lit.Pos(),
-				"non-optional message field '%s' not initialized in protobuf message '%s'",
-				field.Name(), litType.String())
+		if initialized[field.Name()] {
+			continue
+		}
+		ref := rootField(litType, field.Name())
+		if isRecursiveField(litType, field, isRequestMessage(litType)) {
+			reportRecursiveField(lit.Pos(), ref, litType, pass)
+			continue
+		}
+		reportField(pass, ref, analysis.Diagnostic{
+			Pos:      lit.Pos(),
+			Category: KindMissingField,
+			Message: fmt.Sprintf("non-optional message field '%s' not initialized in protobuf message %s%s",
+				field.Name(), describeType(pass, litType), gatewayNote(litType, field.Name())),
+			SuggestedFixes: missingFieldFix(lit, field, isRequestMessage(litType), pass),
+		})
+	}
+}
+
+// markNestedLiterals marks the message literals nested as field values in lit, at any
+// depth, as analyzed
+func markNestedLiterals(lit *ast.CompositeLit, analyzed map[ast.Node]bool, pass *analysis.Pass) {
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		value := ast.Unparen(kv.Value)
+		if unary, ok := value.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+			value = ast.Unparen(unary.X)
+		}
+		nested, ok := value.(*ast.CompositeLit)
+		if !ok || !isProtobufMessageType(pass.TypesInfo.TypeOf(nested)) {
+			continue
 		}
+		analyzed[nested] = true
+		markNestedLiterals(nested, analyzed, pass)
 	}
 }
 
@@ -248,7 +507,7 @@ func getFieldFromType(t types.Type, fieldName string) *types.Var {
 
 	for i := 0; i < structType.NumFields(); i++ {
 		field := structType.Field(i)
-		if field.Name() == fieldName {
+		if field.Name() == fieldName || messageSchema.FieldName(field) == fieldName {
 			return field
 		}
 	}
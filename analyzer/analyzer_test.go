@@ -1,11 +1,19 @@
 package analyzer_test
 
 import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/nickheyer/go_no_nil_linter/analyzer"
-	examplev1 "github.com/nickheyer/go_no_nil_linter/gen/example/v1"
-	"google.golang.org/protobuf/types/known/timestamppb"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
 )
 
 func TestAnalyzerDefinition(t *testing.T) {
@@ -27,333 +35,857 @@ func TestAnalyzerDefinition(t *testing.T) {
 	}
 }
 
-// TestProtobufMessageCreation tests that protobuf messages can be created correctly
-func TestProtobufMessageCreation(t *testing.T) {
-	tests := []struct {
-		name      string
-		createFn  func() *examplev1.UserResponse
-		shouldErr bool
-		desc      string
-	}{
-		{
-			name: "valid_full_initialization",
-			createFn: func() *examplev1.UserResponse {
-				return &examplev1.UserResponse{
-					User: &examplev1.User{
-						Id:   "123",
-						Name: "John",
-						Address: &examplev1.Address{
-							Street:     "123 Main St",
-							City:       "NYC",
-							PostalCode: "10001",
-							Location: &examplev1.Location{
-								Latitude:  40.7128,
-								Longitude: -74.0060,
-							},
-						},
-						CreatedAt: timestamppb.Now(),
-						ContactInfo: &examplev1.ContactInfo{
-							Email: "john@example.com",
-							Phone: "555-1234",
-						},
-					},
-					LastLogin: timestamppb.Now(),
-				}
-			},
-			shouldErr: false,
-			desc:      "All required fields initialized - should pass",
-		},
-		{
-			name: "optional_fields_can_be_nil",
-			createFn: func() *examplev1.UserResponse {
-				return &examplev1.UserResponse{
-					User: &examplev1.User{
-						Id:       "123",
-						Name:     "John",
-						Nickname: nil, // Optional field
-						Address: &examplev1.Address{
-							Street:     "123 Main St",
-							City:       "NYC",
-							PostalCode: "10001",
-							Location: &examplev1.Location{
-								Latitude:  40.7128,
-								Longitude: -74.0060,
-							},
-							Apartment: nil, // Optional field
-						},
-						CreatedAt: timestamppb.Now(),
-						ContactInfo: &examplev1.ContactInfo{
-							Email:          "john@example.com",
-							Phone:          "555-1234",
-							MailingAddress: nil, // Optional message field
-						},
-					},
-					LastLogin: timestamppb.Now(),
-					Manager:   nil, // Optional message field
-				}
-			},
-			shouldErr: false,
-			desc:      "Optional fields can be nil - should pass",
-		},
+// TestResultClassification tests the Result exposed to downstream analyzers
+func TestResultClassification(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "classify")
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+
+	result, ok := results[0].Result.(*analyzer.Result)
+	if !ok {
+		t.Fatalf("Expected *analyzer.Result, got %T", results[0].Result)
+	}
+
+	byName := make(map[string]*types.TypeName)
+	for _, obj := range result.Messages() {
+		byName[obj.Name()] = obj
+	}
+
+	for _, name := range []string{"UserResponse", "User", "Address", "Location", "Timestamp", "GetUserRequest"} {
+		if byName[name] == nil {
+			t.Errorf("Expected message %s to be classified", name)
+		}
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			response := tt.createFn()
-			if response == nil {
-				t.Error("Response should not be nil")
+	if obj := byName["UserResponse"]; obj == nil || !result.IsResponse(obj.Type()) {
+		t.Error("UserResponse should be classified as a response")
+	}
+	if obj := byName["GetUserRequest"]; obj == nil || result.IsResponse(obj.Type()) {
+		t.Error("GetUserRequest should not be classified as a response")
+	}
+
+	var required []string
+	if obj := byName["UserResponse"]; obj != nil {
+		for _, field := range result.Required(types.NewPointer(obj.Type())) {
+			required = append(required, field.Name())
+		}
+	}
+	if len(required) != 2 || required[0] != "User" || required[1] != "LastLogin" {
+		t.Errorf("Expected UserResponse required fields [User LastLogin], got %v", required)
+	}
+}
+
+// TestZeroValueMessages tests that empty well-known messages are reported separately from nil
+func TestZeroValueMessages(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "zerovalue")
+}
+
+// TestEarlyReturns tests that responses bound to variables are evaluated at the returns that hand them back
+func TestEarlyReturns(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "earlyreturn")
+}
+
+// TestBranchSemantics tests that fields set on only some branches of an if are reported with their guard
+func TestBranchSemantics(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "branches")
+}
+
+// TestLoopSemantics tests value tracing through Go 1.22 range-over-int and per-iteration loop variables
+func TestLoopSemantics(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "loops")
+}
+
+// TestMapLookups tests the advisory rule for map lookups assigned to required fields
+func TestMapLookups(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "maplookup")
+	if got := fmt.Sprint(severities(t, results)); got != "map[advisory:2]" {
+		t.Errorf("Expected 2 advisory findings, got %s", got)
+	}
+}
+
+// severities counts the findings of results by the severity recorded for them, checking
+// that each message starts with the prefix of its severity
+func severities(t *testing.T, results []*analysistest.Result) map[analyzer.Severity]int {
+	t.Helper()
+	counts := make(map[analyzer.Severity]int)
+	for _, r := range results {
+		res := r.Result.(*analyzer.Result)
+		for _, d := range r.Diagnostics {
+			severity := res.Severity(d)
+			if !strings.HasPrefix(d.Message, severity.Prefix()) {
+				t.Errorf("Expected the %s finding %q to start with %q", severity, d.Message, severity.Prefix())
 			}
-			
-			// Verify basic structure
-			if !tt.shouldErr {
-				if response.User == nil {
-					t.Error("User should not be nil in valid response")
-				}
-				if response.LastLogin == nil {
-					t.Error("LastLogin should not be nil in valid response")
+			counts[severity]++
+		}
+	}
+	return counts
+}
+
+// TestIgnoreDirectives tests that ignore directives, with a reason or without, and balanced unchecked regions suppress findings
+func TestIgnoreDirectives(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "suppress", "noreason")
+}
+
+// TestRequireReason tests that under -require-reason the analyzer's directives without a reason suppress nothing and are reported with the findings they cover
+func TestRequireReason(t *testing.T) {
+	analyzer.Analyzer.Flags.Set("require-reason", "true")
+	defer analyzer.Analyzer.Flags.Set("require-reason", "false")
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "requirereason")
+}
+
+// TestMockPackages tests that values built in mock packages are not validated recursively
+func TestMockPackages(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "svc/mocks")
+}
+
+// TestTaggedStructs tests that -tagged-structs treats structs with protobuf tags as messages
+func TestTaggedStructs(t *testing.T) {
+	if err := analyzer.Analyzer.Flags.Set("tagged-structs", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer analyzer.Analyzer.Flags.Set("tagged-structs", "false")
+
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "taggedstructs")
+}
+
+// TestClassificationCorpus tests the message and field classification rules.
+// It is also the core of the corpus for the mutation-testing harness.
+func TestClassificationCorpus(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "corpus")
+}
+
+// TestReportUnverified tests informational diagnostics for values the analyzer can't verify
+func TestReportUnverified(t *testing.T) {
+	if err := analyzer.Analyzer.Flags.Set("report-unverified", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer analyzer.Analyzer.Flags.Set("report-unverified", "false")
+
+	results := analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "unverified")
+	if counts := severities(t, results); len(counts) != 1 || counts[analyzer.SeverityInfo] == 0 {
+		t.Errorf("Expected only info findings, got %v", counts)
+	}
+}
+
+// TestSharedResponses tests that package-level and struct-field responses mutated per call are reported
+func TestSharedResponses(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "shared")
+}
+
+// TestTypeNames tests that message types are rendered by their shortest unique name and proto package
+func TestTypeNames(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "typenames")
+}
+
+// TestReflection tests the advisory for reflective sets on response messages
+func TestReflection(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "reflection")
+}
+
+// TestReflectionError tests that -reflection=error hard-fails outside tests
+func TestReflectionError(t *testing.T) {
+	if err := analyzer.Analyzer.Flags.Set("reflection", "error"); err != nil {
+		t.Fatal(err)
+	}
+	defer analyzer.Analyzer.Flags.Set("reflection", "advisory")
+
+	results := analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "reflectionerror")
+	// The package's test variant reports the finding outside tests again
+	if got := fmt.Sprint(severities(t, results)); got != "map[advisory:1 error:2]" {
+		t.Errorf("Expected errors outside tests and an advisory finding in them, got %s", got)
+	}
+}
+
+// TestContextValues tests that unchecked messages pulled from context values are reported
+func TestContextValues(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "contextvalue")
+}
+
+func TestTypeAssertion(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "typeassert")
+}
+
+func TestPooledResponses(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "pooled")
+}
+
+// TestFindings tests that every diagnostic is exposed with the syntax it was reported on
+func TestFindings(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "findings")
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	result := results[0].Result.(*analyzer.Result)
+
+	if len(result.Findings) != len(results[0].Diagnostics) {
+		t.Fatalf("Expected %d findings, got %d", len(results[0].Diagnostics), len(result.Findings))
+	}
+
+	var nodes []string
+	for _, f := range result.Findings {
+		if f.Node == nil || f.File == nil || f.Fset == nil {
+			t.Fatalf("Finding %q has no syntax", f.Diagnostic.Message)
+		}
+		if f.Node.Pos() != f.Diagnostic.Pos {
+			t.Errorf("Finding %q: node starts at %v, diagnostic at %v",
+				f.Diagnostic.Message, f.Fset.Position(f.Node.Pos()), f.Fset.Position(f.Diagnostic.Pos))
+		}
+		if _, ok := f.Path[len(f.Path)-1].(*ast.File); !ok || f.Path[0] != f.Node {
+			t.Errorf("Finding %q: path should run from the node to the file", f.Diagnostic.Message)
+		}
+		nodes = append(nodes, fmt.Sprintf("%T<%T", f.Node, f.Path[1]))
+	}
+
+	// Nil values are reported on the value; its parent is the assignment or key-value pair
+	want := []string{"*ast.Ident<*ast.AssignStmt", "*ast.Ident<*ast.KeyValueExpr", "*ast.CompositeLit<*ast.UnaryExpr"}
+	if strings.Join(nodes, " ") != strings.Join(want, " ") {
+		t.Errorf("Expected finding nodes %v, got %v", want, nodes)
+	}
+}
+
+// TestFieldPaths tests that findings record the message type a check started from and
+// the path to the field from it, however deep the field is nested
+func TestFieldPaths(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "fieldpaths")
+	var got []string
+	for _, f := range results[0].Result.(*analyzer.Result).Findings {
+		got = append(got, f.MessageType+" "+f.FieldPath)
+	}
+	want := []string{
+		"stubpb.UserResponse UserResponse.User.Address.Location",
+		"stubpb.UserResponse UserResponse.User.Address",
+		"stubpb.UserResponse UserResponse.User",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected field paths %q, got %q", want, got)
+	}
+}
+
+// TestMessageCopies tests -forbid-message-copy and its proto.Clone fix
+func TestMessageCopies(t *testing.T) {
+	if err := analyzer.Analyzer.Flags.Set("forbid-message-copy", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer analyzer.Analyzer.Flags.Set("forbid-message-copy", "false")
+
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), analyzer.Analyzer, "messagecopy")
+}
+
+// TestMissingFieldFixes tests the suggested fixes that add uninitialized fields as empty messages
+func TestMissingFieldFixes(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), analyzer.Analyzer, "fixes")
+}
+
+// TestFunctionalOptions tests required field checks at functional-options constructor calls
+func TestFunctionalOptions(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "options")
+}
+
+// TestGatewayJSON tests that -gateway-json names the JSON key REST clients lose
+func TestGatewayJSON(t *testing.T) {
+	if err := analyzer.Analyzer.Flags.Set("gateway-json", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer analyzer.Analyzer.Flags.Set("gateway-json", "false")
+
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "gateway")
+}
+
+// TestResponseNames tests -response-suffixes and -response-pattern
+func TestResponseNames(t *testing.T) {
+	for flag, value := range map[string]string{"response-suffixes": "Output", "response-pattern": `^(Get|List)\w+Out$`} {
+		if err := analyzer.Analyzer.Flags.Set(flag, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer analyzer.Analyzer.Flags.Set("response-suffixes", "Response,Reply,Result")
+	defer analyzer.Analyzer.Flags.Set("response-pattern", "")
+
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "responsenames")
+}
+
+// TestCheckAllMessages tests that -check-all-messages checks messages that aren't responses
+func TestCheckAllMessages(t *testing.T) {
+	if err := analyzer.Analyzer.Flags.Set("check-all-messages", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer analyzer.Analyzer.Flags.Set("check-all-messages", "false")
+
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "checkall")
+}
+
+// TestExportPolicy tests the language-neutral policy uses proto names and field numbers
+func TestExportPolicy(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "api/v1/userpb")
+	policy := analyzer.ExportPolicy([]*types.Package{results[0].Pass.Pkg})
+
+	if policy.Schema != analyzer.PolicySchema {
+		t.Errorf("Expected schema %q, got %q", analyzer.PolicySchema, policy.Schema)
+	}
+	var names []string
+	for _, msg := range policy.Messages {
+		names = append(names, msg.Name)
+	}
+	if strings.Join(names, " ") != "api.v1.GetUserResponse api.v1.User" {
+		t.Fatalf("Expected messages [api.v1.GetUserResponse api.v1.User], got %v", names)
+	}
+
+	resp := policy.Messages[0]
+	if !resp.Response || len(resp.RequiredFields) != 1 {
+		t.Fatalf("Unexpected GetUserResponse policy %+v", resp)
+	}
+	want := analyzer.PolicyField{Name: "user", Number: 1, JSONName: "user", Type: "api.v1.User"}
+	if resp.RequiredFields[0] != want {
+		t.Errorf("Expected required field %+v, got %+v", want, resp.RequiredFields[0])
+	}
+}
+
+// TestExplainField tests the requiredness explained for editor hovers
+func TestExplainField(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "api/v1/userpb")
+	scope := results[0].Pass.Pkg.Scope()
+	explain := func(message, field string) analyzer.FieldRequirement {
+		t.Helper()
+		msg := scope.Lookup(message).(*types.TypeName)
+		structType := msg.Type().Underlying().(*types.Struct)
+		for i := 0; i < structType.NumFields(); i++ {
+			if structType.Field(i).Name() == field {
+				req, ok := analyzer.ExplainField(msg, structType.Field(i))
+				if !ok {
+					t.Fatalf("Expected %s.%s to be explained", message, field)
 				}
+				return req
 			}
-		})
+		}
+		t.Fatalf("No field %s.%s", message, field)
+		return analyzer.FieldRequirement{}
 	}
-}
 
-// TestMessageFieldValidation tests that message fields are properly validated
-func TestMessageFieldValidation(t *testing.T) {
-	tests := []struct {
-		name     string
-		setup    func() interface{}
-		validate func(interface{}) error
-		desc     string
+	for _, tt := range []struct {
+		message, field string
+		required       bool
+		reason         string
 	}{
-		{
-			name: "user_with_all_message_fields",
-			setup: func() interface{} {
-				return &examplev1.User{
-					Id:   "123",
-					Name: "John",
-					Address: &examplev1.Address{
-						Street:     "123 Main St",
-						City:       "NYC",
-						PostalCode: "10001",
-						Location: &examplev1.Location{
-							Latitude:  40.7128,
-							Longitude: -74.0060,
-						},
-					},
-					CreatedAt: timestamppb.Now(),
-					ContactInfo: &examplev1.ContactInfo{
-						Email: "john@example.com",
-						Phone: "555-1234",
-					},
-				}
-			},
-			validate: func(v interface{}) error {
-				user := v.(*examplev1.User)
-				if user.Address == nil {
-					t.Error("Address should not be nil")
-				}
-				if user.Address.Location == nil {
-					t.Error("Location should not be nil")
-				}
-				if user.CreatedAt == nil {
-					t.Error("CreatedAt should not be nil")
-				}
-				if user.ContactInfo == nil {
-					t.Error("ContactInfo should not be nil")
-				}
-				return nil
-			},
-			desc: "All required message fields should be non-nil",
-		},
-		{
-			name: "address_with_location",
-			setup: func() interface{} {
-				return &examplev1.Address{
-					Street:     "123 Main St",
-					City:       "NYC",
-					PostalCode: "10001",
-					Location: &examplev1.Location{
-						Latitude:  40.7128,
-						Longitude: -74.0060,
-					},
-				}
-			},
-			validate: func(v interface{}) error {
-				addr := v.(*examplev1.Address)
-				if addr.Location == nil {
-					t.Error("Location should not be nil")
-				}
-				if addr.Location.Latitude == 0 && addr.Location.Longitude == 0 {
-					// This is actually OK - zero values are fine for scalars
-				}
-				return nil
-			},
-			desc: "Nested message Location should be non-nil",
-		},
+		{"GetUserResponse", "User", true, "singular message field"},
+		{"User", "Id", false, "scalar fields"},
+	} {
+		req := explain(tt.message, tt.field)
+		if req.Required != tt.required || !strings.Contains(req.Reason, tt.reason) {
+			t.Errorf("Expected %s.%s required=%v because of %s, got %+v", tt.message, tt.field, tt.required, tt.reason, req)
+		}
+		if !strings.HasPrefix(req.Rule, "https://") {
+			t.Errorf("Expected a link to the rule documentation for %s.%s, got %q", tt.message, tt.field, req.Rule)
+		}
+	}
+	if req := explain("GetUserResponse", "User"); !req.Checked || req.Message != "api.v1.GetUserResponse" {
+		t.Errorf("Expected api.v1.GetUserResponse to be checked, got %+v", req)
 	}
+	if req := explain("User", "Id"); req.Checked {
+		t.Errorf("Expected api.v1.User to be checked only when nested, got %+v", req)
+	}
+}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			obj := tt.setup()
-			if obj == nil {
-				t.Fatal("Setup returned nil")
-			}
-			
-			if err := tt.validate(obj); err != nil {
-				t.Errorf("Validation failed: %v", err)
-			}
+// TestExportPolicyExtensions tests that message extensions are exported with the policy
+func TestExportPolicyExtensions(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "api/audit/auditpb")
+	policy := analyzer.ExportPolicy([]*types.Package{results[0].Pass.Pkg})
+
+	want := []analyzer.PolicyExtension{
+		{Name: "api.audit.Audit.previous", Number: 102, Extendee: "api.audit.AuditResponse", Type: "api.audit.Audit"},
+		{Name: "api.audit.audit", Number: 100, Extendee: "api.audit.AuditResponse", Type: "api.audit.Audit"},
+	}
+	if fmt.Sprint(policy.Extensions) != fmt.Sprint(want) {
+		t.Errorf("Expected the message extensions %+v, got %+v", want, policy.Extensions)
+	}
+}
+
+// TestOptionality tests that optionality follows the protobuf struct tags
+func TestOptionality(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "optionality")
+}
+
+func TestOutputOnly(t *testing.T) {
+	analyzer.Analyzer.Flags.Set("check-all-messages", "true")
+	analyzer.Analyzer.Flags.Set("forbid-output-only", "true")
+	defer analyzer.Analyzer.Flags.Set("check-all-messages", "false")
+	defer analyzer.Analyzer.Flags.Set("forbid-output-only", "false")
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "outputonly")
+}
+
+func TestServiceInterfaces(t *testing.T) {
+	defer analyzer.Analyzer.Flags.Set("service-interfaces", "")
+	for _, name := range []string{"LibraryServiceServer", "api/library/bookpb.LibraryServiceServer"} {
+		t.Run(name, func(t *testing.T) {
+			analyzer.Analyzer.Flags.Set("service-interfaces", name)
+			analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "service")
 		})
 	}
 }
 
-// TestResponseBuilder tests a helper function pattern
-func TestResponseBuilder(t *testing.T) {
-	// Test that a properly built response has all required fields
-	response := buildValidUserResponse("user-123", "John Doe")
-	
-	if response == nil {
-		t.Fatal("buildValidUserResponse returned nil")
+func TestGRPCHandlers(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "handlers")
+
+	analyzer.Analyzer.Flags.Set("grpc-handlers-only", "true")
+	defer analyzer.Analyzer.Flags.Set("grpc-handlers-only", "false")
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "handlersonly")
+}
+
+func TestBuilders(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "builders")
+}
+
+func TestProtoRuntime(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "protoruntime")
+}
+
+func TestOpaqueAPI(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "opaque")
+}
+
+func TestRecursion(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "recursion")
+}
+
+// TestMaxDepth tests that nested literals beyond -max-depth are trusted
+func TestMaxDepth(t *testing.T) {
+	analyzer.Analyzer.Flags.Set("max-depth", "1")
+	defer analyzer.Analyzer.Flags.Set("max-depth", "32")
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "maxdepth")
+}
+
+// TestExperimentalSchemas tests that Thrift and Avro generated code is checked next to protobuf's
+func TestExperimentalSchemas(t *testing.T) {
+	if err := analyzer.Analyzer.Flags.Set("experimental-schemas", "thrift,avro"); err != nil {
+		t.Fatal(err)
 	}
-	
-	if response.User == nil {
-		t.Error("User should not be nil")
+	defer analyzer.Analyzer.Flags.Set("experimental-schemas", "")
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "mixedrpc")
+	if err := analyzer.Analyzer.Flags.Set("experimental-schemas", "capnp"); err == nil {
+		t.Error("Expected an unknown schema to be rejected")
 	}
-	
-	if response.LastLogin == nil {
-		t.Error("LastLogin should not be nil")
+}
+
+// TestExoticForms tests that parenthesized, converted and indexed forms are checked as the plain ones
+func TestExoticForms(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "exotic")
+}
+
+// exoticTemplate is the package FuzzExoticForms checks a statement in
+const exoticTemplate = `package fuzz
+
+import "stubpb"
+
+type Users []*stubpb.User
+
+func f(resp *stubpb.UserResponse, resps []*stubpb.UserResponse, u *stubpb.User) *stubpb.UserResponse {
+	%s
+	return resp
+}
+`
+
+// FuzzExoticForms runs the analyzer on statements in the forms of the exotic package and
+// mutations of them, checking that it doesn't panic on legal Go. Findings aren't
+// checked, and inputs that don't type-check are left to the type checker.
+func FuzzExoticForms(f *testing.F) {
+	for _, stmt := range []string{
+		"(resp).User = nil",
+		"(resp.User) = (nil)",
+		"resp.User = (*stubpb.User)(nil)",
+		"(*stubpb.UserResponse)(resp).User = u",
+		"resps[0].User, (resps[1]).LastLogin = u, nil",
+		"resp.RelatedUsers = Users{u, (nil)}",
+		"r := &stubpb.UserResponse{User: (*stubpb.User)(&stubpb.User{Address: (nil)})}; (r).LastLogin = stubpb.Now(); return (r)",
+		"v := any(resp).(*stubpb.UserResponse); v.User = ((u))",
+	} {
+		f.Add(stmt)
 	}
-	
-	if response.User != nil {
-		if response.User.Address == nil {
-			t.Error("User.Address should not be nil")
+	testdata, err := filepath.Abs(analysistest.TestData())
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Fuzz(func(t *testing.T, stmt string) {
+		dir := t.TempDir()
+		for _, pkg := range []string{"stubpb", "google.golang.org"} {
+			if err := os.MkdirAll(filepath.Join(dir, "src"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Symlink(filepath.Join(testdata, "src", pkg), filepath.Join(dir, "src", pkg)); err != nil {
+				t.Fatal(err)
+			}
 		}
-		
-		if response.User.Address != nil && response.User.Address.Location == nil {
-			t.Error("User.Address.Location should not be nil")
+		if err := os.MkdirAll(filepath.Join(dir, "src", "fuzz"), 0o755); err != nil {
+			t.Fatal(err)
 		}
-		
-		if response.User.CreatedAt == nil {
-			t.Error("User.CreatedAt should not be nil")
+		src := fmt.Sprintf(exoticTemplate, stmt)
+		if err := os.WriteFile(filepath.Join(dir, "src", "fuzz", "fuzz.go"), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
 		}
-		
-		if response.User.ContactInfo == nil {
-			t.Error("User.ContactInfo should not be nil")
+		analysistest.Run(ignoredErrors{}, dir, analyzer.Analyzer, "fuzz")
+	})
+}
+
+// ignoredErrors is an analysistest.Testing that drops the findings and load errors
+// analysistest reports, leaving panics to fail the test
+type ignoredErrors struct{}
+
+func (ignoredErrors) Errorf(format string, args ...interface{}) {}
+
+// TestColocatedGenerated tests that a package holding generated protobuf files next to
+// its handlers is checked, except for the generated files
+func TestColocatedGenerated(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "colocated")
+}
+
+// TestCheckGenerated tests that -check-generated checks generated files too
+func TestCheckGenerated(t *testing.T) {
+	analyzer.Analyzer.Flags.Set("check-generated", "true")
+	defer analyzer.Analyzer.Flags.Set("check-generated", "false")
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "checkgenerated")
+}
+
+// TestSyntheticOneofs tests that fields the descriptor puts in a synthetic oneof are
+// optional whatever their struct tags say
+func TestSyntheticOneofs(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "syntheticoneof")
+}
+
+// TestExclude tests that packages and files matching -exclude are skipped
+func TestExclude(t *testing.T) {
+	analyzer.Analyzer.Flags.Set("exclude", "exclude/testutil/...,**/*_fixture.go")
+	defer analyzer.Analyzer.Flags.Set("exclude", "")
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "exclude", "exclude/testutil")
+}
+
+func TestSkipTests(t *testing.T) {
+	analyzer.Analyzer.Flags.Set("skip-tests", "true")
+	defer analyzer.Analyzer.Flags.Set("skip-tests", "false")
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "skiptests")
+}
+
+func TestTestsStrict(t *testing.T) {
+	analyzer.Analyzer.Flags.Set("tests-strict", "true")
+	defer analyzer.Analyzer.Flags.Set("tests-strict", "false")
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "teststrict")
+}
+
+func TestCoverage(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "coverage")
+	got := results[0].Result.(*analyzer.Result).Stats.Coverage
+	want := analyzer.Coverage{Verified: 2, Trusted: 3, Suppressed: 1}
+	if got != want {
+		t.Errorf("Got coverage %+v, want %+v", got, want)
+	}
+	if got.Sites() != 6 {
+		t.Errorf("Got %d sites, want 6", got.Sites())
+	}
+}
+
+// TestServiceRoots tests that -service-roots classifies messages by the services using them
+func TestServiceRoots(t *testing.T) {
+	if err := analyzer.Analyzer.Flags.Set("service-roots", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer analyzer.Analyzer.Flags.Set("service-roots", "false")
+
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "serviceroots")
+}
+
+// TestOptionalUsage tests -optional-usage and its fixes calling the generated Has and Clear methods
+func TestOptionalUsage(t *testing.T) {
+	if err := analyzer.Analyzer.Flags.Set("optional-usage", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer analyzer.Analyzer.Flags.Set("optional-usage", "false")
+
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), analyzer.Analyzer, "optionalusage")
+}
+
+// TestTypeFilters tests that -include-types and -exclude-types select the messages checked
+func TestTypeFilters(t *testing.T) {
+	for flag, value := range map[string]string{"include-types": "api.v1.*,stubpb.*", "exclude-types": "**.UserResponse"} {
+		if err := analyzer.Analyzer.Flags.Set(flag, value); err != nil {
+			t.Fatal(err)
 		}
 	}
+	defer analyzer.Analyzer.Flags.Set("include-types", "")
+	defer analyzer.Analyzer.Flags.Set("exclude-types", "")
+
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "typefilter")
 }
 
-// buildValidUserResponse is a helper that creates a valid response
-// This demonstrates the proper pattern for creating response messages
-func buildValidUserResponse(userID, name string) *examplev1.UserResponse {
-	return &examplev1.UserResponse{
-		User: &examplev1.User{
-			Id:   userID,
-			Name: name,
-			Address: &examplev1.Address{
-				Street:     "123 Main St",
-				City:       "New York",
-				PostalCode: "10001",
-				Location: &examplev1.Location{
-					Latitude:  40.7128,
-					Longitude: -74.0060,
-				},
-			},
-			CreatedAt: timestamppb.Now(),
-			ContactInfo: &examplev1.ContactInfo{
-				Email: "user@example.com",
-				Phone: "555-1234",
-			},
-		},
-		LastLogin:    timestamppb.Now(),
-		RelatedUsers: []*examplev1.User{}, // Empty slice is OK
-	}
-}
-
-// TestListResponse tests list response messages
-func TestListResponse(t *testing.T) {
-	response := &examplev1.ListUsersResponse{
-		Users: []*examplev1.User{
-			{
-				Id:   "user-1",
-				Name: "User One",
-				Address: &examplev1.Address{
-					Street:     "123 Main St",
-					City:       "NYC",
-					PostalCode: "10001",
-					Location: &examplev1.Location{
-						Latitude:  40.7128,
-						Longitude: -74.0060,
-					},
-				},
-				CreatedAt: timestamppb.Now(),
-				ContactInfo: &examplev1.ContactInfo{
-					Email: "user1@example.com",
-					Phone: "555-0001",
-				},
-			},
-		},
-		FetchedAt: timestamppb.Now(),
+func TestOptionalFields(t *testing.T) {
+	if err := analyzer.Analyzer.Flags.Set("optional-fields", "UserResponse.LastLogin,*.Location"); err != nil {
+		t.Fatal(err)
 	}
-	
-	if response == nil {
-		t.Fatal("Response should not be nil")
+	defer analyzer.Analyzer.Flags.Set("optional-fields", "")
+
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "optionalfields")
+
+	for _, value := range []string{"Manager", "UserResponse.*"} {
+		if err := analyzer.Analyzer.Flags.Set("optional-fields", value); err == nil {
+			t.Errorf("-optional-fields=%s: want an error", value)
+		}
 	}
-	
-	if response.Users == nil {
-		t.Error("Users slice should not be nil (can be empty)")
+}
+
+func TestRequiredOption(t *testing.T) {
+	defer analyzer.Analyzer.Flags.Set("required-option", "")
+	for _, value := range []string{"api.annotations.required", "(api.annotations.required)", "50001"} {
+		t.Run(value, func(t *testing.T) {
+			if err := analyzer.Analyzer.Flags.Set("required-option", value); err != nil {
+				t.Fatal(err)
+			}
+			analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "requiredoption")
+		})
 	}
-	
-	if response.FetchedAt == nil {
-		t.Error("FetchedAt timestamp should not be nil")
+}
+
+func TestConnectHandlers(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "connecthandlers")
+}
+
+func TestOptionalTypes(t *testing.T) {
+	defer analyzer.Analyzer.Flags.Set("optional-types", "")
+	for _, name := range []string{"TraceInfo", "optionaltypes/debugpb.TraceInfo"} {
+		t.Run(name, func(t *testing.T) {
+			analyzer.Analyzer.Flags.Set("optional-types", name)
+			analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "optionaltypes")
+		})
 	}
-	
-	if len(response.Users) > 0 {
-		user := response.Users[0]
-		if user.Address == nil {
-			t.Error("User in list should have non-nil Address")
+}
+
+func TestGenericWrappers(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "generics")
+}
+
+func TestExclusiveFields(t *testing.T) {
+	analyzer.Analyzer.Flags.Set("exclusive-fields", "User/Error")
+	defer analyzer.Analyzer.Flags.Set("exclusive-fields", "")
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "exclusive")
+}
+
+func TestHelperReturns(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "helpers")
+}
+
+// The driver under go vet prints diagnostics and records facts in the order the
+// analyzer produces them; baselines and golden files rely on that order being the
+// same on every run
+func TestDeterministicDiagnostics(t *testing.T) {
+	pkgs := []string{"loops", "earlyreturn", "options", "helpers", "shared"}
+	diagnostics := func() []string {
+		var out []string
+		for _, r := range analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, pkgs...) {
+			for _, d := range r.Diagnostics {
+				out = append(out, fmt.Sprintf("%v: %s", r.Pass.Fset.Position(d.Pos), d.Message))
+			}
+			// Facts come back in a map; the driver encodes them sorted
+			var facts []string
+			for obj, objFacts := range r.Facts {
+				for _, fact := range objFacts {
+					facts = append(facts, fmt.Sprintf("%v: %v", r.Pass.Fset.Position(obj.Pos()), fact))
+				}
+			}
+			sort.Strings(facts)
+			out = append(out, facts...)
 		}
-		if user.CreatedAt == nil {
-			t.Error("User in list should have non-nil CreatedAt")
+		return out
+	}
+
+	want := diagnostics()
+	for i := 0; i < 5; i++ {
+		if got := diagnostics(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Fatalf("Run %d differs:\n%s\nfirst run:\n%s", i+2, strings.Join(got, "\n"), strings.Join(want, "\n"))
 		}
 	}
 }
 
-// TestScalarFields tests that scalar fields can have zero values
-func TestScalarFields(t *testing.T) {
-	// Scalar fields can be empty/zero - the linter should not complain
-	user := &examplev1.User{
-		Id:   "", // Empty string is OK for scalar
-		Name: "", // Empty string is OK for scalar
-		Address: &examplev1.Address{
-			Street:     "", // Empty string is OK
-			City:       "", // Empty string is OK
-			PostalCode: "", // Empty string is OK
-			Location: &examplev1.Location{
-				Latitude:  0.0, // Zero is OK for scalar
-				Longitude: 0.0, // Zero is OK for scalar
-			},
-		},
-		CreatedAt: timestamppb.Now(), // But message types must be non-nil
-		ContactInfo: &examplev1.ContactInfo{
-			Email: "",
-			Phone: "",
-		},
+func TestNilProvenance(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "provenance")
+}
+
+func TestHelperReturnsFromSkippedPackage(t *testing.T) {
+	analyzer.Analyzer.Flags.Set("check-all-messages", "true")
+	analyzer.Analyzer.Flags.Set("include-packages", "factoryclient")
+	defer analyzer.Analyzer.Flags.Set("check-all-messages", "false")
+	defer analyzer.Analyzer.Flags.Set("include-packages", "")
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "factoryclient")
+}
+
+func TestReassignedVariables(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "reassign")
+}
+
+func TestNilChecks(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "nilness")
+}
+
+func TestSetters(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "setters")
+}
+
+func TestExtensions(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "extensions")
+}
+
+func TestGetters(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "getters")
+}
+
+func TestMapFields(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "mapfields")
+
+	analyzer.Analyzer.Flags.Set("require-map-entries", "true")
+	defer analyzer.Analyzer.Flags.Set("require-map-entries", "false")
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "requiredmaps")
+}
+
+// Drivers analyze the files cgo writes. Findings map back to the original through its
+// //line directives, but fixes would edit cgo's copy, so none are offered.
+func TestCgo(t *testing.T) {
+	if !build.Default.CgoEnabled {
+		t.Skip("cgo is not enabled")
 	}
-	
-	if user == nil {
-		t.Fatal("User should not be nil")
+	results := analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "cgopkg")
+	for _, r := range results {
+		for _, d := range r.Diagnostics {
+			if len(d.SuggestedFixes) > 0 {
+				t.Errorf("Expected no suggested fixes in a cgo file, got %q at %s", d.SuggestedFixes[0].Message, r.Pass.Fset.Position(d.Pos))
+			}
+		}
 	}
-	
-	// The important thing is that message fields are non-nil
-	if user.Address == nil {
-		t.Error("Address message field must be non-nil even if scalars are empty")
+}
+
+func TestRepeatedFields(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "repeated")
+}
+
+func TestConverters(t *testing.T) {
+	analyzer.Analyzer.Flags.Set("check-converters", "true")
+	defer analyzer.Analyzer.Flags.Set("check-converters", "false")
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "converters")
+}
+
+func TestNilNilReturns(t *testing.T) {
+	analyzer.Analyzer.Flags.Set("check-nil-nil-returns", "true")
+	defer analyzer.Analyzer.Flags.Set("check-nil-nil-returns", "false")
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "nilnilreturns")
+}
+
+func TestStubResponses(t *testing.T) {
+	analyzer.Analyzer.Flags.Set("check-stub-responses", "true")
+	defer analyzer.Analyzer.Flags.Set("check-stub-responses", "false")
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "stubresponses")
+}
+
+func TestTableEntries(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "tables")
+}
+
+func TestOneofs(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "oneofs")
+
+	analyzer.Analyzer.Flags.Set("require-oneofs", "true")
+	defer analyzer.Analyzer.Flags.Set("require-oneofs", "false")
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "requiredoneofs")
+}
+
+func TestPartialResponses(t *testing.T) {
+	analyzer.Analyzer.Flags.Set("partial-responses", "with-error")
+	defer analyzer.Analyzer.Flags.Set("partial-responses", "never")
+	analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "partial")
+}
+
+// timestampTestData is the GOPATH of the timestamp fix tests, with a stand-in for
+// timestamppb that analysistest can load
+func timestampTestData() string {
+	return filepath.Join(analysistest.TestData(), "timestamp")
+}
+
+func TestTimestampFixes(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, timestampTestData(), analyzer.Analyzer, "timestampfix")
+
+	analyzer.Analyzer.Flags.Set("fix-timestamp-expr", "timestamppb.New(time.Time{})")
+	defer analyzer.Analyzer.Flags.Set("fix-timestamp-expr", "timestamppb.Now()")
+	analysistest.RunWithSuggestedFixes(t, timestampTestData(), analyzer.Analyzer, "timestampexpr")
+}
+
+func TestAnalysisBudget(t *testing.T) {
+	analyzer.Analyzer.Flags.Set("analysis-budget", "60")
+	defer analyzer.Analyzer.Flags.Set("analysis-budget", "20000")
+	results := analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "budget")
+	stats := results[0].Result.(*analyzer.Result).Stats
+	if stats.BudgetExceeded != 2 {
+		t.Errorf("Expected the budget to be exceeded in 2 functions, got %d", stats.BudgetExceeded)
 	}
-	
-	if user.CreatedAt == nil {
-		t.Error("CreatedAt message field must be non-nil")
+	shallow := append([]string(nil), stats.ShallowFunctions...)
+	sort.Strings(shallow)
+	if got := strings.Join(shallow, ", "); got != "*server.List, large" {
+		t.Errorf("Expected shallow functions *server.List, large, got %s", got)
 	}
-	
-	if user.ContactInfo == nil {
-		t.Error("ContactInfo message field must be non-nil")
+}
+
+// Every suggested fix belongs to a fix rule, so -autofix-rules can decide on it
+func TestFixRules(t *testing.T) {
+	analyzer.Analyzer.Flags.Set("forbid-message-copy", "true")
+	defer analyzer.Analyzer.Flags.Set("forbid-message-copy", "false")
+	analyzer.Analyzer.Flags.Set("optional-usage", "true")
+	defer analyzer.Analyzer.Flags.Set("optional-usage", "false")
+
+	rules := make(map[string]bool)
+	results := analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "fixes", "messagecopy", "optionalusage")
+	results = append(results, analysistest.Run(t, timestampTestData(), analyzer.Analyzer, "timestampfix")...)
+	for _, r := range results {
+		for _, d := range r.Diagnostics {
+			for _, fix := range d.SuggestedFixes {
+				rule := analyzer.FixRule(fix)
+				if rule == "" {
+					t.Errorf("Fix %q has no rule", fix.Message)
+				}
+				rules[rule] = true
+			}
+		}
+	}
+	for _, rule := range []string{analyzer.FixEmptyMessage, analyzer.FixTimestamp, analyzer.FixProtoClone, analyzer.FixOptionalUsage} {
+		if !rules[rule] {
+			t.Errorf("Expected a fix for rule %s", rule)
+		}
+	}
+}
+
+// TestKinds tests that the core diagnostics and the zero-value rule's are reported with their violation kind as the category
+func TestKinds(t *testing.T) {
+	want := map[string]string{
+		"nilLiteral":    analyzer.KindNilLiteral,
+		"nilConversion": analyzer.KindNilLiteral,
+		"nilVariable":   analyzer.KindNilVariable,
+		"nilZeroValue":  analyzer.KindNilVariable,
+		"missingField":  analyzer.KindMissingField,
+		"nestedNil":     analyzer.KindNestedNil,
+		"nestedMissing": analyzer.KindNestedNil,
+
+		"zeroValueLiteral": analyzer.KindZeroValueMessage,
+		"zeroValueNew":     analyzer.KindZeroValueMessage,
 	}
-}
\ No newline at end of file
+	results := analysistest.Run(t, analysistest.TestData(), analyzer.Analyzer, "kinds")
+	for _, result := range results {
+		for _, diag := range result.Diagnostics {
+			var fn string
+			for _, file := range result.Pass.Files {
+				for _, decl := range file.Decls {
+					if decl.Pos() <= diag.Pos && diag.Pos < decl.End() {
+						fn = decl.(*ast.FuncDecl).Name.Name
+					}
+				}
+			}
+			if diag.Category != want[fn] || analyzer.Kind(diag) != want[fn] {
+				t.Errorf("Expected kind %q for %q in %s, got %q", want[fn], diag.Message, fn, diag.Category)
+			}
+		}
+	}
+	if got := analyzer.Kind(analysis.Diagnostic{Category: "map-lookup", Message: "advisory: map lookup assigned"}); got != "map-lookup" {
+		t.Errorf("Expected the category to be the kind, got %q", got)
+	}
+}
+
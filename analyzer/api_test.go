@@ -0,0 +1,170 @@
+package analyzer_test
+
+import (
+	"flag"
+	"fmt"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+var updateAPI = flag.Bool("update-api", false, "record additions to the exported API in testdata/api.txt")
+
+// apiPackages are the packages whose exported API integrators build on; see doc.go
+var apiPackages = []string{
+	"github.com/nickheyer/go_no_nil_linter/analyzer",
+	"github.com/nickheyer/go_no_nil_linter/driver",
+	"github.com/nickheyer/go_no_nil_linter/passes/protodeprecated",
+	"github.com/nickheyer/go_no_nil_linter/passes/protooneof",
+}
+
+// TestAPI compares the exported API with testdata/api.txt. Removing or changing a
+// recorded feature breaks integrators and fails; additions fail until they are recorded
+// with go test -run TestAPI -update-api.
+func TestAPI(t *testing.T) {
+	if testing.Short() {
+		t.Skip("loads the packages from source")
+	}
+	// Dependencies are type-checked from source too, as analysistest does, rather than
+	// read from export data the toolchain may have written in a newer format
+	mode := packages.NeedName | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps
+	pkgs, err := packages.Load(&packages.Config{Mode: mode}, apiPackages...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		t.Fatal("Failed to load the API packages")
+	}
+	current := make(map[string]bool)
+	for _, pkg := range pkgs {
+		for _, feature := range apiFeatures(pkg.Types) {
+			current[feature] = true
+		}
+	}
+
+	golden := filepath.Join("testdata", "api.txt")
+	data, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" && !strings.HasPrefix(line, "#") {
+			recorded[line] = true
+		}
+	}
+
+	var removed, added []string
+	for feature := range recorded {
+		if !current[feature] {
+			removed = append(removed, feature)
+		}
+	}
+	for feature := range current {
+		if !recorded[feature] {
+			added = append(added, feature)
+		}
+	}
+	sort.Strings(removed)
+	sort.Strings(added)
+	for _, feature := range removed {
+		t.Errorf("Incompatible API change, %s is gone or changed", feature)
+	}
+	if len(added) == 0 {
+		return
+	}
+	if !*updateAPI {
+		for _, feature := range added {
+			t.Errorf("Unrecorded API addition %s; run go test -run TestAPI -update-api", feature)
+		}
+		return
+	}
+	if len(removed) > 0 {
+		t.Fatal("Not recording additions while recorded features are missing")
+	}
+	f, err := os.OpenFile(golden, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for _, feature := range added {
+		fmt.Fprintln(f, feature)
+	}
+}
+
+// apiFeatures lists the exported API of a package one feature per line, in the format
+// of the Go distribution's api files: each constant, variable, function, type, exported
+// struct field and exported method is a feature
+func apiFeatures(pkg *types.Package) []string {
+	prefix := "pkg " + pkg.Path() + ", "
+	qualifier := func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
+		return p.Path()
+	}
+	typeString := func(t types.Type) string { return types.TypeString(t, qualifier) }
+	signature := func(sig *types.Signature) string {
+		return strings.TrimPrefix(types.TypeString(sig, qualifier), "func")
+	}
+
+	var features []string
+	add := func(format string, args ...any) {
+		features = append(features, prefix+fmt.Sprintf(format, args...))
+	}
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+		switch obj := obj.(type) {
+		case *types.Const:
+			add("const %s %s = %s", name, typeString(obj.Type()), obj.Val().ExactString())
+		case *types.Var:
+			add("var %s %s", name, typeString(obj.Type()))
+		case *types.Func:
+			add("func %s%s", name, signature(obj.Type().(*types.Signature)))
+		case *types.TypeName:
+			switch u := obj.Type().Underlying().(type) {
+			case *types.Struct:
+				add("type %s struct", name)
+				for i := 0; i < u.NumFields(); i++ {
+					if field := u.Field(i); field.Exported() {
+						add("type %s struct, %s %s", name, field.Name(), typeString(field.Type()))
+					}
+				}
+			case *types.Interface:
+				add("type %s interface", name)
+				for i := 0; i < u.NumMethods(); i++ {
+					if m := u.Method(i); m.Exported() {
+						add("type %s interface, %s%s", name, m.Name(), signature(m.Type().(*types.Signature)))
+					}
+				}
+			default:
+				add("type %s %s", name, typeString(u))
+			}
+			if types.IsInterface(obj.Type()) {
+				continue
+			}
+			for _, t := range []types.Type{obj.Type(), types.NewPointer(obj.Type())} {
+				methods := types.NewMethodSet(t)
+				for i := 0; i < methods.Len(); i++ {
+					m := methods.At(i).Obj()
+					if m.Exported() && methods.At(i).Kind() == types.MethodVal && len(methods.At(i).Index()) == 1 {
+						if _, isPtr := t.(*types.Pointer); isPtr && types.NewMethodSet(obj.Type()).Lookup(m.Pkg(), m.Name()) != nil {
+							continue
+						}
+						add("method (%s) %s%s", typeString(t), m.Name(), signature(m.Type().(*types.Signature)))
+					}
+				}
+			}
+		}
+	}
+	return features
+}
@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Fix rules name the kinds of suggested fixes the analyzer offers, for -autofix-rules
+const (
+	// FixEmptyMessage adds an uninitialized field set to an empty message
+	FixEmptyMessage = "empty-message"
+
+	// FixTimestamp sets a nil or uninitialized Timestamp field to -fix-timestamp-expr
+	FixTimestamp = "timestamp"
+
+	// FixProtoClone replaces a message copied by value with proto.Clone
+	FixProtoClone = "proto-clone"
+
+	// FixOptionalUsage replaces a nil assignment or comparison of an optional field
+	// with its generated Clear or Has method
+	FixOptionalUsage = "optional-usage"
+)
+
+// fixRules are the fix rules, in the order they are listed in flag help
+var fixRules = []string{FixEmptyMessage, FixTimestamp, FixProtoClone, FixOptionalUsage}
+
+// Suggested fix messages, from which FixRule tells the rule of a fix
+const (
+	emptyMessageFixFormat  = "Initialize '%s' with an empty message"
+	timestampFieldFormat   = "Initialize '%s' with %s"
+	timestampNilFormat     = "Replace nil with %s"
+	protoCloneFixMessage   = "Replace the copy with proto.Clone"
+	optionalUsageFixFormat = "Call the generated %s method"
+)
+
+// autofixRules is the set of fix rules that nonillinter -fix applies, set via
+// -autofix-rules. Inserted empty messages can hide logic bugs, and proto.Clone turns
+// the copy into a pointer that later uses may need to account for, so by default only
+// timestamps are applied.
+var autofixRules = fixRulesFlag{FixTimestamp: true}
+
+func init() {
+	Analyzer.Flags.Var(&autofixRules, "autofix-rules",
+		fmt.Sprintf("comma-separated fix rules that nonillinter -fix applies (%s), 'all' or 'none'; the others are still offered in editors", strings.Join(fixRules, ", ")))
+}
+
+// fixRulesFlag is a flag.Value holding a set of fix rules
+type fixRulesFlag map[string]bool
+
+func (f *fixRulesFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	var rules []string
+	for _, rule := range fixRules {
+		if (*f)[rule] {
+			rules = append(rules, rule)
+		}
+	}
+	return strings.Join(rules, ",")
+}
+
+func (f *fixRulesFlag) Set(value string) error {
+	rules := make(fixRulesFlag)
+	for _, rule := range splitPatterns(value) {
+		switch {
+		case rule == "all":
+			for _, r := range fixRules {
+				rules[r] = true
+			}
+		case rule == "none":
+		case containsString(fixRules, rule):
+			rules[rule] = true
+		default:
+			return fmt.Errorf("unknown fix rule %q; expected one of %s, all or none", rule, strings.Join(fixRules, ", "))
+		}
+	}
+	*f = rules
+	return nil
+}
+
+// FixRule returns the fix rule of a suggested fix offered by the analyzer, or "" for
+// a fix it doesn't know
+func FixRule(fix analysis.SuggestedFix) string {
+	switch {
+	case fix.Message == protoCloneFixMessage:
+		return FixProtoClone
+	case strings.HasPrefix(fix.Message, "Call the generated ") && strings.HasSuffix(fix.Message, " method"):
+		return FixOptionalUsage
+	case strings.HasPrefix(fix.Message, "Initialize '") && strings.HasSuffix(fix.Message, "' with an empty message"):
+		return FixEmptyMessage
+	case strings.HasPrefix(fix.Message, "Initialize '"), strings.HasPrefix(fix.Message, "Replace nil with "):
+		return FixTimestamp
+	}
+	return ""
+}
+
+// AutofixEnabled reports whether -autofix-rules lets nonillinter -fix apply a suggested
+// fix. Fixes that aren't applied are still worth offering where a person reviews each
+// one, as in an editor.
+func AutofixEnabled(fix analysis.SuggestedFix) bool {
+	return autofixRules[FixRule(fix)]
+}
+
+// containsString checks if list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
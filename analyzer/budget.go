@@ -0,0 +1,175 @@
+package analyzer
+
+import (
+	"errors"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// defaultNodeBudget is the -analysis-budget of functions when none is set. The deep
+// analysis of a function grows faster than its size, and a generated or hand-rolled
+// function of thousands of lines can hold up a whole package. Functions rarely come
+// near it otherwise, and a number of nodes, unlike a duration, gives the same findings
+// on every machine.
+const defaultNodeBudget = 20000
+
+// budgetFlag is the -analysis-budget flag.Value: a duration such as "50ms", or a
+// number of syntax nodes such as "5000". The zero value is no budget.
+type budgetFlag struct {
+	duration time.Duration
+	nodes    int
+}
+
+func (f *budgetFlag) String() string {
+	switch {
+	case f == nil:
+		return ""
+	case f.duration > 0:
+		return f.duration.String()
+	case f.nodes > 0:
+		return strconv.Itoa(f.nodes)
+	}
+	return ""
+}
+
+func (f *budgetFlag) Set(value string) error {
+	*f = budgetFlag{}
+	if value == "" || value == "0" {
+		return nil
+	}
+	if nodes, err := strconv.Atoi(value); err == nil {
+		if nodes < 0 {
+			return errors.New("node budget must not be negative")
+		}
+		f.nodes = nodes
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return errors.New("expected a duration such as 50ms or a number of syntax nodes such as 5000")
+	}
+	if d < 0 {
+		return errors.New("duration budget must not be negative")
+	}
+	f.duration = d
+	return nil
+}
+
+// funcBudget is what a function has spent of -analysis-budget on deep analysis
+type funcBudget struct {
+	// nodes is the size of the function declaration, including its function literals
+	nodes    int
+	spent    time.Duration
+	exceeded bool
+}
+
+// packageBudget holds the budgets of the functions of a package, keyed by their
+// declaration; function literals share the budget of the declaration they are in
+type packageBudget struct {
+	mu     sync.Mutex
+	funcs  map[*ast.FuncDecl]*funcBudget
+	result *Result
+}
+
+// packageBudgets holds the budgets of the packages being analyzed, keyed by
+// *types.Package; run drops the entry when it finishes
+var packageBudgets sync.Map
+
+// trackBudgets starts accounting -analysis-budget for the package, counting the
+// functions that exceed it in result.Stats
+func trackBudgets(pass *analysis.Pass, result *Result) {
+	packageBudgets.Store(pass.Pkg, &packageBudget{funcs: make(map[*ast.FuncDecl]*funcBudget), result: result})
+}
+
+// withinBudget runs a deep analysis step for the function containing pos: flow-sensitive
+// tracking, SSA data flow or interprocedural summaries. It isn't run, and withinBudget
+// returns false, when the function is larger than a node budget or its steps have
+// taken longer than a duration budget; callers fall back to their shallow checks.
+// A step that starts within budget always runs to completion.
+func withinBudget(pos token.Pos, pass *analysis.Pass, step func()) bool {
+	if analysisBudget.nodes == 0 && analysisBudget.duration == 0 {
+		step()
+		return true
+	}
+	v, ok := packageBudgets.Load(pass.Pkg)
+	decl := enclosingFuncDecl(pos, pass)
+	if !ok || decl == nil {
+		step()
+		return true
+	}
+	pb := v.(*packageBudget)
+
+	pb.mu.Lock()
+	b := pb.funcs[decl]
+	if b == nil {
+		b = &funcBudget{nodes: countNodes(decl)}
+		pb.funcs[decl] = b
+		if analysisBudget.nodes > 0 && b.nodes > analysisBudget.nodes {
+			b.exceeded = true
+			pb.exceeded(decl, b, pass)
+		}
+	}
+	exceeded := b.exceeded
+	pb.mu.Unlock()
+	if exceeded {
+		return false
+	}
+
+	start := time.Now()
+	step()
+	elapsed := time.Since(start)
+
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	b.spent += elapsed
+	if analysisBudget.duration > 0 && b.spent > analysisBudget.duration && !b.exceeded {
+		b.exceeded = true
+		pb.exceeded(decl, b, pass)
+	}
+	return true
+}
+
+// exceeded records that a function ran out of budget; pb.mu is held
+func (pb *packageBudget) exceeded(decl *ast.FuncDecl, b *funcBudget, pass *analysis.Pass) {
+	pb.result.Stats.BudgetExceeded++
+	name := decl.Name.Name
+	if decl.Recv != nil && len(decl.Recv.List) == 1 {
+		name = types.ExprString(decl.Recv.List[0].Type) + "." + name
+	}
+	pb.result.Stats.ShallowFunctions = append(pb.result.Stats.ShallowFunctions, name)
+	log().Info("analysis budget exceeded, falling back to shallow checks",
+		"function", name, "pos", pass.Fset.Position(decl.Pos()),
+		"nodes", b.nodes, "spent", b.spent.Round(time.Microsecond), "budget", analysisBudget.String())
+}
+
+// enclosingFuncDecl returns the function declaration containing pos, or nil
+func enclosingFuncDecl(pos token.Pos, pass *analysis.Pass) *ast.FuncDecl {
+	file := fileAt(pos, pass)
+	if file == nil {
+		return nil
+	}
+	for _, d := range file.Decls {
+		if fn, ok := d.(*ast.FuncDecl); ok && fn.Body != nil && fn.Pos() <= pos && pos < fn.End() {
+			return fn
+		}
+	}
+	return nil
+}
+
+// countNodes returns the number of syntax nodes in n
+func countNodes(n ast.Node) int {
+	count := 0
+	ast.Inspect(n, func(n ast.Node) bool {
+		if n != nil {
+			count++
+		}
+		return true
+	})
+	return count
+}
@@ -0,0 +1,244 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// Handlers often leave building their response to a helper:
+//
+//	func (s *server) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.UserResponse, error) {
+//		resp, err := s.buildResponse(ctx, req)
+//		if err != nil {
+//			return nil, err
+//		}
+//		resp.LastLogin = timestamppb.Now()
+//		return resp, err
+//	}
+//
+// Checked on its own, buildResponse would be reported for LastLogin, which the handler
+// sets. A builder, an unexported helper returning a response and an error whose every
+// call is bound as above and returned by the caller, isn't checked for missing fields
+// at its returns. Its summary is checked where each caller returns the result instead,
+// counting the fields the caller sets on the way.
+
+// packageBuilders maps each package being analyzed to its builders and their summaries
+var packageBuilders sync.Map // *types.Package -> map[*types.Func]*returnSummary
+
+// findBuilders records the package's builders. summarize returns the summary of a
+// function's returns; builders need a known one to be checked at their callers.
+func findBuilders(objs []*types.Func, summarize func(*types.Func) *returnSummary, pass *analysis.Pass) {
+	builders := make(map[*types.Func]*returnSummary)
+	for _, obj := range objs {
+		results := obj.Type().(*types.Signature).Results()
+		if obj.Exported() || results.Len() != 2 || !types.Identical(results.At(1).Type(), errorType) || !shouldCheckType(results.At(0).Type()) {
+			continue
+		}
+		if s := summarize(obj); s.known {
+			builders[obj] = s
+		}
+	}
+	if len(builders) == 0 {
+		return
+	}
+
+	// The calls whose result the caller binds and returns
+	returned := make(map[*ast.Ident]bool)
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			var body *ast.BlockStmt
+			switch fn := n.(type) {
+			case *ast.FuncDecl:
+				body = fn.Body
+			case *ast.FuncLit:
+				body = fn.Body
+			}
+			if body == nil {
+				return true
+			}
+			bound := helperResults(body, pass)
+			inspectFunctionBody(body, func(n ast.Node) {
+				ret, ok := n.(*ast.ReturnStmt)
+				if !ok {
+					return
+				}
+				for _, result := range ret.Results {
+					id, ok := unwrapResponse(result, pass).(*ast.Ident)
+					if !ok {
+						continue
+					}
+					if b := bound[pass.TypesInfo.ObjectOf(id)]; b != nil {
+						returned[calleeIdent(b.call)] = true
+					}
+				}
+			})
+			return true
+		})
+	}
+
+	// Any other use, such as return s.buildResponse(ctx, req), leaves the helper's
+	// returns to be checked where they are, as do helpers nothing calls
+	called := make(map[*types.Func]bool)
+	for id, obj := range pass.TypesInfo.Uses {
+		fn, ok := obj.(*types.Func)
+		if !ok || builders[fn] == nil {
+			continue
+		}
+		if returned[id] {
+			called[fn] = true
+		} else {
+			delete(builders, fn)
+		}
+	}
+	for fn := range builders {
+		if !called[fn] {
+			delete(builders, fn)
+		}
+	}
+	if len(builders) > 0 {
+		packageBuilders.Store(pass.Pkg, builders)
+	}
+}
+
+// calleeIdent returns the name a call refers to its function by
+func calleeIdent(call *ast.CallExpr) *ast.Ident {
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		return fun
+	case *ast.SelectorExpr:
+		return fun.Sel
+	}
+	return nil
+}
+
+// builderSummary returns the summary of fn when it is one of the package's builders,
+// or nil
+func builderSummary(fn *types.Func, pass *analysis.Pass) *returnSummary {
+	builders, ok := packageBuilders.Load(pass.Pkg)
+	if !ok {
+		return nil
+	}
+	return builders.(map[*types.Func]*returnSummary)[fn]
+}
+
+// returnsToCaller checks if ret is a return of a builder, whose response is checked
+// where its callers return it
+func returnsToCaller(ret *ast.ReturnStmt, pass *analysis.Pass) bool {
+	if _, ok := packageBuilders.Load(pass.Pkg); !ok {
+		return false
+	}
+	file := fileAt(ret.Pos(), pass)
+	if file == nil {
+		return false
+	}
+	path, _ := astutil.PathEnclosingInterval(file, ret.Pos(), ret.End())
+	for _, n := range path {
+		switch fn := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.FuncDecl:
+			obj, ok := pass.TypesInfo.Defs[fn.Name].(*types.Func)
+			return ok && builderSummary(obj, pass) != nil
+		}
+	}
+	return false
+}
+
+// checkBuilderResults reports the required fields left unset in the responses a
+// function returns from a builder: those the builder leaves unset on some path that
+// aren't set on the way to the return
+func checkBuilderResults(body *ast.BlockStmt, pass *analysis.Pass) {
+	if _, ok := packageBuilders.Load(pass.Pkg); !ok {
+		return
+	}
+	bound := helperResults(body, pass)
+	if len(bound) == 0 {
+		return
+	}
+
+	var stack []ast.Node
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		stack = append(stack, n)
+
+		ret, ok := n.(*ast.ReturnStmt)
+		if !ok || allowsPartialResponse(ret, pass) {
+			return true
+		}
+		for _, result := range ret.Results {
+			id, ok := unwrapResponse(result, pass).(*ast.Ident)
+			if !ok {
+				continue
+			}
+			obj := pass.TypesInfo.ObjectOf(id)
+			b := bound[obj]
+			if b == nil {
+				continue
+			}
+			fn := typeutil.StaticCallee(pass.TypesInfo, b.call)
+			if s := builderSummary(fn, pass); s != nil {
+				assigned, conditional := assignedFieldsOnPath(stack, obj, pass)
+				reportBuiltFields(fn, s, assigned, conditional, id, pass)
+			}
+		}
+		return true
+	})
+}
+
+// reportBuiltFields reports the required fields of a builder's response that neither
+// the builder sets on every path nor the caller in assigned, at id
+func reportBuiltFields(fn *types.Func, s *returnSummary, assigned map[string]bool, conditional map[string]string, id *ast.Ident, pass *analysis.Pass) {
+	msgType := fn.Type().(*types.Signature).Results().At(0).Type()
+	initialized := make(map[string]bool)
+	for _, name := range s.initialized {
+		initialized[name] = true
+	}
+	unset := make(map[string]bool)
+	for _, name := range s.unset {
+		unset[name] = true
+	}
+
+	structType := getStructType(msgType)
+	if structType == nil {
+		return
+	}
+	msg := msgType
+	if ptr, ok := msgType.(*types.Pointer); ok {
+		msg = ptr.Elem()
+	}
+	for _, field := range requiredFields(structType, msgType, isRequestMessage(msgType)) {
+		name := field.Name()
+		if initialized[name] || assigned[name] {
+			continue
+		}
+		var message string
+		switch guard, ok := conditional[name]; {
+		case ok:
+			message = fmt.Sprintf("non-optional message field '%s' may be uninitialized on some paths in protobuf message %s built by %s(): it is only set when %s%s",
+				name, describeType(pass, msg), fn.Name(), guard, gatewayNote(msgType, name))
+		case unset[name]:
+			message = fmt.Sprintf("non-optional message field '%s' not initialized in protobuf message %s built by %s()%s",
+				name, describeType(pass, msg), fn.Name(), gatewayNote(msgType, name))
+		default:
+			message = fmt.Sprintf("non-optional message field '%s' may be uninitialized on some paths in protobuf message %s built by %s()%s",
+				name, describeType(pass, msg), fn.Name(), gatewayNote(msgType, name))
+		}
+		reportField(pass, rootField(msg, name), analysis.Diagnostic{
+			Pos:      id.Pos(),
+			Category: KindMissingField,
+			Message:  message,
+		})
+	}
+}
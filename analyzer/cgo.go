@@ -0,0 +1,61 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// filterCgo wraps pass.Report for packages that use cgo. Drivers analyze the files cgo
+// writes rather than the package's own: for each file that imports "C" a rewritten copy,
+// whose //line directives map positions back to the original, and intermediates such as
+// _cgo_gotypes.go that have no original. Findings in intermediates are dropped. Findings
+// in rewritten copies keep their positions, which already name the original file, but
+// lose their suggested fixes: the edits are offsets into the copy, which drivers would
+// patch instead of the source.
+func filterCgo(pass *analysis.Pass) {
+	intermediate := make(map[*token.File]bool)
+	rewritten := make(map[*token.File]bool)
+	for _, file := range pass.Files {
+		tf := pass.Fset.File(file.Pos())
+		if tf == nil {
+			continue
+		}
+		switch {
+		case pass.Fset.Position(file.Package).Filename != tf.Name():
+			rewritten[tf] = true
+		case cgoGenerated(file):
+			intermediate[tf] = true
+		}
+	}
+	if len(intermediate) == 0 && len(rewritten) == 0 {
+		return
+	}
+
+	report := pass.Report
+	pass.Report = func(d analysis.Diagnostic) {
+		tf := pass.Fset.File(d.Pos)
+		if intermediate[tf] {
+			return
+		}
+		if rewritten[tf] {
+			d.SuggestedFixes = nil
+		}
+		report(d)
+	}
+}
+
+// cgoGenerated checks if a file was written by cmd/cgo: it says so in its header, or
+// holds only //go:cgo_ directives for the linker
+func cgoGenerated(file *ast.File) bool {
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			if c.Text == "// Code generated by cmd/cgo; DO NOT EDIT." || strings.HasPrefix(c.Text, "//go:cgo_") {
+				return true
+			}
+		}
+	}
+	return false
+}
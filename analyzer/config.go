@@ -0,0 +1,345 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var (
+	// responsePattern sets the protobuf schema's response name pattern via -response-pattern
+	responsePattern = regexpFlag{re: &protobuf.ResponsePattern}
+
+	// checkAllMessages checks every protobuf message, not just response-named ones
+	checkAllMessages bool
+
+	// includePackages holds the comma-separated package patterns set via -include-packages
+	includePackages string
+
+	// excludePatterns holds the comma-separated package patterns and file globs set via
+	// -exclude
+	excludePatterns string
+
+	// serviceInterfaceNames holds the comma-separated gRPC server interfaces, besides the
+	// generated ones, whose implementations' results are checked as responses
+	serviceInterfaceNames string
+
+	// mapLookupMode controls the map-lookup rule: "off", "advisory" or "error"
+	mapLookupMode = "advisory"
+
+	// partialResponses controls whether responses returned with a non-nil error may leave
+	// required fields unset: "never" or "with-error"
+	partialResponses = "never"
+
+	// mockPackages holds the comma-separated package patterns treated as generated mocks
+	mockPackages = "mocks/...,mock/...,mock_*,github.com/golang/mock/...,go.uber.org/mock/..."
+
+	// reflectionMode controls the reflection rule: "off", "advisory" or "error"
+	reflectionMode = "advisory"
+
+	// forbidMessageCopy reports messages copied by value (x := *resp)
+	forbidMessageCopy bool
+
+	// forbidOutputOnly reports OUTPUT_ONLY fields set in request messages
+	forbidOutputOnly bool
+
+	// gatewayJSON adds gRPC-Gateway JSON consequences to nil and uninitialized field diagnostics
+	gatewayJSON bool
+
+	// exclusiveFields holds the data/status field pairs of responses of which exactly one is set
+	exclusiveFields exclusivePairsFlag
+
+	// reportUnverifiedValues reports required fields whose values the analyzer can't see into
+	reportUnverifiedValues bool
+
+	// analysisBudget bounds the deep analysis of each function, set via -analysis-budget
+	analysisBudget = budgetFlag{nodes: defaultNodeBudget}
+
+	// requireReason reports ignore directives that don't say why the finding was accepted
+	requireReason bool
+
+	// schemaNames holds the experimental schemas checked besides protobuf, set via
+	// -experimental-schemas
+	schemaNames schemasFlag
+
+	// maxDepth bounds how many nested message literals deep values are validated, set via
+	// -max-depth; 0 means no bound
+	maxDepth = 32
+
+	// fixTimestampExpr is the expression suggested fixes set nil or missing Timestamp fields to
+	fixTimestampExpr = mustExprFlag("timestamppb.Now()")
+)
+
+func init() {
+	Analyzer.Flags.StringVar(&protobuf.ResponseSuffixes, "response-suffixes", protobuf.ResponseSuffixes,
+		"comma-separated type name suffixes of the response messages that are checked")
+	Analyzer.Flags.Var(&responsePattern, "response-pattern",
+		"regular expression matching further response message type names, e.g. '^(Get|List)\\w+Output$'")
+	Analyzer.Flags.BoolVar(&checkAllMessages, "check-all-messages", false,
+		"check every protobuf message literal and assignment, not just response messages")
+	Analyzer.Flags.StringVar(&includePackages, "include-packages", "",
+		"comma-separated package path patterns to check (e.g. 'example.com/services/payments/...'); empty checks everything")
+	Analyzer.Flags.StringVar(&excludePatterns, "exclude", "",
+		"comma-separated package path patterns (e.g. 'services/testutil/...') and file path globs (e.g. '**/internal/testutil/**,**/*_fixture.go') of code not to check, such as fixtures building partial messages on purpose")
+	Analyzer.Flags.StringVar(&serviceInterfaceNames, "service-interfaces", "",
+		"comma-separated gRPC server interfaces besides the generated ones (e.g. 'UserServiceServer' or 'example.com/gen/userpb.UserServiceServer'); messages returned by the methods implementing them are checked as responses whatever their names")
+	Analyzer.Flags.StringVar(&mapLookupMode, "map-lookup", mapLookupMode,
+		"how to report map lookups assigned to required response fields without a nil check: off, advisory or error")
+	Analyzer.Flags.StringVar(&partialResponses, "partial-responses", partialResponses,
+		"whether a response returned together with a non-nil error (errors.New, fmt.Errorf, errors.Join, multierr, or a variable checked against nil) may leave required fields unset: never or with-error")
+	Analyzer.Flags.StringVar(&mockPackages, "mock-packages", mockPackages,
+		"comma-separated package path patterns of generated mocks (gomock, mockery); values from them are not validated recursively")
+	Analyzer.Flags.BoolVar(&protobuf.TaggedStructs, "tagged-structs", false,
+		"treat hand-written structs with protobuf:\"...\" or proto:\"...\" field tags as messages even without a ProtoMessage method")
+	Analyzer.Flags.StringVar(&reflectionMode, "reflection", reflectionMode,
+		"how to report reflective sets (reflect.Value.Set) on response messages: off, advisory or error; error only applies outside tests and mock packages")
+	Analyzer.Flags.BoolVar(&forbidMessageCopy, "forbid-message-copy", false,
+		"report protobuf messages copied by value through a dereference (x := *resp) and suggest proto.Clone")
+	Analyzer.Flags.BoolVar(&forbidOutputOnly, "forbid-output-only", false,
+		"report fields annotated (google.api.field_behavior) = OUTPUT_ONLY that are set in request messages")
+	Analyzer.Flags.BoolVar(&gatewayJSON, "gateway-json", false,
+		"for services consumed through gRPC-Gateway, say which JSON key REST clients lose when a required field is nil or unset")
+	Analyzer.Flags.Var(&exclusiveFields, "exclusive-fields",
+		"comma-separated DataField/StatusField pairs (e.g. 'User/Error'); responses with both fields must set exactly one at each return, and neither field is required on its own")
+	Analyzer.Flags.BoolVar(&reportUnverifiedValues, "report-unverified", false,
+		"emit informational diagnostics for required fields set from function calls, parameters or channel receives, which are trusted without verification")
+	Analyzer.Flags.Var(&analysisBudget, "analysis-budget",
+		"bound on the flow-sensitive, SSA and interprocedural analysis of each function: a duration (e.g. '50ms') or a number of syntax nodes (e.g. '5000'); functions over it get the shallow checks only. Defaults to 20000 nodes; empty or 0 disables")
+	Analyzer.Flags.Var(&schemaNames, "experimental-schemas",
+		"comma-separated schema systems whose generated code is checked besides protobuf's: thrift (Apache Thrift structs, whose required fields must be set) and avro (gogen-avro and hamba/avro records). Experimental")
+	Analyzer.Flags.IntVar(&maxDepth, "max-depth", maxDepth,
+		"how many nested message literals deep field values are validated; deeper literals are trusted. 0 means no limit")
+	Analyzer.Flags.BoolVar(&requireReason, "require-reason", requireReason,
+		"require //nonillinter:ignore and //nolint:nonillinter directives to give a reason; without one they suppress nothing and are reported with the findings they cover")
+	Analyzer.Flags.Var(&fixTimestampExpr, "fix-timestamp-expr",
+		"expression suggested fixes use for nil or missing google.protobuf.Timestamp fields, e.g. 'timestamppb.New(time.Time{})'; it may refer to the timestamppb and time packages")
+}
+
+// regexpFlag is a flag.Value setting an optional regular expression, compiled when set
+type regexpFlag struct {
+	re **regexp.Regexp
+}
+
+func (f *regexpFlag) String() string {
+	if f == nil || f.re == nil || *f.re == nil {
+		return ""
+	}
+	return (*f.re).String()
+}
+
+func (f *regexpFlag) Set(value string) error {
+	if value == "" {
+		*f.re = nil
+		return nil
+	}
+	re, err := regexp.Compile(value)
+	if err != nil {
+		return err
+	}
+	*f.re = re
+	return nil
+}
+
+// exprFlag is a flag.Value holding a Go expression for suggested fixes, parsed when set.
+// The expression may only refer to the packages in fixPackages, by their names.
+type exprFlag struct {
+	src  string
+	expr ast.Expr
+}
+
+func mustExprFlag(src string) exprFlag {
+	var f exprFlag
+	if err := f.Set(src); err != nil {
+		panic(err)
+	}
+	return f
+}
+
+func (f *exprFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.src
+}
+
+func (f *exprFlag) Set(value string) error {
+	expr, err := parser.ParseExpr(value)
+	if err != nil {
+		return err
+	}
+	for _, name := range qualifiers(expr) {
+		if fixPackages[name] == "" {
+			return fmt.Errorf("unknown package %q in %q: only timestamppb and time may be used", name, value)
+		}
+	}
+	f.src, f.expr = value, expr
+	return nil
+}
+
+// qualifiers returns the package names an unresolved expression refers to, as x in x.Sel
+func qualifiers(expr ast.Expr) []string {
+	var names []string
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				names = append(names, id.Name)
+			}
+		}
+		return true
+	})
+	return names
+}
+
+// splitPatterns splits a comma-separated flag value into trimmed, non-empty patterns
+func splitPatterns(value string) []string {
+	var patterns []string
+	for _, p := range strings.Split(value, ",") {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+// matchPackagePattern reports whether a package path matches a pattern.
+// Patterns use path.Match syntax, and a trailing "/..." matches the package and everything below it.
+// A pattern without a leading module path also matches any package path ending in it,
+// so "services/payments/..." matches "example.com/repo/services/payments/api".
+func matchPackagePattern(pattern, pkgPath string) bool {
+	if strings.HasSuffix(pattern, "/...") || pattern == "..." {
+		prefix := strings.TrimSuffix(strings.TrimSuffix(pattern, "..."), "/")
+		if prefix == "" {
+			return true
+		}
+		depth := strings.Count(prefix, "/") + 1
+		for _, candidate := range pathSuffixes(pkgPath) {
+			elems := strings.Split(candidate, "/")
+			if len(elems) < depth {
+				continue
+			}
+			if ok, _ := path.Match(prefix, strings.Join(elems[:depth], "/")); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, candidate := range pathSuffixes(pkgPath) {
+		if ok, _ := path.Match(pattern, candidate); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// pathSuffixes returns pkgPath and every suffix of it that starts at a path element boundary
+func pathSuffixes(pkgPath string) []string {
+	suffixes := []string{pkgPath}
+	for i := 0; i < len(pkgPath); i++ {
+		if pkgPath[i] == '/' {
+			suffixes = append(suffixes, pkgPath[i+1:])
+		}
+	}
+	return suffixes
+}
+
+// isPackageIncluded reports whether the package should be checked under -include-packages
+func isPackageIncluded(pkgPath string) bool {
+	patterns := splitPatterns(includePackages)
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if matchPackagePattern(pattern, pkgPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPackageExcluded reports whether a package path matches one of the -exclude
+// patterns, as a package pattern or a glob
+func isPackageExcluded(pkgPath string) bool {
+	for _, pattern := range splitPatterns(excludePatterns) {
+		if matchPackagePattern(pattern, pkgPath) || matchGlob(pattern, pkgPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// excludedFiles returns the files of the package whose paths match one of the -exclude
+// globs
+func excludedFiles(pass *analysis.Pass) map[*token.File]bool {
+	excluded := make(map[*token.File]bool)
+	patterns := splitPatterns(excludePatterns)
+	if len(patterns) == 0 {
+		return excluded
+	}
+	for _, file := range pass.Files {
+		tf := pass.Fset.File(file.Pos())
+		if tf == nil {
+			continue
+		}
+		for _, pattern := range patterns {
+			if matchGlob(pattern, filepath.ToSlash(tf.Name())) {
+				log().Debug("skipping file matched by -exclude", "file", tf.Name(), "pattern", pattern)
+				excluded[tf] = true
+				break
+			}
+		}
+	}
+	return excluded
+}
+
+// matchGlob reports whether a slash-separated path matches a glob. Elements use
+// path.Match syntax, and a "**" element matches any number of elements, none included.
+// Like package patterns, a glob without a leading "**" also matches any path ending in
+// it, so "testutil/*.go" matches "/src/repo/internal/testutil/users.go".
+func matchGlob(pattern, name string) bool {
+	elems := strings.Split(pattern, "/")
+	for _, candidate := range pathSuffixes(name) {
+		if matchElems(elems, strings.Split(candidate, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchElems matches the elements of a path against those of a glob
+func matchElems(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchElems(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], name[0])
+	return ok && matchElems(pattern[1:], name[1:])
+}
+
+// isMockPackage reports whether a package path matches the -mock-packages patterns
+func isMockPackage(pkgPath string) bool {
+	for _, pattern := range splitPatterns(mockPackages) {
+		if matchPackagePattern(pattern, pkgPath) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+package analyzer
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestMatchPackagePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		pkgPath string
+		want    bool
+	}{
+		{"services/payments/...", "example.com/repo/services/payments", true},
+		{"services/payments/...", "example.com/repo/services/payments/api", true},
+		{"services/payments/...", "example.com/repo/services/paymentsv2", false},
+		{"services/payments/...", "example.com/repo/services/orders", false},
+		{"example.com/repo/services/*/api", "example.com/repo/services/orders/api", true},
+		{"services/*/...", "example.com/repo/services/orders/api", true},
+		{"services/*", "example.com/repo/services/orders/api", false},
+		{"...", "anything/at/all", true},
+		{"example.com/repo", "example.com/repo", true},
+		{"example.com/repo", "example.com/repo/sub", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"|"+tt.pkgPath, func(t *testing.T) {
+			if got := matchPackagePattern(tt.pattern, tt.pkgPath); got != tt.want {
+				t.Errorf("matchPackagePattern(%q, %q) = %v, want %v", tt.pattern, tt.pkgPath, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsPackageIncluded(t *testing.T) {
+	defer func(old string) { includePackages = old }(includePackages)
+
+	includePackages = ""
+	if !isPackageIncluded("example.com/any") {
+		t.Error("empty -include-packages should include every package")
+	}
+
+	includePackages = "services/payments/..., services/billing"
+	if !isPackageIncluded("example.com/services/billing") {
+		t.Error("expected services/billing to be included")
+	}
+	if isPackageIncluded("example.com/services/orders") {
+		t.Error("expected services/orders to be excluded")
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"**/internal/testutil/**", "/src/repo/internal/testutil/users.go", true},
+		{"**/internal/testutil/**", "example.com/repo/internal/testutil", true},
+		{"**/internal/testutil/**", "/src/repo/internal/testutilv2/users.go", false},
+		{"**/mocks/**", "/src/repo/services/users/mocks/server.go", true},
+		{"**/*_fixture.go", "/src/repo/services/users/partial_fixture.go", true},
+		{"**/*_fixture.go", "/src/repo/services/users/handler.go", false},
+		{"testutil/*.go", "/src/repo/internal/testutil/users.go", true},
+		{"testutil/*.go", "/src/repo/internal/testutil/sub/users.go", false},
+		{"services/**/fixtures.go", "/src/repo/services/fixtures.go", true},
+	}
+
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsPackageExcluded(t *testing.T) {
+	defer func(old string) { excludePatterns = old }(excludePatterns)
+
+	excludePatterns = "services/testutil/..., **/mocks/**"
+	for pkgPath, want := range map[string]bool{
+		"example.com/repo/services/testutil":      true,
+		"example.com/repo/services/testutil/fake": true,
+		"example.com/repo/services/users/mocks":   true,
+		"example.com/repo/services/users":         false,
+	} {
+		if got := isPackageExcluded(pkgPath); got != want {
+			t.Errorf("isPackageExcluded(%q) = %v, want %v", pkgPath, got, want)
+		}
+	}
+}
+
+func TestIsMockPackage(t *testing.T) {
+	tests := []struct {
+		pkgPath string
+		want    bool
+	}{
+		{"example.com/svc/mocks", true},
+		{"example.com/svc/mocks/userpb", true},
+		{"example.com/svc/mock_userpb", true},
+		{"go.uber.org/mock/gomock", true},
+		{"example.com/svc/mockingbird", false},
+		{"example.com/svc/handlers", false},
+	}
+
+	for _, tt := range tests {
+		if got := isMockPackage(tt.pkgPath); got != tt.want {
+			t.Errorf("isMockPackage(%q) = %v, want %v", tt.pkgPath, got, tt.want)
+		}
+	}
+}
+
+func TestIsResponseName(t *testing.T) {
+	defer func(old string) { protobuf.ResponseSuffixes = old }(protobuf.ResponseSuffixes)
+	defer func(old *regexp.Regexp) { protobuf.ResponsePattern = old }(protobuf.ResponsePattern)
+
+	tests := []struct {
+		suffixes, pattern, name string
+		want                    bool
+	}{
+		{"Response,Reply,Result", "", "GetUserResponse", true},
+		{"Response,Reply,Result", "", "GetUserReply", true},
+		{"Response,Reply,Result", "", "GetUserOutput", false},
+		{"Response, Output", "", "GetUserOutput", true},
+		{"Output", "", "GetUserResponse", false},
+		{"", "^(Get|List)\\w+Out$", "ListUsersOut", true},
+		{"", "^(Get|List)\\w+Out$", "DeleteUserOut", false},
+		{"Response", "Out$", "GetUserResponse", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.suffixes+"|"+tt.pattern+"|"+tt.name, func(t *testing.T) {
+			protobuf.ResponseSuffixes = tt.suffixes
+			if err := responsePattern.Set(tt.pattern); err != nil {
+				t.Fatal(err)
+			}
+			if got := isResponseName(tt.name); got != tt.want {
+				t.Errorf("isResponseName(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+
+	if err := responsePattern.Set("("); err == nil {
+		t.Error("an invalid -response-pattern should be rejected")
+	}
+}
+
+func TestExclusivePairsFlag(t *testing.T) {
+	var pairs exclusivePairsFlag
+	if err := pairs.Set("User/Error, Data / Status"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := pairs.String(), "User/Error,Data/Status"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	for _, value := range []string{"User", "User/", "/Error", "User/User"} {
+		if err := pairs.Set(value); err == nil {
+			t.Errorf("Set(%q) should fail", value)
+		}
+	}
+}
+
+func TestExprFlag(t *testing.T) {
+	var expr exprFlag
+	for _, value := range []string{"timestamppb.Now()", "timestamppb.New(time.Time{})", "timestamppb.New(time.Unix(0, 0))"} {
+		if err := expr.Set(value); err != nil {
+			t.Errorf("Set(%q) = %v", value, err)
+		}
+	}
+	for _, value := range []string{"timestamppb.Now(", "clock.Now()", "ptypes.TimestampNow()"} {
+		if err := expr.Set(value); err == nil {
+			t.Errorf("Set(%q) should fail", value)
+		}
+	}
+}
+
+func TestBudgetFlag(t *testing.T) {
+	var budget budgetFlag
+	for value, want := range map[string]budgetFlag{
+		"5000": {nodes: 5000},
+		"50ms": {duration: 50 * time.Millisecond},
+		"0":    {},
+		"":     {},
+	} {
+		if err := budget.Set(value); err != nil || budget != want {
+			t.Errorf("Set(%q) = %v, %+v; want %+v", value, err, budget, want)
+		}
+	}
+	for _, value := range []string{"-1", "-5s", "fast"} {
+		if err := budget.Set(value); err == nil {
+			t.Errorf("Set(%q) should fail", value)
+		}
+	}
+}
+
+func TestFixRulesFlag(t *testing.T) {
+	var rules fixRulesFlag
+	for value, want := range map[string]string{
+		"timestamp":                  "timestamp",
+		"proto-clone, empty-message": "empty-message,proto-clone",
+		"all":                        "empty-message,timestamp,proto-clone,optional-usage",
+		"none":                       "",
+	} {
+		if err := rules.Set(value); err != nil || rules.String() != want {
+			t.Errorf("Set(%q) = %v, %q; want %q", value, err, rules.String(), want)
+		}
+	}
+	if err := rules.Set("timestamp,everything"); err == nil {
+		t.Error("an unknown fix rule should be rejected")
+	}
+}
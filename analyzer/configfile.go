@@ -0,0 +1,239 @@
+package analyzer
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// configFile implements -config: setting it loads a YAML or TOML file whose keys are
+// analyzer flag names and applies each entry as if it had been passed on the command
+// line. Because it is an ordinary analyzer flag it also works under go vet -vettool.
+// Flags later on the command line override values from the file.
+//
+//	# .nonillinter.yaml
+//	include-packages:
+//	  - services/payments/...
+//	map-lookup: error
+//	tagged-structs: true
+type configFile struct {
+	flags *flag.FlagSet
+	path  string
+}
+
+func init() {
+	Analyzer.Flags.Var(&configFile{flags: &Analyzer.Flags}, "config",
+		"load analyzer settings from a YAML (.yaml, .yml) or TOML (.toml) file whose keys are flag names; later flags override it")
+}
+
+func (c *configFile) String() string {
+	if c == nil {
+		return ""
+	}
+	return c.path
+}
+
+func (c *configFile) Set(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	settings, err := parseConfigFile(path, string(data))
+	if err != nil {
+		return err
+	}
+
+	if err := applySettings(c.flags, settings); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	c.path = path
+	return nil
+}
+
+// Configure applies settings keyed by analyzer flag name, without the leading dash and
+// with lists joined by commas, as -config does for the entries of a file. It lets
+// drivers with their own configuration, such as the golangci-lint plugin, pass it on.
+func Configure(settings map[string]string) error {
+	return applySettings(&Analyzer.Flags, settings)
+}
+
+// applySettings sets each flag named by a key, in key order; "_" may be used in place
+// of "-" in keys
+func applySettings(flags *flag.FlagSet, settings map[string]string) error {
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name := strings.ReplaceAll(key, "_", "-")
+		if name == "config" || flags.Lookup(name) == nil {
+			return fmt.Errorf("unknown setting %q", key)
+		}
+		if err := flags.Set(name, settings[key]); err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+// parseConfigFile parses the flat subset of YAML or TOML (chosen by extension) that
+// settings need: scalar values and lists of scalars, which are joined with commas
+func parseConfigFile(path, data string) (map[string]string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return parseYAMLConfig(path, data)
+	case ".toml":
+		return parseTOMLConfig(path, data)
+	default:
+		return nil, fmt.Errorf("%s: unsupported config format, use .yaml, .yml or .toml", path)
+	}
+}
+
+// parseYAMLConfig parses top-level "key: value" entries, where value is a scalar, a flow
+// list [a, b] or a block list of "- item" lines below the key
+func parseYAMLConfig(path, data string) (map[string]string, error) {
+	settings := make(map[string]string)
+	var listKey string
+	var list []string
+	flush := func() {
+		if listKey != "" {
+			settings[listKey] = strings.Join(list, ",")
+		}
+		listKey, list = "", nil
+	}
+
+	for i, raw := range strings.Split(data, "\n") {
+		line := strings.TrimRight(stripComment(raw, "#"), " \t\r")
+		if strings.TrimSpace(line) == "" || line == "---" {
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed != line && strings.HasPrefix(trimmed, "-") {
+			if listKey == "" {
+				return nil, fmt.Errorf("%s:%d: list item without a key", path, i+1)
+			}
+			item, err := unquoteConfigValue(strings.TrimSpace(strings.TrimPrefix(trimmed, "-")))
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %v", path, i+1, err)
+			}
+			list = append(list, item)
+			continue
+		}
+		flush()
+		if trimmed != line {
+			return nil, fmt.Errorf("%s:%d: nested settings are not supported", path, i+1)
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected key: value", path, i+1)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if value == "" {
+			listKey = key
+			list = []string{}
+			continue
+		}
+		parsed, err := parseConfigValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, i+1, err)
+		}
+		settings[key] = parsed
+	}
+	flush()
+	return settings, nil
+}
+
+// parseTOMLConfig parses top-level "key = value" entries, where value is a string,
+// boolean, number or array of those; arrays may span lines
+func parseTOMLConfig(path, data string) (map[string]string, error) {
+	settings := make(map[string]string)
+	lines := strings.Split(data, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(stripComment(lines[i], "#"))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			return nil, fmt.Errorf("%s:%d: tables are not supported, put settings at the top level", path, i+1)
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected key = value", path, i+1)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		start := i
+		for strings.HasPrefix(value, "[") && !strings.HasSuffix(value, "]") && i+1 < len(lines) {
+			i++
+			value += " " + strings.TrimSpace(stripComment(lines[i], "#"))
+		}
+		parsed, err := parseConfigValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, start+1, err)
+		}
+		settings[key] = parsed
+	}
+	return settings, nil
+}
+
+// parseConfigValue parses a scalar or a [a, b] list, returning lists joined with commas
+func parseConfigValue(value string) (string, error) {
+	if !strings.HasPrefix(value, "[") {
+		return unquoteConfigValue(value)
+	}
+	if !strings.HasSuffix(value, "]") {
+		return "", fmt.Errorf("unterminated list %s", value)
+	}
+	var items []string
+	for _, item := range strings.Split(strings.TrimSuffix(strings.TrimPrefix(value, "["), "]"), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		unquoted, err := unquoteConfigValue(item)
+		if err != nil {
+			return "", err
+		}
+		items = append(items, unquoted)
+	}
+	return strings.Join(items, ","), nil
+}
+
+// unquoteConfigValue removes single or double quotes around a scalar
+func unquoteConfigValue(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		return strconv.Unquote(value)
+	case strings.HasPrefix(value, "'"):
+		if len(value) < 2 || !strings.HasSuffix(value, "'") {
+			return "", fmt.Errorf("unterminated string %s", value)
+		}
+		return value[1 : len(value)-1], nil
+	}
+	return value, nil
+}
+
+// stripComment removes a trailing comment that starts outside quotes
+func stripComment(line, marker string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case strings.HasPrefix(line[i:], marker) && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
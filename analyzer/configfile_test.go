@@ -0,0 +1,112 @@
+package analyzer
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseConfigFile(t *testing.T) {
+	want := map[string]string{
+		"include-packages": "services/payments/...,services/billing",
+		"map-lookup":       "error",
+		"tagged-structs":   "true",
+		"mock-packages":    "mocks/...",
+	}
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"block.yaml", `
+# shared team settings
+include-packages:
+  - services/payments/...
+  - "services/billing"   # quoted
+map-lookup: error
+tagged-structs: true
+mock-packages: 'mocks/...'
+`},
+		{"flow.yml", `---
+include-packages: [services/payments/..., "services/billing"]
+map-lookup: "error"
+tagged-structs: true
+mock-packages: mocks/...
+`},
+		{"settings.toml", `
+# shared team settings
+include-packages = [
+  "services/payments/...",
+  "services/billing", # trailing comma
+]
+map-lookup = "error"
+tagged-structs = true
+mock-packages = 'mocks/...'
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseConfigFile(tt.name, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("parseConfigFile() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseConfigFileErrors(t *testing.T) {
+	tests := []struct {
+		name, data, want string
+	}{
+		{"c.json", `{}`, "unsupported config format"},
+		{"c.yaml", "checks:\n  map-lookup: error\n", "nested settings are not supported"},
+		{"c.yaml", "map-lookup error\n", "expected key: value"},
+		{"c.toml", "[nonillinter]\nmap-lookup = \"error\"\n", "tables are not supported"},
+		{"c.toml", "include-packages = [\"a\"\n", "unterminated list"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"|"+tt.want, func(t *testing.T) {
+			_, err := parseConfigFile(tt.name, tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parseConfigFile() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigFlag(t *testing.T) {
+	var fs flag.FlagSet
+	mode := fs.String("map-lookup", "advisory", "")
+	tagged := fs.Bool("tagged-structs", false, "")
+	fs.Var(&configFile{flags: &fs}, "config", "")
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".nonillinter.yaml")
+	if err := os.WriteFile(path, []byte("map_lookup: error\ntagged-structs: true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Flags after -config override the file
+	if err := fs.Parse([]string{"-config", path, "-tagged-structs=false"}); err != nil {
+		t.Fatal(err)
+	}
+	if *mode != "error" || *tagged {
+		t.Errorf("got map-lookup=%q tagged-structs=%v, want error and false", *mode, *tagged)
+	}
+
+	bad := filepath.Join(dir, "bad.toml")
+	if err := os.WriteFile(bad, []byte("severity = \"error\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Set("config", bad); err == nil || !strings.Contains(err.Error(), `unknown setting "severity"`) {
+		t.Errorf("Set() error = %v, want unknown setting", err)
+	}
+}
@@ -0,0 +1,92 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// connectPackages are the import paths of the Connect runtime, current and legacy
+var connectPackages = map[string]bool{
+	"connectrpc.com/connect":         true,
+	"github.com/bufbuild/connect-go": true,
+}
+
+// Connect handlers don't return their response message but a generic wrapper around it:
+//
+//	func (s *server) GetUser(ctx context.Context, req *connect.Request[pb.GetUserRequest]) (*connect.Response[pb.UserResponse], error) {
+//		return connect.NewResponse(&pb.UserResponse{User: u}), nil
+//	}
+//
+// The wrapper only carries the message along with headers and trailers, so the message
+// is evaluated where the wrapper is returned, as if it was returned itself.
+
+// responseWrapper checks if call is connect.NewResponse(msg)
+func responseWrapper(call *ast.CallExpr, pass *analysis.Pass) bool {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	return ok && fn.Name() == "NewResponse" && fn.Pkg() != nil && connectPackages[fn.Pkg().Path()] && len(call.Args) == 1
+}
+
+// unwrapResponse returns the message a returned expression wraps for a Connect handler's
+// reply: msg in connect.NewResponse(msg) and &connect.Response[T]{Msg: msg}, and the
+// message held by other generic wrappers; see generics.go. Other expressions are
+// returned as they are, without parentheses and conversions.
+func unwrapResponse(expr ast.Expr, pass *analysis.Pass) ast.Expr {
+	expr = unwrapExpr(expr, pass)
+	switch e := expr.(type) {
+	case *ast.CallExpr:
+		if responseWrapper(e, pass) {
+			return ast.Unparen(e.Args[0])
+		}
+	case *ast.UnaryExpr:
+		lit, ok := ast.Unparen(e.X).(*ast.CompositeLit)
+		if ok && e.Op == token.AND && connectResponseMessage(pass.TypesInfo.TypeOf(e)) != nil {
+			for _, elt := range lit.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					if id, ok := kv.Key.(*ast.Ident); ok && id.Name == "Msg" {
+						return ast.Unparen(kv.Value)
+					}
+				}
+			}
+			return expr
+		}
+	}
+	if msg := wrappedMessage(expr, pass); msg != nil {
+		return unwrapResponse(msg, pass)
+	}
+	return expr
+}
+
+// connectResponseMessage returns *T for *connect.Response[T], or nil
+func connectResponseMessage(t types.Type) types.Type {
+	return connectMessage(t, "Response")
+}
+
+// connectMessage returns *T for *connect.<wrapper>[T], such as *connect.Request[T], or nil
+func connectMessage(t types.Type, wrapper string) types.Type {
+	ptr, ok := t.(*types.Pointer)
+	if !ok {
+		return nil
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	if !ok || named.TypeArgs().Len() != 1 {
+		return nil
+	}
+	obj := named.Origin().Obj()
+	if obj.Name() != wrapper || obj.Pkg() == nil || !connectPackages[obj.Pkg().Path()] {
+		return nil
+	}
+	return types.NewPointer(named.TypeArgs().At(0))
+}
+
+// handlerMessage returns the message type a handler's result carries: the result type
+// itself, or the message of a Connect response
+func handlerMessage(t types.Type) types.Type {
+	if msg := connectResponseMessage(t); msg != nil {
+		return msg
+	}
+	return t
+}
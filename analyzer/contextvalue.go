@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// checkContextValue reports a message pulled out of a context with the comma-ok type
+// assertion and assigned to a required field without checking it:
+//
+//	u, _ := ctx.Value(userKey).(*pb.User)
+//	resp.User = u
+//
+// A missing key makes ctx.Value return nil, so the assertion yields a nil *pb.User.
+// The value is considered checked when it is compared against nil or the ok result
+// is used. The single-value form ctx.Value(k).(*pb.User) panics instead and is not reported.
+func checkContextValue(value ast.Expr, field fieldRef, msgType types.Type, pass *analysis.Pass) {
+	ident, ok := ast.Unparen(value).(*ast.Ident)
+	if !ok {
+		return
+	}
+	obj, ok := pass.TypesInfo.Uses[ident].(*types.Var)
+	if !ok {
+		return
+	}
+	if !uncheckedContextValue(obj, pass) {
+		return
+	}
+
+	reportField(pass, field, analysis.Diagnostic{
+		Pos:      value.Pos(),
+		Category: KindContextValue,
+		Message: fmt.Sprintf("variable '%s' from a context value assigned to non-optional message field '%s' in protobuf message %s is nil when the key is missing; check ok or compare it against nil",
+			ident.Name, field, describeType(pass, msgType)),
+	})
+}
+
+// uncheckedContextValue reports whether a variable is declared as v, ok := ctx.Value(k).(T)
+// (or v, _ := ...) and neither v is compared against nil nor ok is used
+func uncheckedContextValue(obj *types.Var, pass *analysis.Pass) bool {
+	assert := uncheckedAssertion(obj, pass)
+	if assert == nil {
+		return false
+	}
+	call, ok := ast.Unparen(assert.X).(*ast.CallExpr)
+	return ok && isContextValueCall(call, pass)
+}
+
+// isContextValueCall checks if a call is the Value method of a context.Context
+func isContextValueCall(call *ast.CallExpr, pass *analysis.Pass) bool {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Name() != "Value" {
+		return false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return false
+	}
+	if fn.Pkg() != nil && fn.Pkg().Path() == "context" {
+		return true
+	}
+	// Request types that embed or implement context.Context (e.g. framework contexts)
+	return implementsContext(recv.Type())
+}
+
+// implementsContext checks if a type has the four methods of context.Context
+func implementsContext(t types.Type) bool {
+	methods := types.NewMethodSet(t)
+	if _, isPtr := t.(*types.Pointer); !isPtr && !types.IsInterface(t) {
+		methods = types.NewMethodSet(types.NewPointer(t))
+	}
+	for _, name := range []string{"Deadline", "Done", "Err", "Value"} {
+		if methods.Lookup(nil, name) == nil {
+			return false
+		}
+	}
+	return true
+}
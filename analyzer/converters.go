@@ -0,0 +1,160 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// checkConverters enables the converter rule: functions that copy one message into an
+// equivalent one, field by field, must carry over the required fields both share
+var checkConverters bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&checkConverters, "check-converters", false,
+		"report converter functions between messages that drop a required message field both messages have")
+}
+
+// Teams often keep an internal and a public version of a message, and convert between
+// them with a function such as:
+//
+//	func toPublicUser(u *internalpb.User) *publicpb.User {
+//		return &publicpb.User{Id: u.Id, Address: toPublicAddress(u.Address)}
+//	}
+//
+// When a required field is added to both messages later, the converter keeps compiling
+// but leaves it behind. A converter is a function taking one message and returning
+// another. Each required message field of the result that the source has too, by name,
+// must be set, and from a value that reads the source's field, such as u.CreatedAt,
+// u.GetCreatedAt() or a call taking either.
+
+// checkConverterFuncs checks the converter functions declared in the package under
+// -check-converters
+func checkConverterFuncs(pass *analysis.Pass) {
+	if !checkConverters {
+		return
+	}
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil {
+				checkConverter(fn, pass)
+			}
+		}
+	}
+}
+
+// checkConverter reports the shared required fields a converter drops or fills in from
+// something other than the source
+func checkConverter(fn *ast.FuncDecl, pass *analysis.Pass) {
+	obj, ok := pass.TypesInfo.Defs[fn.Name].(*types.Func)
+	if !ok {
+		return
+	}
+	sig := obj.Type().(*types.Signature)
+	if sig.Params().Len() != 1 || sig.Results().Len() == 0 {
+		return
+	}
+	src, dstType := sig.Params().At(0), sig.Results().At(0).Type()
+	if !isMessagePointer(src.Type()) || !isMessagePointer(dstType) || types.Identical(src.Type(), dstType) {
+		return
+	}
+
+	// Converters that hand on another function's result are checked there
+	set, built := convertedFields(fn.Body, dstType, pass)
+	if !built {
+		return
+	}
+	srcType, dst := src.Type().(*types.Pointer).Elem(), dstType.(*types.Pointer).Elem()
+	aliases := fieldAliases(fn.Body, func(root types.Object) bool { return root == src }, pass)
+	for _, field := range requiredFields(getStructType(dstType), dstType, isRequestMessage(dstType)) {
+		srcField := getFieldFromType(src.Type(), field.Name())
+		if srcField == nil || !isMessageField(srcField) {
+			continue
+		}
+		value, ok := set[field.Name()]
+		switch {
+		case !ok:
+			reportField(pass, rootField(dst, field.Name()), analysis.Diagnostic{
+				Pos:      fn.Name.Pos(),
+				Category: KindConverter,
+				Message: fmt.Sprintf("converter %s drops required field '%s': %s has it, but the returned %s never sets it",
+					fn.Name.Name, field.Name(), describeType(pass, srcType), describeType(pass, dst)),
+			})
+		case !readsField(value, src, field.Name(), aliases, pass):
+			reportField(pass, rootField(dst, field.Name()), analysis.Diagnostic{
+				Pos:      value.Pos(),
+				Category: KindConverter,
+				Message: fmt.Sprintf("converter %s sets required field '%s' of %s without reading %s.%s",
+					fn.Name.Name, field.Name(), describeType(pass, dst), src.Name(), field.Name()),
+			})
+		}
+	}
+}
+
+// isMessagePointer checks for a pointer to a generated message
+func isMessagePointer(t types.Type) bool {
+	_, ok := t.(*types.Pointer)
+	return ok && isProtobufMessageType(t)
+}
+
+// convertedFields returns the values a function sets fields of messages of type
+// dstType to, in literals and by assignment, with nil values left out. built reports
+// whether the function builds such a message itself.
+func convertedFields(body *ast.BlockStmt, dstType types.Type, pass *analysis.Pass) (set map[string]ast.Expr, built bool) {
+	dst := dstType.(*types.Pointer).Elem()
+	set = make(map[string]ast.Expr)
+	record := func(name string, value ast.Expr) {
+		if _, seen := set[name]; !seen && !isNilValue(value, pass) {
+			set[name] = value
+		}
+	}
+	inspectFunctionBody(body, func(n ast.Node) {
+		switch node := n.(type) {
+		case *ast.CompositeLit:
+			if t := pass.TypesInfo.TypeOf(node); t == nil || !types.Identical(t, dst) {
+				return
+			}
+			built = true
+			for _, elt := range node.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					if id, ok := kv.Key.(*ast.Ident); ok {
+						record(id.Name, kv.Value)
+					}
+				}
+			}
+		case *ast.AssignStmt:
+			if len(node.Lhs) != len(node.Rhs) {
+				return
+			}
+			for i, lhs := range node.Lhs {
+				sel := storedField(lhs, pass)
+				if sel == nil {
+					continue
+				}
+				if t := pass.TypesInfo.TypeOf(sel.X); t != nil && (types.Identical(t, dst) || types.Identical(t, dstType)) {
+					record(sel.Sel.Name, node.Rhs[i])
+				}
+			}
+		}
+	})
+	return set, built
+}
+
+// readsField checks if value reads field name of src anywhere within it, directly,
+// through a generated accessor or through a variable in aliases
+func readsField(value ast.Expr, src types.Object, name string, aliases map[types.Object]fieldAlias, pass *analysis.Pass) bool {
+	found := false
+	ast.Inspect(value, func(n ast.Node) bool {
+		expr, ok := n.(ast.Expr)
+		if found || !ok {
+			return !found
+		}
+		if root, path := storePath(expr, aliases, pass); root == src && len(path) > 0 && path[0] == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,192 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const protoPackagePath = "google.golang.org/protobuf/proto"
+
+// checkMessageCopies reports protobuf messages copied by value through a dereference,
+// as in x := *resp, when -forbid-message-copy is set. Generated messages carry internal
+// state (the message state, size cache and unknown fields) that must not be shared
+// between copies, and the nil-field analysis can't follow a value once it is copied.
+// For x := *resp the diagnostic suggests proto.Clone instead.
+func checkMessageCopies(inspect *inspector.Inspector, pass *analysis.Pass) {
+	if !forbidMessageCopy {
+		return
+	}
+
+	nodeFilter := []ast.Node{
+		(*ast.AssignStmt)(nil),
+		(*ast.ValueSpec)(nil),
+		(*ast.ReturnStmt)(nil),
+		(*ast.CallExpr)(nil),
+		(*ast.CompositeLit)(nil),
+	}
+	inspect.WithStack(nodeFilter, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		var values []ast.Expr
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			values = node.Rhs
+			if node.Tok == token.DEFINE && len(node.Lhs) == 1 && len(node.Rhs) == 1 {
+				if star, ok := messageDereference(node.Rhs[0], pass); ok {
+					reportMessageCopy(star, cloneFix(star, fileOf(stack), pass), pass)
+					return true
+				}
+			}
+		case *ast.ValueSpec:
+			values = node.Values
+		case *ast.ReturnStmt:
+			values = node.Results
+		case *ast.CallExpr:
+			values = node.Args
+		case *ast.CompositeLit:
+			for _, elt := range node.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					elt = kv.Value
+				}
+				values = append(values, elt)
+			}
+		}
+		for _, value := range values {
+			if star, ok := messageDereference(value, pass); ok {
+				reportMessageCopy(star, nil, pass)
+			}
+		}
+		return true
+	})
+}
+
+// messageDereference returns the dereference when expr is *p for a pointer to a message
+func messageDereference(expr ast.Expr, pass *analysis.Pass) (*ast.StarExpr, bool) {
+	star, ok := ast.Unparen(expr).(*ast.StarExpr)
+	if !ok {
+		return nil, false
+	}
+	tv, ok := pass.TypesInfo.Types[star]
+	if !ok || !tv.IsValue() {
+		return nil, false
+	}
+	if _, isPtr := tv.Type.(*types.Pointer); isPtr || !isProtobufMessageType(tv.Type) {
+		return nil, false
+	}
+	return star, true
+}
+
+func reportMessageCopy(star *ast.StarExpr, fixes []analysis.SuggestedFix, pass *analysis.Pass) {
+	pass.Report(analysis.Diagnostic{
+		Pos:      star.Pos(),
+		End:      star.End(),
+		Category: KindMessageCopy,
+		Message: fmt.Sprintf("protobuf message %s is copied by value; copies share internal state and hide nil fields from analysis, use proto.Clone",
+			describeType(pass, pass.TypesInfo.TypeOf(star))),
+		SuggestedFixes: fixes,
+	})
+}
+
+// cloneFix rewrites *p to proto.Clone(p).(*T), importing the proto package if needed.
+// The variable becomes a pointer, which later uses may need to account for.
+func cloneFix(star *ast.StarExpr, file *ast.File, pass *analysis.Pass) []analysis.SuggestedFix {
+	if file == nil {
+		return nil
+	}
+	protoName, edits := protoImport(file)
+	if protoName == "" {
+		return nil
+	}
+	ptrType := types.TypeString(types.NewPointer(pass.TypesInfo.TypeOf(star)), fileQualifier(file, pass))
+	replacement := fmt.Sprintf("%s.Clone(%s).(%s)", protoName, types.ExprString(star.X), ptrType)
+	edits = append(edits, analysis.TextEdit{Pos: star.Pos(), End: star.End(), NewText: []byte(replacement)})
+	return []analysis.SuggestedFix{{
+		Message:   protoCloneFixMessage,
+		TextEdits: edits,
+	}}
+}
+
+// protoImport returns the name the file uses for the proto package, with the edits
+// that add the import when the file doesn't have it yet
+func protoImport(file *ast.File) (string, []analysis.TextEdit) {
+	name, missing := importName(file, protoPackagePath, "proto")
+	if !missing {
+		return name, nil
+	}
+	return name, []analysis.TextEdit{addImportsEdit(file, []string{protoPackagePath})}
+}
+
+// importName returns the name the file uses for the package at path, and whether the
+// file has yet to import it under name. It returns "" when the file can't name the
+// package: it is imported as _ or ., or name is taken by something else in the file.
+func importName(file *ast.File, path, name string) (string, bool) {
+	for _, imp := range file.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p == path {
+			if imp.Name == nil {
+				return name, false
+			}
+			if imp.Name.Name == "_" || imp.Name.Name == "." {
+				return "", false
+			}
+			return imp.Name.Name, false
+		}
+	}
+	if file.Scope != nil && file.Scope.Lookup(name) != nil {
+		return "", false
+	}
+	return name, true
+}
+
+// addImportsEdit adds imports of paths to the file, into its import block if it has one
+func addImportsEdit(file *ast.File, paths []string) analysis.TextEdit {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			break
+		}
+		if gen.Lparen.IsValid() {
+			var text strings.Builder
+			for _, path := range paths {
+				text.WriteString("\t" + strconv.Quote(path) + "\n")
+			}
+			return analysis.TextEdit{Pos: gen.Rparen, End: gen.Rparen, NewText: []byte(text.String())}
+		}
+	}
+	var text strings.Builder
+	for _, path := range paths {
+		text.WriteString("\n\nimport " + strconv.Quote(path))
+	}
+	return analysis.TextEdit{Pos: file.Name.End(), End: file.Name.End(), NewText: []byte(text.String())}
+}
+
+// fileQualifier qualifies types by the name the file imports their package under
+func fileQualifier(file *ast.File, pass *analysis.Pass) types.Qualifier {
+	return func(pkg *types.Package) string {
+		if pkg == pass.Pkg {
+			return ""
+		}
+		for _, imp := range file.Imports {
+			if path, _ := strconv.Unquote(imp.Path.Value); path == pkg.Path() && imp.Name != nil {
+				return imp.Name.Name
+			}
+		}
+		return pkg.Name()
+	}
+}
+
+// fileOf returns the *ast.File at the bottom of an inspector stack
+func fileOf(stack []ast.Node) *ast.File {
+	if len(stack) == 0 {
+		return nil
+	}
+	file, _ := stack[0].(*ast.File)
+	return file
+}
@@ -0,0 +1,183 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// Coverage counts the response construction sites of a package, its response literals
+// and new(T) calls in the files it checks, by how far the analyzer verified them. Each
+// site is counted once, as suppressed if any finding on it was, else as trusted if any
+// of its fields was taken on trust.
+type Coverage struct {
+	// Verified is the number of sites whose required fields were all checked
+	Verified int
+
+	// Trusted is the number of sites the analyzer took on trust: a required field set
+	// from a value it can't see into (see reportUnverified), a response handed to a
+	// function that may fill it in, or one returned with an error under -partial-responses
+	Trusted int
+
+	// Suppressed is the number of sites with a finding dropped by an ignore directive or
+	// an unchecked region; see suppress.go
+	Suppressed int
+}
+
+// Sites returns the number of response construction sites
+func (c Coverage) Sites() int {
+	return c.Verified + c.Trusted + c.Suppressed
+}
+
+// VerifiedFraction returns the fraction of sites that were verified, 1 when there are none
+func (c Coverage) VerifiedFraction() float64 {
+	if c.Sites() == 0 {
+		return 1
+	}
+	return float64(c.Verified) / float64(c.Sites())
+}
+
+// siteCoverage holds the trust decisions and suppressed findings of a package's sites
+type siteCoverage struct {
+	mu         sync.Mutex
+	trusted    map[ast.Node]bool
+	suppressed map[ast.Node]bool
+}
+
+// packageCoverage holds the siteCoverage of the packages being analyzed, keyed by
+// *types.Package; run drops the entry when it finishes
+var packageCoverage sync.Map
+
+// trackCoverage starts recording the trust decisions of the package
+func trackCoverage(pass *analysis.Pass) {
+	packageCoverage.Store(pass.Pkg, &siteCoverage{trusted: make(map[ast.Node]bool), suppressed: make(map[ast.Node]bool)})
+}
+
+// trustSite records that the response site holding node, or bound to the variable it
+// stores into or returns, was taken on trust
+func trustSite(node ast.Node, pass *analysis.Pass) {
+	markSite(node.Pos(), node.End(), pass, func(c *siteCoverage, site ast.Node) { c.trusted[site] = true })
+}
+
+// suppressSite records that a finding on a response site was suppressed
+func suppressSite(d analysis.Diagnostic, pass *analysis.Pass) {
+	markSite(d.Pos, d.End, pass, func(c *siteCoverage, site ast.Node) { c.suppressed[site] = true })
+}
+
+func markSite(pos, end token.Pos, pass *analysis.Pass, mark func(*siteCoverage, ast.Node)) {
+	v, ok := packageCoverage.Load(pass.Pkg)
+	if !ok {
+		return
+	}
+	site := siteAt(pos, end, pass)
+	if site == nil {
+		return
+	}
+	c := v.(*siteCoverage)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	mark(c, site)
+}
+
+// siteAt returns the response site the syntax from pos to end belongs to: the innermost
+// site enclosing it, or the site bound to the variable it names, assigns a field of or
+// is assigned to, or nil
+func siteAt(pos, end token.Pos, pass *analysis.Pass) ast.Node {
+	file := fileAt(pos, pass)
+	if file == nil {
+		return nil
+	}
+	if end < pos {
+		end = pos
+	}
+	path, _ := astutil.PathEnclosingInterval(file, pos, end)
+	for _, n := range path {
+		if site := responseSite(n, pass); site != nil {
+			return site
+		}
+		var target ast.Expr
+		switch n := n.(type) {
+		case *ast.Ident:
+			target = n
+		case *ast.AssignStmt:
+			if len(n.Lhs) == 1 {
+				target = n.Lhs[0]
+			}
+		case *ast.FuncDecl, *ast.FuncLit:
+			return nil
+		}
+		// resp.User.Address = a stores into resp
+		for target != nil {
+			sel := storedField(target, pass)
+			if sel == nil {
+				break
+			}
+			target = sel.X
+		}
+		if target == nil {
+			continue
+		}
+		if id := unwrapIdent(target, pass); id != nil {
+			if obj := pass.TypesInfo.ObjectOf(id); obj != nil {
+				if site := responseSite(declarations(pass).initializer(obj), pass); site != nil {
+					return site
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// responseSite returns the site n constructs: the literal of a checked message type in
+// X{...} or &X{...}, or a new(X) call, or nil
+func responseSite(n ast.Node, pass *analysis.Pass) ast.Node {
+	expr, ok := n.(ast.Expr)
+	if !ok {
+		return nil
+	}
+	if lit := messageLiteral(expr, shouldCheckType, pass); lit != nil {
+		return lit
+	}
+	if call := newMessageCall(expr, shouldCheckType, pass); call != nil {
+		return call
+	}
+	return nil
+}
+
+// countCoverage fills in result.Stats.Coverage from the sites of the files not skipped
+func countCoverage(pass *analysis.Pass, result *Result, skipped map[*token.File]bool) {
+	v, ok := packageCoverage.Load(pass.Pkg)
+	if !ok {
+		return
+	}
+	c := v.(*siteCoverage)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	coverage := &result.Stats.Coverage
+	for _, file := range pass.Files {
+		if skipped[pass.Fset.File(file.Pos())] {
+			continue
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n.(type) {
+			case *ast.CompositeLit, *ast.CallExpr:
+			default:
+				return true
+			}
+			site := responseSite(n, pass)
+			switch {
+			case site != n:
+			case c.suppressed[site]:
+				coverage.Suppressed++
+			case c.trusted[site]:
+				coverage.Trusted++
+			default:
+				coverage.Verified++
+			}
+			return true
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// declIndex records where the variables of a package are declared and how they are
+// used, from one walk over its files. Tracing a variable back to its declaration is
+// asked for each identifier the checks meet, and walking every file each time made
+// large packages quadratic.
+type declIndex struct {
+	// specs and defines are the var and := statements declaring each variable
+	specs   map[types.Object]*ast.ValueSpec
+	defines map[types.Object]*ast.AssignStmt
+
+	// reassigned holds the variables assigned after their declaration, by a plain
+	// assignment, as the key or value of a range clause, or through their address
+	reassigned map[types.Object]bool
+
+	// nilCompared holds the variables compared against nil with == or !=
+	nilCompared map[types.Object]bool
+
+	// params holds the parameters and receivers of the package's functions
+	params map[types.Object]bool
+
+	// used holds the objects referred to anywhere in the package
+	used map[types.Object]bool
+}
+
+// packageDecls maps each package being analyzed to its declIndex, built on first use
+var packageDecls sync.Map // *types.Package -> *declIndex
+
+// declarations returns the package's declIndex
+func declarations(pass *analysis.Pass) *declIndex {
+	if index, ok := packageDecls.Load(pass.Pkg); ok {
+		return index.(*declIndex)
+	}
+	index := buildDeclIndex(pass)
+	packageDecls.Store(pass.Pkg, index)
+	return index
+}
+
+func buildDeclIndex(pass *analysis.Pass) *declIndex {
+	index := &declIndex{
+		specs:       make(map[types.Object]*ast.ValueSpec),
+		defines:     make(map[types.Object]*ast.AssignStmt),
+		reassigned:  make(map[types.Object]bool),
+		nilCompared: make(map[types.Object]bool),
+		params:      make(map[types.Object]bool),
+		used:        make(map[types.Object]bool),
+	}
+	// uses returns the variable expr refers to, as an assignment's operand or nil
+	uses := func(expr ast.Expr) types.Object {
+		if ident, ok := expr.(*ast.Ident); ok {
+			return pass.TypesInfo.Uses[ident]
+		}
+		return nil
+	}
+	addParams := func(lists ...*ast.FieldList) {
+		for _, list := range lists {
+			if list == nil {
+				continue
+			}
+			for _, field := range list.List {
+				for _, name := range field.Names {
+					if obj := pass.TypesInfo.Defs[name]; obj != nil {
+						index.params[obj] = true
+					}
+				}
+			}
+		}
+	}
+
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.ValueSpec:
+				for _, name := range node.Names {
+					if obj := pass.TypesInfo.Defs[name]; obj != nil {
+						index.specs[obj] = node
+					}
+				}
+			case *ast.AssignStmt:
+				for _, lhs := range node.Lhs {
+					if id, ok := lhs.(*ast.Ident); ok && node.Tok == token.DEFINE {
+						if obj := pass.TypesInfo.Defs[id]; obj != nil {
+							index.defines[obj] = node
+						}
+					}
+					// Uses (not Defs) also catches := redeclaring an existing variable
+					if obj := uses(lhs); obj != nil {
+						index.reassigned[obj] = true
+					}
+				}
+			case *ast.RangeStmt:
+				if node.Tok == token.ASSIGN {
+					for _, expr := range []ast.Expr{node.Key, node.Value} {
+						if obj := uses(expr); obj != nil {
+							index.reassigned[obj] = true
+						}
+					}
+				}
+			case *ast.UnaryExpr:
+				if obj := uses(node.X); obj != nil && node.Op == token.AND {
+					index.reassigned[obj] = true
+				}
+			case *ast.BinaryExpr:
+				if node.Op != token.EQL && node.Op != token.NEQ {
+					break
+				}
+				for _, pair := range [][2]ast.Expr{{node.X, node.Y}, {node.Y, node.X}} {
+					if id, ok := ast.Unparen(pair[0]).(*ast.Ident); ok && isNilIdent(pair[1]) {
+						if obj := pass.TypesInfo.ObjectOf(id); obj != nil {
+							index.nilCompared[obj] = true
+						}
+					}
+				}
+			case *ast.FuncDecl:
+				addParams(node.Recv, node.Type.Params)
+			case *ast.FuncLit:
+				addParams(node.Type.Params)
+			}
+			return true
+		})
+	}
+	for _, obj := range pass.TypesInfo.Uses {
+		index.used[obj] = true
+	}
+	return index
+}
+
+// initializer returns the value a variable is declared with by := or var, when the
+// statement assigns one value to each name, or nil
+func (index *declIndex) initializer(obj types.Object) ast.Expr {
+	if assign := index.defines[obj]; assign != nil && len(assign.Lhs) == len(assign.Rhs) {
+		for i, lhs := range assign.Lhs {
+			if lhs.Pos() == obj.Pos() {
+				return assign.Rhs[i]
+			}
+		}
+	}
+	if spec := index.specs[obj]; spec != nil && len(spec.Names) == len(spec.Values) {
+		for i, name := range spec.Names {
+			if name.Pos() == obj.Pos() {
+				return spec.Values[i]
+			}
+		}
+	}
+	return nil
+}
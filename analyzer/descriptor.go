@@ -0,0 +1,311 @@
+package analyzer
+
+import (
+	"go/constant"
+	"go/types"
+	"strings"
+	"sync"
+
+	"github.com/nickheyer/go_no_nil_linter/internal/protopolicy"
+	"golang.org/x/tools/go/analysis"
+)
+
+// fieldBehaviorOutputOnly is google.api.FieldBehavior OUTPUT_ONLY
+const fieldBehaviorOutputOnly = 3
+
+// fieldBehaviorExtension is the field number of the google.api.field_behavior option
+const fieldBehaviorExtension = 1052
+
+// descriptorMetadata is what the analyzer reads from the file descriptors embedded in a
+// generated Go package: the google.api.field_behavior annotations of each field, its
+// serialized options and its proto3 optional fields, keyed by message name relative to
+// the proto package (Outer.Inner) and field number, and the extensions the package
+// declares, keyed by the name of their E_ variable. messages names the package's structs
+// by that key, and described holds the names the descriptors declare.
+type descriptorMetadata struct {
+	behaviors  map[string]map[int][]int
+	options    map[string]map[int]string
+	optional   map[string]map[int]bool
+	extensions map[string]*protoExtension
+	messages   map[*types.Struct]string
+	described  map[string]bool
+}
+
+// descriptorCache holds the descriptorMetadata of each *types.Package. The passes
+// running at once share the packages they import, and with them the metadata; a pass
+// drops the entries of the packages it sees when it finishes (see evictDescriptors),
+// so a long-lived process such as gopls doesn't keep the packages of earlier loads.
+var descriptorCache sync.Map
+
+// evictDescriptors drops the cached metadata of the packages a pass could see. A pass
+// running alongside that needs one again parses it anew.
+func evictDescriptors(pass *analysis.Pass) {
+	visible := visiblePackages(pass.Pkg)
+	descriptorCache.Range(func(key, _ interface{}) bool {
+		if visible[key.(*types.Package)] {
+			descriptorCache.Delete(key)
+		}
+		return true
+	})
+}
+
+// visiblePackages returns pkg and the packages it imports, directly or not: those whose
+// types a pass over pkg can meet
+func visiblePackages(pkg *types.Package) map[*types.Package]bool {
+	visible := make(map[*types.Package]bool)
+	var visit func(*types.Package)
+	visit = func(p *types.Package) {
+		if visible[p] {
+			return
+		}
+		visible[p] = true
+		for _, imp := range p.Imports() {
+			visit(imp)
+		}
+	}
+	visit(pkg)
+	return visible
+}
+
+// descriptorMetadataOf returns the metadata of a generated package, parsed from its
+// file_*_rawDesc string constants
+func descriptorMetadataOf(pkg *types.Package) *descriptorMetadata {
+	if cached, ok := descriptorCache.Load(pkg); ok {
+		return cached.(*descriptorMetadata)
+	}
+	meta := &descriptorMetadata{
+		behaviors:  make(map[string]map[int][]int),
+		options:    make(map[string]map[int]string),
+		optional:   make(map[string]map[int]bool),
+		extensions: make(map[string]*protoExtension),
+		messages:   make(map[*types.Struct]string),
+		described:  make(map[string]bool),
+	}
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		if obj, ok := scope.Lookup(name).(*types.TypeName); ok {
+			if structType, ok := obj.Type().Underlying().(*types.Struct); ok {
+				meta.messages[structType] = strings.ReplaceAll(name, "_", ".")
+			}
+		}
+		if !strings.HasPrefix(name, "file_") || !strings.HasSuffix(name, "_rawDesc") {
+			continue
+		}
+		c, ok := scope.Lookup(name).(*types.Const)
+		if !ok || c.Val().Kind() != constant.String {
+			continue
+		}
+		desc := constant.StringVal(c.Val())
+		protoPkg := descriptorPackage(desc)
+		walkDescriptor(desc, func(field, _ uint64, value string) {
+			switch field {
+			case 4: // message_type
+				meta.addMessage(protoPkg, "", value)
+			case 7: // extension
+				meta.addExtension(protoPkg, "", value)
+			}
+		})
+	}
+	cached, _ := descriptorCache.LoadOrStore(pkg, meta)
+	return cached.(*descriptorMetadata)
+}
+
+// addMessage records the field behaviors and options of a serialized DescriptorProto and
+// its nested messages, and the extensions declared in them
+func (m *descriptorMetadata) addMessage(protoPkg, prefix, desc string) {
+	var name string
+	var fields, nested, extensions []string
+	walkDescriptor(desc, func(field, _ uint64, value string) {
+		switch field {
+		case 1:
+			name = value
+		case 2:
+			fields = append(fields, value)
+		case 3:
+			nested = append(nested, value)
+		case 6:
+			extensions = append(extensions, value)
+		}
+	})
+	if name == "" {
+		return
+	}
+	name = prefix + name
+	m.described[name] = true
+
+	for _, f := range fields {
+		var number uint64
+		var behaviors []int
+		var options string
+		var optional bool
+		walkDescriptor(f, func(field, varint uint64, value string) {
+			switch field {
+			case 3: // number
+				number = varint
+			case 8: // options
+				behaviors = append(behaviors, optionFieldBehaviors(value)...)
+				options += value
+			case 17: // proto3_optional
+				optional = varint != 0
+			}
+		})
+		if options != "" {
+			if m.options[name] == nil {
+				m.options[name] = make(map[int]string)
+			}
+			m.options[name][int(number)] = options
+		}
+		if optional {
+			if m.optional[name] == nil {
+				m.optional[name] = make(map[int]bool)
+			}
+			m.optional[name][int(number)] = true
+		}
+		if len(behaviors) == 0 {
+			continue
+		}
+		if m.behaviors[name] == nil {
+			m.behaviors[name] = make(map[int][]int)
+		}
+		m.behaviors[name][int(number)] = behaviors
+	}
+	for _, n := range nested {
+		m.addMessage(protoPkg, name+".", n)
+	}
+	for _, e := range extensions {
+		m.addExtension(protoPkg, name, e)
+	}
+}
+
+// optionFieldBehaviors reads the google.api.field_behavior values from serialized
+// FieldOptions; the repeated enum may be packed or not
+func optionFieldBehaviors(options string) []int {
+	var behaviors []int
+	walkDescriptor(options, func(field, varint uint64, value string) {
+		if field != fieldBehaviorExtension {
+			return
+		}
+		if value == "" {
+			behaviors = append(behaviors, int(varint))
+			return
+		}
+		for value != "" {
+			v, rest, ok := readVarint(value)
+			if !ok {
+				return
+			}
+			behaviors = append(behaviors, int(v))
+			value = rest
+		}
+	})
+	return behaviors
+}
+
+// isOutputOnlyField checks if a field of a generated message is annotated
+// (google.api.field_behavior) = OUTPUT_ONLY: it is set by the server, and clients
+// should leave it unset
+func isOutputOnlyField(msgType types.Type, structType *types.Struct, field *types.Var) bool {
+	obj := namedTypeName(msgType)
+	if obj == nil || obj.Pkg() == nil {
+		return false
+	}
+	for i := 0; i < structType.NumFields(); i++ {
+		if messageSchema.FieldName(structType.Field(i)) != field.Name() {
+			continue
+		}
+		tag, ok := protopolicy.ParseTag(structType.Tag(i))
+		if !ok {
+			return false
+		}
+		message := strings.ReplaceAll(obj.Name(), "_", ".")
+		for _, behavior := range descriptorMetadataOf(obj.Pkg()).behaviors[message][tag.Number] {
+			if behavior == fieldBehaviorOutputOnly {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// isProto3OptionalField checks if a field of a generated message is declared optional in
+// a proto3 file. The descriptor puts such a field in a synthetic oneof of its own and
+// says so with proto3_optional, while generators don't all mark it in the struct tag.
+func isProto3OptionalField(structType *types.Struct, field *types.Var) bool {
+	name := messageSchema.FieldName(field)
+	if field.Pkg() == nil || name == "" {
+		return false
+	}
+	meta := descriptorMetadataOf(field.Pkg())
+	message, ok := meta.messages[structType]
+	if !ok || meta.optional[message] == nil {
+		return false
+	}
+	for i := 0; i < structType.NumFields(); i++ {
+		if messageSchema.FieldName(structType.Field(i)) != name {
+			continue
+		}
+		tag, ok := protopolicy.ParseTag(structType.Tag(i))
+		return ok && meta.optional[message][tag.Number]
+	}
+	return false
+}
+
+// walkDescriptor calls fn for each field of a serialized protobuf message with its
+// number and either its varint value or its length-delimited bytes. Fixed-width
+// fields are skipped; it stops at the first malformed field.
+func walkDescriptor(desc string, fn func(field, varint uint64, value string)) {
+	for desc != "" {
+		key, rest, ok := readVarint(desc)
+		if !ok {
+			return
+		}
+		desc = rest
+		field, wireType := key>>3, key&7
+		switch wireType {
+		case 0: // varint
+			v, rest, ok := readVarint(desc)
+			if !ok {
+				return
+			}
+			desc = rest
+			fn(field, v, "")
+		case 1: // 64-bit
+			if len(desc) < 8 {
+				return
+			}
+			desc = desc[8:]
+		case 2: // length-delimited
+			n, rest, ok := readVarint(desc)
+			if !ok || n > uint64(len(rest)) {
+				return
+			}
+			fn(field, 0, rest[:n])
+			desc = rest[n:]
+		case 5: // 32-bit
+			if len(desc) < 4 {
+				return
+			}
+			desc = desc[4:]
+		default:
+			return
+		}
+	}
+}
+
+// readVarint decodes a base 128 varint from the start of s
+func readVarint(s string) (uint64, string, bool) {
+	var v uint64
+	for shift := uint(0); shift < 64; shift += 7 {
+		if s == "" {
+			return 0, s, false
+		}
+		b := s[0]
+		s = s[1:]
+		v |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return v, s, true
+		}
+	}
+	return 0, s, false
+}
@@ -1,6 +1,7 @@
 package analyzer
 
 import (
+	"fmt"
 	"go/ast"
 	"go/token"
 	"go/types"
@@ -10,20 +11,14 @@ import (
 
 // isNilValue checks if an expression evaluates to nil
 func isNilValue(expr ast.Expr, pass *analysis.Pass) bool {
+	// Typed nil, (*Type)(nil), is nil under its conversion; see normalize.go
+	expr = unwrapExpr(expr, pass)
+
 	// Check for nil literal
 	if ident, ok := expr.(*ast.Ident); ok && ident.Name == "nil" {
 		return true
 	}
 
-	// Check for typed nil: (*Type)(nil)
-	if call, ok := expr.(*ast.CallExpr); ok {
-		if len(call.Args) == 1 {
-			if ident, ok := call.Args[0].(*ast.Ident); ok && ident.Name == "nil" {
-				return true
-			}
-		}
-	}
-
 	// Check for variable that might be nil
 	if ident, ok := expr.(*ast.Ident); ok {
 		if isNilVariable(ident, pass) {
@@ -41,6 +36,46 @@ func isNilValue(expr ast.Expr, pass *analysis.Pass) bool {
 	return false
 }
 
+// isZeroValueMessage checks if an expression is an empty well-known message such as
+// &timestamppb.Timestamp{} or new(timestamppb.Timestamp). These satisfy the nil check
+// but usually mean the linter was silenced rather than the data flow fixed.
+func isZeroValueMessage(expr ast.Expr, pass *analysis.Pass) bool {
+	expr = unwrapExpr(expr, pass)
+	if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		expr = unwrapExpr(unary.X, pass)
+	}
+
+	switch e := expr.(type) {
+	case *ast.CompositeLit:
+		if len(e.Elts) != 0 {
+			return false
+		}
+	case *ast.CallExpr:
+		ident, ok := e.Fun.(*ast.Ident)
+		if !ok || len(e.Args) != 1 {
+			return false
+		}
+		if _, ok := pass.TypesInfo.Uses[ident].(*types.Builtin); !ok || ident.Name != "new" {
+			return false
+		}
+	default:
+		return false
+	}
+
+	exprType := pass.TypesInfo.TypeOf(expr)
+	return exprType != nil && isWellKnownType(exprType)
+}
+
+// reportZeroValueMessage reports a non-optional field explicitly set to an empty well-known message
+func reportZeroValueMessage(pass *analysis.Pass, pos token.Pos, field fieldRef, msgType types.Type, valueType types.Type) {
+	reportField(pass, field, analysis.Diagnostic{
+		Pos:      pos,
+		Category: KindZeroValueMessage,
+		Message: fmt.Sprintf("non-optional message field '%s' in protobuf message %s is set to an empty %s; assign a real value instead of a zero-value placeholder",
+			field, describeType(pass, msgType), describeType(pass, valueType)),
+	})
+}
+
 // isNilVariable checks if a variable identifier is nil
 func isNilVariable(ident *ast.Ident, pass *analysis.Pass) bool {
 	// Get the object this identifier refers to
@@ -56,25 +91,15 @@ func isNilVariable(ident *ast.Ident, pass *analysis.Pass) bool {
 		}
 	}
 
-	// Try to find the variable declaration
-	var decl *ast.ValueSpec
-	for _, file := range pass.Files {
-		ast.Inspect(file, func(n ast.Node) bool {
-			if vs, ok := n.(*ast.ValueSpec); ok {
-				for _, name := range vs.Names {
-					if pass.TypesInfo.ObjectOf(name) == obj {
-						decl = vs
-						return false
-					}
-				}
-			}
-			return true
-		})
-		if decl != nil {
-			break
-		}
+	// The value reaching a field store accounts for reassignments, and the nil checks
+	// guarding the store; see ssaflow.go and nilness.go
+	if store := reachingStore(ident, pass); store != nil && !isMemoryLoad(store.Val) {
+		return nilnessAt(store.Val, store) == isNil
 	}
 
+	// Try to find the variable declaration
+	decl := findValueSpec(obj, pass)
+
 	if decl == nil {
 		// Could be a parameter or return value, assume not nil
 		return false
@@ -82,7 +107,12 @@ func isNilVariable(ident *ast.Ident, pass *analysis.Pass) bool {
 
 	// Check if it has an initializer
 	if len(decl.Values) == 0 {
-		// No initializer means zero value
+		// No initializer means zero value, unless the variable is assigned later
+		// (e.g. inside a loop body or range clause)
+		if isReassigned(obj, pass) {
+			return false
+		}
+
 		// For pointers and interfaces, zero value is nil
 		objType := obj.Type()
 		if _, ok := objType.(*types.Pointer); ok {
@@ -104,26 +134,74 @@ func isNilVariable(ident *ast.Ident, pass *analysis.Pass) bool {
 	return false
 }
 
-// validateMessageValue recursively validates a message value for nil fields
-func validateMessageValue(expr ast.Expr, exprType types.Type, pass *analysis.Pass, fieldContext string) {
+// isReassigned checks if a variable is assigned after its declaration, either by a plain
+// assignment, as the key or value of a range clause, or through its address.
+// Such a variable can't be assumed to still hold its zero value.
+func isReassigned(obj types.Object, pass *analysis.Pass) bool {
+	return declarations(pass).reassigned[obj]
+}
+
+// isMockValue checks if a value comes from a generated mock package, either because its
+// type is declared there or because it is produced by a function or variable declared there.
+// Mock internals are not meaningful message data, so they are not validated recursively.
+func isMockValue(expr ast.Expr, exprType types.Type, pass *analysis.Pass) bool {
+	if obj := namedTypeName(exprType); obj != nil && obj.Pkg() != nil && isMockPackage(obj.Pkg().Path()) {
+		return true
+	}
+
+	for {
+		switch e := expr.(type) {
+		case *ast.ParenExpr:
+			expr = e.X
+			continue
+		case *ast.UnaryExpr:
+			expr = e.X
+			continue
+		case *ast.CallExpr:
+			expr = e.Fun
+			continue
+		case *ast.SelectorExpr:
+			if obj := pass.TypesInfo.ObjectOf(e.Sel); obj != nil && obj.Pkg() != nil && isMockPackage(obj.Pkg().Path()) {
+				return true
+			}
+			expr = e.X
+			continue
+		case *ast.Ident:
+			obj := pass.TypesInfo.ObjectOf(e)
+			return obj != nil && obj.Pkg() != nil && isMockPackage(obj.Pkg().Path())
+		}
+		return false
+	}
+}
+
+// validateMessageValue recursively validates a message value for nil fields.
+// requestSide is set when the value is nested in a request message, whose
+// OUTPUT_ONLY fields are left to the server.
+func validateMessageValue(expr ast.Expr, exprType types.Type, pass *analysis.Pass, fieldContext fieldRef, requestSide bool) {
+	expr = unwrapExpr(expr, pass)
+	if isMockValue(expr, exprType, pass) {
+		log().Debug("skipping mock value", "field", fieldContext.path, "pos", pass.Fset.Position(expr.Pos()))
+		return
+	}
+
 	switch e := expr.(type) {
 	case *ast.Ident:
 		// Variable reference - try to trace to its declaration
-		validateVariableMessage(e, exprType, pass, fieldContext)
+		validateVariableMessage(e, exprType, pass, fieldContext, requestSide)
 
 	case *ast.CompositeLit:
 		// Struct literal - check its fields recursively
-		validateCompositeLiteralMessage(e, exprType, pass, fieldContext)
+		validateCompositeLiteralMessage(e, exprType, pass, fieldContext, requestSide)
 
 	case *ast.CallExpr:
-		// Function call - we can't easily analyze what it returns
-		// Conservative approach: assume it's valid
-		return
+		// Function call - helpers summarized by an incompleteReturnFact are reported,
+		// anything else is assumed valid; see returns.go
+		checkCallResult(e, pass, fieldContext, token.NoPos)
 
 	case *ast.UnaryExpr:
 		// Address operation (&expr)
 		if e.Op == token.AND {
-			validateMessageValue(e.X, exprType, pass, fieldContext)
+			validateMessageValue(e.X, exprType, pass, fieldContext, requestSide)
 		}
 
 	case *ast.SelectorExpr:
@@ -133,54 +211,32 @@ func validateMessageValue(expr ast.Expr, exprType types.Type, pass *analysis.Pas
 	}
 }
 
-// validateVariableMessage traces a variable to its declaration and validates it
-func validateVariableMessage(ident *ast.Ident, exprType types.Type, pass *analysis.Pass, fieldContext string) {
+// validateVariableMessage traces a variable to the value it holds where it is stored,
+// or else to its declaration, and validates it
+func validateVariableMessage(ident *ast.Ident, exprType types.Type, pass *analysis.Pass, fieldContext fieldRef, requestSide bool) {
 	obj := pass.TypesInfo.ObjectOf(ident)
 	if obj == nil {
 		return
 	}
 
-	// Find the variable declaration - handle both var and := declarations
-	var decl *ast.ValueSpec
-	var declAssign *ast.AssignStmt
-	
-	for _, file := range pass.Files {
-		ast.Inspect(file, func(n ast.Node) bool {
-			// Check for short variable declaration (:=)
-			if assign, ok := n.(*ast.AssignStmt); ok && assign.Tok == token.DEFINE {
-				for _, lhs := range assign.Lhs {
-					if id, ok := lhs.(*ast.Ident); ok {
-						if pass.TypesInfo.ObjectOf(id) == obj {
-							declAssign = assign
-							return false
-						}
-					}
-				}
-			}
-			
-			// Check for var declaration
-			if vs, ok := n.(*ast.ValueSpec); ok {
-				for _, name := range vs.Names {
-					if pass.TypesInfo.ObjectOf(name) == obj {
-						decl = vs
-						return false
-					}
-				}
-			}
-			return true
-		})
-		if decl != nil || declAssign != nil {
-			break
+	if v := reachingValue(ident, pass); v != nil && !isMemoryLoad(v) {
+		if value, ok := reachingSyntax(v, pass); ok {
+			handleValidation(value, exprType, pass, fieldContext, requestSide, ident.Pos())
 		}
+		return
 	}
-	
+
+	// Find the variable declaration - handle both var and := declarations
+	index := declarations(pass)
+	decl, declAssign := index.specs[obj], index.defines[obj]
+
 	// Handle short declaration (:=)
 	if declAssign != nil {
 		for i, lhs := range declAssign.Lhs {
 			if id, ok := lhs.(*ast.Ident); ok && pass.TypesInfo.ObjectOf(id) == obj {
 				if i < len(declAssign.Rhs) {
 					value := declAssign.Rhs[i]
-					handleValidation(value, exprType, pass, fieldContext, ident.Pos())
+					handleValidation(value, exprType, pass, fieldContext, requestSide, ident.Pos())
 				}
 				return
 			}
@@ -194,10 +250,16 @@ func validateVariableMessage(ident *ast.Ident, exprType types.Type, pass *analys
 
 	// If no initializer, it's zero value (nil for pointers)
 	if len(decl.Values) == 0 {
+		if isReassigned(obj, pass) {
+			return
+		}
 		if _, ok := exprType.(*types.Pointer); ok {
-			pass.Reportf(ident.Pos(),
-				"variable '%s' used for field '%s' is nil (zero value)",
-				ident.Name, fieldContext)
+			reportField(pass, fieldContext, analysis.Diagnostic{
+				Pos:      ident.Pos(),
+				Category: KindNilVariable,
+				Message: fmt.Sprintf("variable '%s' used for field '%s' is nil (zero value)%s",
+					ident.Name, fieldContext, nilProvenance(ident, pass)),
+			})
 		}
 		return
 	}
@@ -206,18 +268,26 @@ func validateVariableMessage(ident *ast.Ident, exprType types.Type, pass *analys
 	for i, name := range decl.Names {
 		if pass.TypesInfo.ObjectOf(name) == obj && i < len(decl.Values) {
 			value := decl.Values[i]
-			handleValidation(value, exprType, pass, fieldContext, ident.Pos())
+			handleValidation(value, exprType, pass, fieldContext, requestSide, ident.Pos())
 		}
 	}
 }
 
 // handleValidation processes a value expression for validation
-func handleValidation(value ast.Expr, exprType types.Type, pass *analysis.Pass, fieldContext string, reportPos token.Pos) {
+func handleValidation(value ast.Expr, exprType types.Type, pass *analysis.Pass, fieldContext fieldRef, requestSide bool, reportPos token.Pos) {
+	value = unwrapExpr(value, pass)
+
+	// Handle a helper call, u := createUser()
+	if call, ok := value.(*ast.CallExpr); ok {
+		checkCallResult(call, pass, fieldContext, reportPos)
+		return
+	}
+
 	// Handle direct composite literal
 	if comp, ok := value.(*ast.CompositeLit); ok {
 		compType := pass.TypesInfo.TypeOf(comp)
 		if compType != nil {
-			validateCompositeLiteralMessageAtUse(comp, compType, pass, fieldContext, reportPos)
+			validateCompositeLiteralMessageAtUse(comp, compType, pass, fieldContext, requestSide, reportPos)
 		}
 		return
 	}
@@ -228,7 +298,7 @@ func handleValidation(value ast.Expr, exprType types.Type, pass *analysis.Pass,
 			// Get the type of the composite literal itself (without the &)
 			compType := pass.TypesInfo.TypeOf(comp)
 			if compType != nil {
-				validateCompositeLiteralMessageAtUse(comp, compType, pass, fieldContext, reportPos)
+				validateCompositeLiteralMessageAtUse(comp, compType, pass, fieldContext, requestSide, reportPos)
 			}
 		}
 	}
@@ -236,19 +306,24 @@ func handleValidation(value ast.Expr, exprType types.Type, pass *analysis.Pass,
 
 // validateCompositeLiteralMessage recursively validates a composite literal
 // This is called when validating fields within a Response message
-func validateCompositeLiteralMessage(lit *ast.CompositeLit, litType types.Type, pass *analysis.Pass, fieldContext string) {
+func validateCompositeLiteralMessage(lit *ast.CompositeLit, litType types.Type, pass *analysis.Pass, fieldContext fieldRef, requestSide bool) {
 	// Get the struct type
 	structType := getStructType(litType)
 	if structType == nil {
 		return
 	}
 
-	// Get all message fields for this type
-	// When we're recursively validating, we check ALL message types, not just Response types
-	messageFields := getMessageFields(structType)
-	if len(messageFields) == 0 {
+	// Stop at cycles and at -max-depth; see recursion.go
+	leave, ok := enterLiteral(lit, pass)
+	if !ok {
 		return
 	}
+	defer leave()
+
+	// Get all message fields for this type
+	// When we're recursively validating, we check ALL message types, not just Response types.
+	// A message without any still has its map fields checked below.
+	messageFields := requiredFields(structType, litType, requestSide)
 
 	// Track which fields are initialized
 	initialized := make(map[string]bool)
@@ -268,6 +343,10 @@ func validateCompositeLiteralMessage(lit *ast.CompositeLit, litType types.Type,
 
 		fieldName := fieldIdent.Name
 		initialized[fieldName] = true
+		if f := getFieldFromType(litType, fieldName); f != nil {
+			checkMapValues(kv.Value, f, litType, pass)
+			checkRepeatedValues(kv.Value, f, litType, pass)
+		}
 
 		// Find the corresponding field
 		var field *types.Var
@@ -282,42 +361,69 @@ func validateCompositeLiteralMessage(lit *ast.CompositeLit, litType types.Type,
 			continue
 		}
 
-		// Check if value is nil
-		if isNilValue(kv.Value, pass) {
-			pass.Reportf(kv.Value.Pos(),
-				"nil assignment to non-optional message field '%s.%s' in protobuf message '%s'",
-				fieldContext, fieldName, litType.String())
+		// Check if value is nil; a recursive field ends its chain there
+		if isNilValue(kv.Value, pass) && isRecursiveField(litType, field, requestSide) {
+			reportRecursiveField(kv.Value.Pos(), fieldContext.child(fieldName), litType, pass)
+		} else if isNilValue(kv.Value, pass) {
+			reportField(pass, fieldContext.child(fieldName), analysis.Diagnostic{
+				Pos:      kv.Value.Pos(),
+				Category: KindNestedNil,
+				Message: fmt.Sprintf("nil assignment to non-optional message field '%s.%s' in protobuf message %s%s%s",
+					fieldContext, fieldName, describeType(pass, litType), nilProvenance(kv.Value, pass), gatewayNote(litType, fieldName)),
+				SuggestedFixes: nilTimestampFix(kv.Value, field, pass),
+			})
+		} else if isZeroValueMessage(kv.Value, pass) {
+			reportZeroValueMessage(pass, kv.Value.Pos(), fieldContext.child(fieldName), litType, pass.TypesInfo.TypeOf(kv.Value))
 		} else {
+			reportUnverified(kv.Value, fieldContext.child(fieldName), litType, pass)
+
 			// Recursively validate non-nil message values
 			valueType := pass.TypesInfo.TypeOf(kv.Value)
 			if valueType != nil && isProtobufMessageType(valueType) {
-				nestedContext := fieldContext + "." + fieldName
-				validateMessageValue(kv.Value, valueType, pass, nestedContext)
+				nestedContext := fieldContext.child(fieldName)
+				validateMessageValue(kv.Value, valueType, pass, nestedContext, requestSide)
 			}
 		}
 	}
 
-	// Check for uninitialized required message fields
+	// Check for uninitialized required message fields, other than those the function
+	// sets after building the response; see collectLaterFields
 	for _, field := range messageFields {
-		if !initialized[field.Name()] {
-			pass.Reportf(lit.Pos(),
-				"non-optional message field '%s.%s' not initialized in protobuf message '%s'",
-				fieldContext, field.Name(), litType.String())
+		if initialized[field.Name()] || setLater(lit, field.Name(), pass) {
+			continue
+		}
+		if isRecursiveField(litType, field, requestSide) {
+			reportRecursiveField(lit.Pos(), fieldContext.child(field.Name()), litType, pass)
+		} else {
+			reportField(pass, fieldContext.child(field.Name()), analysis.Diagnostic{
+				Pos:      lit.Pos(),
+				Category: KindNestedNil,
+				Message: fmt.Sprintf("non-optional message field '%s.%s' not initialized in protobuf message %s%s",
+					fieldContext, field.Name(), describeType(pass, litType), gatewayNote(litType, field.Name())),
+				SuggestedFixes: missingFieldFix(lit, field, requestSide, pass),
+			})
 		}
 	}
 }
 
 // validateCompositeLiteralMessageAtUse is like validateCompositeLiteralMessage but reports errors
 // at a specific position (where the variable is used, not where it's declared)
-func validateCompositeLiteralMessageAtUse(lit *ast.CompositeLit, litType types.Type, pass *analysis.Pass, fieldContext string, reportPos token.Pos) {
+func validateCompositeLiteralMessageAtUse(lit *ast.CompositeLit, litType types.Type, pass *analysis.Pass, fieldContext fieldRef, requestSide bool, reportPos token.Pos) {
 	// Get the struct type
 	structType := getStructType(litType)
 	if structType == nil {
 		return
 	}
 
+	// Stop at cycles and at -max-depth; see recursion.go
+	leave, ok := enterLiteral(lit, pass)
+	if !ok {
+		return
+	}
+	defer leave()
+
 	// Get all message fields for this type
-	messageFields := getMessageFields(structType)
+	messageFields := requiredFields(structType, litType, requestSide)
 	if len(messageFields) == 0 {
 		return
 	}
@@ -354,87 +460,110 @@ func validateCompositeLiteralMessageAtUse(lit *ast.CompositeLit, litType types.T
 			continue
 		}
 
-		// Check if value is nil
-		if isNilValue(kv.Value, pass) {
-			pass.Reportf(reportPos,
-				"variable used in '%s' has nil in non-optional message field '%s' of type '%s'",
-				fieldContext, fieldName, litType.String())
+		// Check if value is nil; a recursive field ends its chain there
+		if isNilValue(kv.Value, pass) && isRecursiveField(litType, field, requestSide) {
+			reportRecursiveField(reportPos, fieldContext.child(fieldName), litType, pass)
+		} else if isNilValue(kv.Value, pass) {
+			reportField(pass, fieldContext.child(fieldName), analysis.Diagnostic{
+				Pos:      reportPos,
+				Category: KindNestedNil,
+				Message: fmt.Sprintf("variable used in '%s' has nil in non-optional message field '%s' of type %s%s%s",
+					fieldContext, fieldName, describeType(pass, litType), nilProvenance(kv.Value, pass), gatewayNote(litType, fieldName)),
+			})
+		} else if isZeroValueMessage(kv.Value, pass) {
+			reportZeroValueMessage(pass, reportPos, fieldContext.child(fieldName), litType, pass.TypesInfo.TypeOf(kv.Value))
 		} else {
 			// Recursively validate non-nil message values
 			valueType := pass.TypesInfo.TypeOf(kv.Value)
 			if valueType != nil && isProtobufMessageType(valueType) {
-				nestedContext := fieldContext + "." + fieldName
+				nestedContext := fieldContext.child(fieldName)
 				// Continue recursive validation but still report at original use position
-				validateMessageValueAtPos(kv.Value, valueType, pass, nestedContext, reportPos)
+				validateMessageValueAtPos(kv.Value, valueType, pass, nestedContext, requestSide, reportPos)
 			}
 		}
 	}
 
 	// Check for uninitialized required message fields and report at use position
 	for _, field := range messageFields {
-		if !initialized[field.Name()] {
-			pass.Reportf(reportPos,
-				"variable used in '%s' has uninitialized non-optional message field '%s' of type '%s'",
-				fieldContext, field.Name(), litType.String())
+		if initialized[field.Name()] {
+			continue
+		}
+		if isRecursiveField(litType, field, requestSide) {
+			reportRecursiveField(reportPos, fieldContext.child(field.Name()), litType, pass)
+		} else {
+			reportField(pass, fieldContext.child(field.Name()), analysis.Diagnostic{
+				Pos:      reportPos,
+				Category: KindNestedNil,
+				Message: fmt.Sprintf("variable used in '%s' has uninitialized non-optional message field '%s' of type %s%s",
+					fieldContext, field.Name(), describeType(pass, litType), gatewayNote(litType, field.Name())),
+				SuggestedFixes: missingFieldFix(lit, field, requestSide, pass),
+			})
 		}
 	}
 }
 
 // validateMessageValueAtPos is like validateMessageValue but reports at a specific position
-func validateMessageValueAtPos(expr ast.Expr, exprType types.Type, pass *analysis.Pass, fieldContext string, reportPos token.Pos) {
+func validateMessageValueAtPos(expr ast.Expr, exprType types.Type, pass *analysis.Pass, fieldContext fieldRef, requestSide bool, reportPos token.Pos) {
+	expr = unwrapExpr(expr, pass)
+	if isMockValue(expr, exprType, pass) {
+		log().Debug("skipping mock value", "field", fieldContext.path, "pos", pass.Fset.Position(expr.Pos()))
+		return
+	}
+
 	switch e := expr.(type) {
 	case *ast.Ident:
 		// Variable reference - trace and validate at reportPos
-		validateVariableMessageAtPos(e, exprType, pass, fieldContext, reportPos)
+		validateVariableMessageAtPos(e, exprType, pass, fieldContext, requestSide, reportPos)
 
 	case *ast.CompositeLit:
 		// Struct literal - validate at reportPos
-		validateCompositeLiteralMessageAtUse(e, exprType, pass, fieldContext, reportPos)
+		validateCompositeLiteralMessageAtUse(e, exprType, pass, fieldContext, requestSide, reportPos)
+
+	case *ast.CallExpr:
+		// Helper call - report what it leaves unset at reportPos
+		checkCallResult(e, pass, fieldContext, reportPos)
 
 	case *ast.UnaryExpr:
 		// Address operation (&expr)
 		if e.Op == token.AND {
-			validateMessageValueAtPos(e.X, exprType, pass, fieldContext, reportPos)
+			validateMessageValueAtPos(e.X, exprType, pass, fieldContext, requestSide, reportPos)
 		}
 	}
 }
 
 // validateVariableMessageAtPos is like validateVariableMessage but reports at a specific position
-func validateVariableMessageAtPos(ident *ast.Ident, exprType types.Type, pass *analysis.Pass, fieldContext string, reportPos token.Pos) {
+func validateVariableMessageAtPos(ident *ast.Ident, exprType types.Type, pass *analysis.Pass, fieldContext fieldRef, requestSide bool, reportPos token.Pos) {
 	obj := pass.TypesInfo.ObjectOf(ident)
 	if obj == nil {
 		return
 	}
 
-	// Find the variable declaration
-	var decl *ast.ValueSpec
-	for _, file := range pass.Files {
-		ast.Inspect(file, func(n ast.Node) bool {
-			if vs, ok := n.(*ast.ValueSpec); ok {
-				for _, name := range vs.Names {
-					if pass.TypesInfo.ObjectOf(name) == obj {
-						decl = vs
-						return false
-					}
-				}
-			}
-			return true
-		})
-		if decl != nil {
-			break
+	if v := reachingValue(ident, pass); v != nil && !isMemoryLoad(v) {
+		if value, ok := reachingSyntax(v, pass); ok {
+			handleValidation(value, exprType, pass, fieldContext, requestSide, reportPos)
 		}
+		return
 	}
 
+	// Find the variable declaration
+	decl := findValueSpec(obj, pass)
+
 	if decl == nil {
 		return
 	}
 
 	// If no initializer, it's zero value (nil for pointers)
 	if len(decl.Values) == 0 {
+		if isReassigned(obj, pass) {
+			return
+		}
 		if _, ok := exprType.(*types.Pointer); ok {
-			pass.Reportf(reportPos,
-				"variable '%s' used for field '%s' is nil (zero value)",
-				ident.Name, fieldContext)
+			reportField(pass, fieldContext, analysis.Diagnostic{
+				Pos:      reportPos,
+				Category: KindNilVariable,
+				Message: fmt.Sprintf("variable '%s' used for field '%s' is nil (zero value)%s",
+					ident.Name, fieldContext, nilProvenance(ident, pass)),
+			})
 		}
 		return
 	}
@@ -445,7 +574,7 @@ func validateVariableMessageAtPos(ident *ast.Ident, exprType types.Type, pass *a
 			value := decl.Values[i]
 			
 			if comp, ok := value.(*ast.CompositeLit); ok {
-				validateCompositeLiteralMessageAtUse(comp, exprType, pass, fieldContext, reportPos)
+				validateCompositeLiteralMessageAtUse(comp, exprType, pass, fieldContext, requestSide, reportPos)
 				continue
 			}
 			
@@ -454,7 +583,7 @@ func validateVariableMessageAtPos(ident *ast.Ident, exprType types.Type, pass *a
 					// Get the type of the composite literal itself (without the &)
 					compType := pass.TypesInfo.TypeOf(comp)
 					if compType != nil {
-						validateCompositeLiteralMessageAtUse(comp, compType, pass, fieldContext, reportPos)
+						validateCompositeLiteralMessageAtUse(comp, compType, pass, fieldContext, requestSide, reportPos)
 					}
 				}
 			}
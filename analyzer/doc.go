@@ -0,0 +1,17 @@
+// Package analyzer defines nonillinter, an Analyzer that reports nil and uninitialized
+// non-optional message fields in protobuf responses.
+//
+// Integrators build on the exported API: golangci-lint plugins configure it through
+// Configure, Bazel nogo and go vet drivers run Analyzer, and report tooling reads
+// Result, its Findings, Kind and the policy types, and commands of their own run it with
+// the driver package. It follows semantic versioning: within a major version, exported
+// identifiers of this package, the driver and the passes packages are only added,
+// never removed or changed in an incompatible way. api_test.go holds the
+// exported API against testdata/api.txt, so a breaking change fails the tests rather
+// than a downstream build.
+//
+// Code that other packages of this module share, but integrators shouldn't depend on
+// yet, goes in internal packages instead, where it can change between minor versions.
+// Analyzer flags, diagnostic messages and suggested fixes are not part of the Go API;
+// see the Violation Kinds section of the README for what stays stable there.
+package analyzer
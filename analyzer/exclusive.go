@@ -0,0 +1,160 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// exclusivePair names a data field and an error or status field of a response, of which
+// exactly one is set: a response carries either its payload or the reason it has none
+type exclusivePair struct {
+	data   string
+	status string
+}
+
+// exclusivePairsFlag is a flag.Value holding the pairs set via -exclusive-fields,
+// written as comma-separated data/status field names, e.g. "User/Error,Data/Status"
+type exclusivePairsFlag []exclusivePair
+
+func (f *exclusivePairsFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	pairs := make([]string, len(*f))
+	for i, pair := range *f {
+		pairs[i] = pair.data + "/" + pair.status
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f *exclusivePairsFlag) Set(value string) error {
+	var pairs []exclusivePair
+	for _, entry := range splitPatterns(value) {
+		data, status, ok := strings.Cut(entry, "/")
+		data, status = strings.TrimSpace(data), strings.TrimSpace(status)
+		if !ok || data == "" || status == "" || data == status {
+			return fmt.Errorf("invalid field pair %q, want DataField/StatusField", entry)
+		}
+		pairs = append(pairs, exclusivePair{data: data, status: status})
+	}
+	*f = pairs
+	return nil
+}
+
+// exclusivePairsOf returns the -exclusive-fields pairs whose fields a message struct has both of
+func exclusivePairsOf(structType *types.Struct) []exclusivePair {
+	if structType == nil || len(exclusiveFields) == 0 {
+		return nil
+	}
+	names := make(map[string]bool, structType.NumFields())
+	for i := 0; i < structType.NumFields(); i++ {
+		names[structType.Field(i).Name()] = true
+	}
+	var pairs []exclusivePair
+	for _, pair := range exclusiveFields {
+		if names[pair.data] && names[pair.status] {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
+// exclusiveFieldNames returns the fields of a message struct that belong to an exclusive pair
+func exclusiveFieldNames(structType *types.Struct) map[string]bool {
+	pairs := exclusivePairsOf(structType)
+	if len(pairs) == 0 {
+		return nil
+	}
+	names := make(map[string]bool, 2*len(pairs))
+	for _, pair := range pairs {
+		names[pair.data] = true
+		names[pair.status] = true
+	}
+	return names
+}
+
+// checkExclusiveFields reports returns of response messages that set both or neither
+// field of an -exclusive-fields pair, e.g. with User/Error:
+//
+//	return &pb.GetUserResponse{User: u, Error: status}, nil // both
+//	return &pb.GetUserResponse{}, nil                      // neither
+//
+// Returned literals are judged by their elements, and tracked response variables by
+// their literal plus the assignments on the path to the return. Variables handed to
+// other functions, and other returned values, can't be judged and are skipped.
+func checkExclusiveFields(body *ast.BlockStmt, tracked map[types.Object]*trackedResponse, pass *analysis.Pass) {
+	if len(exclusiveFields) == 0 {
+		return
+	}
+
+	var stack []ast.Node
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		stack = append(stack, n)
+
+		ret, ok := n.(*ast.ReturnStmt)
+		if !ok {
+			return true
+		}
+		for _, result := range ret.Results {
+			var set map[string]bool
+			var msgType types.Type
+			maybe := make(map[string]bool)
+			if lit := messageLiteral(result, shouldCheckType, pass); lit != nil {
+				set, msgType = literalFields(lit, pass), pass.TypesInfo.TypeOf(lit)
+			} else if id, ok := result.(*ast.Ident); ok {
+				t := tracked[pass.TypesInfo.ObjectOf(id)]
+				if t == nil || t.passedToCall {
+					continue
+				}
+				set, msgType = t.fields(pass), t.litType
+				assigned, conditional := assignedFieldsOnPath(stack, t.obj, pass)
+				for name := range assigned {
+					set[name] = true
+				}
+				// A field set on some paths may be the one that is set; only
+				// fields set on every path make a pair "both set"
+				for name := range conditional {
+					maybe[name] = true
+				}
+			} else {
+				continue
+			}
+			reportExclusiveFields(result, msgType, set, maybe, pass)
+		}
+		return true
+	})
+}
+
+// reportExclusiveFields reports each exclusive pair of msgType that set doesn't hold
+// exactly one field of. Fields in maybe are set on some paths only, so a pair with one
+// of them isn't "neither set".
+func reportExclusiveFields(result ast.Expr, msgType types.Type, set, maybe map[string]bool, pass *analysis.Pass) {
+	for _, pair := range exclusivePairsOf(getStructType(msgType)) {
+		var state string
+		switch {
+		case set[pair.data] && set[pair.status]:
+			state = "both set"
+		case !set[pair.data] && !set[pair.status] && !maybe[pair.data] && !maybe[pair.status]:
+			state = "neither set"
+		default:
+			continue
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos:      result.Pos(),
+			Category: KindExclusiveFields,
+			Message: fmt.Sprintf("fields '%s' and '%s' of protobuf message %s are %s; exactly one should be set at each return",
+				pair.data, pair.status, describeType(pass, msgType), state),
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/types"
+	"reflect"
+)
+
+// docsURL is where the rules are documented
+const docsURL = "https://github.com/nickheyer/go_no_nil_linter"
+
+// FieldRequirement explains whether the analyzer requires a field of a message type, and
+// why, for editors showing it on hover; see ExplainField
+type FieldRequirement struct {
+	// Message is the proto full name of the message declaring the field, and Field the
+	// name code sets it by
+	Message string `json:"message"`
+	Field   string `json:"field"`
+
+	// Required reports whether the field must be set wherever Message is checked
+	Required bool `json:"required"`
+
+	// Checked reports whether Message is checked where it is built, as a response or
+	// under -check-all-messages; other messages are checked when nested in one that is
+	Checked bool `json:"checked"`
+
+	// Reason says what decided Required: the field's kind, its struct tag, the embedded
+	// descriptor, a directive or a flag
+	Reason string `json:"reason"`
+
+	// Rule links to the documentation of the rule behind Reason
+	Rule string `json:"rule"`
+}
+
+// ExplainField explains the requiredness of a field of the message type msg under the
+// current flags, as the checks decide it: see getMessageFields and requiredFields. It
+// returns false when msg isn't a message or field isn't one of its fields.
+func ExplainField(msg *types.TypeName, field *types.Var) (FieldRequirement, bool) {
+	structType := getStructType(msg.Type())
+	if structType == nil || !isProtobufMessageType(msg.Type()) {
+		return FieldRequirement{}, false
+	}
+	index := -1
+	for i := 0; i < structType.NumFields(); i++ {
+		if structType.Field(i) == field {
+			index = i
+		}
+	}
+	if index < 0 {
+		return FieldRequirement{}, false
+	}
+
+	name := messageSchema.FieldName(field)
+	req := FieldRequirement{
+		Message: protoFullName(msg),
+		Field:   name,
+		Checked: shouldCheckType(msg.Type()),
+		Rule:    docsURL + "#what-it-ignores",
+	}
+	if name == "" {
+		req.Field = field.Name()
+		req.Reason = "code outside the generated package can't set the field"
+		return req, true
+	}
+
+	for _, required := range requiredFields(structType, msg.Type(), isRequestMessage(msg.Type())) {
+		if required.Name() != name {
+			continue
+		}
+		req.Required = true
+		req.Rule = docsURL + "#what-it-checks"
+		marked, _ := markedRequired(msg.Type(), structType, name)
+		switch {
+		case marked:
+			req.Reason = fmt.Sprintf("the field is marked (%s) = true in the .proto file, and -required-option requires the fields marked so", requiredOption.name)
+		case !containsField(getMessageFields(structType), name):
+			// Only the flags add fields that aren't required by default
+			req.Reason = "the field is part of a oneof, required by -require-oneofs"
+			if _, ok := field.Type().Underlying().(*types.Map); ok {
+				req.Reason = "the field is a map of messages, whose entries are required by -require-map-entries"
+			}
+		default:
+			req.Reason = fmt.Sprintf("a singular message field without explicit presence is left nil only by mistake, so every %s must set it", msg.Name())
+		}
+		return req, true
+	}
+
+	switch field.Type().Underlying().(type) {
+	case *types.Slice:
+		req.Reason = "repeated fields may be empty, so they aren't required; nil elements are reported"
+		return req, true
+	case *types.Map:
+		req.Reason = "map fields may be empty, so they aren't required unless -require-map-entries is set; nil values are reported"
+		return req, true
+	}
+	switch {
+	case !isMessageField(field):
+		req.Reason = "scalar fields always have a usable value, so they aren't required"
+	case isOptionalFieldOf(msg.Type(), name):
+		req.Reason = "the field is exempted by -optional-fields"
+	case isOptionalType(field.Type()):
+		req.Reason = fmt.Sprintf("the field holds %s, declared optional with a nonil:optional directive or -optional-types", protoFullName(namedTypeName(field.Type())))
+	case isProto3OptionalField(structType, field):
+		req.Reason = "the field is declared optional in the .proto file, as read from the descriptor embedded in the generated code"
+	case messageSchema.IsOptionalField(structType, field):
+		req.Reason = fmt.Sprintf("the field's struct tag marks it optional or part of a oneof: protobuf:%q", reflect.StructTag(structType.Tag(index)).Get("protobuf"))
+	case exclusiveFieldNames(structType)[name]:
+		req.Rule = docsURL + "#error-messages"
+		req.Reason = "the field is paired with another by -exclusive-fields, so exactly one of them is set at each return"
+	case isRequestMessage(msg.Type()) && isOutputOnlyField(msg.Type(), structType, field):
+		req.Reason = "the field is annotated OUTPUT_ONLY, so the server sets it and requests leave it out"
+	default:
+		req.Reason = "the field isn't required under the current settings"
+		if _, decided := markedRequired(msg.Type(), structType, name); decided {
+			req.Reason = fmt.Sprintf("the field isn't marked (%s) = true in the .proto file, and -required-option requires only the fields marked so", requiredOption.name)
+		}
+	}
+	return req, true
+}
+
+// containsField checks if fields holds a field named name
+func containsField(fields []*types.Var, name string) bool {
+	for _, field := range fields {
+		if field.Name() == name {
+			return true
+		}
+	}
+	return false
+}
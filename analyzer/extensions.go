@@ -0,0 +1,158 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// protoExtension is an extension field declared in a generated package, as read from
+// its file descriptor
+type protoExtension struct {
+	// FullName is the proto full name, e.g. "api.audit.audit"
+	FullName string
+
+	Number int
+
+	// Extendee is the proto full name of the message the extension extends
+	Extendee string
+
+	// Message is the proto full name of the extension's message type, or "" for
+	// extensions of other types, which can't be nil
+	Message string
+}
+
+// FieldDescriptorProto types of message values
+const (
+	descriptorTypeGroup   = 10
+	descriptorTypeMessage = 11
+)
+
+// addExtension records a serialized FieldDescriptorProto declaring an extension, at the
+// top level of the file or in the message named parent (Outer.Inner). Extensions are
+// keyed by their E_ variable, named as protoc-gen-go names it: E_Name at the top level
+// and E_Outer_Inner_Name in a message.
+func (m *descriptorMetadata) addExtension(protoPkg, parent, desc string) {
+	var name, extendee, typeName string
+	var number, typ uint64
+	walkDescriptor(desc, func(field, varint uint64, value string) {
+		switch field {
+		case 1:
+			name = value
+		case 2:
+			extendee = strings.TrimPrefix(value, ".")
+		case 3:
+			number = varint
+		case 5:
+			typ = varint
+		case 6:
+			typeName = strings.TrimPrefix(value, ".")
+		}
+	})
+	if name == "" {
+		return
+	}
+
+	ext := &protoExtension{Number: int(number), Extendee: extendee}
+	if typ == descriptorTypeMessage || typ == descriptorTypeGroup {
+		ext.Message = typeName
+	}
+	goName := "E_" + goCamelCase(name)
+	ext.FullName = name
+	if parent != "" {
+		goName = "E_" + goCamelCase(parent) + "_" + goCamelCase(name)
+		ext.FullName = parent + "." + name
+	}
+	if protoPkg != "" {
+		ext.FullName = protoPkg + "." + ext.FullName
+	}
+	m.extensions[goName] = ext
+}
+
+// goCamelCase converts a proto name to the Go name protoc-gen-go generates for it:
+// snake_case becomes CamelCase and the dots between nested names underscores
+func goCamelCase(s string) string {
+	isLower := func(c byte) bool { return 'a' <= c && c <= 'z' }
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '.' && i+1 < len(s) && isLower(s[i+1]):
+			// Skip the dot in ".lower"
+		case c == '.':
+			b = append(b, '_')
+		case c == '_' && (i == 0 || s[i-1] == '.'):
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isLower(s[i+1]):
+			// Skip the underscore in "_lower"
+		case '0' <= c && c <= '9':
+			b = append(b, c)
+		default:
+			if isLower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(s) && isLower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+// extensionOf returns the extension an E_ variable of a generated package holds, or nil
+func extensionOf(expr ast.Expr, pass *analysis.Pass) *protoExtension {
+	var id *ast.Ident
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		id = e
+	case *ast.SelectorExpr:
+		id = e.Sel
+	default:
+		return nil
+	}
+	v, ok := pass.TypesInfo.Uses[id].(*types.Var)
+	if !ok || v.Pkg() == nil || v.Parent() != v.Pkg().Scope() {
+		return nil
+	}
+	return descriptorMetadataOf(v.Pkg()).extensions[v.Name()]
+}
+
+// checkSetExtension checks proto.SetExtension(m, xt, v) calls that set a message
+// extension of a checked message: v must not be nil, and a message literal must have
+// its own required fields set, as for a field assignment
+func checkSetExtension(call *ast.CallExpr, pass *analysis.Pass) {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Name() != "SetExtension" || fn.Pkg() == nil || fn.Pkg().Path() != "google.golang.org/protobuf/proto" || len(call.Args) != 3 {
+		return
+	}
+	msgType := pass.TypesInfo.TypeOf(call.Args[0])
+	if ptr, ok := msgType.(*types.Pointer); ok {
+		msgType = ptr.Elem()
+	}
+	if msgType == nil || !shouldCheckType(msgType) {
+		return
+	}
+	ext := extensionOf(call.Args[1], pass)
+	if ext == nil || ext.Message == "" {
+		return
+	}
+
+	value := call.Args[2]
+	if isNilValue(value, pass) {
+		reportField(pass, rootField(msgType, ext.FullName), analysis.Diagnostic{
+			Pos:      value.Pos(),
+			Category: nilKind(value, pass),
+			Message: fmt.Sprintf("nil assignment to message extension '%s' of protobuf message %s",
+				ext.FullName, describeType(pass, msgType)),
+		})
+		return
+	}
+	if valueType := pass.TypesInfo.TypeOf(value); valueType != nil && isProtobufMessageType(valueType) {
+		validateMessageValue(value, valueType, pass, rootField(msgType, ext.FullName), isRequestMessage(msgType))
+	}
+}
@@ -0,0 +1,95 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// Finding pairs a reported diagnostic with the syntax it was reported on, so codemod
+// tools built on go/ast can rewrite the flagged literal or assignment directly instead
+// of patching text at an offset.
+type Finding struct {
+	// Diagnostic is the diagnostic as reported to the driver
+	Diagnostic analysis.Diagnostic
+
+	// Node is the flagged syntax: the largest expression (or assignment) starting at the
+	// diagnostic position, e.g. the nil value of `User: nil` or a composite literal
+	// missing required fields
+	Node ast.Node
+
+	// Path lists the nodes enclosing Node, from Node itself out to the *ast.File;
+	// Path[1] is the assignment or key-value pair holding a flagged value
+	Path []ast.Node
+
+	// File is the file containing Node and Fset the file set its positions refer to
+	File *ast.File
+	Fset *token.FileSet
+
+	// MessageType and FieldPath name the message field the finding is about, if any:
+	// the message type the check started from, e.g. "userpb.UserResponse", and the
+	// path to the field from it, e.g. "UserResponse.User.Address.Location"
+	MessageType, FieldPath string
+
+	// Severity is the severity of the finding, its kind's unless a mode raised it
+	Severity Severity
+}
+
+// recordFindings wraps pass.Report so every diagnostic is also added to result.Findings
+func recordFindings(pass *analysis.Pass, result *Result) {
+	report := pass.Report
+	pass.Report = func(d analysis.Diagnostic) {
+		finding := newFinding(pass, d)
+		finding.Severity = severityAt(pass, d.Category, d.Pos)
+		if r, ok := reportingField.Load(pass); ok && r.(fieldReport).pos == d.Pos && r.(fieldReport).message == d.Message {
+			finding.MessageType, finding.FieldPath = r.(fieldReport).field.describe(pass)
+		}
+		result.Findings = append(result.Findings, finding)
+		report(d)
+	}
+}
+
+// newFinding locates the syntax a diagnostic was reported on
+func newFinding(pass *analysis.Pass, d analysis.Diagnostic) Finding {
+	finding := Finding{Diagnostic: d, Fset: pass.Fset}
+	for _, file := range pass.Files {
+		if d.Pos < file.Pos() || d.Pos > file.End() {
+			continue
+		}
+		end := d.End
+		if end < d.Pos {
+			end = d.Pos
+		}
+		path, _ := astutil.PathEnclosingInterval(file, d.Pos, end)
+		if len(path) == 0 {
+			break
+		}
+
+		// Widen to the largest expression (or assignment) that starts at the
+		// diagnostic, e.g. from the field name to the whole `User: nil` pair
+		node := 0
+	widen:
+		for i, n := range path {
+			if n.Pos() != d.Pos {
+				break
+			}
+			switch n.(type) {
+			case ast.Expr:
+				node = i
+			case *ast.AssignStmt:
+				node = i
+				break widen
+			default:
+				break widen
+			}
+		}
+
+		finding.Node = path[node]
+		finding.Path = path[node:]
+		finding.File = file
+		break
+	}
+	return finding
+}
@@ -0,0 +1,196 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// fixPackages are the packages a -fix-timestamp-expr expression may refer to, by name
+var fixPackages = map[string]string{
+	"timestamppb": "google.golang.org/protobuf/types/known/timestamppb",
+	"time":        "time",
+}
+
+// missingFieldFix suggests adding an uninitialized field to the literal that leaves it
+// out, set to an empty message that has its own required message fields filled in the
+// same way:
+//
+//	Address: &pb.Address{Location: &pb.Location{}}
+//
+// Timestamps are set to the -fix-timestamp-expr expression, importing what it needs.
+// The value is a valid starting point rather than real data. No fix is offered when a
+// message in it is another well-known type, whose empty value the zero-value-message
+// rule reports as a placeholder, when its package isn't imported by the file, or when
+// the message is recursive. Literals of opaque messages can't set fields at all; see
+// opaque.go.
+func missingFieldFix(lit *ast.CompositeLit, field *types.Var, requestSide bool, pass *analysis.Pass) []analysis.SuggestedFix {
+	file := fileAt(lit.Pos(), pass)
+	if file == nil || isOpaqueMessage(pass.TypesInfo.TypeOf(lit)) {
+		return nil
+	}
+	imports := make(map[string]bool)
+	value, ok := emptyMessageValue(field.Type(), file, requestSide, make(map[*types.TypeName]bool), imports, pass)
+	if !ok {
+		return nil
+	}
+	message := fmt.Sprintf(emptyMessageFixFormat, field.Name())
+	if isTimestampType(field.Type()) {
+		message = fmt.Sprintf(timestampFieldFormat, field.Name(), value)
+	}
+	edits := []analysis.TextEdit{insertElementEdit(lit, field.Name()+": "+value, pass)}
+	return []analysis.SuggestedFix{{
+		Message:   message,
+		TextEdits: append(importEdits(file, imports), edits...),
+	}}
+}
+
+// nilTimestampFix suggests replacing a nil literal set to a Timestamp field with the
+// -fix-timestamp-expr expression, importing what it needs
+func nilTimestampFix(value ast.Expr, field *types.Var, pass *analysis.Pass) []analysis.SuggestedFix {
+	id, ok := ast.Unparen(value).(*ast.Ident)
+	if !ok || !pass.TypesInfo.Types[id].IsNil() || !isTimestampType(field.Type()) {
+		return nil
+	}
+	file := fileAt(value.Pos(), pass)
+	if file == nil {
+		return nil
+	}
+	imports := make(map[string]bool)
+	replacement, ok := timestampValue(file, imports)
+	if !ok {
+		return nil
+	}
+	edits := []analysis.TextEdit{{Pos: id.Pos(), End: id.End(), NewText: []byte(replacement)}}
+	return []analysis.SuggestedFix{{
+		Message:   fmt.Sprintf(timestampNilFormat, replacement),
+		TextEdits: append(importEdits(file, imports), edits...),
+	}}
+}
+
+// emptyMessageValue renders &T{...} for a message type t, with its required message
+// fields set recursively, as the file would write it. Packages the value needs that
+// the file has yet to import are added to imports.
+func emptyMessageValue(t types.Type, file *ast.File, requestSide bool, seen map[*types.TypeName]bool, imports map[string]bool, pass *analysis.Pass) (string, bool) {
+	if isTimestampType(t) {
+		return timestampValue(file, imports)
+	}
+	prefix := ""
+	if ptr, ok := t.(*types.Pointer); ok {
+		prefix, t = "&", ptr.Elem()
+	}
+	obj := namedTypeName(t)
+	structType := getStructType(t)
+	if obj == nil || structType == nil || seen[obj] || isWellKnownType(t) || !fileCanName(file, obj.Pkg(), pass) {
+		return "", false
+	}
+	seen[obj] = true
+	defer delete(seen, obj)
+
+	var elts []string
+	required := requiredFields(structType, t, requestSide)
+	if len(required) > 0 && isOpaqueMessage(t) {
+		return "", false
+	}
+	for _, field := range required {
+		value, ok := emptyMessageValue(field.Type(), file, requestSide, seen, imports, pass)
+		if !ok {
+			return "", false
+		}
+		elts = append(elts, field.Name()+": "+value)
+	}
+	return prefix + types.TypeString(t, fileQualifier(file, pass)) + "{" + strings.Join(elts, ", ") + "}", true
+}
+
+// isTimestampType checks if t is *timestamppb.Timestamp
+func isTimestampType(t types.Type) bool {
+	ptr, ok := t.(*types.Pointer)
+	if !ok {
+		return false
+	}
+	obj := namedTypeName(ptr.Elem())
+	return obj != nil && obj.Pkg() != nil && obj.Pkg().Path() == fixPackages["timestamppb"] && obj.Name() == "Timestamp"
+}
+
+// timestampValue renders the -fix-timestamp-expr expression with the names the file
+// uses for its packages. Packages the file has yet to import are added to imports.
+func timestampValue(file *ast.File, imports map[string]bool) (string, bool) {
+	names := make(map[string]string)
+	for _, pkg := range qualifiers(fixTimestampExpr.expr) {
+		name, missing := importName(file, fixPackages[pkg], pkg)
+		if name == "" {
+			return "", false
+		}
+		names[pkg] = name
+		if missing {
+			imports[fixPackages[pkg]] = true
+		}
+	}
+	// Rename qualifiers on a copy; the parsed flag value is shared by every fix
+	expr, _ := parser.ParseExpr(fixTimestampExpr.src)
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				id.Name = names[id.Name]
+			}
+		}
+		return true
+	})
+	var buf bytes.Buffer
+	if err := format.Node(&buf, token.NewFileSet(), expr); err != nil {
+		return "", false
+	}
+	return buf.String(), true
+}
+
+// importEdits returns the edit adding the imports, in path order, or none
+func importEdits(file *ast.File, imports map[string]bool) []analysis.TextEdit {
+	if len(imports) == 0 {
+		return nil
+	}
+	var paths []string
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return []analysis.TextEdit{addImportsEdit(file, paths)}
+}
+
+// fileCanName checks if the file can refer to a package's types: the package under
+// analysis, or one the file imports under a name
+func fileCanName(file *ast.File, pkg *types.Package, pass *analysis.Pass) bool {
+	if pkg == pass.Pkg {
+		return true
+	}
+	for _, imp := range file.Imports {
+		if path, _ := strconv.Unquote(imp.Path.Value); path == pkg.Path() {
+			return imp.Name == nil || (imp.Name.Name != "_" && imp.Name.Name != ".")
+		}
+	}
+	return false
+}
+
+// insertElementEdit adds an element at the end of a literal, after a comma on the same
+// line, or on a line of its own when the literal spans several
+func insertElementEdit(lit *ast.CompositeLit, elt string, pass *analysis.Pass) analysis.TextEdit {
+	if len(lit.Elts) == 0 {
+		return analysis.TextEdit{Pos: lit.Rbrace, End: lit.Rbrace, NewText: []byte(elt)}
+	}
+	last := lit.Elts[len(lit.Elts)-1]
+	closing := pass.Fset.Position(lit.Rbrace)
+	if pass.Fset.Position(last.End()).Line == closing.Line {
+		return analysis.TextEdit{Pos: last.End(), End: last.End(), NewText: []byte(", " + elt)}
+	}
+	// gofmt indents with tabs, one column each
+	indent := strings.Repeat("\t", closing.Column-1)
+	return analysis.TextEdit{Pos: lit.Rbrace, End: lit.Rbrace, NewText: []byte("\t" + elt + ",\n" + indent)}
+}
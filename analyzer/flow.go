@@ -0,0 +1,908 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// trackedResponse is a response bound to a local variable, e.g. resp := &pb.X{} or
+// resp := new(pb.X). Its required fields are evaluated where the variable escapes
+// through a return rather than where it is built, since fields are usually filled in
+// afterwards, one statement at a time.
+type trackedResponse struct {
+	obj types.Object
+
+	// init is the expression the variable is bound to: lit, a new(T) call, a clone or a
+	// builder's Build call
+	init ast.Expr
+
+	// lit is the message literal, or the builder literal of a Build call, nil for new(T)
+	lit     *ast.CompositeLit
+	litType types.Type
+
+	// template is the literal a proto.Clone copy starts out as; see protoruntime.go
+	template *ast.CompositeLit
+
+	// passedToCall is set when the variable is handed to a function that may fill it in
+	passedToCall bool
+}
+
+// collectTrackedResponses finds response literals and new(T) values bound to local
+// variables in a function body, or assigned to a named result once at its top level.
+// Variables that are reassigned or have their address taken are not tracked and keep
+// literal-site evaluation.
+func collectTrackedResponses(body *ast.BlockStmt, pass *analysis.Pass) map[types.Object]*trackedResponse {
+	return collectTrackedLiterals(body, shouldCheckType, pass)
+}
+
+// collectTrackedLiterals finds literals and new(T) values of message types accepted by
+// accept that are bound to local variables in a function body, under the rules of collectTrackedResponses
+func collectTrackedLiterals(body *ast.BlockStmt, accept func(types.Type) bool, pass *analysis.Pass) map[types.Object]*trackedResponse {
+	tracked := make(map[types.Object]*trackedResponse)
+	disqualified := make(map[types.Object]bool)
+
+	// Named results are bound by an assignment in the body rather than declared there
+	results := make(map[types.Object]bool)
+	if sig := enclosingSignature(body, pass); sig != nil {
+		for i := 0; i < sig.Results().Len(); i++ {
+			results[sig.Results().At(i)] = true
+		}
+	}
+	topLevel := make(map[ast.Stmt]bool, len(body.List))
+	for _, stmt := range body.List {
+		topLevel[stmt] = true
+	}
+
+	track := func(name *ast.Ident, value ast.Expr) bool {
+		obj := pass.TypesInfo.ObjectOf(name)
+		if obj == nil {
+			return false
+		}
+		t := &trackedResponse{obj: obj}
+		if lit := messageLiteral(value, accept, pass); lit != nil {
+			t.init, t.lit, t.litType = lit, lit, pass.TypesInfo.TypeOf(lit)
+		} else if call := newMessageCall(value, accept, pass); call != nil {
+			t.init, t.litType = call, pass.TypesInfo.TypeOf(call.Args[0])
+		} else if lit, msgType := builtLiteral(value, accept, pass); lit != nil {
+			t.init, t.lit, t.litType = value, lit, msgType
+		} else if template, cloneType := clonedTemplate(value, accept, pass); template != nil {
+			t.init, t.template, t.litType = value, template, cloneType
+		} else {
+			return false
+		}
+		if _, seen := tracked[obj]; seen {
+			disqualified[obj] = true
+			return true
+		}
+		tracked[obj] = t
+		return true
+	}
+
+	inspectFunctionBody(body, func(n ast.Node) {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range node.Lhs {
+				id := unwrapIdent(lhs, pass)
+				if id == nil {
+					continue
+				}
+				if node.Tok == token.DEFINE && len(node.Lhs) == len(node.Rhs) {
+					track(id, node.Rhs[i])
+					continue
+				}
+				if node.Tok == token.ASSIGN && topLevel[node] && len(node.Lhs) == len(node.Rhs) &&
+					results[pass.TypesInfo.ObjectOf(id)] && track(id, node.Rhs[i]) {
+					continue
+				}
+				// Reassignment of a tracked variable breaks the link to its literal
+				if obj := pass.TypesInfo.ObjectOf(id); obj != nil {
+					disqualified[obj] = true
+				}
+			}
+
+		case *ast.ValueSpec:
+			if len(node.Names) == len(node.Values) {
+				for i, name := range node.Names {
+					track(name, node.Values[i])
+				}
+			}
+
+		case *ast.UnaryExpr:
+			if id := unwrapIdent(node.X, pass); id != nil && node.Op == token.AND {
+				if obj := pass.TypesInfo.ObjectOf(id); obj != nil {
+					disqualified[obj] = true
+				}
+			}
+
+		case *ast.CallExpr:
+			// Wrapping the response, for a Connect reply or in a generic wrapper, doesn't
+			// fill it in; see connect.go and generics.go
+			if responseWrapper(node, pass) {
+				return
+			}
+			wrapped := make(map[ast.Expr]bool)
+			for _, value := range wrappedValues(node, pass) {
+				wrapped[value] = true
+			}
+			for i, arg := range node.Args {
+				if wrapped[arg] || keepsArgument(node, i, pass) {
+					continue
+				}
+				if id, ok := arg.(*ast.Ident); ok {
+					if t := tracked[pass.TypesInfo.ObjectOf(id)]; t != nil {
+						t.passedToCall = true
+					}
+				}
+			}
+		}
+	})
+
+	for obj := range disqualified {
+		delete(tracked, obj)
+	}
+	return tracked
+}
+
+// messageLiteral returns the composite literal in X{...} or &X{...} when accept
+// takes its type, or nil
+func messageLiteral(expr ast.Expr, accept func(types.Type) bool, pass *analysis.Pass) *ast.CompositeLit {
+	if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		expr = unary.X
+	}
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+	litType := pass.TypesInfo.TypeOf(lit)
+	if litType == nil || !accept(litType) {
+		return nil
+	}
+	return lit
+}
+
+// newMessageCall returns expr when it is a new(T) call for a message type accepted by
+// accept, or nil
+func newMessageCall(expr ast.Expr, accept func(types.Type) bool, pass *analysis.Pass) *ast.CallExpr {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return nil
+	}
+	id, ok := ast.Unparen(call.Fun).(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	if _, ok := pass.TypesInfo.Uses[id].(*types.Builtin); !ok || id.Name != "new" {
+		return nil
+	}
+	t := pass.TypesInfo.TypeOf(call.Args[0])
+	if t == nil || !accept(t) {
+		return nil
+	}
+	return call
+}
+
+// checkTrackedResponses evaluates each tracked response at the returns that hand it back.
+// Only assignments on the path to a return count: statements in enclosing blocks that run
+// before it. Early returns that don't return the variable are not evaluated.
+func checkTrackedResponses(body *ast.BlockStmt, tracked map[types.Object]*trackedResponse, pass *analysis.Pass) {
+	returned := make(map[types.Object]bool)
+
+	var stack []ast.Node
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		stack = append(stack, n)
+
+		ret, ok := n.(*ast.ReturnStmt)
+		if !ok {
+			return true
+		}
+		responses, positions := returnedResponses(ret, tracked, pass)
+		for i, t := range responses {
+			returned[t.obj] = true
+			if t.passedToCall {
+				continue
+			}
+			if allowsPartialResponse(ret, pass) {
+				// A builder's response is verified where its caller returns it
+				if !returnsToCaller(ret, pass) {
+					trustSite(t.init, pass)
+				}
+				continue
+			}
+			assigned, conditional := assignedFieldsOnPath(stack, t.obj, pass)
+			reportMissingFields(t, assigned, conditional, positions[i], pass)
+		}
+		return true
+	})
+
+	// Responses that never escape through a return are evaluated at the literal,
+	// counting assignments anywhere in the function
+	for _, t := range sortedTracked(tracked) {
+		if t.passedToCall {
+			trustSite(t.init, pass)
+		}
+		if returned[t.obj] || t.passedToCall {
+			continue
+		}
+		assigned := make(map[string]bool)
+		collectCapturedAssignments(body, t.obj, assigned, pass)
+		inspectFunctionBody(body, func(n ast.Node) {
+			switch node := n.(type) {
+			case *ast.AssignStmt:
+				collectFieldAssignments(node, t.obj, assigned, pass)
+			case *ast.CallExpr:
+				collectSetterAssignments(node, t.obj, assigned, pass)
+			}
+		})
+		reportMissingFields(t, assigned, nil, t.init.Pos(), pass)
+	}
+}
+
+// returnedResponses returns the tracked responses a return statement hands back, with
+// the positions to report them at: its results or, for a naked return, the named
+// results bound before it
+func returnedResponses(ret *ast.ReturnStmt, tracked map[types.Object]*trackedResponse, pass *analysis.Pass) (responses []*trackedResponse, positions []token.Pos) {
+	for _, result := range ret.Results {
+		id, ok := unwrapResponse(result, pass).(*ast.Ident)
+		if !ok {
+			continue
+		}
+		if t := tracked[pass.TypesInfo.ObjectOf(id)]; t != nil {
+			responses, positions = append(responses, t), append(positions, id.Pos())
+		}
+	}
+	if len(ret.Results) > 0 {
+		return responses, positions
+	}
+	sig := enclosingSignature(ret, pass)
+	if sig == nil {
+		return nil, nil
+	}
+	for i := 0; i < sig.Results().Len(); i++ {
+		if t := tracked[sig.Results().At(i)]; t != nil && t.init.Pos() < ret.Pos() {
+			responses, positions = append(responses, t), append(positions, ret.Pos())
+		}
+	}
+	return responses, positions
+}
+
+// sortedTracked returns the tracked responses in source order, so diagnostics are
+// reported in the same order on every run
+func sortedTracked(tracked map[types.Object]*trackedResponse) []*trackedResponse {
+	sorted := make([]*trackedResponse, 0, len(tracked))
+	for _, t := range tracked {
+		sorted = append(sorted, t)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].init.Pos() < sorted[j].init.Pos()
+	})
+	return sorted
+}
+
+// assignedFieldsOnPath collects fields of obj assigned by statements that precede the
+// innermost node of stack in each enclosing block. Fields assigned on only some paths
+// there, by one branch of an if, switch or select or in the body of a loop that may
+// not run, are returned in conditional instead, mapped to the condition under which
+// they are set. Fields assigned by a function literal count as assigned: it may run
+// anywhere before the return.
+func assignedFieldsOnPath(stack []ast.Node, obj types.Object, pass *analysis.Pass) (assigned map[string]bool, conditional map[string]string) {
+	assigned = make(map[string]bool)
+	conditional = make(map[string]string)
+	if len(stack) > 0 {
+		collectCapturedAssignments(stack[0], obj, assigned, pass)
+	}
+	for i := 0; i < len(stack)-1; i++ {
+		var stmts []ast.Stmt
+		switch block := stack[i].(type) {
+		case *ast.BlockStmt:
+			stmts = block.List
+		case *ast.CaseClause:
+			stmts = block.Body
+		case *ast.CommClause:
+			stmts = block.Body
+		case *ast.IfStmt:
+			stmts = []ast.Stmt{block.Init}
+		case *ast.SwitchStmt:
+			stmts = []ast.Stmt{block.Init}
+		case *ast.TypeSwitchStmt:
+			stmts = []ast.Stmt{block.Init}
+		case *ast.ForStmt:
+			stmts = []ast.Stmt{block.Init}
+		default:
+			continue
+		}
+		collectStatementAssignments(stmts, stack[i+1], obj, assigned, conditional, pass)
+	}
+	for name := range assigned {
+		delete(conditional, name)
+	}
+	return assigned, conditional
+}
+
+// collectCapturedAssignments records the fields of obj assigned inside the function
+// literals of n
+func collectCapturedAssignments(n ast.Node, obj types.Object, assigned map[string]bool, pass *analysis.Pass) {
+	ast.Inspect(n, func(n ast.Node) bool {
+		lit, ok := n.(*ast.FuncLit)
+		if !ok {
+			return true
+		}
+		ast.Inspect(lit.Body, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.AssignStmt:
+				collectFieldAssignments(node, obj, assigned, pass)
+			case *ast.CallExpr:
+				collectSetterAssignments(node, obj, assigned, pass)
+			}
+			return true
+		})
+		return false
+	})
+}
+
+// collectStatementAssignments records field assignments made by stmts up to stop.
+// Compound statements are followed into their blocks: if, switch and select statements
+// branch (see mergeBranches), and loops are left through their ends and breaks (see
+// collectLoop).
+func collectStatementAssignments(stmts []ast.Stmt, stop ast.Node, obj types.Object, assigned map[string]bool, conditional map[string]string, pass *analysis.Pass) {
+	for _, stmt := range stmts {
+		if stmt == nil {
+			continue
+		}
+		if stmt == stop {
+			return
+		}
+		// Breaks and continues may name the loop or switch by its label
+		var label *ast.Ident
+		if labeled, ok := stmt.(*ast.LabeledStmt); ok {
+			label, stmt = labeled.Label, labeled.Stmt
+			if stmt == stop {
+				return
+			}
+		}
+		switch s := stmt.(type) {
+		case *ast.AssignStmt:
+			collectFieldAssignments(s, obj, assigned, pass)
+		case *ast.ExprStmt:
+			if call, ok := s.X.(*ast.CallExpr); ok {
+				collectSetterAssignments(call, obj, assigned, pass)
+			}
+		case *ast.BlockStmt:
+			collectStatementAssignments(s.List, nil, obj, assigned, conditional, pass)
+		case *ast.IfStmt:
+			collectBranchAssignments(s, "", obj, assigned, conditional, pass)
+		case *ast.SwitchStmt:
+			collectStatementAssignments([]ast.Stmt{s.Init}, nil, obj, assigned, conditional, pass)
+			mergeBranches(switchBranches(s, label, obj, pass), assigned, conditional)
+		case *ast.TypeSwitchStmt:
+			collectStatementAssignments([]ast.Stmt{s.Init}, nil, obj, assigned, conditional, pass)
+			mergeBranches(typeSwitchBranches(s, label, obj, pass), assigned, conditional)
+		case *ast.SelectStmt:
+			mergeBranches(selectBranches(s, label, obj, pass), assigned, conditional)
+		case *ast.ForStmt, *ast.RangeStmt:
+			collectLoop(s, label, obj, assigned, conditional, pass)
+		}
+	}
+}
+
+// collectLoop records the field assignments of a for or range loop, labeled label if
+// it has one, for the code that follows it. That code runs once the loop ends or
+// breaks, so a field counts as assigned when every way out of the loop sets it; see
+// loopExits. A loop that may not run at all sets its fields only under the condition
+// for it to run, while one that always runs at least once (for without a condition, or
+// range over a positive constant, e.g. for i := range 3) sets them outright.
+func collectLoop(loop ast.Stmt, label *ast.Ident, obj types.Object, assigned map[string]bool, conditional map[string]string, pass *analysis.Pass) {
+	var body *ast.BlockStmt
+	var guard string
+	ends := true
+	switch l := loop.(type) {
+	case *ast.ForStmt:
+		collectStatementAssignments([]ast.Stmt{l.Init}, nil, obj, assigned, conditional, pass)
+		body = l.Body
+		if l.Cond == nil {
+			ends = false
+		} else {
+			guard = types.ExprString(l.Cond)
+		}
+	case *ast.RangeStmt:
+		body = l.Body
+		if !rangesOverPositiveConstant(l, pass) {
+			guard = rangeGuard(l, pass)
+		}
+	}
+	if guard == "" {
+		mergeBranches(loopExits(body, label, ends, "the loop runs to the end", obj, pass), assigned, conditional)
+		return
+	}
+	b := newPathBranch(guard)
+	mergeBranches(loopExits(body, label, ends, guard, obj, pass), b.assigned, b.conditional)
+	mergeBranches([]pathBranch{b, newPathBranch("")}, make(map[string]bool), conditional)
+}
+
+// loopExits returns the ways out of a loop body to the code after the loop, with the
+// fields assigned on the way: its breaks and continues, as a continue may end the loop
+// at its head, and the end of the body, taken under endGuard, unless the loop only ends
+// by a break (ends is false) or something always leaves the body before it
+func loopExits(body *ast.BlockStmt, label *ast.Ident, ends bool, endGuard string, obj types.Object, pass *analysis.Pass) []pathBranch {
+	exits := jumpExits(body, label, true, obj, pass)
+	if ends && reachesEnd(body.List, pass) {
+		end := newPathBranch(endGuard)
+		collectStatementAssignments(body.List, nil, obj, end.assigned, end.conditional, pass)
+		exits = append(exits, end)
+	}
+	return exits
+}
+
+// jumpExits returns the ways out of a loop, switch or select, labeled label if it has
+// one, through the breaks in block, its body, and through its continues when it is a
+// loop, with the fields assigned on the way to each. Breaks and continues for an inner
+// loop or switch stay inside it, and those for an outer loop skip the code after this
+// statement too; neither is a way out.
+func jumpExits(block ast.Node, label *ast.Ident, loop bool, obj types.Object, pass *analysis.Pass) []pathBranch {
+	var exits []pathBranch
+	var stack []ast.Node
+	ast.Inspect(block, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		stack = append(stack, n)
+		br, ok := n.(*ast.BranchStmt)
+		if !ok || !jumpsOut(br, stack[1:len(stack)-1], label, loop) {
+			return true
+		}
+		exit := newPathBranch(fmt.Sprintf("the %s at %s is taken", br.Tok, shortPosition(pass, br.Pos())))
+		exit.assigned, exit.conditional = assignedFieldsOnPath(stack, obj, pass)
+		exits = append(exits, exit)
+		return true
+	})
+	return exits
+}
+
+// jumpsOut reports whether br, nested in the nodes between, leaves the loop, switch or
+// select holding them, labeled label if it has one
+func jumpsOut(br *ast.BranchStmt, between []ast.Node, label *ast.Ident, loop bool) bool {
+	if br.Tok != token.BREAK && (br.Tok != token.CONTINUE || !loop) {
+		return false
+	}
+	if br.Label != nil {
+		return label != nil && br.Label.Name == label.Name
+	}
+	for _, n := range between {
+		switch n.(type) {
+		case *ast.ForStmt, *ast.RangeStmt:
+			return false
+		case *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+			if br.Tok == token.BREAK {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// rangeGuard returns the condition for the body of a range loop to run, e.g.
+// "len(users) > 0"
+func rangeGuard(rng *ast.RangeStmt, pass *analysis.Pass) string {
+	x := types.ExprString(rng.X)
+	t := pass.TypesInfo.TypeOf(rng.X)
+	if t == nil {
+		return "the loop over " + x + " runs"
+	}
+	if ptr, ok := t.Underlying().(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		if u.Info()&types.IsInteger != 0 {
+			return x + " > 0"
+		}
+		if u.Info()&types.IsString != 0 {
+			return "len(" + x + ") > 0"
+		}
+	case *types.Slice, *types.Map, *types.Array:
+		return "len(" + x + ") > 0"
+	}
+	return "the loop over " + x + " runs"
+}
+
+// pathBranch holds the field assignments of one way through a statement that chooses
+// between blocks, and the guard it is taken under. A branch that leaves, through a
+// return, goto or panic, never reaches the code after the statement. Neither does one
+// that breaks or continues: it goes to the end or the head of its loop or switch
+// instead, which count its assignments as a way out; see jumpExits.
+type pathBranch struct {
+	guard       string
+	leaves      bool
+	assigned    map[string]bool
+	conditional map[string]string
+}
+
+func newPathBranch(guard string) pathBranch {
+	return pathBranch{guard: guard, assigned: make(map[string]bool), conditional: make(map[string]string)}
+}
+
+// collectPathBranch collects the assignments made by the statements of a branch, up to
+// stop
+func collectPathBranch(guard string, stmts []ast.Stmt, stop ast.Node, obj types.Object, pass *analysis.Pass) pathBranch {
+	b := newPathBranch(guard)
+	b.leaves = stop == nil && len(stmts) > 0 && endsBlock(stmts[len(stmts)-1], pass)
+	collectStatementAssignments(stmts, stop, obj, b.assigned, b.conditional, pass)
+	return b
+}
+
+// mergeBranches records the field assignments of the branches of a statement for the
+// code that follows it. Branches that leave don't reach that code and don't count. A
+// field set in every other branch is assigned; one set in only some of them goes into
+// conditional along with the guard of the first branch that sets it.
+func mergeBranches(branches []pathBranch, assigned map[string]bool, conditional map[string]string) {
+	var reaching []pathBranch
+	for _, b := range branches {
+		if !b.leaves {
+			reaching = append(reaching, b)
+		}
+	}
+	if len(reaching) > 0 {
+		for name := range reaching[0].assigned {
+			everywhere := true
+			for _, b := range reaching[1:] {
+				everywhere = everywhere && b.assigned[name]
+			}
+			if everywhere {
+				assigned[name] = true
+			}
+		}
+	}
+	for _, b := range reaching {
+		for name := range b.assigned {
+			if _, seen := conditional[name]; !assigned[name] && !seen {
+				conditional[name] = b.guard
+			}
+		}
+		for name, guard := range b.conditional {
+			if _, seen := conditional[name]; !assigned[name] && !seen {
+				conditional[name] = guard
+			}
+		}
+	}
+}
+
+// collectBranchAssignments records the field assignments made by an if statement (and
+// its else-if chain) for the code that follows it; see mergeBranches. Guards are the
+// condition of a branch, e.g. "ok" or "!(err != nil)". outer is the guard already
+// implied by enclosing else branches.
+func collectBranchAssignments(stmt *ast.IfStmt, outer string, obj types.Object, assigned map[string]bool, conditional map[string]string, pass *analysis.Pass) {
+	collectStatementAssignments([]ast.Stmt{stmt.Init}, nil, obj, assigned, conditional, pass)
+	cond := types.ExprString(stmt.Cond)
+	negated := "!" + cond
+	if _, simple := ast.Unparen(stmt.Cond).(*ast.Ident); !simple {
+		negated = "!(" + cond + ")"
+	}
+	if outer != "" {
+		cond, negated = outer+" && "+cond, outer+" && "+negated
+	}
+
+	branches := []pathBranch{collectPathBranch(cond, stmt.Body.List, nil, obj, pass)}
+	switch els := stmt.Else.(type) {
+	case nil:
+		branches = append(branches, newPathBranch(negated))
+	case *ast.BlockStmt:
+		branches = append(branches, collectPathBranch(negated, els.List, nil, obj, pass))
+	case *ast.IfStmt:
+		// else if: the chain is one more branch, with its own guards
+		b := newPathBranch(negated)
+		collectBranchAssignments(els, negated, obj, b.assigned, b.conditional, pass)
+		b.leaves = chainTerminates(els, pass)
+		branches = append(branches, b)
+	}
+	mergeBranches(branches, assigned, conditional)
+}
+
+// switchBranches returns the branches of a switch statement, labeled label if it has
+// one, one for each case clause and, without a default clause, one for none matching. A
+// clause ending in fallthrough runs on into the next.
+func switchBranches(stmt *ast.SwitchStmt, label *ast.Ident, obj types.Object, pass *analysis.Pass) []pathBranch {
+	var guards []string
+	for _, c := range stmt.Body.List {
+		guards = append(guards, caseGuard(stmt.Tag, c.(*ast.CaseClause).List))
+	}
+	others := func(skip int) string {
+		var rest []string
+		for i, guard := range guards {
+			if i != skip && guard != "" {
+				rest = append(rest, guard)
+			}
+		}
+		if len(rest) == 0 {
+			return ""
+		}
+		return "!(" + strings.Join(rest, " || ") + ")"
+	}
+
+	var branches []pathBranch
+	hasDefault := false
+	for i, c := range stmt.Body.List {
+		guard := guards[i]
+		if c.(*ast.CaseClause).List == nil {
+			hasDefault = true
+			guard = others(i)
+		}
+		branches = append(branches, caseBranch(guard, stmt.Body.List[i:], label, obj, pass))
+	}
+	if !hasDefault {
+		branches = append(branches, newPathBranch(others(-1)))
+	}
+	return branches
+}
+
+// caseGuard returns the condition for a case clause of a switch on tag to be taken,
+// e.g. "kind == a || kind == b", or "" for the default clause
+func caseGuard(tag ast.Expr, list []ast.Expr) string {
+	conds := make([]string, len(list))
+	for i, e := range list {
+		conds[i] = types.ExprString(e)
+		if tag != nil {
+			conds[i] = types.ExprString(tag) + " == " + conds[i]
+		}
+	}
+	return strings.Join(conds, " || ")
+}
+
+// typeSwitchBranches returns the branches of a type switch, labeled label if it has
+// one, one for each case clause and, without a default clause, one for none matching
+func typeSwitchBranches(stmt *ast.TypeSwitchStmt, label *ast.Ident, obj types.Object, pass *analysis.Pass) []pathBranch {
+	var x ast.Expr
+	switch s := stmt.Assign.(type) {
+	case *ast.AssignStmt:
+		x = s.Rhs[0].(*ast.TypeAssertExpr).X
+	case *ast.ExprStmt:
+		x = s.X.(*ast.TypeAssertExpr).X
+	}
+	var all []string
+	for _, c := range stmt.Body.List {
+		for _, e := range c.(*ast.CaseClause).List {
+			all = append(all, types.ExprString(e))
+		}
+	}
+	none := types.ExprString(x) + " is not one of " + strings.Join(all, ", ")
+
+	var branches []pathBranch
+	hasDefault := false
+	for i, c := range stmt.Body.List {
+		var names []string
+		for _, e := range c.(*ast.CaseClause).List {
+			names = append(names, types.ExprString(e))
+		}
+		guard := types.ExprString(x) + " is " + strings.Join(names, " or ")
+		if names == nil {
+			hasDefault = true
+			guard = none
+		}
+		branches = append(branches, caseBranch(guard, stmt.Body.List[i:], label, obj, pass))
+	}
+	if !hasDefault {
+		branches = append(branches, newPathBranch(none))
+	}
+	return branches
+}
+
+// selectBranches returns the branches of a select statement, labeled label if it has
+// one, one for each clause; a select always takes one of them
+func selectBranches(stmt *ast.SelectStmt, label *ast.Ident, obj types.Object, pass *analysis.Pass) []pathBranch {
+	var branches []pathBranch
+	for i, c := range stmt.Body.List {
+		guard := "the select takes its default case"
+		switch comm := c.(*ast.CommClause).Comm.(type) {
+		case *ast.SendStmt:
+			guard = "the select sends on " + types.ExprString(comm.Chan)
+		case *ast.ExprStmt:
+			guard = "the select receives from " + types.ExprString(ast.Unparen(comm.X).(*ast.UnaryExpr).X)
+		case *ast.AssignStmt:
+			guard = "the select receives from " + types.ExprString(ast.Unparen(comm.Rhs[0]).(*ast.UnaryExpr).X)
+		}
+		branches = append(branches, caseBranch(guard, stmt.Body.List[i:], label, obj, pass))
+	}
+	return branches
+}
+
+// caseBranch collects the branch of the first of clauses, case clauses of a switch or
+// comm clauses of a select labeled label, taken under guard. It reaches the code after
+// the statement through the end of the clause and through each break for the
+// statement, which ends the clause early; fallthrough continues into the next clause.
+func caseBranch(guard string, clauses []ast.Stmt, label *ast.Ident, obj types.Object, pass *analysis.Pass) pathBranch {
+	var stmts []ast.Stmt
+	for i, c := range clauses {
+		switch clause := c.(type) {
+		case *ast.CaseClause:
+			stmts = append(stmts, clause.Body...)
+		case *ast.CommClause:
+			stmts = append(stmts, clause.Comm)
+			stmts = append(stmts, clause.Body...)
+		}
+		last, ok := lastStmt(stmts).(*ast.BranchStmt)
+		if !ok || last.Tok != token.FALLTHROUGH || i == len(clauses)-1 {
+			break
+		}
+		stmts = stmts[:len(stmts)-1]
+	}
+	exits := jumpExits(&ast.BlockStmt{List: stmts}, label, false, obj, pass)
+	if reachesEnd(stmts, pass) {
+		exits = append(exits, collectPathBranch(guard, stmts, nil, obj, pass))
+	}
+	b := newPathBranch(guard)
+	b.leaves = len(exits) == 0
+	mergeBranches(exits, b.assigned, b.conditional)
+	return b
+}
+
+// lastStmt returns the last of stmts, or nil
+func lastStmt(stmts []ast.Stmt) ast.Stmt {
+	if len(stmts) == 0 {
+		return nil
+	}
+	return stmts[len(stmts)-1]
+}
+
+// chainTerminates checks if every branch of an if/else-if chain ends in a statement that
+// doesn't reach the code after it; a missing final else falls through
+func chainTerminates(stmt *ast.IfStmt, pass *analysis.Pass) bool {
+	list := stmt.Body.List
+	if len(list) == 0 || !endsBlock(list[len(list)-1], pass) {
+		return false
+	}
+	switch els := stmt.Else.(type) {
+	case *ast.BlockStmt:
+		return len(els.List) > 0 && endsBlock(els.List[len(els.List)-1], pass)
+	case *ast.IfStmt:
+		return chainTerminates(els, pass)
+	}
+	return false
+}
+
+// isTerminating checks if a statement leaves the code being followed: a return, a goto,
+// which jumps out of it, or a panic. A call of a function named panic that isn't the
+// builtin may return. A break or continue only goes to the end or the head of its loop
+// or switch; see endsBlock.
+func isTerminating(stmt ast.Stmt, pass *analysis.Pass) bool {
+	switch s := stmt.(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.BranchStmt:
+		return s.Tok == token.GOTO
+	case *ast.ExprStmt:
+		call, ok := s.X.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		id, ok := ast.Unparen(call.Fun).(*ast.Ident)
+		if !ok || id.Name != "panic" {
+			return false
+		}
+		_, builtin := pass.TypesInfo.Uses[id].(*types.Builtin)
+		return builtin
+	}
+	return false
+}
+
+// rangesOverPositiveConstant checks for Go 1.22 range-over-int loops with a constant count above zero
+func rangesOverPositiveConstant(rng *ast.RangeStmt, pass *analysis.Pass) bool {
+	tv, ok := pass.TypesInfo.Types[rng.X]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.Int {
+		return false
+	}
+	return constant.Sign(tv.Value) > 0
+}
+
+// endsBlock checks if a statement never reaches the statement after it: it terminates,
+// or breaks or continues
+func endsBlock(stmt ast.Stmt, pass *analysis.Pass) bool {
+	if br, ok := stmt.(*ast.BranchStmt); ok && (br.Tok == token.BREAK || br.Tok == token.CONTINUE) {
+		return true
+	}
+	return isTerminating(stmt, pass)
+}
+
+// reachesEnd checks if the end of stmts may run: none of them ends the block
+func reachesEnd(stmts []ast.Stmt, pass *analysis.Pass) bool {
+	for _, stmt := range stmts {
+		if endsBlock(stmt, pass) {
+			return false
+		}
+	}
+	return true
+}
+
+// collectFieldAssignments records obj.Field = value assignments with non-nil values
+func collectFieldAssignments(assign *ast.AssignStmt, obj types.Object, assigned map[string]bool, pass *analysis.Pass) {
+	for i, lhs := range assign.Lhs {
+		sel := storedField(lhs, pass)
+		if sel == nil || i >= len(assign.Rhs) {
+			continue
+		}
+		id := baseIdent(sel, pass)
+		if id == nil || pass.TypesInfo.ObjectOf(id) != obj {
+			continue
+		}
+		if !isNilValue(assign.Rhs[i], pass) {
+			assigned[sel.Sel.Name] = true
+		}
+	}
+}
+
+// reportMissingFields reports required fields of a tracked response that are neither set
+// in its literal nor in assigned. Fields in conditional are only set on some paths, and
+// the report names the condition they are set under; the others have no assignment
+// that runs before pos.
+func reportMissingFields(t *trackedResponse, assigned map[string]bool, conditional map[string]string, pos token.Pos, pass *analysis.Pass) {
+	structType := getStructType(t.litType)
+	if structType == nil {
+		return
+	}
+
+	initialized := literalFields(t.template, pass)
+	if t.lit != nil {
+		for _, elt := range t.lit.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				if id, ok := kv.Key.(*ast.Ident); ok {
+					initialized[id.Name] = true
+				}
+			}
+		}
+	}
+
+	for _, field := range requiredFields(structType, t.litType, isRequestMessage(t.litType)) {
+		if initialized[field.Name()] || assigned[field.Name()] {
+			continue
+		}
+		if guard, ok := conditional[field.Name()]; ok {
+			reportField(pass, rootField(t.litType, field.Name()), analysis.Diagnostic{
+				Pos:      pos,
+				Category: KindMissingField,
+				Message: fmt.Sprintf("non-optional message field '%s' may be uninitialized on some paths in protobuf message %s: it is only set when %s%s",
+					field.Name(), describeType(pass, t.litType), guard, gatewayNote(t.litType, field.Name())),
+			})
+			continue
+		}
+		var fixes []analysis.SuggestedFix
+		if t.lit != nil {
+			fixes = missingFieldFix(t.lit, field, isRequestMessage(t.litType), pass)
+		}
+		reportField(pass, rootField(t.litType, field.Name()), analysis.Diagnostic{
+			Pos:      pos,
+			Category: KindMissingField,
+			Message: fmt.Sprintf("non-optional message field '%s' not initialized in protobuf message %s%s",
+				field.Name(), describeType(pass, t.litType), gatewayNote(t.litType, field.Name())),
+			SuggestedFixes: fixes,
+		})
+	}
+}
+
+// inspectFunctionBody calls fn for every node in body, not descending into nested function literals
+func inspectFunctionBody(body *ast.BlockStmt, fn func(ast.Node)) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			return true
+		}
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		fn(n)
+		return true
+	})
+}
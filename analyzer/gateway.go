@@ -0,0 +1,48 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"github.com/nickheyer/go_no_nil_linter/internal/protopolicy"
+)
+
+// gatewayNote returns the suffix added to nil and uninitialized field diagnostics under
+// -gateway-json. gRPC-Gateway marshals responses with protojson, which drops nil message
+// fields from the JSON body (or writes null with EmitUnpopulated), so REST clients see
+// the field disappear rather than an empty object.
+func gatewayNote(msgType types.Type, fieldName string) string {
+	if !gatewayJSON {
+		return ""
+	}
+	return fmt.Sprintf("; REST clients of the gRPC-Gateway will get no %q key in the JSON response (null with EmitUnpopulated)",
+		jsonFieldName(msgType, fieldName))
+}
+
+// jsonFieldName returns the JSON key protojson uses for a message field: the json= option
+// of its protobuf tag, else the proto field name, falling back to the json tag and then
+// the Go field name
+func jsonFieldName(msgType types.Type, fieldName string) string {
+	structType := getStructType(msgType)
+	if structType == nil {
+		return fieldName
+	}
+	for i := 0; i < structType.NumFields(); i++ {
+		if messageSchema.FieldName(structType.Field(i)) != fieldName {
+			continue
+		}
+		if tag, ok := protopolicy.ParseTag(structType.Tag(i)); ok && tag.JSONName != "" {
+			return tag.JSONName
+		}
+		tag := reflect.StructTag(structType.Tag(i))
+		if jsonTag, ok := tag.Lookup("json"); ok {
+			if name, _, _ := strings.Cut(jsonTag, ","); name != "" && name != "-" {
+				return name
+			}
+		}
+		break
+	}
+	return fieldName
+}
@@ -0,0 +1,67 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// checkGenerated checks generated files like the package's own
+var checkGenerated bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&checkGenerated, "check-generated", false,
+		"also check generated files: *.pb.go and files with a \"Code generated ... DO NOT EDIT.\" header")
+}
+
+// Generated code lives in packages of its own or next to the code using it, for
+// example a service's .pb.go files regenerated into its package, or mocks next to the
+// interfaces they implement. The generated files aren't checked: their literals build
+// descriptors and test doubles, and they are rewritten on every run of the generator.
+// The rest of the package is.
+//
+// A file is generated when it is named *.pb.go, or has the header Go reserves for it,
+// a line "// Code generated ... DO NOT EDIT." before the package clause. That covers
+// protoc-gen-go and its plugins, gogo-proto, vtproto and mockgen alike.
+
+// generatedFiles returns the generated files of the package, or none under
+// -check-generated
+func generatedFiles(pass *analysis.Pass) map[*token.File]bool {
+	generated := make(map[*token.File]bool)
+	if checkGenerated {
+		return generated
+	}
+	for _, file := range pass.Files {
+		tf := pass.Fset.File(file.Pos())
+		if tf == nil {
+			continue
+		}
+		// The copies cgo rewrites files importing "C" into carry its header, but they
+		// are the package's own code under its names; see cgo.go
+		if pass.Fset.Position(file.Package).Filename != tf.Name() {
+			continue
+		}
+		if strings.HasSuffix(tf.Name(), ".pb.go") || ast.IsGenerated(file) {
+			log().Debug("skipping generated file", "package", pass.Pkg.Path(), "file", tf.Name())
+			generated[tf] = true
+		}
+	}
+	return generated
+}
+
+// dropSkipped wraps pass.Report to drop findings in the skipped files of the package,
+// those generated and those matching -exclude
+func dropSkipped(pass *analysis.Pass, skipped map[*token.File]bool) {
+	if len(skipped) == 0 {
+		return
+	}
+	report := pass.Report
+	pass.Report = func(d analysis.Diagnostic) {
+		if skipped[pass.Fset.File(d.Pos)] {
+			return
+		}
+		report(d)
+	}
+}
@@ -0,0 +1,126 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// Handlers often return their response in a generic wrapper, such as
+//
+//	type Result[T any] struct { Value T; Err error }
+//	func Ok[T any](v T) Result[T] { return Result[T]{Value: v} }
+//
+// A wrapper whose type parameter has no methods can only hold the message: it has no
+// way to set its fields. So a response handed to one isn't filled in there, and a
+// wrapper returned is evaluated as the response it holds, as for connect.NewResponse.
+
+// wrappedValues returns the values a generic wrapper is built from: the arguments of a
+// call to a generic function, and the fields of a generic struct literal, whose types
+// are a type parameter without methods, T or *T. Calls count when they return the
+// value, or a wrapper of it such as Result[T].
+func wrappedValues(expr ast.Expr, pass *analysis.Pass) []ast.Expr {
+	expr = ast.Unparen(expr)
+	if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		expr = ast.Unparen(unary.X)
+	}
+
+	var values []ast.Expr
+	switch e := expr.(type) {
+	case *ast.CallExpr:
+		fn, ok := typeutil.Callee(pass.TypesInfo, e).(*types.Func)
+		if !ok {
+			return nil
+		}
+		sig := fn.Type().(*types.Signature)
+		if sig.TypeParams().Len() == 0 || sig.Results().Len() == 0 {
+			return nil
+		}
+		for i, arg := range e.Args {
+			if i >= sig.Params().Len() {
+				break
+			}
+			if param := opaqueTypeParam(sig.Params().At(i).Type()); param != nil && carries(sig.Results().At(0).Type(), param) {
+				values = append(values, arg)
+			}
+		}
+	case *ast.CompositeLit:
+		named, ok := types.Unalias(pass.TypesInfo.TypeOf(e)).(*types.Named)
+		if !ok || named.TypeArgs().Len() == 0 {
+			return nil
+		}
+		generic, ok := named.Origin().Underlying().(*types.Struct)
+		if !ok {
+			return nil
+		}
+		for _, elt := range e.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			key, ok := kv.Key.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			for i := 0; i < generic.NumFields(); i++ {
+				if generic.Field(i).Name() == key.Name && opaqueTypeParam(generic.Field(i).Type()) != nil {
+					values = append(values, kv.Value)
+				}
+			}
+		}
+	}
+	return values
+}
+
+// opaqueTypeParam returns the type parameter t is, or points to, when its constraint
+// has no methods, or nil
+func opaqueTypeParam(t types.Type) *types.TypeParam {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	param, ok := t.(*types.TypeParam)
+	if !ok {
+		return nil
+	}
+	if iface, ok := param.Constraint().Underlying().(*types.Interface); !ok || iface.NumMethods() != 0 {
+		return nil
+	}
+	return param
+}
+
+// carries checks if a result type is param, a pointer to it, or a generic type
+// instantiated with it, such as Result[T] or *Response[T]
+func carries(t types.Type, param *types.TypeParam) bool {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if t == param {
+		return true
+	}
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return false
+	}
+	for i := 0; i < named.TypeArgs().Len(); i++ {
+		if arg := named.TypeArgs().At(i); arg == param {
+			return true
+		} else if ptr, ok := arg.(*types.Pointer); ok && ptr.Elem() == param {
+			return true
+		}
+	}
+	return false
+}
+
+// wrappedMessage returns the message a generic wrapper holds, or nil when expr isn't a
+// wrapper or holds something else
+func wrappedMessage(expr ast.Expr, pass *analysis.Pass) ast.Expr {
+	for _, value := range wrappedValues(expr, pass) {
+		if t := pass.TypesInfo.TypeOf(value); t != nil && isProtobufMessageType(t) {
+			return ast.Unparen(value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// laterFields holds the fields of nested message literals that are set after the
+// response holding them is built, for each package being analyzed, keyed by
+// *types.Package; run drops the entry when it finishes
+var laterFields sync.Map
+
+// fieldAlias is a local variable bound to a field of another variable, such as
+// u := resp.GetUser(), through which the field's message is mutated in place
+type fieldAlias struct {
+	root types.Object
+	path []string
+}
+
+// getterField returns the receiver and field name of a call to a generated accessor,
+// such as resp.GetUser(). Accessors return the field itself, so the result aliases
+// resp.User.
+func getterField(expr ast.Expr, pass *analysis.Pass) (ast.Expr, string, bool) {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok || len(call.Args) != 0 {
+		return nil, "", false
+	}
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return nil, "", false
+	}
+	name, ok := strings.CutPrefix(sel.Sel.Name, "Get")
+	if !ok || name == "" {
+		return nil, "", false
+	}
+	selection := pass.TypesInfo.Selections[sel]
+	if selection == nil || selection.Kind() != types.MethodVal || !isProtobufMessageType(selection.Recv()) {
+		return nil, "", false
+	}
+	field := getFieldFromType(selection.Recv(), name)
+	sig, ok := selection.Type().(*types.Signature)
+	if field == nil || !ok || sig.Results().Len() != 1 || !types.Identical(sig.Results().At(0).Type(), field.Type()) {
+		return nil, "", false
+	}
+	return sel.X, name, true
+}
+
+// storePath returns the variable an expression reads message fields from and the
+// fields it reads: resp.User.Address and resp.GetUser().GetAddress() are both resp
+// and [User Address]. Variables in aliases are followed to the variable they alias.
+func storePath(expr ast.Expr, aliases map[types.Object]fieldAlias, pass *analysis.Pass) (types.Object, []string) {
+	var path []string
+	for {
+		switch e := unwrapExpr(expr, pass).(type) {
+		case *ast.Ident:
+			obj := pass.TypesInfo.ObjectOf(e)
+			if alias, ok := aliases[obj]; ok {
+				obj, path = alias.root, append(path, reversed(alias.path)...)
+			}
+			return obj, reversed(path)
+		case *ast.SelectorExpr:
+			if selection := pass.TypesInfo.Selections[e]; selection == nil || selection.Kind() != types.FieldVal {
+				return nil, nil
+			}
+			path = append(path, e.Sel.Name)
+			expr = e.X
+		default:
+			recv, name, ok := getterField(e, pass)
+			if !ok {
+				return nil, nil
+			}
+			path = append(path, name)
+			expr = recv
+		}
+	}
+}
+
+// reversed returns a copy of path in reverse order
+func reversed(path []string) []string {
+	out := make([]string, len(path))
+	for i, name := range path {
+		out[len(path)-1-i] = name
+	}
+	return out
+}
+
+// fieldAliases finds the variables of a function body bound once to a field of a
+// variable accepted by accept, such as u := resp.GetUser()
+func fieldAliases(body *ast.BlockStmt, accept func(types.Object) bool, pass *analysis.Pass) map[types.Object]fieldAlias {
+	aliases := make(map[types.Object]fieldAlias)
+	reassigned := make(map[types.Object]bool)
+	inspectFunctionBody(body, func(n ast.Node) {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok {
+			return
+		}
+		for i, lhs := range assign.Lhs {
+			id := unwrapIdent(lhs, pass)
+			if id == nil {
+				continue
+			}
+			obj := pass.TypesInfo.ObjectOf(id)
+			if assign.Tok != token.DEFINE || len(assign.Lhs) != len(assign.Rhs) {
+				reassigned[obj] = true
+				continue
+			}
+			root, path := storePath(assign.Rhs[i], aliases, pass)
+			if root != nil && accept(root) && len(path) > 0 {
+				aliases[obj] = fieldAlias{root: root, path: path}
+			}
+		}
+	})
+	for obj := range reassigned {
+		delete(aliases, obj)
+	}
+	return aliases
+}
+
+// collectLaterFields records the fields of nested message literals in tracked responses
+// that the function sets afterwards, through the response (resp.User.Address = a), a
+// generated accessor (resp.GetUser().Address = a) or a variable bound to one
+// (u := resp.GetUser(); u.Address = a). The nested literal and the response share the
+// message, so those fields aren't reported as uninitialized at the literal. Stores
+// anywhere in the function count, as for responses that are never returned.
+func collectLaterFields(body *ast.BlockStmt, tracked map[types.Object]*trackedResponse, pass *analysis.Pass) {
+	if len(tracked) == 0 {
+		return
+	}
+
+	aliases := fieldAliases(body, func(root types.Object) bool { return tracked[root] != nil }, pass)
+	later := laterFieldsOf(pass)
+	inspectFunctionBody(body, func(n ast.Node) {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != len(assign.Rhs) {
+			return
+		}
+		for i, lhs := range assign.Lhs {
+			sel := storedField(lhs, pass)
+			if sel == nil || isNilValue(assign.Rhs[i], pass) {
+				continue
+			}
+			root, path := storePath(sel.X, aliases, pass)
+			t := tracked[root]
+			if t == nil || t.lit == nil || len(path) == 0 {
+				continue
+			}
+			if nested := nestedLiteral(t.lit, path, pass); nested != nil {
+				if later[nested] == nil {
+					later[nested] = make(map[string]bool)
+				}
+				later[nested][sel.Sel.Name] = true
+			}
+		}
+	})
+}
+
+// nestedLiteral returns the message literal set at path in lit, e.g. the User literal
+// in &Resp{User: &User{...}} for [User], or nil
+func nestedLiteral(lit *ast.CompositeLit, path []string, pass *analysis.Pass) *ast.CompositeLit {
+	for _, name := range path {
+		var next *ast.CompositeLit
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			if id, ok := kv.Key.(*ast.Ident); ok && id.Name == name {
+				next = messageLiteral(ast.Unparen(kv.Value), isProtobufMessageType, pass)
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		lit = next
+	}
+	return lit
+}
+
+// laterFieldsOf returns the nested literal fields set later in the package, creating
+// the set on first use
+func laterFieldsOf(pass *analysis.Pass) map[*ast.CompositeLit]map[string]bool {
+	fields, _ := laterFields.LoadOrStore(pass.Pkg, make(map[*ast.CompositeLit]map[string]bool))
+	return fields.(map[*ast.CompositeLit]map[string]bool)
+}
+
+// setLater checks if field of a nested message literal is set after the response
+// holding the literal is built; see collectLaterFields
+func setLater(lit *ast.CompositeLit, field string, pass *analysis.Pass) bool {
+	fields, ok := laterFields.Load(pass.Pkg)
+	return ok && fields.(map[*ast.CompositeLit]map[string]bool)[lit][field]
+}
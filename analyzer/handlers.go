@@ -0,0 +1,185 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// grpcHandlersOnly restricts findings to the bodies of gRPC handler methods, for teams
+// that only care about what goes over the wire
+var grpcHandlersOnly bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&grpcHandlersOnly, "grpc-handlers-only", false,
+		"only report findings in methods implementing a gRPC server interface, including unset fields of responses built by helpers")
+}
+
+// onlyHandlers wraps pass.Report under -grpc-handlers-only to drop the findings outside
+// the methods implementing a service interface; see service.go. Helpers building their
+// responses aren't checked on their own then, so the fields they leave unset are
+// reported where a handler returns or stores the result instead.
+func onlyHandlers(pass *analysis.Pass) {
+	if !grpcHandlersOnly {
+		return
+	}
+	methods := serviceMethods(pass)
+	var handlers []ast.Node
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			if obj, ok := pass.TypesInfo.Defs[fn.Name].(*types.Func); ok && methods[obj] {
+				handlers = append(handlers, fn)
+			}
+		}
+	}
+
+	report := pass.Report
+	pass.Report = func(d analysis.Diagnostic) {
+		for _, fn := range handlers {
+			if fn.Pos() <= d.Pos && d.Pos < fn.End() {
+				report(d)
+				return
+			}
+		}
+	}
+}
+
+// helperResult is a handler's variable bound to the message a helper returns, as in
+// book, err := s.buildBook(ctx, req), with the fields the handler sets on it afterwards
+type helperResult struct {
+	call *ast.CallExpr
+	set  map[string]bool
+}
+
+// checkHandlerResults reports the required fields left unset in the responses a handler
+// returns from a helper: return s.buildBook(ctx, req), or a variable bound to its
+// result and returned later. The helper's returnFact says which fields none of its
+// returns set; those the handler sets itself before returning aren't reported. Helpers
+// whose own literals were checked have reported the fields there already, unless
+// -grpc-handlers-only drops those findings.
+func checkHandlerResults(fn *ast.FuncDecl, obj *types.Func, pass *analysis.Pass) {
+	index := -1
+	results := obj.Type().(*types.Signature).Results()
+	for i := 0; i < results.Len() && index < 0; i++ {
+		if isProtobufMessageType(handlerMessage(results.At(i).Type())) {
+			index = i
+		}
+	}
+	if index < 0 {
+		return
+	}
+	bound := helperResults(fn.Body, pass)
+	inspectFunctionBody(fn.Body, func(n ast.Node) {
+		ret, ok := n.(*ast.ReturnStmt)
+		if !ok {
+			return
+		}
+		var result ast.Expr
+		switch {
+		case len(ret.Results) > index:
+			result = ast.Unparen(ret.Results[index])
+		case len(ret.Results) == 1:
+			// return s.buildBook(ctx, req) forwards the helper's results
+			result = ast.Unparen(ret.Results[0])
+		default:
+			return
+		}
+		switch r := unwrapResponse(result, pass).(type) {
+		case *ast.CallExpr:
+			checkHandlerCall(r, nil, obj, r.Pos(), pass)
+		case *ast.Ident:
+			if b := bound[pass.TypesInfo.ObjectOf(r)]; b != nil {
+				checkHandlerCall(b.call, b.set, obj, r.Pos(), pass)
+			}
+		}
+	})
+}
+
+// helperResults finds the variables of a handler body defined from the message result
+// of a call and never reassigned, with the fields stored on them. Variables passed to
+// another call or whose address is taken may be filled in there, so they are left out.
+func helperResults(body *ast.BlockStmt, pass *analysis.Pass) map[types.Object]*helperResult {
+	bound := make(map[types.Object]*helperResult)
+	escaped := make(map[types.Object]bool)
+	uses := func(expr ast.Expr) {
+		if id, ok := ast.Unparen(expr).(*ast.Ident); ok {
+			escaped[pass.TypesInfo.ObjectOf(id)] = true
+		}
+	}
+	inspectFunctionBody(body, func(n ast.Node) {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			call, _ := ast.Unparen(node.Rhs[0]).(*ast.CallExpr)
+			for i, lhs := range node.Lhs {
+				if sel := storedField(lhs, pass); sel != nil && len(node.Lhs) == len(node.Rhs) {
+					if root, path := storePath(sel.X, nil, pass); root != nil && len(path) == 0 && bound[root] != nil && !isNilValue(node.Rhs[i], pass) {
+						bound[root].set[sel.Sel.Name] = true
+					}
+					continue
+				}
+				id := unwrapIdent(lhs, pass)
+				if id == nil {
+					continue
+				}
+				obj := pass.TypesInfo.ObjectOf(id)
+				if node.Tok != token.DEFINE || len(node.Rhs) != 1 || call == nil || pass.TypesInfo.Defs[id] == nil {
+					escaped[obj] = true
+					continue
+				}
+				callee := typeutil.StaticCallee(pass.TypesInfo, call)
+				if callee != nil && messageResultIndex(callee) == i {
+					bound[obj] = &helperResult{call: call, set: make(map[string]bool)}
+				}
+			}
+		case *ast.CallExpr:
+			for _, arg := range node.Args {
+				uses(arg)
+			}
+		case *ast.UnaryExpr:
+			if node.Op == token.AND {
+				uses(node.X)
+			}
+		}
+	})
+	for obj := range escaped {
+		delete(bound, obj)
+	}
+	return bound
+}
+
+// checkHandlerCall reports the fields a helper's result leaves unset and set doesn't
+// hold, at pos in handler
+func checkHandlerCall(call *ast.CallExpr, set map[string]bool, handler *types.Func, pos token.Pos, pass *analysis.Pass) {
+	fn := typeutil.StaticCallee(pass.TypesInfo, call)
+	if fn == nil || messageResultIndex(fn) < 0 || builderSummary(fn, pass) != nil {
+		return
+	}
+	var fact returnFact
+	if !pass.ImportObjectFact(fn, &fact) || (fact.Checked && !grpcHandlersOnly) {
+		return
+	}
+	msgType := fn.Type().(*types.Signature).Results().At(messageResultIndex(fn)).Type()
+	msg := msgType
+	if ptr, ok := msgType.(*types.Pointer); ok {
+		msg = ptr.Elem()
+	}
+	for _, field := range fact.Unset {
+		if set[field] {
+			continue
+		}
+		reportField(pass, rootField(msg, field), analysis.Diagnostic{
+			Pos:      pos,
+			Category: KindMissingField,
+			Message: fmt.Sprintf("non-optional message field '%s' not initialized in protobuf message %s returned by %s() from handler %s%s",
+				field, describeType(pass, msg), calleeName(fn, pass), handler.Name(), gatewayNote(msgType, field)),
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Violation kinds, reported as the diagnostic Category. They are stable rule IDs for
+// filtering and suppressing findings in tooling, such as SARIF rule IDs and
+// golangci-lint exclude rules. The core checks report the first four; each opt-in rule
+// reports its own.
+const (
+	// KindNilLiteral is the nil literal assigned to a required message field
+	KindNilLiteral = "nil-literal"
+
+	// KindNilVariable is a variable holding nil used for a required message field
+	KindNilVariable = "nil-variable"
+
+	// KindMissingField is a required message field left unset
+	KindMissingField = "missing-field"
+
+	// KindNestedNil is a required field of a nested message, reached through the value
+	// of a field, that is nil or left unset
+	KindNestedNil = "nested-nil"
+
+	// KindZeroValueMessage is a required field set to an empty well-known message
+	KindZeroValueMessage = "zero-value-message"
+
+	// KindMapLookup is a map lookup that may be nil used for a required field
+	KindMapLookup = "map-lookup"
+
+	// KindReflection is a reflective write into a protobuf message
+	KindReflection = "reflection"
+
+	// KindContextValue is a message from a context value used without checking it
+	KindContextValue = "context-value"
+
+	// KindTypeAssertion is a message from a comma-ok type assertion used without
+	// checking it
+	KindTypeAssertion = "type-assertion"
+
+	// KindMessageCopy is a protobuf message copied by value
+	KindMessageCopy = "message-copy"
+
+	// KindExclusiveFields is a pair of fields set both or neither
+	KindExclusiveFields = "exclusive-fields"
+
+	// KindOutputOnly is an output-only field set in a request
+	KindOutputOnly = "output-only"
+
+	// KindNilNilReturn is a nil response returned with a nil error
+	KindNilNilReturn = "nil-nil-return"
+
+	// KindConverter is a converter function dropping a required field
+	KindConverter = "converter"
+
+	// KindStubResponse is a response of only zero values and empty messages
+	KindStubResponse = "stub-response"
+
+	// KindSharedResponse is a response shared between calls and mutated
+	KindSharedResponse = "shared-response"
+
+	// KindPooledResponse is a response from a sync.Pool not fully reinitialized
+	KindPooledResponse = "pooled-response"
+
+	// KindOptionalUsage is an optional field cleared or tested through nil
+	KindOptionalUsage = "optional-usage"
+
+	// KindUnverified is a required field value that could not be verified
+	KindUnverified = "unverified"
+
+	// KindRecursiveField is a required field leading back to its own message
+	KindRecursiveField = "recursive-field"
+
+	// KindIgnoreDirective is an ignore directive without a reason, under -require-reason
+	KindIgnoreDirective = "ignore-directive"
+
+	// KindUncheckedRegion is an unbalanced unchecked region directive
+	KindUncheckedRegion = "unchecked-region"
+)
+
+// Severity is how serious a finding is. The messages of findings below SeverityError
+// start with their severity, e.g. "advisory: ", so it shows in the text output and in
+// golangci-lint, which have no other place for it.
+type Severity string
+
+const (
+	// SeverityError is a violation
+	SeverityError Severity = "error"
+
+	// SeverityAdvisory is a likely violation the analyzer can't prove
+	SeverityAdvisory Severity = "advisory"
+
+	// SeverityInfo is a note on code the analyzer couldn't verify
+	SeverityInfo Severity = "info"
+)
+
+// Prefix returns the prefix of the messages of findings with the severity
+func (s Severity) Prefix() string {
+	if s == SeverityError {
+		return ""
+	}
+	return string(s) + ": "
+}
+
+// KindInfo describes a violation kind
+type KindInfo struct {
+	Kind string
+
+	// Severity is the severity of the kind's findings. -map-lookup=error and
+	// -reflection=error raise those of their kinds to SeverityError; see
+	// (*Result).Severity.
+	Severity Severity
+
+	// Description says what the findings are about, as in SARIF rule descriptions
+	Description string
+}
+
+// kinds is the table of violation kinds, the core checks' first
+var kinds = []KindInfo{
+	{KindNilLiteral, SeverityError, "nil assigned to a non-optional protobuf message field"},
+	{KindNilVariable, SeverityError, "nil variable used for a non-optional protobuf message field"},
+	{KindMissingField, SeverityError, "non-optional protobuf message field not initialized"},
+	{KindNestedNil, SeverityError, "nil or uninitialized non-optional field in a nested protobuf message"},
+	{KindZeroValueMessage, SeverityError, "non-optional message field set to an empty placeholder message"},
+	{KindMapLookup, SeverityAdvisory, "map lookup that may be nil assigned to a non-optional message field"},
+	{KindReflection, SeverityAdvisory, "reflective write to a protobuf message that bypasses nil-safety checks"},
+	{KindContextValue, SeverityError, "message from a context value used without checking it was present"},
+	{KindTypeAssertion, SeverityError, "message from a comma-ok type assertion used without checking it succeeded"},
+	{KindMessageCopy, SeverityError, "protobuf message copied by value"},
+	{KindExclusiveFields, SeverityError, "fields that should be set exclusively are both or neither set"},
+	{KindOutputOnly, SeverityError, "output-only field set in a request"},
+	{KindNilNilReturn, SeverityError, "nil response returned with a nil error"},
+	{KindConverter, SeverityError, "converter function that drops a required field of the message it converts"},
+	{KindStubResponse, SeverityError, "response returned with only zero values and empty messages"},
+	{KindSharedResponse, SeverityError, "response shared between calls and mutated"},
+	{KindPooledResponse, SeverityError, "response from a sync.Pool not reset and fully reinitialized"},
+	{KindOptionalUsage, SeverityError, "optional field cleared or tested through nil instead of its generated methods"},
+	{KindUnverified, SeverityInfo, "required field value that could not be verified"},
+	{KindRecursiveField, SeverityError, "non-optional message field leading back to its own message"},
+	{KindIgnoreDirective, SeverityError, "ignore directive without a reason"},
+	{KindUncheckedRegion, SeverityError, "unbalanced //nonil:begin-unchecked or //nonil:end-unchecked"},
+}
+
+// Kinds returns the violation kinds the analyzer reports, the core checks' first
+func Kinds() []KindInfo {
+	return append([]KindInfo(nil), kinds...)
+}
+
+// LookupKind returns the description of a violation kind, if the analyzer reports it
+func LookupKind(kind string) (KindInfo, bool) {
+	for _, k := range kinds {
+		if k.Kind == kind {
+			return k, true
+		}
+	}
+	return KindInfo{}, false
+}
+
+// kindSeverity returns the severity of a kind's findings; a diagnostic without a kind
+// is an error
+func kindSeverity(kind string) Severity {
+	if k, ok := LookupKind(kind); ok {
+		return k.Severity
+	}
+	return SeverityError
+}
+
+// severityAt returns the severity of a finding of a kind reported by the pass at pos:
+// the kind's, unless -map-lookup=error or, outside tests and mocks, -reflection=error
+// raises it
+func severityAt(pass *analysis.Pass, kind string, pos token.Pos) Severity {
+	switch {
+	case kind == KindMapLookup && mapLookupMode == "error":
+		return SeverityError
+	case kind == KindReflection && reflectionMode == "error" && isProductionFile(pos, pass):
+		return SeverityError
+	}
+	return kindSeverity(kind)
+}
+
+// Severity returns the severity of a diagnostic reported for the package, as recorded
+// when it was reported, or that of its kind when none was
+func (r *Result) Severity(d analysis.Diagnostic) Severity {
+	for _, f := range r.Findings {
+		if f.Diagnostic.Pos == d.Pos && f.Diagnostic.Category == d.Category && f.Diagnostic.Message == d.Message {
+			if f.Severity != "" {
+				return f.Severity
+			}
+			break
+		}
+	}
+	return kindSeverity(d.Category)
+}
+
+// Kind returns the violation kind of a diagnostic reported by the analyzer: its
+// Category, or the analyzer name for one without
+func Kind(d analysis.Diagnostic) string {
+	if d.Category != "" {
+		return d.Category
+	}
+	return Analyzer.Name
+}
+
+// nilKind returns the kind of a nil value stored in a required field. A nil literal
+// converted to the field's type, as in (*pb.User)(nil), is still a literal.
+func nilKind(value ast.Expr, pass *analysis.Pass) string {
+	for {
+		call, ok := ast.Unparen(value).(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 {
+			break
+		}
+		if tv, ok := pass.TypesInfo.Types[call.Fun]; !ok || !tv.IsType() {
+			break
+		}
+		value = call.Args[0]
+	}
+	if isNilIdent(value) {
+		return KindNilLiteral
+	}
+	return KindNilVariable
+}
+
+// fieldRef is the field a check is about: the message type it started from and the
+// path of field names from there, e.g. User.Address from UserResponse. It prints as the
+// path, the way messages name nested fields, and is recorded with the findings about
+// it so tooling doesn't have to read it back from the message; see reportField.
+type fieldRef struct {
+	root types.Type
+	path string
+}
+
+// rootField refers to the field name of msgType
+func rootField(msgType types.Type, name string) fieldRef {
+	return fieldRef{root: msgType, path: name}
+}
+
+// child refers to the field name of the message this field holds
+func (f fieldRef) child(name string) fieldRef {
+	return fieldRef{root: f.root, path: f.path + "." + name}
+}
+
+func (f fieldRef) String() string {
+	return f.path
+}
+
+// describe returns the message type the field's path starts from, as the messages name
+// it without the pointer (e.g. "userpb.UserResponse"), and the full path from the name
+// of that type (e.g. "UserResponse.User.Address.Location")
+func (f fieldRef) describe(pass *analysis.Pass) (messageType, path string) {
+	obj := namedTypeName(f.root)
+	if obj == nil {
+		return "", f.path
+	}
+	return types.TypeString(obj.Type(), shortQualifier(pass)), obj.Name() + "." + f.path
+}
+
+// fieldReport is a diagnostic being reported about field
+type fieldReport struct {
+	pos     token.Pos
+	message string
+	field   fieldRef
+}
+
+// reportingField holds, for each pass, the diagnostic being reported with its field, for
+// recordFindings to record with it. Report wrappers may report diagnostics of their own
+// meanwhile, which are told apart by position and message.
+var reportingField sync.Map // *analysis.Pass -> fieldReport
+
+// reportField reports d, a finding about field
+func reportField(pass *analysis.Pass, field fieldRef, d analysis.Diagnostic) {
+	reportingField.Store(pass, fieldReport{pos: d.Pos, message: d.Message, field: field})
+	defer reportingField.Delete(pass)
+	pass.Report(d)
+}
+
+// FieldPath returns the message type a diagnostic reported for the package is about
+// (e.g. "userpb.UserResponse") and the full path of the field from that type (e.g.
+// "UserResponse.User.Address.Location"), as recorded when it was reported. Both are
+// empty when the diagnostic isn't about a message field.
+func (r *Result) FieldPath(d analysis.Diagnostic) (messageType, path string) {
+	for _, f := range r.Findings {
+		if f.Diagnostic.Pos == d.Pos && f.Diagnostic.Category == d.Category && f.Diagnostic.Message == d.Message {
+			return f.MessageType, f.FieldPath
+		}
+	}
+	return "", ""
+}
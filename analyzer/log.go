@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+var (
+	// verbose enables -verbose (info) logging of what the analyzer decided and skipped
+	verbose bool
+
+	// veryVerbose enables -vv (debug) logging, including per-type classification
+	veryVerbose bool
+
+	// logOutput is where log records are written, replaced in tests
+	logOutput io.Writer = os.Stderr
+
+	loggerOnce sync.Once
+	logger     *slog.Logger
+)
+
+func init() {
+	// Not -v: the analysis drivers reserve it as a no-op shim for legacy vet flags.
+	// The nonillinter command accepts -v as shorthand.
+	Analyzer.Flags.BoolVar(&verbose, "verbose", false, "log skipped packages and files and other analyzer decisions to stderr")
+	Analyzer.Flags.BoolVar(&veryVerbose, "vv", false, "like -verbose, and also log per-type classification and cache details")
+}
+
+// log returns the analyzer's logger. It is built on first use, after flags are parsed,
+// and discards everything unless -verbose or -vv is set.
+func log() *slog.Logger {
+	loggerOnce.Do(func() {
+		logger = newLogger(logOutput, verbose, veryVerbose)
+	})
+	return logger
+}
+
+// newLogger returns a text logger at info level for -verbose, debug level for -vv, or a
+// logger that drops every record otherwise
+func newLogger(w io.Writer, verbose, veryVerbose bool) *slog.Logger {
+	switch {
+	case veryVerbose:
+		return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	case verbose:
+		return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	default:
+		return slog.New(discardHandler{})
+	}
+}
+
+// discardHandler drops every record; slog.DiscardHandler needs Go 1.24
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
@@ -0,0 +1,35 @@
+package analyzer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNewLoggerLevels(t *testing.T) {
+	tests := []struct {
+		name                 string
+		verbose, veryVerbose bool
+		wantInfo, wantDebug  bool
+	}{
+		{"quiet", false, false, false, false},
+		{"v", true, false, true, false},
+		{"vv", false, true, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := newLogger(&buf, tt.verbose, tt.veryVerbose)
+			l.Info("info record")
+			l.Debug("debug record")
+
+			if got := strings.Contains(buf.String(), "info record"); got != tt.wantInfo {
+				t.Errorf("info logged = %v, want %v", got, tt.wantInfo)
+			}
+			if got := strings.Contains(buf.String(), "debug record"); got != tt.wantDebug {
+				t.Errorf("debug logged = %v, want %v", got, tt.wantDebug)
+			}
+		})
+	}
+}
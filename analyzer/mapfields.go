@@ -0,0 +1,86 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// requireMapEntries treats map fields with message values as required: a checked
+// message must set them, and not to an empty map literal
+var requireMapEntries bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&requireMapEntries, "require-map-entries", false,
+		"report checked messages that leave a map field with message values unset or set it to an empty map literal")
+}
+
+// mapMessageField checks if a field is a protobuf map whose values are messages, such
+// as map<string, User> generated as map[string]*User
+func mapMessageField(field *types.Var) bool {
+	m, ok := field.Type().Underlying().(*types.Map)
+	if !ok {
+		return false
+	}
+	ptr, ok := m.Elem().(*types.Pointer)
+	return ok && isProtobufMessageType(ptr)
+}
+
+// checkMapValues checks a map literal stored into a map field of a message:
+// map[string]*pb.User{"a": nil} holds an entry the marshaler can't encode as a message,
+// and non-nil values are validated like message fields. Under -require-map-entries an
+// empty literal is reported too.
+func checkMapValues(value ast.Expr, field *types.Var, msgType types.Type, pass *analysis.Pass) {
+	if !mapMessageField(field) {
+		return
+	}
+	lit, ok := ast.Unparen(value).(*ast.CompositeLit)
+	if !ok {
+		return
+	}
+	if len(lit.Elts) == 0 && requireMapEntries {
+		reportField(pass, rootField(msgType, field.Name()), analysis.Diagnostic{
+			Pos:      lit.Pos(),
+			Category: KindMissingField,
+			Message: fmt.Sprintf("map field '%s' has no entries in protobuf message %s",
+				field.Name(), describeType(pass, msgType)),
+		})
+		return
+	}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		entry := fmt.Sprintf("%s[%s]", field.Name(), types.ExprString(kv.Key))
+		if isNilValue(kv.Value, pass) {
+			reportField(pass, rootField(msgType, entry), analysis.Diagnostic{
+				Pos:      kv.Value.Pos(),
+				Category: nilKind(kv.Value, pass),
+				Message: fmt.Sprintf("nil value for entry '%s' of map field in protobuf message %s%s",
+					entry, describeType(pass, msgType), nilProvenance(kv.Value, pass)),
+			})
+		} else if valueType := pass.TypesInfo.TypeOf(kv.Value); valueType != nil && isProtobufMessageType(valueType) {
+			validateMessageValue(kv.Value, valueType, pass, rootField(msgType, entry), isRequestMessage(msgType))
+		}
+	}
+}
+
+// withMapFields adds the map fields with message values of a message to its required
+// fields under -require-map-entries, keeping the order of the struct
+func withMapFields(structType *types.Struct, fields []*types.Var) []*types.Var {
+	required := make(map[*types.Var]bool, len(fields))
+	for _, field := range fields {
+		required[field] = true
+	}
+	var all []*types.Var
+	for i := 0; i < structType.NumFields(); i++ {
+		field := structType.Field(i)
+		if required[field] || (field.Exported() && mapMessageField(field)) {
+			all = append(all, field)
+		}
+	}
+	return all
+}
@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// checkMapLookup reports a map lookup assigned to a required response field without a nil check.
+// A missing key yields a nil message, so both resp.User = usersByID[id] and
+// u := usersByID[id]; resp.User = u are flagged unless u is compared against nil
+// or the lookup uses the comma-ok form.
+func checkMapLookup(value ast.Expr, field fieldRef, msgType types.Type, pass *analysis.Pass) {
+	if mapLookupMode == "off" {
+		return
+	}
+
+	var lookup *ast.IndexExpr
+	var variable string
+	switch v := ast.Unparen(value).(type) {
+	case *ast.IndexExpr:
+		if isMapIndex(v, pass) {
+			lookup = v
+		}
+	case *ast.Ident:
+		lookup = uncheckedMapLookupVariable(v, pass)
+		variable = v.Name
+	}
+	if lookup == nil {
+		return
+	}
+
+	severity := severityAt(pass, KindMapLookup, value.Pos())
+	source := "map lookup"
+	if variable != "" {
+		source = "variable '" + variable + "' from a map lookup"
+	}
+	reportField(pass, field, analysis.Diagnostic{
+		Pos:      value.Pos(),
+		Category: KindMapLookup,
+		Message: fmt.Sprintf("%s%s assigned to non-optional message field '%s' in protobuf message %s may be nil for a missing key; check it or use the comma-ok form",
+			severity.Prefix(), source, field, describeType(pass, msgType)),
+	})
+}
+
+// isMapIndex checks if an index expression indexes a map
+func isMapIndex(index *ast.IndexExpr, pass *analysis.Pass) bool {
+	t := pass.TypesInfo.TypeOf(index.X)
+	if t == nil {
+		return false
+	}
+	_, ok := t.Underlying().(*types.Map)
+	return ok
+}
+
+// uncheckedMapLookupVariable returns the map lookup that initializes a variable declared as
+// v := m[k] (or var v = m[k]) when v is never compared against nil, or nil otherwise
+func uncheckedMapLookupVariable(ident *ast.Ident, pass *analysis.Pass) *ast.IndexExpr {
+	obj := pass.TypesInfo.ObjectOf(ident)
+	if obj == nil {
+		return nil
+	}
+
+	index := declarations(pass)
+	if index.nilCompared[obj] {
+		return nil
+	}
+	// Only the single-value form; v, ok := m[k] has more names than values
+	var lhs, rhs []ast.Expr
+	if assign := index.defines[obj]; assign != nil {
+		lhs, rhs = assign.Lhs, assign.Rhs
+	} else if spec := index.specs[obj]; spec != nil {
+		lhs, rhs = []ast.Expr{spec.Names[0]}, spec.Values
+		if len(spec.Names) != 1 {
+			return nil
+		}
+	}
+	if len(lhs) != 1 || len(rhs) != 1 {
+		return nil
+	}
+	if lookup, ok := ast.Unparen(rhs[0]).(*ast.IndexExpr); ok && isMapIndex(lookup, pass) {
+		return lookup
+	}
+	return nil
+}
+
+// isNilIdent checks if an expression is the predeclared nil
+func isNilIdent(expr ast.Expr) bool {
+	ident, ok := ast.Unparen(expr).(*ast.Ident)
+	return ok && ident.Name == "nil"
+}
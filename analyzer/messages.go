@@ -1,9 +1,6 @@
 package analyzer
 
-import (
-	"go/types"
-	"strings"
-)
+import "go/types"
 
 // isProtobufMessageType checks if a type is a protobuf message type
 func isProtobufMessageType(t types.Type) bool {
@@ -18,134 +15,55 @@ func isProtobufMessageType(t types.Type) bool {
 		return false
 	}
 
-	// Check if it has the ProtoMessage() method
-	return hasProtoMessageMethod(named)
+	// Check if the schema takes it for a message
+	return isMessageNamedType(named)
 }
 
-// hasProtoMessageMethod checks if a type has the ProtoMessage() method
-func hasProtoMessageMethod(t *types.Named) bool {
-	// Look for ProtoMessage() method
-	for i := 0; i < t.NumMethods(); i++ {
-		method := t.Method(i)
-		if method.Name() == "ProtoMessage" {
-			sig, ok := method.Type().(*types.Signature)
-			if !ok {
-				continue
-			}
-			// ProtoMessage() should have no params and no returns
-			if sig.Params().Len() == 0 && sig.Results().Len() == 0 {
-				return true
-			}
-		}
-	}
-	return false
+// isMessageNamedType checks if a named type is a message of the schema; see schema.go
+func isMessageNamedType(t *types.Named) bool {
+	return messageSchema.IsMessage(t)
 }
 
 // isMessageField checks if a struct field is a message type (not a scalar type)
 func isMessageField(field *types.Var) bool {
-	fieldType := field.Type()
-
-	// Skip repeated fields (slices) - they are always optional
-	if _, ok := fieldType.(*types.Slice); ok {
-		return false
-	}
-
-	// Dereference pointer if needed
-	if ptr, ok := fieldType.(*types.Pointer); ok {
-		fieldType = ptr.Elem()
-	}
-
-	// Must be a named type
-	named, ok := fieldType.(*types.Named)
-	if !ok {
-		return false
-	}
-
-	// Must have ProtoMessage() method
-	if !hasProtoMessageMethod(named) {
-		return false
-	}
-
-	// Get package path and type name
-	obj := named.Obj()
-	if obj == nil {
-		return false
-	}
-
-	pkg := obj.Pkg()
-	typeName := obj.Name()
-
-	// Check if it's a well-known type (these are message types)
-	if pkg != nil {
-		pkgPath := pkg.Path()
-		
-		// Google protobuf well-known types
-		if strings.Contains(pkgPath, "google.golang.org/protobuf/types/known") {
-			return true
-		}
-		
-		// Google API types (date, money, etc.)
-		if strings.Contains(pkgPath, "google.golang.org/genproto/googleapis/type") {
-			return true
-		}
-	}
+	return messageSchema.IsMessageField(field)
+}
 
-	// Check if it's a scalar wrapper (these should be treated as scalars, not messages)
-	scalarWrappers := map[string]bool{
-		"StringValue":  true,
-		"Int32Value":   true,
-		"Int64Value":   true,
-		"UInt32Value":  true,
-		"UInt64Value":  true,
-		"FloatValue":   true,
-		"DoubleValue":  true,
-		"BoolValue":    true,
-		"BytesValue":   true,
+// isOptionalField checks if a field may be left nil: the schema or the descriptor says
+// so, or it holds a message type declared optional everywhere
+func isOptionalField(structType *types.Struct, field *types.Var) bool {
+	// Fields holding a message type declared optional everywhere; see optionaltypes.go
+	if isOptionalType(field.Type()) {
+		return true
 	}
-
-	if scalarWrappers[typeName] {
-		return false // Scalar wrappers are optional by nature
+	// proto3 optional fields whose tags don't say so; see descriptor.go
+	if isProto3OptionalField(structType, field) {
+		return true
 	}
-
-	// It's a custom message type
-	return true
+	return messageSchema.IsOptionalField(structType, field)
 }
 
-// isOptionalField checks if a field has the 'optional' keyword in proto3
-func isOptionalField(field *types.Var) bool {
-	fieldType := field.Type()
-	
-	// In proto3, optional message fields become **Type (double pointer)
-	// Check if it's a pointer to a pointer
-	if ptr, ok := fieldType.(*types.Pointer); ok {
-		if _, ok := ptr.Elem().(*types.Pointer); ok {
-			return true // Double pointer indicates optional in proto3
-		}
-		
-		// Single pointer to a message type could be optional
-		// In proto3, optional fields have specific characteristics
-		// For a more robust check, we'd parse struct tags, but as a heuristic:
-		// If it's a pointer to a message type, check if there's a corresponding Has method
-		underlying := ptr.Elem()
-		if named, ok := underlying.(*types.Named); ok {
-			// Optional message fields in proto3 typically have Has<FieldName>() methods
-			// This is a conservative check - if unsure, treat as required
-			_ = named // Could check for Has methods here
-		}
+// getMessageFields returns all non-optional message fields from a struct type. The
+// slice is shared; callers must not modify it.
+func getMessageFields(structType *types.Struct) []*types.Var {
+	if fields, ok := messageFieldLists.Load(structType); ok {
+		return fields.([]*types.Var)
 	}
-	
-	return false // Conservative: assume required unless we can prove optional
+	// Passes racing on a type compute the same fields, so either may be kept
+	fields, _ := messageFieldLists.LoadOrStore(structType, collectMessageFields(structType))
+	return fields.([]*types.Var)
 }
 
-// getMessageFields returns all non-optional message fields from a struct type
-func getMessageFields(structType *types.Struct) []*types.Var {
+// collectMessageFields computes getMessageFields
+func collectMessageFields(structType *types.Struct) []*types.Var {
 	var messageFields []*types.Var
 
 	for i := 0; i < structType.NumFields(); i++ {
 		field := structType.Field(i)
 
-		// Skip unexported fields
-		if !field.Exported() {
+		// Skip fields code can't set; those the opaque API hides go by their accessors' name
+		name := messageSchema.FieldName(field)
+		if name == "" {
 			continue
 		}
 
@@ -155,36 +73,61 @@ func getMessageFields(structType *types.Struct) []*types.Var {
 		}
 
 		// Check if it's optional
-		if isOptionalField(field) {
+		if isOptionalField(structType, field) {
 			continue
 		}
 
+		if name != field.Name() {
+			field = types.NewField(field.Pos(), field.Pkg(), name, field.Type(), false)
+		}
 		messageFields = append(messageFields, field)
 	}
 
-	return messageFields
+	// Appending to the shared slice copies it
+	return messageFields[:len(messageFields):len(messageFields)]
 }
 
-// isWellKnownType checks if a type is a Google well-known type
-func isWellKnownType(t types.Type) bool {
-	// Dereference pointer if needed
-	if ptr, ok := t.(*types.Pointer); ok {
-		t = ptr.Elem()
-	}
-
-	named, ok := t.(*types.Named)
-	if !ok {
-		return false
+// requiredFields returns the non-optional message fields of a message of type msgType,
+// its oneofs under -require-oneofs and its map fields with message values under
+// -require-map-entries, less those -required-option leaves unmarked. On the request
+// side, fields annotated OUTPUT_ONLY are exempt: they are set by the server.
+// Fields paired by -exclusive-fields or named by -optional-fields are exempt on both sides.
+func requiredFields(structType *types.Struct, msgType types.Type, requestSide bool) []*types.Var {
+	fields := getMessageFields(structType)
+	if requireOneofs {
+		fields = withOneofs(structType, fields)
+	}
+	if requireMapEntries {
+		fields = withMapFields(structType, fields)
+	}
+	fields = withRequiredOption(msgType, structType, fields)
+	exclusive := exclusiveFieldNames(structType)
+	required := fields[:0:0]
+	for _, field := range fields {
+		if requestSide && isOutputOnlyField(msgType, structType, field) {
+			continue
+		}
+		// One side of a data/error pair is always unset; see exclusive.go
+		if exclusive[field.Name()] {
+			continue
+		}
+		if isOptionalFieldOf(msgType, field.Name()) {
+			continue
+		}
+		required = append(required, field)
 	}
+	return required
+}
 
-	obj := named.Obj()
-	if obj == nil || obj.Pkg() == nil {
-		return false
-	}
+// schemaFields returns the non-optional message fields of a message of type msgType that
+// -required-option and -optional-fields keep, for the uses that don't depend on flags
+// like -require-oneofs or on the side the message is on
+func schemaFields(structType *types.Struct, msgType types.Type) []*types.Var {
+	return withoutOptionalFields(msgType, withRequiredOption(msgType, structType, getMessageFields(structType)))
+}
 
-	pkgPath := obj.Pkg().Path()
-	
-	// Check for well-known types packages
-	return strings.Contains(pkgPath, "google.golang.org/protobuf/types/known") ||
-		strings.Contains(pkgPath, "google.golang.org/genproto/googleapis/type")
-}
\ No newline at end of file
+// isWellKnownType checks if a type is one of the schema's well-known types, such as
+// google.protobuf.Timestamp
+func isWellKnownType(t types.Type) bool {
+	return messageSchema.IsWellKnownType(t)
+}
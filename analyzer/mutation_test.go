@@ -0,0 +1,165 @@
+//go:build mutation
+
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Mutation testing for the boolean-heavy classification and detection code.
+//
+// Each mutant flips one comparison, logical operator, negation or boolean return in
+// the target files, then runs the curated corpus (the analysistest suites) against it
+// through a `go test -overlay`, leaving the working tree untouched. A mutant that
+// survives means no test notices that piece of logic being inverted.
+//
+// Run with:
+//
+//	go test -tags mutation -run TestMutation ./analyzer
+//	go test -tags mutation -run TestMutation ./analyzer -mutation.files=messages.go -mutation.threshold=0.8
+
+var (
+	mutationFiles     = flag.String("mutation.files", "detector.go,messages.go", "comma-separated files in this package to mutate")
+	mutationCorpus    = flag.String("mutation.corpus", ".", "-run pattern selecting the tests that make up the corpus")
+	mutationThreshold = flag.Float64("mutation.threshold", 0, "fail when the fraction of killed mutants is below this value")
+)
+
+// mutant is a single-token edit of a source file
+type mutant struct {
+	file        string
+	offset, end int
+	replacement string
+	description string
+}
+
+var swappedOps = map[token.Token]token.Token{
+	token.EQL:  token.NEQ,
+	token.NEQ:  token.EQL,
+	token.LSS:  token.GEQ,
+	token.GEQ:  token.LSS,
+	token.GTR:  token.LEQ,
+	token.LEQ:  token.GTR,
+	token.LAND: token.LOR,
+	token.LOR:  token.LAND,
+}
+
+// findMutants enumerates the mutation points in a source file
+func findMutants(filename string, src []byte) ([]mutant, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var mutants []mutant
+	add := func(pos token.Pos, length int, replacement, description string) {
+		p := fset.Position(pos)
+		mutants = append(mutants, mutant{
+			file:        filename,
+			offset:      p.Offset,
+			end:         p.Offset + length,
+			replacement: replacement,
+			description: fmt.Sprintf("%s:%d: %s", filepath.Base(filename), p.Line, description),
+		})
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.BinaryExpr:
+			if swapped, ok := swappedOps[node.Op]; ok {
+				add(node.OpPos, len(node.Op.String()), swapped.String(),
+					fmt.Sprintf("%s -> %s", node.Op, swapped))
+			}
+		case *ast.UnaryExpr:
+			if node.Op == token.NOT {
+				add(node.OpPos, 1, "", "remove !")
+			}
+		case *ast.ReturnStmt:
+			for _, result := range node.Results {
+				if ident, ok := result.(*ast.Ident); ok && (ident.Name == "true" || ident.Name == "false") {
+					flipped := map[string]string{"true": "false", "false": "true"}[ident.Name]
+					add(ident.Pos(), len(ident.Name), flipped,
+						fmt.Sprintf("return %s -> return %s", ident.Name, flipped))
+				}
+			}
+		}
+		return true
+	})
+	return mutants, nil
+}
+
+func TestMutation(t *testing.T) {
+	tmp := t.TempDir()
+	var all []mutant
+	sources := make(map[string][]byte)
+
+	for _, name := range strings.Split(*mutationFiles, ",") {
+		path, err := filepath.Abs(strings.TrimSpace(name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sources[path] = src
+		mutants, err := findMutants(path, src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, mutants...)
+	}
+
+	killed := 0
+	var survivors []string
+	for i, m := range all {
+		src := sources[m.file]
+		mutated := append(append(append([]byte{}, src[:m.offset]...), m.replacement...), src[m.end:]...)
+
+		mutatedPath := filepath.Join(tmp, fmt.Sprintf("mutant%d.go", i))
+		if err := os.WriteFile(mutatedPath, mutated, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		overlay, err := json.Marshal(map[string]map[string]string{"Replace": {m.file: mutatedPath}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		overlayPath := filepath.Join(tmp, fmt.Sprintf("overlay%d.json", i))
+		if err := os.WriteFile(overlayPath, overlay, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		var out bytes.Buffer
+		cmd := exec.Command("go", "test", "-count=1", "-overlay", overlayPath, "-run", *mutationCorpus, ".")
+		cmd.Stdout = &out
+		cmd.Stderr = &out
+		if err := cmd.Run(); err != nil {
+			killed++
+			continue
+		}
+		survivors = append(survivors, m.description)
+	}
+
+	for _, s := range survivors {
+		t.Logf("survived: %s", s)
+	}
+	if len(all) == 0 {
+		t.Fatal("no mutants generated")
+	}
+	score := float64(killed) / float64(len(all))
+	t.Logf("mutation score: %d/%d killed (%.0f%%)", killed, len(all), score*100)
+	if score < *mutationThreshold {
+		t.Errorf("mutation score %.2f is below -mutation.threshold %.2f", score, *mutationThreshold)
+	}
+}
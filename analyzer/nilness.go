@@ -0,0 +1,111 @@
+package analyzer
+
+import (
+	"go/token"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// nilness is what is known about whether an SSA value is nil, as in the x/tools
+// nilness analyzer. That analyzer reports its findings but exports neither a result
+// nor facts, so the same facts are derived here from the SSA form the checks already
+// build: constants, values that are never nil such as fresh allocations, phis, and the
+// nil checks on dominating branches.
+type nilness int
+
+const (
+	isNil    nilness = -1
+	unknown  nilness = 0
+	isNonNil nilness = 1
+)
+
+// nilnessAt returns whether v is nil when instr executes. A value that may be nil is
+// known nil, or known non-nil, under a dominating v == nil or v != nil check:
+//
+//	u := lookup(id)
+//	if u == nil {
+//		resp.User = u // nil
+//	}
+func nilnessAt(v ssa.Value, instr ssa.Instruction) nilness {
+	if n := nilnessOf(v, make(map[*ssa.Phi]bool)); n != unknown {
+		return n
+	}
+	block := instr.Block()
+	for dom := block.Idom(); dom != nil; dom = dom.Idom() {
+		branch, ok := dom.Instrs[len(dom.Instrs)-1].(*ssa.If)
+		if !ok {
+			continue
+		}
+		eq, ok := nilCheck(branch.Cond, v)
+		if !ok {
+			continue
+		}
+		// A successor dominates the block only if it is entered from the check alone
+		then, els := dom.Succs[0], dom.Succs[1]
+		switch {
+		case then != els && len(then.Preds) == 1 && then.Dominates(block):
+		case then != els && len(els.Preds) == 1 && els.Dominates(block):
+			eq = !eq
+		default:
+			continue
+		}
+		if eq {
+			return isNil
+		}
+		return isNonNil
+	}
+	return unknown
+}
+
+// nilnessOf returns whether v is nil on every path, regardless of where it is used
+func nilnessOf(v ssa.Value, seen map[*ssa.Phi]bool) nilness {
+	switch v := v.(type) {
+	case *ssa.Const:
+		if v.IsNil() {
+			return isNil
+		}
+		return isNonNil
+	case *ssa.Alloc, *ssa.MakeClosure, *ssa.MakeMap, *ssa.MakeChan, *ssa.MakeInterface,
+		*ssa.FieldAddr, *ssa.IndexAddr, *ssa.Function, *ssa.Global:
+		// Fresh allocations such as &T{} and the addresses of variables
+		return isNonNil
+	case *ssa.ChangeType:
+		return nilnessOf(v.X, seen)
+	case *ssa.Phi:
+		if seen[v] {
+			return unknown
+		}
+		seen[v] = true
+		result := unknown
+		for _, edge := range v.Edges {
+			if phi, ok := edge.(*ssa.Phi); ok && seen[phi] {
+				// A loop back edge carries one of the other incoming values
+				continue
+			}
+			n := nilnessOf(edge, seen)
+			if n == unknown || (result != unknown && n != result) {
+				return unknown
+			}
+			result = n
+		}
+		return result
+	}
+	return unknown
+}
+
+// nilCheck reports whether cond compares v against nil, and whether with == (eq)
+// rather than !=
+func nilCheck(cond ssa.Value, v ssa.Value) (eq bool, ok bool) {
+	binop, isBinOp := cond.(*ssa.BinOp)
+	if !isBinOp || (binop.Op != token.EQL && binop.Op != token.NEQ) {
+		return false, false
+	}
+	x, y := binop.X, binop.Y
+	if c, isConst := x.(*ssa.Const); isConst && c.IsNil() {
+		x, y = y, x
+	}
+	if c, isConst := y.(*ssa.Const); !isConst || !c.IsNil() || x != v {
+		return false, false
+	}
+	return binop.Op == token.EQL, true
+}
@@ -0,0 +1,66 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// checkNilNilReturns enables the nil-nil-return rule
+var checkNilNilReturns bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&checkNilNilReturns, "check-nil-nil-returns", false,
+		"report return nil, nil in functions returning a response and an error")
+}
+
+// checkNilNilReturn reports return nil, nil in a function returning (*Response, error).
+// Callers take a nil error to mean the response is there, and a gRPC handler that
+// returns one fails the call with an internal error instead of a status the client can
+// act on.
+func checkNilNilReturn(ret *ast.ReturnStmt, pass *analysis.Pass) {
+	if !checkNilNilReturns || len(ret.Results) != 2 || !isNilIdent(ret.Results[0]) || !isNilIdent(ret.Results[1]) {
+		return
+	}
+	sig := enclosingSignature(ret, pass)
+	if sig == nil || sig.Results().Len() != 2 || !types.Identical(sig.Results().At(1).Type(), errorType) {
+		return
+	}
+	respType := sig.Results().At(0).Type()
+	if _, ok := respType.(*types.Pointer); !ok || !shouldCheckType(respType) {
+		return
+	}
+	pass.Report(analysis.Diagnostic{
+		Pos:      ret.Pos(),
+		End:      ret.End(),
+		Category: KindNilNilReturn,
+		Message: fmt.Sprintf("nil response %s returned with a nil error; return a response or a non-nil error",
+			describeType(pass, respType.(*types.Pointer).Elem())),
+	})
+}
+
+// enclosingSignature returns the signature of the function or function literal a
+// return statement or function body belongs to, or nil
+func enclosingSignature(n ast.Node, pass *analysis.Pass) *types.Signature {
+	file := fileAt(n.Pos(), pass)
+	if file == nil {
+		return nil
+	}
+	path, _ := astutil.PathEnclosingInterval(file, n.Pos(), n.End())
+	for _, n := range path {
+		switch fn := n.(type) {
+		case *ast.FuncLit:
+			sig, _ := pass.TypesInfo.TypeOf(fn).(*types.Signature)
+			return sig
+		case *ast.FuncDecl:
+			if obj, ok := pass.TypesInfo.Defs[fn.Name].(*types.Func); ok {
+				return obj.Type().(*types.Signature)
+			}
+			return nil
+		}
+	}
+	return nil
+}
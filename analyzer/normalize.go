@@ -0,0 +1,52 @@
+package analyzer
+
+import (
+	"go/ast"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Checks and traces match expressions by their shape: a field store is a selector, a
+// variable an identifier. Legal Go wraps both in forms that don't change the value:
+//
+//	(resp).User = nil
+//	(resp.User) = (nil)
+//	(*pb.User)(nil)
+//	(*pb.UserResponse)(p).User = u
+//
+// Expressions are unwrapped before their shape is matched, so these forms are checked
+// as the plain ones.
+
+// unwrapExpr returns expr without the parentheses and type conversions around it: x
+// for (x), (*pb.User)(x) and pb.Users(x)
+func unwrapExpr(expr ast.Expr, pass *analysis.Pass) ast.Expr {
+	for {
+		expr = ast.Unparen(expr)
+		call, ok := expr.(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 || call.Ellipsis.IsValid() || !pass.TypesInfo.Types[call.Fun].IsType() {
+			return expr
+		}
+		expr = call.Args[0]
+	}
+}
+
+// storedField returns the field selector an assigned expression is, as in resp.User
+// and (resp.User), or nil
+func storedField(lhs ast.Expr, pass *analysis.Pass) *ast.SelectorExpr {
+	sel, _ := unwrapExpr(lhs, pass).(*ast.SelectorExpr)
+	return sel
+}
+
+// baseIdent returns the variable whose field a selector selects: resp in resp.User,
+// (resp).User and (*pb.UserResponse)(resp).User, or nil
+func baseIdent(sel *ast.SelectorExpr, pass *analysis.Pass) *ast.Ident {
+	id, _ := unwrapExpr(sel.X, pass).(*ast.Ident)
+	return id
+}
+
+// unwrapIdent returns the identifier an expression is under parentheses and
+// conversions, or nil
+func unwrapIdent(expr ast.Expr, pass *analysis.Pass) *ast.Ident {
+	id, _ := unwrapExpr(expr, pass).(*ast.Ident)
+	return id
+}
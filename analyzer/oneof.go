@@ -0,0 +1,122 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// requireOneofs treats the oneofs of checked messages as required: a literal or
+// response must set one of their cases
+var requireOneofs bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&requireOneofs, "require-oneofs", false,
+		"report checked messages that leave a oneof without a case set, as for a required message field")
+}
+
+// oneofWrapper describes the wrapper struct protoc-gen-go generates for a oneof case,
+// such as UserResponse_Payload for the payload case of a oneof in UserResponse:
+//
+//	type UserResponse_Payload struct {
+//		Payload *Payload `protobuf:"bytes,3,opt,name=payload,proto3,oneof"`
+//	}
+//
+//	func (*UserResponse_Payload) isUserResponse_Result() {}
+//
+// msgType is the message holding the oneof, and field the case's value.
+type oneofWrapper struct {
+	msgType types.Type
+	field   *types.Var
+}
+
+// oneofWrapperOf returns the oneof wrapper a type is, or ok false
+func oneofWrapperOf(t types.Type) (wrapper oneofWrapper, ok bool) {
+	if ptr, isPtr := t.(*types.Pointer); isPtr {
+		t = ptr.Elem()
+	}
+	named, isNamed := t.(*types.Named)
+	if !isNamed || named.Obj().Pkg() == nil {
+		return wrapper, false
+	}
+	structType, isStruct := named.Underlying().(*types.Struct)
+	if !isStruct || structType.NumFields() != 1 {
+		return wrapper, false
+	}
+	tag, _ := reflect.StructTag(structType.Tag(0)).Lookup("protobuf")
+	if !containsString(strings.Split(tag, ","), "oneof") {
+		return wrapper, false
+	}
+
+	// The marker method names the oneof interface, isMessage_Oneof
+	methods := types.NewMethodSet(types.NewPointer(named))
+	for i := 0; i < methods.Len(); i++ {
+		name, found := strings.CutPrefix(methods.At(i).Obj().Name(), "is")
+		if !found {
+			continue
+		}
+		if j := strings.LastIndex(name, "_"); j > 0 {
+			if tn, isType := named.Obj().Pkg().Scope().Lookup(name[:j]).(*types.TypeName); isType {
+				return oneofWrapper{msgType: tn.Type(), field: structType.Field(0)}, true
+			}
+		}
+	}
+	return wrapper, false
+}
+
+// checkOneofWrapper checks a oneof wrapper literal of a checked message whose case is a
+// message: &pb.UserResponse_Payload{Payload: nil} sets the oneof to a case without a
+// value, so it must hold a valid message like a required field
+func checkOneofWrapper(lit *ast.CompositeLit, pass *analysis.Pass) {
+	wrapper, ok := oneofWrapperOf(pass.TypesInfo.TypeOf(lit))
+	if !ok || !shouldCheckType(wrapper.msgType) || !isMessageField(wrapper.field) {
+		return
+	}
+	wrapperName := describeType(pass, pass.TypesInfo.TypeOf(lit))
+	name := wrapper.field.Name()
+
+	for _, elt := range lit.Elts {
+		value := elt
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			value = kv.Value
+		}
+		if isNilValue(value, pass) {
+			reportField(pass, rootField(wrapper.msgType, name), analysis.Diagnostic{
+				Pos:      value.Pos(),
+				Category: nilKind(value, pass),
+				Message: fmt.Sprintf("nil assignment to message field '%s' of oneof case %s in protobuf message %s",
+					name, wrapperName, describeType(pass, wrapper.msgType)),
+			})
+		} else if valueType := pass.TypesInfo.TypeOf(value); valueType != nil && isProtobufMessageType(valueType) {
+			validateMessageValue(value, valueType, pass, rootField(wrapper.msgType, name), isRequestMessage(wrapper.msgType))
+		}
+		return
+	}
+	reportField(pass, rootField(wrapper.msgType, name), analysis.Diagnostic{
+		Pos:      lit.Pos(),
+		Category: KindMissingField,
+		Message: fmt.Sprintf("message field '%s' of oneof case %s not initialized in protobuf message %s",
+			name, wrapperName, describeType(pass, wrapper.msgType)),
+	})
+}
+
+// withOneofs adds the oneof fields of a message to its required fields under
+// -require-oneofs, keeping the order of the struct
+func withOneofs(structType *types.Struct, fields []*types.Var) []*types.Var {
+	required := make(map[*types.Var]bool, len(fields))
+	for _, field := range fields {
+		required[field] = true
+	}
+	var all []*types.Var
+	for i := 0; i < structType.NumFields(); i++ {
+		field := structType.Field(i)
+		if _, oneof := reflect.StructTag(structType.Tag(i)).Lookup("protobuf_oneof"); required[field] || (oneof && field.Exported()) {
+			all = append(all, field)
+		}
+	}
+	return all
+}
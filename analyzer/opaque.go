@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// protoc-gen-go's opaque API, the default of Editions 2024, hides the fields of the
+// messages it generates behind accessors:
+//
+//	type UserResponse struct {
+//		state         protoimpl.MessageState
+//		xxx_hidden_User *User `protobuf:"bytes,1,opt,name=user"`
+//	}
+//
+// Code outside the generated package can't name the fields, so messages are built
+// through setters, resp.SetUser(u), or a builder:
+//
+//	pb.UserResponse_builder{User: u}.Build()
+//
+// Hidden fields go by their accessors' name (see the schema's FieldName), so setters
+// are checked as assignments like those of the hybrid API (see protoruntime.go), and a
+// builder literal is checked as a literal of the message it builds, at the Build call.
+// Builders have a field for every field of the message, so they have no fields to
+// tell required ones apart; the message's hidden fields do.
+
+// builtLiteral returns the builder literal in X_builder{...}.Build(), and the message
+// type it builds, when accept takes it, or nil
+func builtLiteral(expr ast.Expr, accept func(types.Type) bool, pass *analysis.Pass) (*ast.CompositeLit, types.Type) {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok || len(call.Args) != 0 {
+		return nil, nil
+	}
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Build" {
+		return nil, nil
+	}
+	lit, ok := ast.Unparen(sel.X).(*ast.CompositeLit)
+	if !ok {
+		return nil, nil
+	}
+	built, ok := messageSchema.BuilderMessage(pass.TypesInfo.TypeOf(lit)).(*types.Pointer)
+	if !ok || !accept(built.Elem()) {
+		return nil, nil
+	}
+	return lit, built.Elem()
+}
+
+// isOpaqueMessage checks if some field of a message type is hidden behind accessors, so
+// a literal of it can't set the field
+func isOpaqueMessage(t types.Type) bool {
+	structType := getStructType(t)
+	if structType == nil {
+		return false
+	}
+	for i := 0; i < structType.NumFields(); i++ {
+		field := structType.Field(i)
+		if name := messageSchema.FieldName(field); name != "" && name != field.Name() {
+			return true
+		}
+	}
+	return false
+}
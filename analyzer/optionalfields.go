@@ -0,0 +1,103 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// optionalFieldPatterns holds the field patterns set via -optional-fields
+var optionalFieldPatterns fieldPatternsFlag
+
+func init() {
+	Analyzer.Flags.Var(&optionalFieldPatterns, "optional-fields",
+		"comma-separated patterns of message fields not to require, written Message.Field (e.g. 'UserResponse.Manager' or 'example.v1.UserResponse.Manager'); the message is matched like -include-types, so '*.AuditInfo' exempts the AuditInfo field of every message")
+}
+
+// Older schemas have fields that are optional in practice but not declared optional,
+// such as a Manager left unset for the people at the top of the org chart. Until the
+// .proto file says so, -optional-fields exempts them one field at a time, usually from
+// the config file:
+//
+//	# .nonillinter.yaml
+//	optional-fields:
+//	  - UserResponse.Manager
+//	  - "*.AuditInfo"
+//
+// The last element of a pattern is the Go name of the field, and the elements before
+// it a message pattern, matched against the message's proto full name and Go type name
+// as for -include-types. Unlike -optional-types, which exempts every field holding a
+// message type, the exemption is for the field of the messages named.
+
+// fieldPattern is a parsed -optional-fields pattern
+type fieldPattern struct {
+	message []string
+	field   string
+}
+
+// fieldPatternsFlag is a flag.Value holding comma-separated Message.Field patterns
+type fieldPatternsFlag struct {
+	value    string
+	patterns []fieldPattern
+}
+
+func (f *fieldPatternsFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.value
+}
+
+func (f *fieldPatternsFlag) Set(value string) error {
+	var patterns []fieldPattern
+	for _, entry := range splitPatterns(value) {
+		elems := typeNameElems(entry)
+		if len(elems) < 2 || strings.Contains(elems[len(elems)-1], "*") {
+			return fmt.Errorf("invalid field pattern %q, want Message.Field", entry)
+		}
+		patterns = append(patterns, fieldPattern{message: elems[:len(elems)-1], field: elems[len(elems)-1]})
+	}
+	f.value, f.patterns = value, patterns
+	return nil
+}
+
+// isOptionalFieldOf checks if -optional-fields exempts the field name of a message of
+// type msgType
+func isOptionalFieldOf(msgType types.Type, name string) bool {
+	if len(optionalFieldPatterns.patterns) == 0 {
+		return false
+	}
+	obj := namedTypeName(msgType)
+	if obj == nil || obj.Pkg() == nil {
+		return false
+	}
+	names := messageNames(obj)
+	for _, pattern := range optionalFieldPatterns.patterns {
+		if pattern.field != name {
+			continue
+		}
+		for _, msgName := range names {
+			for i := range msgName {
+				if matchElems(pattern.message, msgName[i:]) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// withoutOptionalFields returns fields without those -optional-fields exempts in a
+// message of type msgType
+func withoutOptionalFields(msgType types.Type, fields []*types.Var) []*types.Var {
+	if len(optionalFieldPatterns.patterns) == 0 {
+		return fields
+	}
+	kept := fields[:0:0]
+	for _, field := range fields {
+		if !isOptionalFieldOf(msgType, field.Name()) {
+			kept = append(kept, field)
+		}
+	}
+	return kept
+}
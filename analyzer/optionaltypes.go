@@ -0,0 +1,171 @@
+package analyzer
+
+import (
+	"encoding/gob"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Some messages are optional wherever they appear, such as a DebugInfo attached to
+// responses in development builds only. Instead of accepting each field holding one,
+// the message type is declared optional, either with -optional-types or with a
+// directive in its doc comment:
+//
+//	message DebugInfo {
+//	  // nonil:optional
+//	  ...
+//	}
+//
+// protoc-gen-go copies the comments of a message into the doc comment of its Go type,
+// so the directive is written once in the .proto file and survives regeneration.
+
+// optionalDirective marks a message type as optional wherever it appears as a field
+const optionalDirective = "nonil:optional"
+
+// optionalTypeNames holds the message types set via -optional-types
+var optionalTypeNames typeNamesFlag
+
+func init() {
+	Analyzer.Flags.Var(&optionalTypeNames, "optional-types",
+		"comma-separated message types (e.g. 'DebugInfo' or 'example.com/gen/debugpb.DebugInfo') whose fields are optional wherever they appear, like types whose doc comment says nonil:optional")
+	gob.Register(new(optionalTypeFact))
+}
+
+// typeNamesFlag is a flag.Value holding comma-separated type names, bare or qualified
+// with the import path of their package
+type typeNamesFlag []string
+
+func (f *typeNamesFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return strings.Join(*f, ",")
+}
+
+func (f *typeNamesFlag) Set(value string) error {
+	*f = splitPatterns(value)
+	return nil
+}
+
+// matches checks if obj is one of the names
+func (f typeNamesFlag) matches(obj *types.TypeName) bool {
+	for _, name := range f {
+		if name == obj.Name() || (obj.Pkg() != nil && name == obj.Pkg().Path()+"."+obj.Name()) {
+			return true
+		}
+	}
+	return false
+}
+
+// optionalTypeFact marks a message type whose doc comment holds the optional directive.
+// Facts travel with the package, so the generated package declaring the type doesn't
+// have to be checked, or even loaded from source.
+type optionalTypeFact struct{}
+
+func (*optionalTypeFact) AFact() {}
+
+func (*optionalTypeFact) String() string { return "optional" }
+
+// optionalTypes counts, for each *types.TypeName marked optional, the passes running
+// that have recorded it. Type names are shared by the packages importing them, and
+// every pass records the marks it sees before reading the set, then releases them when
+// it finishes (see releaseOptionalTypes), so the set holds only the types of the
+// packages being analyzed.
+var (
+	optionalTypesMu sync.RWMutex
+	optionalTypes   = make(map[*types.TypeName]int)
+)
+
+// passOptionalTypes holds the types each pass recorded in optionalTypes, by
+// *types.Package
+var passOptionalTypes sync.Map
+
+// loadOptionalTypes marks the package's message types that carry the optional
+// directive, and records those marked by its dependencies
+func loadOptionalTypes(pass *analysis.Pass) {
+	var marked []*types.TypeName
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				doc := ts.Doc
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+				obj, ok := pass.TypesInfo.Defs[ts.Name].(*types.TypeName)
+				if ok && hasOptionalDirective(doc) && isProtobufMessageType(obj.Type()) {
+					pass.ExportObjectFact(obj, new(optionalTypeFact))
+					marked = append(marked, obj)
+				}
+			}
+		}
+	}
+	for _, fact := range pass.AllObjectFacts() {
+		if obj, ok := fact.Object.(*types.TypeName); ok {
+			if _, ok := fact.Fact.(*optionalTypeFact); ok {
+				marked = append(marked, obj)
+			}
+		}
+	}
+
+	optionalTypesMu.Lock()
+	defer optionalTypesMu.Unlock()
+	for _, obj := range marked {
+		optionalTypes[obj]++
+	}
+	passOptionalTypes.Store(pass.Pkg, marked)
+}
+
+// releaseOptionalTypes drops the types a pass recorded, once no other pass running
+// holds them
+func releaseOptionalTypes(pass *analysis.Pass) {
+	marked, ok := passOptionalTypes.LoadAndDelete(pass.Pkg)
+	if !ok {
+		return
+	}
+	optionalTypesMu.Lock()
+	defer optionalTypesMu.Unlock()
+	for _, obj := range marked.([]*types.TypeName) {
+		if optionalTypes[obj]--; optionalTypes[obj] == 0 {
+			delete(optionalTypes, obj)
+		}
+	}
+}
+
+// hasOptionalDirective checks if a doc comment has a line holding just the optional
+// directive, written as //nonil:optional or, as copied from a .proto file, // nonil:optional
+func hasOptionalDirective(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, c := range doc.List {
+		if strings.TrimSpace(strings.TrimPrefix(c.Text, "//")) == optionalDirective {
+			return true
+		}
+	}
+	return false
+}
+
+// isOptionalType checks if a field of type t holds a message type declared optional
+func isOptionalType(t types.Type) bool {
+	obj := namedTypeName(t)
+	if obj == nil {
+		return false
+	}
+	optionalTypesMu.RLock()
+	marked := optionalTypes[obj] > 0
+	optionalTypesMu.RUnlock()
+	if marked {
+		return true
+	}
+	return optionalTypeNames.matches(obj)
+}
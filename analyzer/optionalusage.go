@@ -0,0 +1,179 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// suggestOptionalUsage reports optional fields used like required ones, set via
+// -optional-usage
+var suggestOptionalUsage bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&suggestOptionalUsage, "optional-usage", false,
+		"report optional fields cleared by assigning nil or tested against nil when the generated code has Has and Clear methods for them, and suggest the methods")
+}
+
+// A field a team decides can stay unset is made optional in the .proto file, and the
+// findings on it go away. The code written while it was required doesn't: it clears
+// the field with resp.Debug = nil and tests it with resp.Debug != nil. For fields with
+// explicit presence, the hybrid and opaque APIs of protoc-gen-go generate methods that
+// say what the code means, and only the methods survive the move to the opaque API:
+//
+//	resp.ClearDebug()
+//	if resp.HasDebug() { ... }
+//
+// With -optional-usage, nil assignments and nil comparisons of a field the schema makes
+// optional are reported wherever its message has the Has and Clear methods, with a fix
+// that calls them. Fields the schema still requires are left to the nil checks, so the
+// Go code follows the .proto file as it changes.
+
+// checkOptionalUsage reports optional fields cleared or tested through nil
+func checkOptionalUsage(inspect *inspector.Inspector, pass *analysis.Pass) {
+	if !suggestOptionalUsage {
+		return
+	}
+
+	nodeFilter := []ast.Node{
+		(*ast.AssignStmt)(nil),
+		(*ast.BinaryExpr)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			// resp.Debug = nil
+			if node.Tok != token.ASSIGN || len(node.Lhs) != 1 || len(node.Rhs) != 1 || !isNilLiteral(node.Rhs[0], pass) {
+				return
+			}
+			base, field := optionalField(node.Lhs[0], false, pass)
+			if field == nil {
+				return
+			}
+			call := fmt.Sprintf("%s.Clear%s()", types.ExprString(base), field.Name())
+			reportOptionalUsage(node, fmt.Sprintf("optional field '%s' of protobuf message %s is cleared by assigning nil; call %s",
+				field.Name(), describeType(pass, messageType(pass.TypesInfo.TypeOf(base))), call), "Clear"+field.Name(), call, pass)
+
+		case *ast.BinaryExpr:
+			// resp.Debug != nil, resp.GetDebug() == nil
+			if node.Op != token.EQL && node.Op != token.NEQ {
+				return
+			}
+			operand := node.X
+			switch {
+			case isNilLiteral(node.Y, pass):
+			case isNilLiteral(node.X, pass):
+				operand = node.Y
+			default:
+				return
+			}
+			base, field := optionalField(operand, true, pass)
+			if field == nil {
+				return
+			}
+			call := fmt.Sprintf("%s.Has%s()", types.ExprString(base), field.Name())
+			if node.Op == token.EQL {
+				call = "!" + call
+			}
+			reportOptionalUsage(node, fmt.Sprintf("presence of optional field '%s' of protobuf message %s is tested against nil; use %s",
+				field.Name(), describeType(pass, messageType(pass.TypesInfo.TypeOf(base))), call), "Has"+field.Name(), call, pass)
+		}
+	})
+}
+
+// optionalField returns the message and field of resp.Debug, or of resp.GetDebug() when
+// getters are accepted, when the field is optional and the message has Has and Clear
+// methods for it, or nil
+func optionalField(expr ast.Expr, getters bool, pass *analysis.Pass) (ast.Expr, *types.Var) {
+	expr = ast.Unparen(expr)
+	var sel *ast.SelectorExpr
+	name := ""
+	switch e := expr.(type) {
+	case *ast.SelectorExpr:
+		sel, name = e, e.Sel.Name
+	case *ast.CallExpr:
+		s, ok := ast.Unparen(e.Fun).(*ast.SelectorExpr)
+		if !getters || !ok || len(e.Args) != 0 || !strings.HasPrefix(s.Sel.Name, "Get") {
+			return nil, nil
+		}
+		sel, name = s, strings.TrimPrefix(s.Sel.Name, "Get")
+	default:
+		return nil, nil
+	}
+	msgType := pass.TypesInfo.TypeOf(sel.X)
+	if msgType == nil || !isProtobufMessageType(msgType) {
+		return nil, nil
+	}
+	structType := getStructType(msgType)
+	if structType == nil {
+		return nil, nil
+	}
+	var field *types.Var
+	for i := 0; i < structType.NumFields(); i++ {
+		if structType.Field(i).Name() == name {
+			field = structType.Field(i)
+		}
+	}
+	if field == nil || !hasPresenceMethods(msgType, name) {
+		return nil, nil
+	}
+	for _, required := range requiredFields(structType, msgType, isRequestMessage(msgType)) {
+		if required == field {
+			return nil, nil
+		}
+	}
+	return sel.X, field
+}
+
+// isNilLiteral checks if expr is nil, or nil converted to a pointer type. Unlike
+// isNilValue it doesn't follow variables, whose assignment the fix would drop.
+func isNilLiteral(expr ast.Expr, pass *analysis.Pass) bool {
+	return pass.TypesInfo.Types[unwrapExpr(expr, pass)].IsNil()
+}
+
+// hasPresenceMethods checks if a message type has the HasX() bool and ClearX() methods
+// generated for a field X with explicit presence
+func hasPresenceMethods(msgType types.Type, name string) bool {
+	if _, ok := msgType.(*types.Pointer); !ok {
+		msgType = types.NewPointer(msgType)
+	}
+	has, _, _ := types.LookupFieldOrMethod(msgType, true, nil, "Has"+name)
+	clear, _, _ := types.LookupFieldOrMethod(msgType, true, nil, "Clear"+name)
+	hasFn, ok := has.(*types.Func)
+	if !ok {
+		return false
+	}
+	if _, ok := clear.(*types.Func); !ok {
+		return false
+	}
+	sig := hasFn.Type().(*types.Signature)
+	return sig.Params().Len() == 0 && sig.Results().Len() == 1 && types.Identical(sig.Results().At(0).Type(), types.Typ[types.Bool])
+}
+
+// reportOptionalUsage reports a nil assignment or comparison of an optional field, with
+// a fix replacing it with the call of the generated method
+func reportOptionalUsage(node ast.Node, message, method, call string, pass *analysis.Pass) {
+	pass.Report(analysis.Diagnostic{
+		Pos:      node.Pos(),
+		End:      node.End(),
+		Category: KindOptionalUsage,
+		Message:  message,
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message:   fmt.Sprintf(optionalUsageFixFormat, method),
+			TextEdits: []analysis.TextEdit{{Pos: node.Pos(), End: node.End(), NewText: []byte(call)}},
+		}},
+	})
+}
+
+// messageType returns the message type a pointer to it points to
+func messageType(t types.Type) types.Type {
+	if ptr, ok := t.(*types.Pointer); ok {
+		return ptr.Elem()
+	}
+	return t
+}
@@ -0,0 +1,248 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// Functional options build a response through closures:
+//
+//	func WithUser(u *pb.User) Option { return func(r *pb.UserResponse) { r.User = u } }
+//	func NewResponse(opts ...Option) *pb.UserResponse { ... for _, o := range opts { o(r) } ... }
+//
+//	resp := NewResponse(WithUser(u), WithLogin(t))
+//
+// The literal inside NewResponse is handed to the options and is not checked there.
+// Instead, the option functions declared in the package are analyzed to learn which
+// fields they set, and each NewResponse call is checked for required fields that
+// neither the constructor nor any of the passed options sets.
+
+// optionFunc is a package-level function returning a closure that fills in a response
+type optionFunc struct {
+	// fields holds the response fields the closure assigns
+	fields map[string]bool
+
+	// params maps a field to the index of the option function parameter assigned to it
+	// unchanged, as in r.User = u
+	params map[string]int
+}
+
+// optionConstructor is a package-level function taking variadic options and returning a response
+type optionConstructor struct {
+	msgType types.Type
+
+	// covered holds the fields the constructor sets itself
+	covered map[string]bool
+}
+
+// checkOptionConstructors checks calls to functional-options constructors in the package
+func checkOptionConstructors(inspect *inspector.Inspector, pass *analysis.Pass) {
+	options, constructors := collectOptionFuncs(pass)
+	if len(options) == 0 || len(constructors) == 0 {
+		return
+	}
+
+	inspect.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if !ok {
+			return
+		}
+		ctor := constructors[fn]
+		if ctor == nil || call.Ellipsis.IsValid() {
+			return
+		}
+
+		set := make(map[string]bool)
+		for field := range ctor.covered {
+			set[field] = true
+		}
+		for _, arg := range call.Args {
+			optCall, ok := ast.Unparen(arg).(*ast.CallExpr)
+			if !ok {
+				return // an option value we can't see into
+			}
+			optFn, _ := typeutil.Callee(pass.TypesInfo, optCall).(*types.Func)
+			opt := options[optFn]
+			if opt == nil {
+				return
+			}
+			for field := range opt.fields {
+				set[field] = true
+			}
+			paramFields := make([]string, 0, len(opt.params))
+			for field := range opt.params {
+				paramFields = append(paramFields, field)
+			}
+			sort.Strings(paramFields)
+			for _, field := range paramFields {
+				index := opt.params[field]
+				if index < len(optCall.Args) && isNilIdent(optCall.Args[index]) {
+					reportField(pass, rootField(ctor.msgType, field), analysis.Diagnostic{
+						Pos:      optCall.Args[index].Pos(),
+						Category: KindNilLiteral,
+						Message: fmt.Sprintf("nil assignment to non-optional message field '%s' in protobuf message %s through option %s%s",
+							field, describeType(pass, ctor.msgType), optFn.Name(), gatewayNote(ctor.msgType, field)),
+					})
+				}
+			}
+		}
+
+		structType := getStructType(ctor.msgType)
+		if structType == nil {
+			return
+		}
+		for _, field := range schemaFields(structType, ctor.msgType) {
+			if !set[field.Name()] {
+				reportField(pass, rootField(ctor.msgType, field.Name()), analysis.Diagnostic{
+					Pos:      call.Pos(),
+					Category: KindMissingField,
+					Message: fmt.Sprintf("non-optional message field '%s' not initialized in protobuf message %s by %s or the options passed to it%s",
+						field.Name(), describeType(pass, ctor.msgType), fn.Name(), gatewayNote(ctor.msgType, field.Name())),
+				})
+			}
+		}
+	})
+}
+
+// collectOptionFuncs finds the option functions and option constructors declared in the package
+func collectOptionFuncs(pass *analysis.Pass) (map[*types.Func]*optionFunc, map[*types.Func]*optionConstructor) {
+	options := make(map[*types.Func]*optionFunc)
+	constructors := make(map[*types.Func]*optionConstructor)
+
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Recv != nil || fd.Body == nil {
+				continue
+			}
+			fn, ok := pass.TypesInfo.Defs[fd.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+			sig := fn.Type().(*types.Signature)
+
+			if sig.Results().Len() == 1 && optionTarget(sig.Results().At(0).Type()) != nil {
+				options[fn] = analyzeOptionFunc(fd, sig, pass)
+				continue
+			}
+
+			if sig.Variadic() && sig.Results().Len() >= 1 {
+				last := sig.Params().At(sig.Params().Len() - 1).Type().(*types.Slice).Elem()
+				msgType := optionTarget(last)
+				result := sig.Results().At(0).Type()
+				if msgType == nil || !types.Identical(msgType, result) {
+					continue
+				}
+				constructors[fn] = &optionConstructor{
+					msgType: result,
+					covered: constructorFields(fd.Body, msgType, pass),
+				}
+			}
+		}
+	}
+	return options, constructors
+}
+
+// optionTarget returns the response pointer type an option type func(*Resp) fills in, or nil
+func optionTarget(t types.Type) types.Type {
+	sig, ok := t.Underlying().(*types.Signature)
+	if !ok || sig.Params().Len() != 1 || sig.Results().Len() != 0 {
+		return nil
+	}
+	param := sig.Params().At(0).Type()
+	if _, ok := param.(*types.Pointer); !ok || !isResponseMessage(param) || !isTypeSelected(param) {
+		return nil
+	}
+	return param
+}
+
+// analyzeOptionFunc learns which fields the closures returned by an option function set
+func analyzeOptionFunc(fd *ast.FuncDecl, sig *types.Signature, pass *analysis.Pass) *optionFunc {
+	opt := &optionFunc{fields: make(map[string]bool), params: make(map[string]int)}
+
+	paramIndex := make(map[types.Object]int)
+	for i := 0; i < sig.Params().Len(); i++ {
+		paramIndex[sig.Params().At(i)] = i
+	}
+
+	inspectFunctionBody(fd.Body, func(n ast.Node) {
+		ret, ok := n.(*ast.ReturnStmt)
+		if !ok || len(ret.Results) != 1 {
+			return
+		}
+		lit, ok := ast.Unparen(ret.Results[0]).(*ast.FuncLit)
+		if !ok || len(lit.Type.Params.List) != 1 || len(lit.Type.Params.List[0].Names) != 1 {
+			return
+		}
+		target := pass.TypesInfo.Defs[lit.Type.Params.List[0].Names[0]]
+		if target == nil {
+			return
+		}
+
+		inspectFunctionBody(lit.Body, func(n ast.Node) {
+			assign, ok := n.(*ast.AssignStmt)
+			if !ok || len(assign.Lhs) != len(assign.Rhs) {
+				return
+			}
+			for i, lhs := range assign.Lhs {
+				sel := storedField(lhs, pass)
+				if sel == nil {
+					continue
+				}
+				base := baseIdent(sel, pass)
+				if base == nil || pass.TypesInfo.ObjectOf(base) != target || isNilIdent(unwrapExpr(assign.Rhs[i], pass)) {
+					continue
+				}
+				opt.fields[sel.Sel.Name] = true
+				if id, ok := ast.Unparen(assign.Rhs[i]).(*ast.Ident); ok {
+					if index, ok := paramIndex[pass.TypesInfo.ObjectOf(id)]; ok {
+						opt.params[sel.Sel.Name] = index
+					}
+				}
+			}
+		})
+	})
+	return opt
+}
+
+// constructorFields returns the fields of msgType a constructor sets itself, in
+// composite literals of that type or by assignment
+func constructorFields(body *ast.BlockStmt, msgType types.Type, pass *analysis.Pass) map[string]bool {
+	covered := make(map[string]bool)
+	isTarget := func(t types.Type) bool {
+		return t != nil && namedTypeName(t) != nil && namedTypeName(t) == namedTypeName(msgType)
+	}
+
+	inspectFunctionBody(body, func(n ast.Node) {
+		switch node := n.(type) {
+		case *ast.CompositeLit:
+			if !isTarget(pass.TypesInfo.TypeOf(node)) {
+				return
+			}
+			for _, elt := range node.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok && !isNilIdent(kv.Value) {
+					if id, ok := kv.Key.(*ast.Ident); ok {
+						covered[id.Name] = true
+					}
+				}
+			}
+		case *ast.AssignStmt:
+			if len(node.Lhs) != len(node.Rhs) {
+				return
+			}
+			for i, lhs := range node.Lhs {
+				if sel := storedField(lhs, pass); sel != nil && isTarget(pass.TypesInfo.TypeOf(sel.X)) && !isNilIdent(unwrapExpr(node.Rhs[i], pass)) {
+					covered[sel.Sel.Name] = true
+				}
+			}
+		}
+	})
+	return covered
+}
@@ -0,0 +1,99 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// checkOutputOnlySets reports request messages whose OUTPUT_ONLY fields are set, when
+// -forbid-output-only is set. The server owns those fields and ignores (or rejects)
+// what clients send, so setting them usually means a response was reused as a request
+// or the client expects a value it won't get. Fields are set in request literals, in
+// message literals nested in them, or assigned through req.Field = value.
+func checkOutputOnlySets(inspect *inspector.Inspector, pass *analysis.Pass) {
+	if !forbidOutputOnly {
+		return
+	}
+
+	nodeFilter := []ast.Node{
+		(*ast.CompositeLit)(nil),
+		(*ast.AssignStmt)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		switch node := n.(type) {
+		case *ast.CompositeLit:
+			if litType := pass.TypesInfo.TypeOf(node); litType != nil && isRequestMessage(litType) {
+				checkOutputOnlyLiteral(node, litType, pass)
+			}
+
+		case *ast.AssignStmt:
+			for i, lhs := range node.Lhs {
+				sel := storedField(lhs, pass)
+				if sel == nil || i >= len(node.Rhs) || isNilValue(node.Rhs[i], pass) {
+					continue
+				}
+				baseType := pass.TypesInfo.TypeOf(sel.X)
+				if baseType == nil || !isRequestMessage(baseType) {
+					continue
+				}
+				if field := getFieldFromType(baseType, sel.Sel.Name); field != nil &&
+					isOutputOnlyField(baseType, getStructType(baseType), field) {
+					reportOutputOnlySet(sel.Pos(), rootField(baseType, sel.Sel.Name), baseType, pass)
+				}
+			}
+		}
+	})
+}
+
+// checkOutputOnlyLiteral reports OUTPUT_ONLY fields set in a message literal and in the
+// message literals nested in it. Nested requests are left to their own visit.
+func checkOutputOnlyLiteral(lit *ast.CompositeLit, litType types.Type, pass *analysis.Pass) {
+	structType := getStructType(litType)
+	if structType == nil {
+		return
+	}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		field := getFieldFromType(litType, key.Name)
+		if field == nil {
+			continue
+		}
+		if isOutputOnlyField(litType, structType, field) && !isNilValue(kv.Value, pass) {
+			reportOutputOnlySet(key.Pos(), rootField(litType, key.Name), litType, pass)
+		}
+
+		value := kv.Value
+		if unary, ok := value.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+			value = unary.X
+		}
+		nested, ok := value.(*ast.CompositeLit)
+		if !ok {
+			continue
+		}
+		if nestedType := pass.TypesInfo.TypeOf(nested); nestedType != nil &&
+			isProtobufMessageType(nestedType) && !isRequestMessage(nestedType) {
+			checkOutputOnlyLiteral(nested, nestedType, pass)
+		}
+	}
+}
+
+func reportOutputOnlySet(pos token.Pos, field fieldRef, msgType types.Type, pass *analysis.Pass) {
+	reportField(pass, field, analysis.Diagnostic{
+		Pos:      pos,
+		Category: KindOutputOnly,
+		Message: fmt.Sprintf("OUTPUT_ONLY field '%s' of protobuf message %s is set in a request; the server owns it and ignores the value",
+			field, describeType(pass, msgType)),
+	})
+}
@@ -0,0 +1,172 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// allowsPartialResponse checks if a return may hand back a response with required
+// fields unset under -partial-responses. With "with-error", handlers that collect what
+// they could and return it alongside an error aren't reported, as long as the error is
+// known to be non-nil: clients see the error and don't rely on the response. Builders
+// hand back responses their callers complete, whatever the flag says; see builders.go.
+func allowsPartialResponse(ret *ast.ReturnStmt, pass *analysis.Pass) bool {
+	if returnsToCaller(ret, pass) {
+		return true
+	}
+	if partialResponses != "with-error" || len(ret.Results) < 2 {
+		return false
+	}
+	last := ret.Results[len(ret.Results)-1]
+	if t := pass.TypesInfo.TypeOf(last); t == nil || !types.Identical(t, errorType) {
+		return false
+	}
+	file := fileAt(ret.Pos(), pass)
+	if file == nil {
+		return false
+	}
+	path, _ := astutil.PathEnclosingInterval(file, ret.Pos(), ret.End())
+	return isNonNilError(last, path, pass, make(map[types.Object]bool))
+}
+
+var errorType = types.Universe.Lookup("error").Type()
+
+// joiningFuncs are the functions that combine errors into one that is nil only when all
+// of them are
+var joiningFuncs = map[string]bool{
+	"errors.Join":                  true,
+	"go.uber.org/multierr.Combine": true,
+	"go.uber.org/multierr.Append":  true,
+}
+
+// isNonNilError checks if an error expression is non-nil where it is evaluated. path
+// runs from the expression's statement out to the file. An error is non-nil when it
+// is:
+//   - built by errors.New or fmt.Errorf
+//   - joined by errors.Join or multierr from at least one non-nil error
+//   - a variable inside an if that checks it against nil
+//   - a variable assigned once, from a non-nil error
+func isNonNilError(expr ast.Expr, path []ast.Node, pass *analysis.Pass, seen map[types.Object]bool) bool {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.CallExpr:
+		fn := typeutil.StaticCallee(pass.TypesInfo, e)
+		if fn == nil {
+			return false
+		}
+		switch name := fn.FullName(); {
+		case name == "errors.New" || name == "fmt.Errorf":
+			return true
+		case joiningFuncs[name]:
+			if e.Ellipsis.IsValid() {
+				// errors.Join(errs...) is nil for an empty or all-nil slice
+				return false
+			}
+			for _, arg := range e.Args {
+				if isNonNilError(arg, path, pass, seen) {
+					return true
+				}
+			}
+		}
+
+	case *ast.Ident:
+		obj, ok := pass.TypesInfo.Uses[e].(*types.Var)
+		if !ok || seen[obj] {
+			return false
+		}
+		seen[obj] = true
+		return guardedNonNil(obj, path, pass) || assignedNonNil(obj, path, pass, seen)
+	}
+	return false
+}
+
+// guardedNonNil checks if path runs through the body of an if whose condition checks
+// obj != nil, alone or as an operand of &&
+func guardedNonNil(obj types.Object, path []ast.Node, pass *analysis.Pass) bool {
+	for i := 1; i < len(path); i++ {
+		ifStmt, ok := path[i].(*ast.IfStmt)
+		if ok && path[i-1] == ifStmt.Body && checksNonNil(ifStmt.Cond, obj, pass) {
+			return true
+		}
+	}
+	return false
+}
+
+// checksNonNil checks if cond implies obj != nil
+func checksNonNil(cond ast.Expr, obj types.Object, pass *analysis.Pass) bool {
+	bin, ok := ast.Unparen(cond).(*ast.BinaryExpr)
+	if !ok {
+		return false
+	}
+	switch bin.Op {
+	case token.LAND:
+		return checksNonNil(bin.X, obj, pass) || checksNonNil(bin.Y, obj, pass)
+	case token.NEQ:
+		for _, pair := range [][2]ast.Expr{{bin.X, bin.Y}, {bin.Y, bin.X}} {
+			id, ok := ast.Unparen(pair[0]).(*ast.Ident)
+			if ok && pass.TypesInfo.Uses[id] == obj && pass.TypesInfo.Types[pair[1]].IsNil() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// assignedNonNil checks if obj is a local variable of the enclosing function that is
+// assigned exactly once, from a non-nil error
+func assignedNonNil(obj types.Object, path []ast.Node, pass *analysis.Pass, seen map[types.Object]bool) bool {
+	var body *ast.BlockStmt
+	for _, n := range path {
+		if fn, ok := n.(*ast.FuncDecl); ok {
+			body = fn.Body
+			break
+		}
+		if fn, ok := n.(*ast.FuncLit); ok {
+			body = fn.Body
+			break
+		}
+	}
+	if body == nil {
+		return false
+	}
+
+	var values []ast.Expr
+	assignments := 0
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range node.Lhs {
+				if id := unwrapIdent(lhs, pass); id != nil && pass.TypesInfo.ObjectOf(id) == obj {
+					assignments++
+					if len(node.Lhs) == len(node.Rhs) {
+						values = append(values, node.Rhs[i])
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			for i, name := range node.Names {
+				if pass.TypesInfo.ObjectOf(name) == obj {
+					assignments++
+					if i < len(node.Values) {
+						values = append(values, node.Values[i])
+					}
+				}
+			}
+		case *ast.UnaryExpr:
+			if id := unwrapIdent(node.X, pass); id != nil && node.Op == token.AND && pass.TypesInfo.ObjectOf(id) == obj {
+				// Its address escapes; anything may be stored through it
+				assignments += 2
+			}
+		}
+		return true
+	})
+	if assignments != 1 || len(values) != 1 {
+		return false
+	}
+	valuePath, _ := astutil.PathEnclosingInterval(fileAt(values[0].Pos(), pass), values[0].Pos(), values[0].End())
+	return isNonNilError(values[0], valuePath, pass, seen)
+}
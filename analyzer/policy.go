@@ -0,0 +1,140 @@
+package analyzer
+
+import (
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/nickheyer/go_no_nil_linter/internal/protopolicy"
+)
+
+// PolicySchema identifies the format of an exported Policy
+const PolicySchema = "nonillinter.policy/v1"
+
+// Policy is the required-field policy in a language-neutral form, so linters for the
+// TypeScript or Java clients of the same protos can enforce matching construction
+// rules. Messages and fields are named as in the .proto files, not by Go identifiers.
+type Policy struct {
+	Schema   string          `json:"schema"`
+	Messages []PolicyMessage `json:"messages"`
+
+	// Extensions lists the message extensions declared in the packages; a value set
+	// for one must not be nil, like a required field
+	Extensions []PolicyExtension `json:"extensions"`
+}
+
+// PolicyMessage lists the required fields of one message
+type PolicyMessage struct {
+	// Name is the proto full name, e.g. "example.v1.UserResponse"
+	Name string `json:"name"`
+
+	// Response is set for messages where checking starts (see -response-suffixes)
+	Response bool `json:"response"`
+
+	RequiredFields []PolicyField `json:"requiredFields"`
+}
+
+// PolicyField is a required message field
+type PolicyField struct {
+	Name     string `json:"name"`
+	Number   int    `json:"number"`
+	JSONName string `json:"jsonName"`
+
+	// Type is the proto full name of the field's message type
+	Type string `json:"type"`
+}
+
+// PolicyExtension is an extension field of a message type
+type PolicyExtension struct {
+	// Name is the proto full name, e.g. "api.audit.audit"
+	Name   string `json:"name"`
+	Number int    `json:"number"`
+
+	// Extendee is the proto full name of the message it extends
+	Extendee string `json:"extendee"`
+
+	// Type is the proto full name of the extension's message type
+	Type string `json:"type"`
+}
+
+// ExportPolicy builds the policy for the messages declared in pkgs, typically the
+// generated .pb.go packages, using the current flag settings.
+//
+// Proto names, and the extensions, come from the file descriptor that protoc-gen-go
+// v1.36 and later embeds as a string constant. Messages from packages without one are
+// named by Go import path and type name instead.
+func ExportPolicy(pkgs []*types.Package) *Policy {
+	policy := &Policy{Schema: PolicySchema, Messages: []PolicyMessage{}, Extensions: []PolicyExtension{}}
+	for _, pkg := range pkgs {
+		for _, ext := range descriptorMetadataOf(pkg).extensions {
+			if ext.Message != "" {
+				policy.Extensions = append(policy.Extensions, PolicyExtension{Name: ext.FullName, Number: ext.Number, Extendee: ext.Extendee, Type: ext.Message})
+			}
+		}
+
+		scope := pkg.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || tn.IsAlias() || !isProtobufMessageType(tn.Type()) {
+				continue
+			}
+			structType := getStructType(tn.Type())
+			if structType == nil {
+				continue
+			}
+
+			msg := PolicyMessage{
+				Name:           protoFullName(tn),
+				Response:       isResponseMessage(tn.Type()),
+				RequiredFields: []PolicyField{},
+			}
+			for _, field := range schemaFields(structType, tn.Type()) {
+				msg.RequiredFields = append(msg.RequiredFields, policyField(structType, field))
+			}
+			policy.Messages = append(policy.Messages, msg)
+		}
+	}
+	sort.Slice(policy.Messages, func(i, j int) bool {
+		return policy.Messages[i].Name < policy.Messages[j].Name
+	})
+	sort.Slice(policy.Extensions, func(i, j int) bool {
+		return policy.Extensions[i].Name < policy.Extensions[j].Name
+	})
+	return policy
+}
+
+// policyField describes a required field by its protobuf tag
+func policyField(structType *types.Struct, field *types.Var) PolicyField {
+	pf := PolicyField{Name: field.Name(), JSONName: field.Name()}
+	for i := 0; i < structType.NumFields(); i++ {
+		if messageSchema.FieldName(structType.Field(i)) != field.Name() {
+			continue
+		}
+		if tag, ok := protopolicy.ParseTag(structType.Tag(i)); ok && tag.Name != "" {
+			pf.Name, pf.Number, pf.JSONName = tag.Name, tag.Number, tag.JSONName
+		}
+	}
+	if obj := namedTypeName(field.Type()); obj != nil {
+		pf.Type = protoFullName(obj)
+	}
+	return pf
+}
+
+// protoFullName returns the proto full name of a generated message type. Nested
+// messages are generated as Outer_Inner, which maps back to Outer.Inner.
+func protoFullName(obj *types.TypeName) string {
+	if obj.Pkg() == nil {
+		return obj.Name()
+	}
+	if protoPkg := protoPackageOf(obj.Pkg()); protoPkg != "" {
+		return protoPkg + "." + strings.ReplaceAll(obj.Name(), "_", ".")
+	}
+	// Well-known types may come from older runtimes without a string descriptor
+	switch pkgPath := obj.Pkg().Path(); {
+	case strings.Contains(pkgPath, "google.golang.org/protobuf/types/known"):
+		return "google.protobuf." + obj.Name()
+	case strings.Contains(pkgPath, "google.golang.org/genproto/googleapis/type"):
+		return "google.type." + obj.Name()
+	}
+	return obj.Pkg().Path() + "." + obj.Name()
+}
@@ -0,0 +1,200 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// pooledResponse is a response taken from a sync.Pool and bound to a local variable,
+// e.g. resp := respPool.Get().(*pb.UserResponse)
+type pooledResponse struct {
+	obj     types.Object
+	get     ast.Expr
+	pool    string
+	msgType types.Type
+
+	// reset is set once the response is reset: resp.Reset(), proto.Reset(resp), or
+	// *resp = pb.UserResponse{...}, in which case literal is set and the literal's
+	// fields are checked like any other's
+	reset   bool
+	literal bool
+
+	// assigned holds the fields set after the reset; passedToCall is set when the
+	// response is handed to a function that may set them instead
+	assigned     map[string]bool
+	passedToCall bool
+}
+
+// checkPooledResponses reports responses recycled through a sync.Pool that aren't fully
+// reinitialized:
+//
+//	resp := respPool.Get().(*pb.UserResponse)
+//	resp.User = u
+//	return resp, nil
+//
+// A pooled message comes back with the fields of its last use. Unless it is reset, a
+// field this call doesn't set holds another request's data, or nil when that request
+// didn't set it either; after the reset, each required field has to be set again. A
+// pool whose messages are reinitialized elsewhere, such as by its New function or by
+// the code putting them back, can say so with an ignore directive on the Get.
+func checkPooledResponses(body *ast.BlockStmt, pass *analysis.Pass) {
+	current := make(map[types.Object]*pooledResponse)
+	var pooled []*pooledResponse
+	take := func(lhs, value ast.Expr) {
+		id, ok := lhs.(*ast.Ident)
+		if !ok {
+			return
+		}
+		obj := pass.TypesInfo.ObjectOf(id)
+		get, pool, msgType := poolGet(value, pass)
+		if obj == nil || get == nil {
+			return
+		}
+		p := &pooledResponse{obj: obj, get: get, pool: pool, msgType: msgType, assigned: make(map[string]bool)}
+		current[obj] = p
+		pooled = append(pooled, p)
+	}
+	// pooledVar returns the pooled response expr refers to, or nil
+	pooledVar := func(expr ast.Expr) *pooledResponse {
+		if id, ok := ast.Unparen(expr).(*ast.Ident); ok {
+			return current[pass.TypesInfo.ObjectOf(id)]
+		}
+		return nil
+	}
+
+	inspectFunctionBody(body, func(n ast.Node) {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			switch {
+			case len(node.Lhs) == len(node.Rhs):
+				for i, lhs := range node.Lhs {
+					take(lhs, node.Rhs[i])
+				}
+			case len(node.Rhs) == 1:
+				// resp, ok := respPool.Get().(*pb.UserResponse)
+				take(node.Lhs[0], node.Rhs[0])
+			}
+			for i, lhs := range node.Lhs {
+				star, ok := lhs.(*ast.StarExpr)
+				if !ok || i >= len(node.Rhs) {
+					continue
+				}
+				if p := pooledVar(star.X); p != nil && messageLiteral(node.Rhs[i], shouldCheckType, pass) != nil {
+					p.reset, p.literal = true, true
+				}
+			}
+			for _, p := range current {
+				if p.reset {
+					collectFieldAssignments(node, p.obj, p.assigned, pass)
+				}
+			}
+
+		case *ast.ValueSpec:
+			if len(node.Names) == len(node.Values) {
+				for i, name := range node.Names {
+					take(name, node.Values[i])
+				}
+			}
+
+		case *ast.CallExpr:
+			if p := pooledVar(resetTarget(node, pass)); p != nil {
+				p.reset = true
+				return
+			}
+			for _, p := range current {
+				if !p.reset {
+					continue
+				}
+				collectSetterAssignments(node, p.obj, p.assigned, pass)
+				for i, arg := range node.Args {
+					if pooledVar(arg) == p && !keepsArgument(node, i, pass) && !isPoolMethod(node, "Put", pass) {
+						p.passedToCall = true
+					}
+				}
+			}
+		}
+	})
+
+	for _, p := range pooled {
+		name := p.obj.Name()
+		if !p.reset {
+			pass.Report(analysis.Diagnostic{
+				Pos:      p.get.Pos(),
+				Category: KindPooledResponse,
+				Message: fmt.Sprintf("response '%s' of type %s taken from sync.Pool '%s' is not reset and keeps the fields of its last use, stale or nil; call %s.Reset() and set each required field, or give the reason it is safe in an ignore directive",
+					name, describeType(pass, p.msgType), p.pool, name),
+			})
+			continue
+		}
+		if p.literal || p.passedToCall {
+			continue
+		}
+		structType := getStructType(p.msgType)
+		if structType == nil {
+			continue
+		}
+		var missing []string
+		for _, field := range requiredFields(structType, p.msgType, isRequestMessage(p.msgType)) {
+			if !p.assigned[field.Name()] {
+				missing = append(missing, "'"+field.Name()+"'")
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos:      p.get.Pos(),
+			Category: KindPooledResponse,
+			Message: fmt.Sprintf("non-optional message fields %s of response '%s' of type %s taken from sync.Pool '%s' are not set again after Reset",
+				strings.Join(missing, ", "), name, describeType(pass, p.msgType), p.pool),
+		})
+	}
+}
+
+// poolGet returns the Get call in pool.Get().(*T) when T is a checked message type,
+// with the pool's name and T, or nil
+func poolGet(expr ast.Expr, pass *analysis.Pass) (ast.Expr, string, types.Type) {
+	assert, ok := ast.Unparen(expr).(*ast.TypeAssertExpr)
+	if !ok || assert.Type == nil {
+		return nil, "", nil
+	}
+	call, ok := ast.Unparen(assert.X).(*ast.CallExpr)
+	if !ok || !isPoolMethod(call, "Get", pass) {
+		return nil, "", nil
+	}
+	msgType := pass.TypesInfo.TypeOf(assert.Type)
+	if msgType == nil || !shouldCheckType(msgType) {
+		return nil, "", nil
+	}
+	if ptr, ok := msgType.(*types.Pointer); ok {
+		msgType = ptr.Elem()
+	}
+	return call, types.ExprString(call.Fun.(*ast.SelectorExpr).X), msgType
+}
+
+// isPoolMethod checks if a call is to the method name of a sync.Pool
+func isPoolMethod(call *ast.CallExpr, name string, pass *analysis.Pass) bool {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	return ok && fn.FullName() == "(*sync.Pool)."+name
+}
+
+// resetTarget returns the message a call resets, resp in resp.Reset() or
+// proto.Reset(resp), or nil
+func resetTarget(call *ast.CallExpr, pass *analysis.Pass) ast.Expr {
+	if protoRuntimeFunc(call, "Reset", pass) && len(call.Args) == 1 {
+		return call.Args[0]
+	}
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Reset" || len(call.Args) != 0 {
+		return nil
+	}
+	if t := pass.TypesInfo.TypeOf(sel.X); t != nil && isProtobufMessageType(t) {
+		return sel.X
+	}
+	return nil
+}
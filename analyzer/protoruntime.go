@@ -0,0 +1,155 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// Responses aren't always built from literals. The protobuf runtime copies them,
+//
+//	resp := proto.Clone(defaultResponse).(*pb.UserResponse)
+//	proto.Merge(resp, defaults)
+//
+// and protoc-gen-go's builder API generates a setter for each field:
+//
+//	resp.SetUser(u)
+//
+// A clone starts out with the fields its template sets, when the template is a literal
+// or a package-level variable bound to one and never changed. Merging such a template
+// sets its fields. Neither call changes its source, so handing a response to one
+// doesn't mean it is filled in there. A generated setter is checked as the assignment
+// it stands for, resp.User = u.
+
+// protoRuntimeFunc checks if call is to the protobuf runtime function name
+func protoRuntimeFunc(call *ast.CallExpr, name string, pass *analysis.Pass) bool {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	return ok && fn.Name() == name && fn.Pkg() != nil && fn.Pkg().Path() == protoPackagePath
+}
+
+// clonedTemplate returns the literal a proto.Clone(template).(*T) copy starts out as, and
+// the message type of the copy, or nil when expr isn't one or its template is unknown
+func clonedTemplate(expr ast.Expr, accept func(types.Type) bool, pass *analysis.Pass) (*ast.CompositeLit, types.Type) {
+	assert, ok := ast.Unparen(expr).(*ast.TypeAssertExpr)
+	if !ok || assert.Type == nil {
+		return nil, nil
+	}
+	call, ok := ast.Unparen(assert.X).(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 || !protoRuntimeFunc(call, "Clone", pass) {
+		return nil, nil
+	}
+	ptr, ok := pass.TypesInfo.TypeOf(assert).(*types.Pointer)
+	if !ok || !accept(ptr.Elem()) {
+		return nil, nil
+	}
+	template := templateLiteral(call.Args[0], pass)
+	if template == nil || !types.Identical(pass.TypesInfo.TypeOf(template), ptr.Elem()) {
+		return nil, nil
+	}
+	return template, ptr.Elem()
+}
+
+// templateLiteral returns the message literal expr is, or that the package-level
+// variable it names is bound to, or nil. Variables that are assigned to, or have a field
+// assigned or their address taken anywhere in the package, may not hold the literal.
+func templateLiteral(expr ast.Expr, pass *analysis.Pass) *ast.CompositeLit {
+	expr = ast.Unparen(expr)
+	if lit := messageLiteral(expr, isProtobufMessageType, pass); lit != nil {
+		return lit
+	}
+	id, ok := expr.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	obj, ok := pass.TypesInfo.Uses[id].(*types.Var)
+	if !ok || obj.Pkg() != pass.Pkg || obj.Parent() != pass.Pkg.Scope() {
+		return nil
+	}
+
+	var lit *ast.CompositeLit
+	changed := false
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.ValueSpec:
+				for i, name := range node.Names {
+					if pass.TypesInfo.Defs[name] == obj && i < len(node.Values) {
+						lit = messageLiteral(ast.Unparen(node.Values[i]), isProtobufMessageType, pass)
+					}
+				}
+			case *ast.AssignStmt:
+				for _, lhs := range node.Lhs {
+					if root, _ := storePath(lhs, nil, pass); root == obj {
+						changed = true
+					}
+				}
+			case *ast.UnaryExpr:
+				if root, _ := storePath(node.X, nil, pass); node.Op == token.AND && root == obj {
+					changed = true
+				}
+			}
+			return !changed
+		})
+	}
+	if changed {
+		return nil
+	}
+	return lit
+}
+
+// mergedFields returns the fields proto.Merge(dst, src) sets on obj when dst is obj and
+// src a known template, or nil
+func mergedFields(call *ast.CallExpr, obj types.Object, pass *analysis.Pass) map[string]bool {
+	if len(call.Args) != 2 || !protoRuntimeFunc(call, "Merge", pass) {
+		return nil
+	}
+	dst, ok := ast.Unparen(call.Args[0]).(*ast.Ident)
+	if !ok || pass.TypesInfo.ObjectOf(dst) != obj {
+		return nil
+	}
+	if template := templateLiteral(call.Args[1], pass); template != nil {
+		return literalFields(template, pass)
+	}
+	return nil
+}
+
+// keepsArgument checks if a call leaves its argument as it is, filling nothing in:
+// the source of proto.Clone or proto.Merge, and the destination of a merge from a
+// known template, whose fields are counted by mergedFields instead
+func keepsArgument(call *ast.CallExpr, i int, pass *analysis.Pass) bool {
+	switch {
+	case protoRuntimeFunc(call, "Clone", pass):
+		return true
+	case protoRuntimeFunc(call, "Merge", pass) && len(call.Args) == 2:
+		return i == 1 || templateLiteral(call.Args[1], pass) != nil
+	}
+	return false
+}
+
+// generatedSetter returns the field a generated setter method such as resp.SetUser(u)
+// stores into, as the selector resp.User, and the value stored, or nil if the call
+// isn't one
+func generatedSetter(call *ast.CallExpr, pass *analysis.Pass) (*ast.SelectorExpr, ast.Expr) {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok || len(call.Args) != 1 || !strings.HasPrefix(sel.Sel.Name, "Set") {
+		return nil, nil
+	}
+	fn, ok := pass.TypesInfo.Uses[sel.Sel].(*types.Func)
+	if !ok {
+		return nil, nil
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil || !isProtobufMessageType(recv.Type()) {
+		return nil, nil
+	}
+	name := strings.TrimPrefix(sel.Sel.Name, "Set")
+	field := getFieldFromType(recv.Type(), name)
+	if field == nil || !types.Identical(field.Type(), pass.TypesInfo.TypeOf(call.Args[0])) && !isNilValue(call.Args[0], pass) {
+		return nil, nil
+	}
+	return &ast.SelectorExpr{X: sel.X, Sel: &ast.Ident{NamePos: sel.Sel.NamePos, Name: name}}, call.Args[0]
+}
@@ -0,0 +1,83 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// nilProvenance explains where the nil in a variable came from, as a diagnostic suffix:
+//
+//	; nil introduced at handler.go:12 via declaration of u without initializer, flowed through u2 at handler.go:14
+//
+// It follows var declarations the same way isNilVariable does and returns "" for
+// anything else, such as a nil literal, whose origin is the diagnostic position itself.
+func nilProvenance(expr ast.Expr, pass *analysis.Pass) string {
+	if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		expr = unary.X
+	}
+	ident, ok := expr.(*ast.Ident)
+	if !ok || ident.Name == "nil" {
+		return ""
+	}
+	steps := nilSteps(pass.TypesInfo.ObjectOf(ident), pass, make(map[types.Object]bool))
+	if len(steps) == 0 {
+		return ""
+	}
+	return "; " + strings.Join(steps, ", ")
+}
+
+// nilSteps returns the provenance chain of a nil variable, origin first
+func nilSteps(obj types.Object, pass *analysis.Pass, seen map[types.Object]bool) []string {
+	if obj == nil || seen[obj] {
+		return nil
+	}
+	seen[obj] = true
+	decl := findValueSpec(obj, pass)
+	if decl == nil {
+		return nil
+	}
+
+	for i, name := range decl.Names {
+		if pass.TypesInfo.ObjectOf(name) != obj {
+			continue
+		}
+		if len(decl.Values) == 0 {
+			return []string{fmt.Sprintf("nil introduced at %s via declaration of %s without initializer",
+				shortPosition(pass, name.Pos()), name.Name)}
+		}
+		if i >= len(decl.Values) {
+			return nil
+		}
+		value := ast.Unparen(decl.Values[i])
+		if id, ok := value.(*ast.Ident); ok && id.Name != "nil" {
+			steps := nilSteps(pass.TypesInfo.ObjectOf(id), pass, seen)
+			if len(steps) == 0 {
+				return nil
+			}
+			return append(steps, fmt.Sprintf("flowed through %s at %s", name.Name, shortPosition(pass, name.Pos())))
+		}
+		if isNilValue(value, pass) {
+			return []string{fmt.Sprintf("nil introduced at %s via nil initializer of %s",
+				shortPosition(pass, value.Pos()), name.Name)}
+		}
+		return nil
+	}
+	return nil
+}
+
+// findValueSpec finds the var declaration of a variable in the package, or nil
+func findValueSpec(obj types.Object, pass *analysis.Pass) *ast.ValueSpec {
+	return declarations(pass).specs[obj]
+}
+
+// shortPosition renders a position as file.go:line
+func shortPosition(pass *analysis.Pass, pos token.Pos) string {
+	p := pass.Fset.Position(pos)
+	return fmt.Sprintf("%s:%d", filepath.Base(p.Filename), p.Line)
+}
@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Messages may hold messages of their own type:
+//
+//	message Employee {
+//	  Employee manager = 1;
+//	}
+//
+// A required field leading back to its message, directly or through other required
+// fields, can't be set at every level: each Employee needs a manager, who needs one of
+// their own. Where the chain ends, the field is reported once as recursive, a schema
+// problem, rather than as a field left unset.
+//
+// Nested literals are validated along a path kept for each package, so a literal
+// reached again through variables isn't validated again inside itself, and validation
+// stops -max-depth literals down.
+
+// validationPaths maps each package being analyzed to the nested literals being
+// validated, outermost first
+var validationPaths sync.Map // *types.Package -> *[]*ast.CompositeLit
+
+// enterLiteral adds lit to the package's validation path and returns the function
+// removing it. It returns false, adding nothing, when lit is already on the path or the
+// path is -max-depth literals long.
+func enterLiteral(lit *ast.CompositeLit, pass *analysis.Pass) (func(), bool) {
+	v, _ := validationPaths.LoadOrStore(pass.Pkg, new([]*ast.CompositeLit))
+	path := v.(*[]*ast.CompositeLit)
+	for _, outer := range *path {
+		if outer == lit {
+			log().Debug("skipping literal nested in itself", "pos", pass.Fset.Position(lit.Pos()))
+			return nil, false
+		}
+	}
+	if maxDepth > 0 && len(*path) >= maxDepth {
+		log().Debug("skipping literal beyond -max-depth", "depth", len(*path), "pos", pass.Fset.Position(lit.Pos()))
+		return nil, false
+	}
+	*path = append(*path, lit)
+	return func() { *path = (*path)[:len(*path)-1] }, true
+}
+
+// isRecursiveField checks if a required field of a message type leads back to that
+// type through required message fields
+func isRecursiveField(msgType types.Type, field *types.Var, requestSide bool) bool {
+	target := namedTypeName(msgType)
+	return target != nil && reachesType(field.Type(), target, requestSide, make(map[*types.TypeName]bool))
+}
+
+// reachesType checks if t is target or holds it in a required field, at any depth.
+// seen holds the types visited, which don't lead to target.
+func reachesType(t types.Type, target *types.TypeName, requestSide bool, seen map[*types.TypeName]bool) bool {
+	obj := namedTypeName(t)
+	structType := getStructType(t)
+	if obj == nil || structType == nil {
+		return false
+	}
+	if obj == target {
+		return true
+	}
+	if seen[obj] {
+		return false
+	}
+	seen[obj] = true
+	for _, field := range requiredFields(structType, t, requestSide) {
+		if reachesType(field.Type(), target, requestSide, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// reportRecursiveField reports a recursive required field, at the path fieldPath from
+// the message, left nil or unset at pos
+func reportRecursiveField(pos token.Pos, field fieldRef, msgType types.Type, pass *analysis.Pass) {
+	name := field.path[strings.LastIndex(field.path, ".")+1:]
+	reportField(pass, field, analysis.Diagnostic{
+		Pos:      pos,
+		Category: KindRecursiveField,
+		Message: fmt.Sprintf("recursive non-optional message field '%s' in protobuf message %s: every message needs another through it, so it can't be set at every level; make '%s' optional",
+			field, describeType(pass, msgType), name),
+	})
+}
@@ -0,0 +1,148 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// reflectSetters are the reflect.Value methods that write through to a field
+var reflectSetters = map[string]bool{
+	"Set":        true,
+	"SetZero":    true,
+	"SetPointer": true,
+}
+
+// reflectNavigators are the reflect.Value methods that step from a value to the
+// value it points to or one of its fields
+var reflectNavigators = map[string]bool{
+	"Elem":            true,
+	"Addr":            true,
+	"Field":           true,
+	"FieldByName":     true,
+	"FieldByIndex":    true,
+	"FieldByNameFunc": true,
+}
+
+// checkReflectiveSet reports a reflective write into a response message, e.g.
+//
+//	reflect.ValueOf(resp).Elem().FieldByName("User").Set(reflect.ValueOf(u))
+//
+// The value written is invisible to the analyzer, so nil-safety of the field can't be
+// verified. The diagnostic is advisory unless -reflection=error, which turns it into an
+// error for files outside tests and mock packages.
+func checkReflectiveSet(call *ast.CallExpr, pass *analysis.Pass) {
+	if reflectionMode == "off" {
+		return
+	}
+
+	method, ok := reflectValueMethod(call, pass)
+	if !ok || !reflectSetters[method] {
+		return
+	}
+	sel := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+
+	msgType, field := reflectTarget(sel.X, pass, 0)
+	if msgType == nil {
+		return
+	}
+
+	severity := severityAt(pass, KindReflection, call.Pos())
+	target := "a field"
+	if field != "" {
+		target = "field '" + field + "'"
+	}
+	pass.Report(analysis.Diagnostic{
+		Pos:      call.Pos(),
+		Category: KindReflection,
+		Message: fmt.Sprintf("%sreflective %s of %s in protobuf message %s bypasses nil-safety checks; the value can't be verified",
+			severity.Prefix(), method, target, describeType(pass, msgType)),
+	})
+}
+
+// reflectTarget follows a reflect.Value expression back to the reflect.ValueOf call it
+// was derived from and returns the response message type passed to it, along with the
+// innermost field selected by a constant FieldByName, if any. It follows local variables
+// initialized from such expressions.
+func reflectTarget(expr ast.Expr, pass *analysis.Pass, depth int) (types.Type, string) {
+	if depth > 8 {
+		return nil, ""
+	}
+
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		obj, ok := pass.TypesInfo.Uses[e].(*types.Var)
+		if !ok {
+			return nil, ""
+		}
+		if init := localInitializer(obj, pass); init != nil {
+			return reflectTarget(init, pass, depth+1)
+		}
+
+	case *ast.CallExpr:
+		fn, ok := typeutil.Callee(pass.TypesInfo, e).(*types.Func)
+		if !ok || fn.Pkg() == nil || fn.Pkg().Path() != "reflect" {
+			return nil, ""
+		}
+
+		if method, ok := reflectValueMethod(e, pass); ok {
+			if !reflectNavigators[method] {
+				return nil, ""
+			}
+			msgType, field := reflectTarget(ast.Unparen(e.Fun).(*ast.SelectorExpr).X, pass, depth+1)
+			if msgType != nil && method == "FieldByName" && len(e.Args) == 1 {
+				if tv, ok := pass.TypesInfo.Types[e.Args[0]]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+					if field != "" {
+						field += "."
+					}
+					field += constant.StringVal(tv.Value)
+				}
+			}
+			return msgType, field
+		}
+
+		switch fn.Name() {
+		case "ValueOf":
+			if len(e.Args) == 1 {
+				if t := pass.TypesInfo.TypeOf(e.Args[0]); t != nil && isResponseMessage(t) && isTypeSelected(t) {
+					return t, ""
+				}
+			}
+		case "Indirect":
+			if len(e.Args) == 1 {
+				return reflectTarget(e.Args[0], pass, depth+1)
+			}
+		}
+	}
+	return nil, ""
+}
+
+// reflectValueMethod returns the name of the reflect.Value method a call invokes
+func reflectValueMethod(call *ast.CallExpr, pass *analysis.Pass) (string, bool) {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != "reflect" {
+		return "", false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return "", false
+	}
+	if obj := namedTypeName(recv.Type()); obj == nil || obj.Name() != "Value" {
+		return "", false
+	}
+	return fn.Name(), true
+}
+
+// isProductionFile reports whether pos is in a non-test file of a package that isn't a mock package
+func isProductionFile(pos token.Pos, pass *analysis.Pass) bool {
+	if isMockPackage(pass.Pkg.Path()) {
+		return false
+	}
+	return !strings.HasSuffix(pass.Fset.Position(pos).Filename, "_test.go")
+}
@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// repeatedMessageField checks if a field is a repeated message field, such as
+// repeated User generated as []*User
+func repeatedMessageField(field *types.Var) bool {
+	s, ok := field.Type().Underlying().(*types.Slice)
+	if !ok {
+		return false
+	}
+	ptr, ok := s.Elem().(*types.Pointer)
+	return ok && isProtobufMessageType(ptr)
+}
+
+// checkRepeatedValues checks a slice literal stored into a repeated message field:
+// []*pb.User{nil, u} holds an element the marshaler can't encode, and non-nil elements
+// are validated like message fields. Repeated fields stay optional, so an empty or
+// missing slice is fine. Elements added by append are checked the same way; see
+// checkAppendedValues.
+func checkRepeatedValues(value ast.Expr, field *types.Var, msgType types.Type, pass *analysis.Pass) {
+	if !repeatedMessageField(field) {
+		return
+	}
+	if call := appendCall(value, pass); call != nil {
+		checkAppendedValues(call, field, msgType, pass)
+		return
+	}
+	lit, ok := ast.Unparen(value).(*ast.CompositeLit)
+	if !ok {
+		return
+	}
+	index := 0
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			// Elements may give their index, []*pb.User{2: u}; the next ones follow on
+			elt = kv.Value
+			if tv, ok := pass.TypesInfo.Types[kv.Key]; ok && tv.Value != nil {
+				if i, exact := constant.Int64Val(tv.Value); exact {
+					index = int(i)
+				}
+			}
+		}
+		element := fmt.Sprintf("%s[%d]", field.Name(), index)
+		index++
+		if isNilValue(elt, pass) {
+			reportField(pass, rootField(msgType, element), analysis.Diagnostic{
+				Pos:      elt.Pos(),
+				Category: nilKind(elt, pass),
+				Message: fmt.Sprintf("nil element '%s' of repeated field in protobuf message %s%s",
+					element, describeType(pass, msgType), nilProvenance(elt, pass)),
+			})
+		} else if elemType := pass.TypesInfo.TypeOf(elt); elemType != nil && isProtobufMessageType(elemType) {
+			validateMessageValue(elt, elemType, pass, rootField(msgType, element), isRequestMessage(msgType))
+		}
+	}
+}
+
+// appendCall returns expr when it is a call to the append builtin, or nil
+func appendCall(expr ast.Expr, pass *analysis.Pass) *ast.CallExpr {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	id, ok := ast.Unparen(call.Fun).(*ast.Ident)
+	if !ok || id.Name != "append" {
+		return nil
+	}
+	if _, ok := pass.TypesInfo.Uses[id].(*types.Builtin); !ok {
+		return nil
+	}
+	return call
+}
+
+// checkAppendedValues checks the elements appended to a repeated message field, as in
+// resp.RelatedUsers = append(resp.RelatedUsers, nil). With append(s, more...), a slice
+// literal spread into the call is checked element by element.
+func checkAppendedValues(call *ast.CallExpr, field *types.Var, msgType types.Type, pass *analysis.Pass) {
+	if len(call.Args) < 2 {
+		return
+	}
+	if call.Ellipsis.IsValid() {
+		checkRepeatedValues(call.Args[1], field, msgType, pass)
+		return
+	}
+	for _, arg := range call.Args[1:] {
+		if isNilValue(arg, pass) {
+			reportField(pass, rootField(msgType, field.Name()), analysis.Diagnostic{
+				Pos:      arg.Pos(),
+				Category: nilKind(arg, pass),
+				Message: fmt.Sprintf("nil element appended to repeated field '%s' of protobuf message %s%s",
+					field.Name(), describeType(pass, msgType), nilProvenance(arg, pass)),
+			})
+		} else if elemType := pass.TypesInfo.TypeOf(arg); elemType != nil && isProtobufMessageType(elemType) {
+			validateMessageValue(arg, elemType, pass, rootField(msgType, field.Name()+"[]"), isRequestMessage(msgType))
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"github.com/nickheyer/go_no_nil_linter/internal/protopolicy"
+)
+
+// requiredOption holds the field option set via -required-option
+var requiredOption requiredOptionFlag
+
+func init() {
+	Analyzer.Flags.Var(&requiredOption, "required-option",
+		"a bool field option marking the required fields, by full name (e.g. 'mycompany.required') or extension number (e.g. '50001'); when set, only the message fields marked true are required in messages whose descriptor is embedded in the generated code")
+}
+
+// By default every singular message field without explicit presence is required. Some
+// schema teams say which fields are instead, with an option of their own:
+//
+//	extend google.protobuf.FieldOptions {
+//	  bool required = 50001;
+//	}
+//	message GetUserResponse {
+//	  User user = 1 [(mycompany.required) = true];
+//	  Team team = 2;
+//	}
+//
+// With -required-option=mycompany.required, only the fields marked true are required,
+// read from the file descriptors embedded in the generated code, so the linter enforces
+// exactly what the schema says. The option is found by name in the descriptors of the
+// message's package and the packages it imports, where protoc-gen-go imports the
+// package declaring it; when that package isn't built with protoc-gen-go, give the
+// extension number instead. Messages without an embedded descriptor, such as
+// hand-written ones, keep the default. Fields declared optional stay optional, and
+// -optional-fields still applies.
+
+// requiredOptionFlag is a flag.Value holding a field option by full name or number
+type requiredOptionFlag struct {
+	name   string
+	number int
+}
+
+func (f *requiredOptionFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.name
+}
+
+func (f *requiredOptionFlag) Set(value string) error {
+	value = strings.TrimSpace(strings.Trim(strings.TrimSpace(value), "()"))
+	f.name, f.number = value, 0
+	if value == "" {
+		return nil
+	}
+	if number, err := strconv.Atoi(value); err == nil {
+		if number <= 0 {
+			return fmt.Errorf("invalid extension number %d", number)
+		}
+		f.number = number
+	}
+	return nil
+}
+
+// optionNumber returns the extension number of the option for the messages of pkg: the
+// number given, or that of the FieldOptions extension of that name declared in pkg or a
+// package it imports. It returns 0 if the option isn't found.
+func (f *requiredOptionFlag) optionNumber(pkg *types.Package) int {
+	if f.number != 0 {
+		return f.number
+	}
+	for _, p := range append([]*types.Package{pkg}, pkg.Imports()...) {
+		for _, ext := range descriptorMetadataOf(p).extensions {
+			if ext.FullName == f.name && ext.Extendee == "google.protobuf.FieldOptions" {
+				return ext.Number
+			}
+		}
+	}
+	return 0
+}
+
+// markedRequired reports whether the -required-option marks a field of a message of type
+// msgType true. decided is false when the option doesn't decide: with the flag unset,
+// or for messages without an embedded descriptor.
+func markedRequired(msgType types.Type, structType *types.Struct, name string) (marked, decided bool) {
+	if requiredOption.name == "" {
+		return false, false
+	}
+	obj := namedTypeName(msgType)
+	if obj == nil || obj.Pkg() == nil {
+		return false, false
+	}
+	meta := descriptorMetadataOf(obj.Pkg())
+	message := strings.ReplaceAll(obj.Name(), "_", ".")
+	if !meta.described[message] {
+		return false, false
+	}
+	number := requiredOption.optionNumber(obj.Pkg())
+	if number == 0 {
+		// Nothing in reach declares the option, so no field is marked
+		return false, true
+	}
+	for i := 0; i < structType.NumFields(); i++ {
+		if messageSchema.FieldName(structType.Field(i)) != name {
+			continue
+		}
+		tag, ok := protopolicy.ParseTag(structType.Tag(i))
+		if !ok {
+			return false, true
+		}
+		walkDescriptor(meta.options[message][tag.Number], func(field, varint uint64, _ string) {
+			if field == uint64(number) {
+				marked = varint != 0
+			}
+		})
+		return marked, true
+	}
+	return false, true
+}
+
+// withRequiredOption returns fields without those -required-option leaves unmarked in a
+// message of type msgType
+func withRequiredOption(msgType types.Type, structType *types.Struct, fields []*types.Var) []*types.Var {
+	if requiredOption.name == "" {
+		return fields
+	}
+	kept := fields[:0:0]
+	for _, field := range fields {
+		if marked, decided := markedRequired(msgType, structType, field.Name()); marked || !decided {
+			kept = append(kept, field)
+		}
+	}
+	return kept
+}
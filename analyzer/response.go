@@ -1,9 +1,6 @@
 package analyzer
 
-import (
-	"go/types"
-	"strings"
-)
+import "go/types"
 
 // isResponseMessage checks if a type is a protobuf response message
 // Response messages are types that are returned from service endpoints
@@ -20,7 +17,7 @@ func isResponseMessage(t types.Type) bool {
 	}
 
 	// Must be a protobuf message type
-	if !hasProtoMessageMethod(named) {
+	if !isMessageNamedType(named) {
 		return false
 	}
 
@@ -30,28 +27,44 @@ func isResponseMessage(t types.Type) bool {
 		return false
 	}
 
-	typeName := obj.Name()
-
-	// Check if it matches response naming convention
-	// Response messages typically end with "Response"
-	if strings.HasSuffix(typeName, "Response") {
-		return true
+	// Check if a service returns it under -service-roots; see roots.go
+	if has, decided := serviceRole(obj, responseRole); decided {
+		return has
 	}
 
-	// Could also check for other patterns like "*Reply", "*Result", etc.
-	if strings.HasSuffix(typeName, "Reply") {
-		return true
-	}
+	// Check if it matches the response naming convention
+	return isResponseName(obj.Name())
+}
 
-	if strings.HasSuffix(typeName, "Result") {
-		return true
+// isRequestMessage checks if a type is a protobuf request message, named *Request, or
+// taken by a service under -service-roots. Requests are only checked with
+// -check-all-messages.
+func isRequestMessage(t types.Type) bool {
+	obj := namedTypeName(t)
+	if obj == nil || !isProtobufMessageType(t) {
+		return false
 	}
-
-	return false
+	if has, decided := serviceRole(obj, requestRole); decided {
+		return has
+	}
+	return messageSchema.IsRequestName(obj.Name())
 }
 
 // shouldCheckType determines if we should check this type for nil fields
-// We only check response messages and their submessages
+// We only check response messages and their submessages, unless -check-all-messages is set,
+// and of those only the ones -include-types and -exclude-types select; see typefilter.go
 func shouldCheckType(t types.Type) bool {
+	if !isTypeSelected(t) {
+		return false
+	}
+	if checkAllMessages {
+		return isProtobufMessageType(t)
+	}
 	return isResponseMessage(t)
-}
\ No newline at end of file
+}
+
+// isResponseName checks if a message type name follows a response naming convention,
+// for protobuf -response-suffixes and -response-pattern
+func isResponseName(typeName string) bool {
+	return messageSchema.IsResponseName(typeName)
+}
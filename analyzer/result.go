@@ -0,0 +1,157 @@
+package analyzer
+
+import (
+	"context"
+	"go/types"
+	"log/slog"
+	"reflect"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Result is the classification computed by the analyzer for a package.
+// Other analyzers can list Analyzer in their Requires and read it from
+// pass.ResultOf[analyzer.Analyzer].(*analyzer.Result) instead of
+// re-deriving which messages are responses and which fields are required.
+type Result struct {
+	// ResponseTypes holds every protobuf response message type seen in the package
+	ResponseTypes map[*types.TypeName]bool
+
+	// RequiredFields maps every protobuf message type seen in the package to its
+	// non-optional message fields, in struct declaration order
+	RequiredFields map[*types.TypeName][]*types.Var
+
+	// Findings holds every diagnostic reported for the package together with the
+	// syntax it was reported on, in report order
+	Findings []Finding
+
+	// Stats holds counters about how the package was analyzed
+	Stats Stats
+}
+
+// Stats holds counters about how a package was analyzed
+type Stats struct {
+	// BudgetExceeded is the number of functions whose deep analysis was cut short by
+	// -analysis-budget
+	BudgetExceeded int
+
+	// ShallowFunctions names those functions, as F or *T.M, in the order they ran out.
+	// Their findings come from the shallow checks only, e.g. a response literal checked
+	// where it is built rather than at the returns handing it back.
+	ShallowFunctions []string
+
+	// Coverage counts the package's response construction sites by how far they were
+	// verified; see coverage.go
+	Coverage Coverage
+}
+
+// IsResponse reports whether t (or the type it points to) is a response message
+func (r *Result) IsResponse(t types.Type) bool {
+	obj := namedTypeName(t)
+	return obj != nil && r.ResponseTypes[obj]
+}
+
+// Required returns the non-optional message fields of t (or the type it points to)
+func (r *Result) Required(t types.Type) []*types.Var {
+	obj := namedTypeName(t)
+	if obj == nil {
+		return nil
+	}
+	return r.RequiredFields[obj]
+}
+
+// Messages returns every classified message type, sorted by qualified name
+func (r *Result) Messages() []*types.TypeName {
+	names := make([]*types.TypeName, 0, len(r.RequiredFields))
+	for obj := range r.RequiredFields {
+		names = append(names, obj)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return qualifiedName(names[i]) < qualifiedName(names[j])
+	})
+	return names
+}
+
+var resultType = reflect.TypeOf((*Result)(nil))
+
+// newResult returns an empty Result
+func newResult() *Result {
+	return &Result{
+		ResponseTypes:  make(map[*types.TypeName]bool),
+		RequiredFields: make(map[*types.TypeName][]*types.Var),
+	}
+}
+
+// classifyPackage builds the Result for the types referenced by the package
+func classifyPackage(pass *analysis.Pass) *Result {
+	result := newResult()
+
+	add := func(t types.Type) {
+		obj := namedTypeName(t)
+		if obj == nil {
+			return
+		}
+		if _, seen := result.RequiredFields[obj]; seen {
+			return
+		}
+		if !isProtobufMessageType(t) {
+			return
+		}
+		structType := getStructType(t)
+		if structType == nil {
+			return
+		}
+		required := schemaFields(structType, t)
+		result.RequiredFields[obj] = required
+		if isResponseMessage(t) {
+			result.ResponseTypes[obj] = true
+		}
+
+		if log().Enabled(context.Background(), slog.LevelDebug) {
+			names := make([]string, len(required))
+			for i, field := range required {
+				names[i] = field.Name()
+			}
+			log().Debug("classified message", "type", qualifiedName(obj),
+				"response", result.ResponseTypes[obj], "required", names)
+		}
+	}
+
+	// Types declared in the package itself
+	scope := pass.Pkg.Scope()
+	for _, name := range scope.Names() {
+		if tn, ok := scope.Lookup(name).(*types.TypeName); ok {
+			add(tn.Type())
+		}
+	}
+
+	// Types used by expressions in the package, including imported messages
+	for _, tv := range pass.TypesInfo.Types {
+		if tv.Type != nil {
+			add(tv.Type)
+		}
+	}
+
+	return result
+}
+
+// namedTypeName returns the type name of a named type or pointer to a named type
+func namedTypeName(t types.Type) *types.TypeName {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return nil
+	}
+	return named.Obj()
+}
+
+// qualifiedName returns the package-qualified name of a type name
+func qualifiedName(obj *types.TypeName) string {
+	if obj.Pkg() == nil {
+		return obj.Name()
+	}
+	return obj.Pkg().Path() + "." + obj.Name()
+}
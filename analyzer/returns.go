@@ -0,0 +1,353 @@
+package analyzer
+
+import (
+	"encoding/gob"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// returnFact summarizes a function that returns a protobuf message: the required fields
+// every return sets, and those no return sets, e.g. a createUser() helper that never sets
+// Address. Call sites that put the result into a message are reported as if the unset
+// fields were left out there, and values from functions with a fact count as verified.
+// Facts travel with the package, so constructors from dependencies are validated
+// without re-analyzing their source, including packages -include-packages skips.
+type returnFact struct {
+	// Initialized are the Go names of the required fields every return sets, sorted
+	Initialized []string
+
+	// Unset are the Go names of the required fields none of the returns set, sorted
+	Unset []string
+
+	// Checked is set when the function's own message literals were checked, so its
+	// unset fields have been reported there already
+	Checked bool
+}
+
+func (*returnFact) AFact() {}
+
+func (f *returnFact) String() string {
+	s := "returns(initialized: " + strings.Join(f.Initialized, ", ") + "; unset: " + strings.Join(f.Unset, ", ")
+	if f.Checked {
+		s += "; checked"
+	}
+	return s + ")"
+}
+
+// Drivers that serialize facts between packages (unitchecker under go vet) register
+// the analyzer's FactTypes with gob; registering here as well covers drivers built on
+// the library that don't
+func init() {
+	gob.Register(new(returnFact))
+}
+
+// returnSummary is the analysis of one function's message result. known is false when
+// some return hands back a value the analysis can't follow, such as a parameter or the
+// result of an unanalyzed call.
+type returnSummary struct {
+	initialized []string
+	unset       []string
+	known       bool
+}
+
+// exportReturnFacts analyzes the functions of the package that return a protobuf message.
+// Every exported function gets a returnFact, so other packages can validate its results;
+// unexported ones only when some required field is never set and their literals weren't
+// checked here. Functions calling each other are summarized callees first. checked is
+// false when the package itself is skipped; the functions of its skipped files, those
+// generated, excluded or tests under -skip-tests and -tests-strict, never are.
+func exportReturnFacts(pass *analysis.Pass, checked bool, skipped map[*token.File]bool) {
+	decls := make(map[*types.Func]*ast.FuncDecl)
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			if obj, ok := pass.TypesInfo.Defs[fn.Name].(*types.Func); ok && messageResultIndex(obj) >= 0 {
+				decls[obj] = fn
+			}
+		}
+	}
+
+	summaries := make(map[*types.Func]*returnSummary)
+	var summarize func(obj *types.Func) *returnSummary
+	summarize = func(obj *types.Func) *returnSummary {
+		if s, ok := summaries[obj]; ok {
+			if s == nil {
+				// Recursive call; its returns are covered by the outer analysis
+				return &returnSummary{}
+			}
+			return s
+		}
+		summaries[obj] = nil
+		// Over budget, the function's results are unknown like those of an unanalyzed call
+		s := &returnSummary{}
+		withinBudget(decls[obj].Pos(), pass, func() {
+			s = summarizeReturns(decls[obj], obj, func(callee *types.Func) *returnSummary {
+				if decls[callee] != nil {
+					return summarize(callee)
+				}
+				var fact returnFact
+				if pass.ImportObjectFact(callee, &fact) {
+					return &returnSummary{initialized: fact.Initialized, unset: fact.Unset, known: true}
+				}
+				return &returnSummary{}
+			}, pass)
+		})
+		summaries[obj] = s
+		return s
+	}
+
+	// Service methods have their results checked whatever the message is called
+	services := serviceMethods(pass)
+
+	// In source order, so facts and logs come out the same on every run
+	objs := make([]*types.Func, 0, len(decls))
+	for obj := range decls {
+		objs = append(objs, obj)
+	}
+	sort.Slice(objs, func(i, j int) bool { return objs[i].Pos() < objs[j].Pos() })
+
+	// Builders are checked where their callers return the response; see builders.go
+	findBuilders(objs, summarize, pass)
+
+	helpers := make(map[*types.Func]bool)
+	for _, obj := range objs {
+		s := summarize(obj)
+		if !s.known {
+			continue
+		}
+		msgType := obj.Type().(*types.Signature).Results().At(messageResultIndex(obj)).Type()
+		fact := &returnFact{Initialized: s.initialized, Unset: s.unset, Checked: checked && !skipped[pass.Fset.File(decls[obj].Pos())] && (shouldCheckType(msgType) || services[obj]) && builderSummary(obj, pass) == nil}
+		// Under -grpc-handlers-only, checked helpers are reported where handlers use them
+		if obj.Exported() || (len(fact.Unset) > 0 && (!fact.Checked || grpcHandlersOnly)) {
+			pass.ExportObjectFact(obj, fact)
+		} else {
+			helpers[obj] = true
+		}
+	}
+	checkedHelpers.Store(pass.Pkg, helpers)
+}
+
+// checkedHelpers maps each package being analyzed to the unexported functions it
+// summarizes without exporting a fact, as nothing is left for their callers to report:
+// every return sets each required field, or the fields left unset are reported at the
+// returns. Values from them are verified all the same; see opaqueSource.
+var checkedHelpers sync.Map // *types.Package -> map[*types.Func]bool
+
+// isCheckedHelper checks if fn is one of the package's checkedHelpers
+func isCheckedHelper(fn *types.Func, pass *analysis.Pass) bool {
+	helpers, ok := checkedHelpers.Load(pass.Pkg)
+	return ok && helpers.(map[*types.Func]bool)[fn]
+}
+
+// messageResultIndex returns the index of the first result of fn that is a protobuf
+// message, or -1
+func messageResultIndex(fn *types.Func) int {
+	results := fn.Type().(*types.Signature).Results()
+	for i := 0; i < results.Len(); i++ {
+		if isProtobufMessageType(results.At(i).Type()) {
+			return i
+		}
+	}
+	return -1
+}
+
+// summarizeReturns collects the required fields set by every return of decl and those
+// set by none. Returns of nil are skipped; calls are summarized through callee.
+func summarizeReturns(decl *ast.FuncDecl, obj *types.Func, callee func(*types.Func) *returnSummary, pass *analysis.Pass) *returnSummary {
+	index := messageResultIndex(obj)
+	msgType := obj.Type().(*types.Signature).Results().At(index).Type()
+	structType := getStructType(msgType)
+	if structType == nil {
+		return &returnSummary{}
+	}
+	var required []string
+	for _, field := range requiredFields(structType, msgType, isRequestMessage(msgType)) {
+		required = append(required, field.Name())
+	}
+	tracked := collectTrackedLiterals(decl.Body, isProtobufMessageType, pass)
+
+	// never holds the fields no return sets so far, sometimes those some return leaves unset
+	var never map[string]bool
+	sometimes := make(map[string]bool)
+	known := true
+	intersect := func(set map[string]bool) {
+		unset := make(map[string]bool)
+		for _, name := range required {
+			if set[name] {
+				continue
+			}
+			sometimes[name] = true
+			if never == nil || never[name] {
+				unset[name] = true
+			}
+		}
+		never = unset
+	}
+
+	var stack []ast.Node
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		if _, ok := n.(*ast.FuncLit); ok || !known {
+			return false
+		}
+		stack = append(stack, n)
+
+		ret, ok := n.(*ast.ReturnStmt)
+		if !ok {
+			return true
+		}
+		if len(ret.Results) <= index {
+			// Named results or a forwarded multi-value call
+			known = false
+			return false
+		}
+		result := ast.Unparen(ret.Results[index])
+		if tv, ok := pass.TypesInfo.Types[result]; ok && tv.IsNil() {
+			return true
+		}
+
+		switch r := result.(type) {
+		case *ast.Ident:
+			t := tracked[pass.TypesInfo.ObjectOf(r)]
+			if t == nil || t.passedToCall {
+				known = false
+				return false
+			}
+			set, _ := assignedFieldsOnPath(stack, t.obj, pass)
+			for name := range t.fields(pass) {
+				set[name] = true
+			}
+			intersect(set)
+
+		case *ast.CallExpr:
+			// Builders of the opaque API; see opaque.go
+			if lit, _ := builtLiteral(r, isProtobufMessageType, pass); lit != nil {
+				intersect(literalFields(lit, pass))
+				return true
+			}
+			fn := typeutil.StaticCallee(pass.TypesInfo, r)
+			if fn == nil {
+				known = false
+				return false
+			}
+			s := callee(fn)
+			if !s.known {
+				known = false
+				return false
+			}
+			// Fields the callee sets on some paths only count as unset
+			set := make(map[string]bool)
+			for _, name := range s.initialized {
+				set[name] = true
+			}
+			intersect(set)
+
+		default:
+			if lit := messageLiteral(result, isProtobufMessageType, pass); lit != nil {
+				intersect(literalFields(lit, pass))
+				return true
+			}
+			known = false
+			return false
+		}
+		return true
+	})
+
+	if !known || never == nil {
+		return &returnSummary{}
+	}
+	summary := &returnSummary{initialized: []string{}, unset: []string{}, known: true}
+	for _, name := range required {
+		if !sometimes[name] {
+			summary.initialized = append(summary.initialized, name)
+		}
+		if never[name] {
+			summary.unset = append(summary.unset, name)
+		}
+	}
+	sort.Strings(summary.initialized)
+	sort.Strings(summary.unset)
+	return summary
+}
+
+// fields returns the fields a tracked response starts out with, set by its literal or
+// by the template it is a clone of
+func (t *trackedResponse) fields(pass *analysis.Pass) map[string]bool {
+	set := literalFields(t.lit, pass)
+	for name := range literalFields(t.template, pass) {
+		set[name] = true
+	}
+	return set
+}
+
+// literalFields returns the fields a message literal sets to a non-nil value; none for
+// a nil literal, as tracked for new(T)
+func literalFields(lit *ast.CompositeLit, pass *analysis.Pass) map[string]bool {
+	set := make(map[string]bool)
+	if lit == nil {
+		return set
+	}
+	litType := pass.TypesInfo.TypeOf(lit)
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if id, ok := kv.Key.(*ast.Ident); ok && !isNilValue(kv.Value, pass) {
+				// Generated code sets hidden fields, which go by their accessors' name
+				if field := getFieldFromType(litType, id.Name); field != nil && messageSchema.FieldName(field) != "" {
+					set[messageSchema.FieldName(field)] = true
+				} else {
+					set[id.Name] = true
+				}
+			}
+		}
+	}
+	return set
+}
+
+// checkCallResult reports the required fields a called helper leaves unset in the
+// message it returns, at reportPos (the call itself when it is token.NoPos)
+func checkCallResult(call *ast.CallExpr, pass *analysis.Pass, fieldContext fieldRef, reportPos token.Pos) {
+	fn := typeutil.StaticCallee(pass.TypesInfo, call)
+	if fn == nil {
+		return
+	}
+	var fact returnFact
+	if !pass.ImportObjectFact(fn, &fact) || (fact.Checked && !grpcHandlersOnly) {
+		return
+	}
+	if reportPos == token.NoPos {
+		reportPos = call.Pos()
+	}
+	msgType := fn.Type().(*types.Signature).Results().At(messageResultIndex(fn)).Type()
+	name := calleeName(fn, pass)
+	for _, field := range fact.Unset {
+		reportField(pass, fieldContext.child(field), analysis.Diagnostic{
+			Pos:      reportPos,
+			Category: KindNestedNil,
+			Message: fmt.Sprintf("non-optional message field '%s.%s' not initialized in protobuf message %s returned by %s()%s",
+				fieldContext, field, describeType(pass, msgType), name, gatewayNote(msgType, field)),
+		})
+	}
+}
+
+// calleeName names a called function in findings, qualified with its package's name
+// when it is declared in another package
+func calleeName(fn *types.Func, pass *analysis.Pass) string {
+	if fn.Pkg() != pass.Pkg {
+		return fn.Pkg().Name() + "." + fn.Name()
+	}
+	return fn.Name()
+}
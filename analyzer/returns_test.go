@@ -0,0 +1,37 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Facts cross package boundaries gob-encoded as analysis.Fact interface values, and
+// go vet caches them by content, so the encoding has to round-trip and be stable
+func TestReturnFactGob(t *testing.T) {
+	fact := &returnFact{Initialized: []string{"Address"}, Unset: []string{"CreatedAt"}}
+
+	encode := func() []byte {
+		var buf bytes.Buffer
+		var value analysis.Fact = fact
+		if err := gob.NewEncoder(&buf).Encode(&value); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	data := encode()
+	if !bytes.Equal(data, encode()) {
+		t.Error("Encoding the same fact twice gave different bytes")
+	}
+
+	var decoded analysis.Fact
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, fact) {
+		t.Errorf("Decoded %v, want %v", decoded, fact)
+	}
+}
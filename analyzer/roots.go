@@ -0,0 +1,177 @@
+package analyzer
+
+import (
+	"go/types"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// serviceRoots classifies messages by the services using them, set via -service-roots
+var serviceRoots bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&serviceRoots, "service-roots", false,
+		"classify messages as responses and requests by the generated service interfaces returning and taking them rather than by name; messages of packages no service uses are still classified by name")
+}
+
+// Response and request messages are told apart by name: -response-suffixes and
+// -response-pattern. Services don't always follow the convention, though, and an RPC
+// returning a Book or an OrderSummary hands it to clients like a GetBookResponse. With
+// -service-roots the messages are classified by the services using them instead: a
+// message is a response when a method of a service interface returns it, and a request
+// when one takes it. The interfaces are the server interfaces of service.go, and the
+// gRPC client interfaces generated with them, UserServiceClient with its
+// NewUserServiceClient; streaming methods count the messages their streams send and
+// receive.
+//
+// Services are looked up in the package declaring the message, where protoc-gen-go-grpc
+// writes them, and in the analyzed package and its imports, which holds Connect's and
+// Twirp's and those named by -service-interfaces. Messages of packages no service uses,
+// such as a package of shared messages, are still classified by name.
+
+// messageRole is what a service does with a message
+type messageRole uint8
+
+const (
+	requestRole messageRole = 1 << iota
+	responseRole
+)
+
+// rootRole keys a message type name and the role a service gives it
+type rootRole struct {
+	obj  *types.TypeName
+	role messageRole
+}
+
+var (
+	// rootMessages holds the rootRole of each message a service uses. Entries aren't
+	// dropped: type names are shared by the packages importing them.
+	rootMessages sync.Map
+
+	// rootPackages holds the packages declaring a message some service uses
+	rootPackages sync.Map
+
+	// scannedServices holds a *sync.Once per *types.Package whose services were recorded
+	scannedServices sync.Map
+)
+
+// loadServiceRoots records the messages used by the services the package sees
+func loadServiceRoots(pass *analysis.Pass) {
+	if !serviceRoots {
+		return
+	}
+	for _, pkg := range append([]*types.Package{pass.Pkg}, pass.Pkg.Imports()...) {
+		addServiceRoots(pkg)
+	}
+}
+
+// addServiceRoots records the messages used by the services declared in pkg, once
+func addServiceRoots(pkg *types.Package) {
+	once, _ := scannedServices.LoadOrStore(pkg, new(sync.Once))
+	once.(*sync.Once).Do(func() {
+		for _, obj := range packageServices(pkg) {
+			addServiceMessages(obj, false)
+		}
+		for _, obj := range packageClients(pkg) {
+			addServiceMessages(obj, true)
+		}
+	})
+}
+
+// addServiceMessages records the messages the methods of a service interface take and
+// return. A client sends the requests its server receives, so the streams of its
+// methods send requests and receive responses.
+func addServiceMessages(obj *types.TypeName, client bool) {
+	iface := obj.Type().Underlying().(*types.Interface)
+	for i := 0; i < iface.NumMethods(); i++ {
+		sig := iface.Method(i).Type().(*types.Signature)
+		for j := 0; j < sig.Params().Len(); j++ {
+			param := sig.Params().At(j).Type()
+			if msg := connectMessage(param, "Request"); msg != nil {
+				param = msg
+			}
+			addRootMessage(param, requestRole)
+			addStreamMessages(sig.Params().At(j).Type(), client)
+		}
+		for j := 0; j < sig.Results().Len(); j++ {
+			addRootMessage(handlerMessage(sig.Results().At(j).Type()), responseRole)
+			addStreamMessages(sig.Results().At(j).Type(), client)
+		}
+	}
+}
+
+// addStreamMessages records the messages a stream of a service method carries: those
+// its Send method takes, and those its Recv method returns
+func addStreamMessages(stream types.Type, client bool) {
+	sent, received := responseRole, requestRole
+	if client {
+		sent, received = requestRole, responseRole
+	}
+	if sig := streamMethod(stream, "Send"); sig != nil && sig.Params().Len() == 1 {
+		addRootMessage(sig.Params().At(0).Type(), sent)
+	}
+	if sig := streamMethod(stream, "Recv"); sig != nil && sig.Results().Len() > 0 {
+		addRootMessage(sig.Results().At(0).Type(), received)
+	}
+}
+
+// streamMethod returns the signature of the method name of a stream type, or nil
+func streamMethod(stream types.Type, name string) *types.Signature {
+	obj, _, _ := types.LookupFieldOrMethod(stream, true, nil, name)
+	if fn, ok := obj.(*types.Func); ok {
+		return fn.Type().(*types.Signature)
+	}
+	return nil
+}
+
+// addRootMessage records that a service gives the message of type t a role
+func addRootMessage(t types.Type, role messageRole) {
+	if !isProtobufMessageType(t) {
+		return
+	}
+	obj := namedTypeName(t)
+	if obj == nil || obj.Pkg() == nil {
+		return
+	}
+	rootMessages.Store(rootRole{obj, role}, true)
+	rootPackages.Store(obj.Pkg(), true)
+}
+
+// packageClients returns the gRPC client interfaces declared in pkg: UserServiceClient,
+// with its NewUserServiceClient constructor
+func packageClients(pkg *types.Package) []*types.TypeName {
+	var clients []*types.TypeName
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		obj, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || !types.IsInterface(obj.Type()) || !strings.HasSuffix(name, "Client") {
+			continue
+		}
+		fn, ok := scope.Lookup("New" + name).(*types.Func)
+		if !ok {
+			continue
+		}
+		results := fn.Type().(*types.Signature).Results()
+		if results.Len() > 0 && types.Identical(results.At(0).Type(), obj.Type()) {
+			clients = append(clients, obj)
+		}
+	}
+	return clients
+}
+
+// serviceRole checks if a service gives the message obj the role under -service-roots.
+// decided is false when the name decides instead: with the mode off, or for the
+// messages of a package no service uses.
+func serviceRole(obj *types.TypeName, role messageRole) (has, decided bool) {
+	if !serviceRoots || obj.Pkg() == nil {
+		return false, false
+	}
+	addServiceRoots(obj.Pkg())
+	if _, ok := rootPackages.Load(obj.Pkg()); !ok {
+		return false, false
+	}
+	_, has = rootMessages.Load(rootRole{obj, role})
+	return has, true
+}
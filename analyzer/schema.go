@@ -0,0 +1,163 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+
+	"github.com/nickheyer/go_no_nil_linter/internal/avropolicy"
+	"github.com/nickheyer/go_no_nil_linter/internal/protopolicy"
+	"github.com/nickheyer/go_no_nil_linter/internal/thriftpolicy"
+)
+
+// schema is what the engine needs to know about the generated types of a schema system.
+// The engine tracks nil values and initialized fields through named types and struct
+// fields; the schema says which types are messages, which fields must hold one, and
+// where checking starts. Everything the engine decides about a type goes through
+// these methods, so another schema system, such as Thrift or Avro generated code, is
+// supported by implementing them.
+type schema interface {
+	// IsMessage checks if a named type is a message
+	IsMessage(t *types.Named) bool
+
+	// IsMessageField checks if a struct field holds a single message, which must not
+	// be nil unless the field is optional
+	IsMessageField(field *types.Var) bool
+
+	// IsOptionalField checks if a field of a message may be left nil
+	IsOptionalField(structType *types.Struct, field *types.Var) bool
+
+	// FieldName returns the name code sets a field by, which accessors may stand for
+	// when the generated code hides the field, or "" when code can't set it
+	FieldName(field *types.Var) string
+
+	// BuilderMessage returns the message type a builder type builds, or nil when t
+	// isn't one; a builder literal has the message's fields
+	BuilderMessage(t types.Type) types.Type
+
+	// IsWellKnownType checks if a type is one of the schema's standard messages,
+	// which are built by their own constructors rather than literals
+	IsWellKnownType(t types.Type) bool
+
+	// IsResponseName and IsRequestName classify messages by type name: responses are
+	// checked, and requests are only with -check-all-messages
+	IsResponseName(typeName string) bool
+	IsRequestName(typeName string) bool
+}
+
+// protobuf is the protobuf schema, with its settings bound to the analyzer's flags
+var protobuf = &protopolicy.Policy{ResponseSuffixes: "Response,Reply,Result"}
+
+// messageSchema is the schema the engine checks messages of
+var messageSchema schema = protobuf
+
+// experimentalSchemas are the schemas -experimental-schemas can add to protobuf's
+var experimentalSchemas = map[string]schema{
+	"thrift": &thriftpolicy.Policy{},
+	"avro":   &avropolicy.Policy{},
+}
+
+// schemas checks the messages of several schema systems, as in a codebase with mixed
+// RPC stacks. Each type and field is classified by the schema it belongs to, and names
+// by the first schema, so -response-suffixes and -response-pattern apply to all.
+type schemas []schema
+
+func (s schemas) IsMessage(t *types.Named) bool {
+	for _, schema := range s {
+		if schema.IsMessage(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s schemas) IsMessageField(field *types.Var) bool {
+	return s.fieldSchema(field) != nil
+}
+
+func (s schemas) IsOptionalField(structType *types.Struct, field *types.Var) bool {
+	if schema := s.fieldSchema(field); schema != nil {
+		return schema.IsOptionalField(structType, field)
+	}
+	return s[0].IsOptionalField(structType, field)
+}
+
+// fieldSchema returns the schema whose message a field holds, or nil
+func (s schemas) fieldSchema(field *types.Var) schema {
+	for _, schema := range s {
+		if schema.IsMessageField(field) {
+			return schema
+		}
+	}
+	return nil
+}
+
+func (s schemas) FieldName(field *types.Var) string {
+	for _, schema := range s {
+		if name := schema.FieldName(field); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+func (s schemas) BuilderMessage(t types.Type) types.Type {
+	for _, schema := range s {
+		if built := schema.BuilderMessage(t); built != nil {
+			return built
+		}
+	}
+	return nil
+}
+
+func (s schemas) IsWellKnownType(t types.Type) bool {
+	for _, schema := range s {
+		if schema.IsWellKnownType(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s schemas) IsResponseName(typeName string) bool {
+	return s[0].IsResponseName(typeName)
+}
+
+func (s schemas) IsRequestName(typeName string) bool {
+	return s[0].IsRequestName(typeName)
+}
+
+// schemasFlag is the -experimental-schemas flag.Value, setting messageSchema to
+// protobuf's schema and those named
+type schemasFlag struct {
+	names string
+}
+
+func (f *schemasFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.names
+}
+
+func (f *schemasFlag) Set(value string) error {
+	checked := schemas{protobuf}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		schema, ok := experimentalSchemas[name]
+		if !ok {
+			return fmt.Errorf("unknown schema %q: want thrift or avro", name)
+		}
+		checked = append(checked, schema)
+	}
+	f.names = value
+	if len(checked) == 1 {
+		messageSchema = protobuf
+	} else {
+		messageSchema = checked
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// checkServiceImplementations checks the handlers of gRPC services: the generated
+// server interfaces the package sees, and those named by -service-interfaces. A type in
+// the package that implements one of the interfaces has the messages returned by its
+// interface methods checked as responses, whatever they are called: GetBook(ctx, req)
+// (*pb.Book, error) hands its *pb.Book to clients just like a GetBookResponse. The
+// methods are found through the types, so handlers that are only ever registered
+// through the interface are covered too. Messages that are checked anyway are left to
+// the regular checks, apart from those handlers return from helpers; see handlers.go.
+func checkServiceImplementations(pass *analysis.Pass) {
+	methods := serviceMethods(pass)
+	if len(methods) == 0 {
+		return
+	}
+
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			obj, ok := pass.TypesInfo.Defs[fn.Name].(*types.Func)
+			if !ok || !methods[obj] {
+				continue
+			}
+			log().Debug("checking service method results as responses", "method", obj.FullName())
+			checkServiceMethod(fn, obj, pass)
+			checkHandlerResults(fn, obj, pass)
+		}
+	}
+}
+
+// serviceMethods returns the methods declared in the package that implement a method of
+// one of the service interfaces, or nil
+func serviceMethods(pass *analysis.Pass) map[*types.Func]bool {
+	ifaces := serviceInterfaces(pass)
+	if len(ifaces) == 0 {
+		return nil
+	}
+	return implementingMethods(pass, ifaces)
+}
+
+// serviceInterfaces returns the service interfaces declared in the package or in the
+// packages it imports: those generated for gRPC, Connect and Twirp, and those named by
+// -service-interfaces. Names match either bare (UserServiceServer) or qualified with
+// the import path (example.com/gen/userpb.UserServiceServer).
+func serviceInterfaces(pass *analysis.Pass) []*types.Interface {
+	var ifaces []*types.Interface
+	seen := make(map[*types.TypeName]bool)
+	for _, pkg := range append([]*types.Package{pass.Pkg}, pass.Pkg.Imports()...) {
+		for _, obj := range packageServices(pkg) {
+			if !seen[obj] {
+				seen[obj] = true
+				ifaces = append(ifaces, obj.Type().Underlying().(*types.Interface))
+			}
+		}
+	}
+	return ifaces
+}
+
+// packageServices returns the service interfaces declared in pkg: the generated ones,
+// and those named by -service-interfaces
+func packageServices(pkg *types.Package) []*types.TypeName {
+	var services []*types.TypeName
+	add := func(obj *types.TypeName) {
+		if types.IsInterface(obj.Type()) {
+			services = append(services, obj)
+		}
+	}
+	for _, name := range pkg.Scope().Names() {
+		if obj, ok := pkg.Scope().Lookup(name).(*types.TypeName); ok && generatedServer(obj) {
+			add(obj)
+		}
+	}
+	for _, name := range splitPatterns(serviceInterfaceNames) {
+		if dot := strings.LastIndex(name, "."); dot >= 0 {
+			if name[:dot] != pkg.Path() {
+				continue
+			}
+			name = name[dot+1:]
+		}
+		if obj, ok := pkg.Scope().Lookup(name).(*types.TypeName); ok && !generatedServer(obj) {
+			add(obj)
+		}
+	}
+	return services
+}
+
+// generatedServer checks if obj is a service interface written by a code generator:
+//   - protoc-gen-go-grpc's UserServiceServer, whose package also declares
+//     RegisterUserServiceServer or UnimplementedUserServiceServer
+//   - protoc-gen-connect-go's UserServiceHandler, served by NewUserServiceHandler
+//   - protoc-gen-twirp's UserService, served by NewUserServiceServer
+func generatedServer(obj *types.TypeName) bool {
+	if !types.IsInterface(obj.Type()) {
+		return false
+	}
+	name := obj.Name()
+	scope := obj.Pkg().Scope()
+	switch {
+	case strings.HasSuffix(name, "Server"):
+		if _, ok := scope.Lookup("Register" + name).(*types.Func); ok {
+			return true
+		}
+		if _, ok := scope.Lookup("Unimplemented" + name).(*types.TypeName); ok {
+			return true
+		}
+	case strings.HasSuffix(name, "Handler"):
+		if servedBy(scope, "New"+name, obj) {
+			return true
+		}
+	}
+	return servedBy(scope, "New"+name+"Server", obj)
+}
+
+// servedBy checks if scope declares a function named name taking an implementation of
+// the interface obj first, as the constructors of Connect and Twirp servers do
+func servedBy(scope *types.Scope, name string, obj *types.TypeName) bool {
+	fn, ok := scope.Lookup(name).(*types.Func)
+	if !ok {
+		return false
+	}
+	params := fn.Type().(*types.Signature).Params()
+	return params.Len() > 0 && types.Identical(params.At(0).Type(), obj.Type())
+}
+
+// implementingMethods returns the methods declared in the package through which its
+// types implement a method of one of ifaces. Methods promoted from embedded types
+// declared elsewhere, such as UnimplementedUserServiceServer, are not included.
+func implementingMethods(pass *analysis.Pass, ifaces []*types.Interface) map[*types.Func]bool {
+	methods := make(map[*types.Func]bool)
+	scope := pass.Pkg.Scope()
+	for _, name := range scope.Names() {
+		obj, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || obj.IsAlias() || types.IsInterface(obj.Type()) {
+			continue
+		}
+		ptr := types.NewPointer(obj.Type())
+		for _, iface := range ifaces {
+			if !types.Implements(ptr, iface) {
+				continue
+			}
+			for i := 0; i < iface.NumMethods(); i++ {
+				m := iface.Method(i)
+				sel, _, _ := types.LookupFieldOrMethod(ptr, false, m.Pkg(), m.Name())
+				if fn, ok := sel.(*types.Func); ok && fn.Pkg() == pass.Pkg {
+					methods[fn] = true
+				}
+			}
+		}
+	}
+	return methods
+}
+
+// checkServiceMethod checks the messages a service method returns that aren't checked
+// otherwise: returned literals, and literals bound to variables that are returned,
+// which are evaluated at their returns like responses
+func checkServiceMethod(fn *ast.FuncDecl, obj *types.Func, pass *analysis.Pass) {
+	var results []*types.TypeName
+	sig := obj.Type().(*types.Signature)
+	for i := 0; i < sig.Results().Len(); i++ {
+		t := handlerMessage(sig.Results().At(i).Type())
+		if isProtobufMessageType(t) && !shouldCheckType(t) {
+			results = append(results, namedTypeName(t))
+		}
+	}
+	if len(results) == 0 {
+		return
+	}
+	accept := func(t types.Type) bool {
+		name := namedTypeName(t)
+		for _, result := range results {
+			if name == result {
+				return true
+			}
+		}
+		return false
+	}
+
+	analyzed := make(map[*ast.CompositeLit]bool)
+	tracked := collectTrackedLiterals(fn.Body, accept, pass)
+	for _, t := range sortedTracked(tracked) {
+		if t.lit != nil {
+			analyzed[t.lit] = true
+			checkCompositeLiteral(t.lit, t.litType, pass, false)
+		}
+	}
+	checkTrackedResponses(fn.Body, tracked, pass)
+
+	inspectFunctionBody(fn.Body, func(n ast.Node) {
+		ret, ok := n.(*ast.ReturnStmt)
+		if !ok {
+			return
+		}
+		for _, result := range ret.Results {
+			lit := messageLiteral(unwrapResponse(result, pass), accept, pass)
+			if lit == nil || analyzed[lit] {
+				continue
+			}
+			analyzed[lit] = true
+			checkCompositeLiteral(lit, pass.TypesInfo.TypeOf(lit), pass, !allowsPartialResponse(ret, pass))
+		}
+	})
+}
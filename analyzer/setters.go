@@ -0,0 +1,141 @@
+package analyzer
+
+import (
+	"encoding/gob"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// setterFact marks a function that stores one parameter through another, such as a
+// generic helper
+//
+//	func Set[T any](dst *T, v T) { *dst = v }
+//
+// A call passing the address of a message field, Set(&resp.User, nil), is checked as
+// the assignment resp.User = nil. Facts travel with the package, so helpers from
+// shared utility packages are modeled too.
+type setterFact struct {
+	// Dst is the index of the pointer parameter stored through, Value that of the
+	// parameter stored
+	Dst, Value int
+}
+
+func (*setterFact) AFact() {}
+
+func (f *setterFact) String() string {
+	return fmt.Sprintf("setter(dst: %d, value: %d)", f.Dst, f.Value)
+}
+
+func init() {
+	gob.Register(new(setterFact))
+}
+
+// exportSetterFacts finds the package's setters: functions whose body stores a
+// parameter through another, *dst = v, as a top-level statement, so every call does it
+func exportSetterFacts(pass *analysis.Pass) {
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil || fn.Recv != nil {
+				continue
+			}
+			obj, ok := pass.TypesInfo.Defs[fn.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+			if fact := setterOf(fn, obj, pass); fact != nil {
+				pass.ExportObjectFact(obj, fact)
+			}
+		}
+	}
+}
+
+// setterOf returns the setterFact of a function, or nil if it isn't a setter
+func setterOf(fn *ast.FuncDecl, obj *types.Func, pass *analysis.Pass) *setterFact {
+	params := obj.Type().(*types.Signature).Params()
+	index := func(expr ast.Expr) int {
+		id, ok := ast.Unparen(expr).(*ast.Ident)
+		if !ok {
+			return -1
+		}
+		for i := 0; i < params.Len(); i++ {
+			if pass.TypesInfo.Uses[id] == params.At(i) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	for _, stmt := range fn.Body.List {
+		assign, ok := stmt.(*ast.AssignStmt)
+		if !ok || assign.Tok != token.ASSIGN || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+			continue
+		}
+		star, ok := assign.Lhs[0].(*ast.StarExpr)
+		if !ok {
+			continue
+		}
+		dst, value := index(star.X), index(assign.Rhs[0])
+		if dst < 0 || value < 0 || isReassigned(params.At(dst), pass) || isReassigned(params.At(value), pass) {
+			continue
+		}
+		return &setterFact{Dst: dst, Value: value}
+	}
+	return nil
+}
+
+// setterStore returns the field a call to a setter stores into through its address, or
+// a generated setter method stores into, and the value stored, or nil if the call isn't
+// one
+func setterStore(call *ast.CallExpr, pass *analysis.Pass) (*ast.SelectorExpr, ast.Expr) {
+	// Generated setters, resp.SetUser(u); see protoruntime.go
+	if sel, value := generatedSetter(call, pass); sel != nil {
+		return sel, value
+	}
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || call.Ellipsis.IsValid() {
+		return nil, nil
+	}
+	var fact setterFact
+	if !pass.ImportObjectFact(fn.Origin(), &fact) || fact.Dst >= len(call.Args) || fact.Value >= len(call.Args) {
+		return nil, nil
+	}
+	addr, ok := ast.Unparen(call.Args[fact.Dst]).(*ast.UnaryExpr)
+	if !ok || addr.Op != token.AND {
+		return nil, nil
+	}
+	sel, ok := ast.Unparen(addr.X).(*ast.SelectorExpr)
+	if !ok {
+		return nil, nil
+	}
+	return sel, call.Args[fact.Value]
+}
+
+// checkSetterCall checks a call to a setter that stores a value into a message field
+// whose address is passed, as if the field were assigned directly
+func checkSetterCall(call *ast.CallExpr, pass *analysis.Pass) {
+	if sel, value := setterStore(call, pass); sel != nil {
+		checkFieldStore(sel, value, pass)
+	}
+}
+
+// collectSetterAssignments records a setter call that stores a non-nil value into a
+// field of obj, like collectFieldAssignments does for obj.Field = value
+func collectSetterAssignments(call *ast.CallExpr, obj types.Object, assigned map[string]bool, pass *analysis.Pass) {
+	// proto.Merge(obj, template) sets the template's fields; see protoruntime.go
+	for name := range mergedFields(call, obj, pass) {
+		assigned[name] = true
+	}
+	sel, value := setterStore(call, pass)
+	if sel == nil {
+		return
+	}
+	if id := baseIdent(sel, pass); id != nil && pass.TypesInfo.ObjectOf(id) == obj && !isNilValue(value, pass) {
+		assigned[sel.Sel.Name] = true
+	}
+}
@@ -0,0 +1,89 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// checkSharedResponses reports response instances that outlive a single call (package-level
+// variables and struct fields) but are mutated and returned by a function, e.g.
+//
+//	s.cachedResp.User = u
+//	return s.cachedResp, nil
+//
+// Every caller then shares one message: concurrent requests race on its fields, and a
+// field left nil by one request is seen by the next.
+func checkSharedResponses(body *ast.BlockStmt, pass *analysis.Pass) {
+	mutated := make(map[types.Object]ast.Node)
+	names := make(map[types.Object]string)
+	var order []types.Object
+	returned := make(map[types.Object]bool)
+
+	inspectFunctionBody(body, func(n ast.Node) {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range node.Lhs {
+				sel := storedField(lhs, pass)
+				if sel == nil {
+					continue
+				}
+				obj, kind := sharedResponse(sel.X, pass)
+				if obj == nil {
+					continue
+				}
+				if _, seen := mutated[obj]; !seen {
+					mutated[obj] = lhs
+					names[obj] = kind + " '" + types.ExprString(sel.X) + "'"
+					order = append(order, obj)
+				}
+			}
+		case *ast.ReturnStmt:
+			for _, result := range node.Results {
+				if obj, _ := sharedResponse(result, pass); obj != nil {
+					returned[obj] = true
+				}
+			}
+		}
+	})
+
+	for _, obj := range order {
+		if !returned[obj] {
+			continue
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos:      mutated[obj].Pos(),
+			Category: KindSharedResponse,
+			Message: fmt.Sprintf("response held in %s is mutated and returned on every call; concurrent requests race on it and see each other's nil fields, construct a fresh message per call",
+				names[obj]),
+		})
+	}
+}
+
+// sharedResponse returns the object behind a response value that outlives a call: a
+// package-level variable or a struct field. The second result describes which.
+func sharedResponse(expr ast.Expr, pass *analysis.Pass) (types.Object, string) {
+	t := pass.TypesInfo.TypeOf(expr)
+	if t == nil || !isResponseMessage(t) || !isTypeSelected(t) {
+		return nil, ""
+	}
+
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		obj, ok := pass.TypesInfo.Uses[e].(*types.Var)
+		if ok && obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope() {
+			return obj, "package-level variable"
+		}
+	case *ast.SelectorExpr:
+		if selection, ok := pass.TypesInfo.Selections[e]; ok && selection.Kind() == types.FieldVal {
+			return selection.Obj(), "struct field"
+		}
+		// Qualified identifier naming another package's variable
+		if obj, ok := pass.TypesInfo.Uses[e.Sel].(*types.Var); ok && !obj.IsField() && obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope() {
+			return obj, "package-level variable"
+		}
+	}
+	return nil, ""
+}
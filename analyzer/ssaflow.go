@@ -0,0 +1,207 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/ssa"
+)
+
+// reachingValue returns the SSA value that reaches a message field store: expr is the
+// value of resp.Field = expr or of a Field: expr literal element. The store is found
+// through its FieldAddr, positioned at the selector (resp.Field) or the colon
+// (Field: expr). It returns nil when expr is used elsewhere or the store can't be
+// found, and callers fall back to tracing the variable's declaration.
+//
+// Unlike the declaration, the reaching value accounts for reassignments on the way to
+// the store: u = buildUser() after var u *pb.User, or u = nil after a valid u := ...
+func reachingValue(expr ast.Expr, pass *analysis.Pass) ssa.Value {
+	if store := reachingStore(expr, pass); store != nil {
+		return store.Val
+	}
+	return nil
+}
+
+// reachingStore is like reachingValue but returns the store itself, whose position in
+// the function decides which nil checks hold for the value; see nilness.go
+func reachingStore(expr ast.Expr, pass *analysis.Pass) *ssa.Store {
+	fieldPos := fieldStorePos(expr, pass)
+	if !fieldPos.IsValid() {
+		return nil
+	}
+	// Over budget, callers trace the declaration instead
+	var store *ssa.Store
+	withinBudget(fieldPos, pass, func() {
+		store = fieldStore(fieldPos, pass)
+	})
+	return store
+}
+
+// fieldStore returns the store through the FieldAddr at fieldPos, or nil
+func fieldStore(fieldPos token.Pos, pass *analysis.Pass) *ssa.Store {
+	fn := enclosingSSAFunc(fieldPos, pass)
+	if fn == nil {
+		return nil
+	}
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			addr, ok := instr.(*ssa.FieldAddr)
+			if !ok || addr.Pos() != fieldPos {
+				continue
+			}
+			for _, ref := range *addr.Referrers() {
+				if store, ok := ref.(*ssa.Store); ok && store.Addr == addr {
+					return store
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// fieldStorePos returns the position SSA gives the FieldAddr of the store expr is the
+// value of, or token.NoPos
+func fieldStorePos(expr ast.Expr, pass *analysis.Pass) token.Pos {
+	file := fileAt(expr.Pos(), pass)
+	if file == nil {
+		return token.NoPos
+	}
+	path, _ := astutil.PathEnclosingInterval(file, expr.Pos(), expr.End())
+	if len(path) < 2 || path[0] != expr {
+		return token.NoPos
+	}
+	switch parent := path[1].(type) {
+	case *ast.KeyValueExpr:
+		if parent.Value == expr {
+			return parent.Colon
+		}
+	case *ast.AssignStmt:
+		for i, rhs := range parent.Rhs {
+			if rhs != expr || i >= len(parent.Lhs) || len(parent.Lhs) != len(parent.Rhs) {
+				continue
+			}
+			if sel := storedField(parent.Lhs[i], pass); sel != nil {
+				return sel.Sel.Pos()
+			}
+		}
+	}
+	return token.NoPos
+}
+
+// fileAt returns the file of the package containing pos, or nil
+func fileAt(pos token.Pos, pass *analysis.Pass) *ast.File {
+	for _, file := range pass.Files {
+		if file.Pos() <= pos && pos <= file.End() {
+			return file
+		}
+	}
+	return nil
+}
+
+// ssaPackages caches the SSA form of each package being analyzed, keyed by
+// *types.Package; run drops the entry when it finishes
+var ssaPackages sync.Map
+
+// packageSSA returns the SSA form of the package, building it on first use the way
+// buildssa.Analyzer does. It is built lazily rather than by requiring buildssa: with
+// facts the analyzer runs on every dependency, including the standard library, and
+// most packages never store a variable into a message field.
+func packageSSA(pass *analysis.Pass) *ssa.Package {
+	if cached, ok := ssaPackages.Load(pass.Pkg); ok {
+		return cached.(*ssa.Package)
+	}
+	prog := ssa.NewProgram(pass.Fset, 0)
+	for _, imp := range pass.Pkg.Imports() {
+		prog.CreatePackage(imp, nil, nil, true)
+	}
+	pkg := prog.CreatePackage(pass.Pkg, pass.Files, pass.TypesInfo, false)
+	pkg.Build()
+	cached, _ := ssaPackages.LoadOrStore(pass.Pkg, pkg)
+	return cached.(*ssa.Package)
+}
+
+// enclosingSSAFunc returns the innermost source function (or function literal) whose
+// syntax contains pos
+func enclosingSSAFunc(pos token.Pos, pass *analysis.Pass) *ssa.Function {
+	decl := enclosingFuncDecl(pos, pass)
+	if decl == nil {
+		return nil
+	}
+	obj, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+	if !ok {
+		return nil
+	}
+	fn := packageSSA(pass).Prog.FuncValue(obj)
+	if fn == nil {
+		return nil
+	}
+
+	// Descend into the function literal containing pos, if any
+	for {
+		var inner *ssa.Function
+		for _, anon := range fn.AnonFuncs {
+			if syntax := anon.Syntax(); syntax != nil && syntax.Pos() <= pos && pos < syntax.End() {
+				inner = anon
+			}
+		}
+		if inner == nil {
+			return fn
+		}
+		fn = inner
+	}
+}
+
+// isMemoryLoad checks if a value is loaded from memory: a package-level variable, or a
+// local one that escapes because its address is taken. Stores to those can happen
+// anywhere, so the reaching value says nothing and the declaration is traced instead.
+func isMemoryLoad(v ssa.Value) bool {
+	unop, ok := v.(*ssa.UnOp)
+	return ok && unop.Op == token.MUL
+}
+
+// reachingSyntax returns the expression that produced a reaching value when it is a
+// message literal (&T{...}) or a call, so it can be validated like an initializer.
+// ok is false when the value has no single source expression, e.g. a phi or a parameter.
+func reachingSyntax(v ssa.Value, pass *analysis.Pass) (expr ast.Expr, ok bool) {
+	var pos token.Pos
+	switch v := v.(type) {
+	case *ssa.Alloc:
+		pos = v.Pos() // the literal's Lbrace
+	case *ssa.Call:
+		pos = v.Pos() // the call's Lparen
+	case *ssa.Extract:
+		// u, err := f()
+		call, ok := v.Tuple.(*ssa.Call)
+		if !ok {
+			return nil, false
+		}
+		pos = call.Pos()
+	default:
+		return nil, false
+	}
+	file := fileAt(pos, pass)
+	if file == nil {
+		return nil, false
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if expr != nil {
+			return false
+		}
+		switch e := n.(type) {
+		case *ast.CompositeLit:
+			if e.Lbrace == pos {
+				expr = e
+			}
+		case *ast.CallExpr:
+			if e.Lparen == pos {
+				expr = e
+			}
+		}
+		return expr == nil
+	})
+	return expr, expr != nil
+}
@@ -0,0 +1,94 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// checkStubResponses enables the stub-response rule
+var checkStubResponses bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&checkStubResponses, "check-stub-responses", false,
+		"report responses returned outside tests whose fields are all zero values or empty messages, as scaffolding leaves them")
+}
+
+// checkStubResponse reports a response literal returned outside tests that is only
+// stubbed out: each field is set to a zero value or an empty message, and at least one
+// message field is set, as in
+//
+//	return &pb.UserResponse{User: &pb.User{}, Count: 0}, nil
+//
+// Such a response passes the nil checks, but it is what a handler looks like before it
+// is written, and tends to stay in place when it gets through review.
+func checkStubResponse(ret *ast.ReturnStmt, pass *analysis.Pass) {
+	if !checkStubResponses || strings.HasSuffix(pass.Fset.Position(ret.Pos()).Filename, "_test.go") {
+		return
+	}
+	for _, result := range ret.Results {
+		lit := messageLiteral(ast.Unparen(result), shouldCheckType, pass)
+		if lit == nil || !stubShaped(lit, pass) {
+			continue
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos:      lit.Pos(),
+			End:      lit.End(),
+			Category: KindStubResponse,
+			Message: fmt.Sprintf("stub response %s returned: every field is a zero value or an empty message; fill it in or remove the scaffolding",
+				describeType(pass, pass.TypesInfo.TypeOf(lit))),
+		})
+	}
+}
+
+// stubShaped checks if each field a message literal sets is nil, a zero constant, an
+// empty literal or new(T), and some field is set to an empty message
+func stubShaped(lit *ast.CompositeLit, pass *analysis.Pass) bool {
+	messages := 0
+	for _, elt := range lit.Elts {
+		value := elt
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			value = kv.Value
+		}
+		value = ast.Unparen(value)
+		if isNilValue(value, pass) {
+			continue
+		}
+		if tv, ok := pass.TypesInfo.Types[value]; ok && tv.Value != nil {
+			if !zeroConstant(tv.Value) {
+				return false
+			}
+			continue
+		}
+		if msg := messageLiteral(value, isProtobufMessageType, pass); msg != nil && len(msg.Elts) == 0 {
+			messages++
+			continue
+		}
+		if newMessageCall(value, isProtobufMessageType, pass) != nil {
+			messages++
+			continue
+		}
+		if other, ok := value.(*ast.CompositeLit); ok && len(other.Elts) == 0 {
+			// An empty list or map
+			continue
+		}
+		return false
+	}
+	return messages > 0
+}
+
+// zeroConstant checks if a constant is the zero value of its type: 0, "" or false
+func zeroConstant(value constant.Value) bool {
+	switch value.Kind() {
+	case constant.Bool:
+		return !constant.BoolVal(value)
+	case constant.String:
+		return constant.StringVal(value) == ""
+	case constant.Int, constant.Float, constant.Complex:
+		return constant.Sign(value) == 0
+	}
+	return false
+}
@@ -0,0 +1,271 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// IgnoreDirective suppresses the findings on its line, or on the next line when it is
+// on a line of its own. When that line starts a composite literal, findings anywhere in
+// the literal are suppressed too. The directive gives the reason the finding was
+// accepted, so the next reader knows why it is safe; -require-reason makes it mandatory:
+//
+//	//nonillinter:ignore User is filled in by the gateway before the response is sent
+//	resp.User = nil
+//
+// The //nolint:nonillinter comments golangci-lint users already write are honored
+// too, with the reason after a second //, as golangci-lint's nolintlint expects:
+//
+//	resp.User = nil //nolint:nonillinter // filled in by the gateway
+const IgnoreDirective = "//nonillinter:ignore"
+
+// BeginUncheckedDirective and EndUncheckedDirective exclude the region between them,
+// such as a large legacy switch, from analysis while the rest of the file is still
+// checked. Each begin needs a matching end; unbalanced directives exclude nothing and
+// are reported.
+const (
+	BeginUncheckedDirective = "//nonil:begin-unchecked"
+	EndUncheckedDirective   = "//nonil:end-unchecked"
+)
+
+// suppressIgnored wraps pass.Report to drop findings on lines or in composite literals
+// covered by an ignore directive, or in an unchecked region. Under -require-reason, the
+// analyzer's own directives without a reason don't suppress anything, and are reported
+// themselves when they cover a finding; a bare //nolint is left to golangci-lint's
+// nolintlint. Unbalanced regions are reported too.
+func suppressIgnored(pass *analysis.Pass) {
+	type fileLine struct {
+		file *token.File
+		line int
+	}
+	// unreasoned is a directive without a reason and the literals it would cover
+	type unreasoned struct {
+		comment  *ast.Comment
+		name     string
+		literals []region
+	}
+	ignored := make(map[fileLine]bool)
+	needReason := make(map[fileLine]*unreasoned)
+	var unchecked, literals []region
+	var pending []*unreasoned
+	for _, file := range pass.Files {
+		tf := pass.Fset.File(file.Pos())
+		if tf == nil {
+			continue
+		}
+		unchecked = append(unchecked, uncheckedRegions(file, pass)...)
+		var code map[int]token.Pos
+		var lits map[int][]region
+		for _, group := range file.Comments {
+			for _, c := range group.List {
+				name, reason, ok := ignoreDirective(c.Text, pass.Analyzer.Name)
+				if !ok {
+					continue
+				}
+				if code == nil {
+					code, lits = codeStarts(file, tf), literalStarts(file, tf)
+				}
+				line := tf.Line(c.Pos())
+				if start, ok := code[line]; !ok || start > c.Pos() {
+					line++
+				}
+				if requireReason && reason == "" && name != "//nolint" {
+					u := &unreasoned{comment: c, name: name, literals: lits[line]}
+					needReason[fileLine{tf, line}] = u
+					needReason[fileLine{tf, tf.Line(c.Pos())}] = u
+					pending = append(pending, u)
+					continue
+				}
+				ignored[fileLine{tf, line}] = true
+				ignored[fileLine{tf, tf.Line(c.Pos())}] = true
+				literals = append(literals, lits[line]...)
+			}
+		}
+	}
+	if len(ignored) == 0 && len(unchecked) == 0 && len(pending) == 0 {
+		return
+	}
+	excluded := append(unchecked, literals...)
+
+	// covering returns the directive without a reason covering a finding at pos, if any
+	covering := func(pos token.Pos) *unreasoned {
+		if tf := pass.Fset.File(pos); tf != nil {
+			if u := needReason[fileLine{tf, tf.Line(pos)}]; u != nil {
+				return u
+			}
+		}
+		for _, u := range pending {
+			for _, r := range u.literals {
+				if r.begin <= pos && pos < r.end {
+					return u
+				}
+			}
+		}
+		return nil
+	}
+
+	reported := make(map[*unreasoned]bool)
+	report := pass.Report
+	pass.Report = func(d analysis.Diagnostic) {
+		if tf := pass.Fset.File(d.Pos); tf != nil && ignored[fileLine{tf, tf.Line(d.Pos)}] {
+			suppressSite(d, pass)
+			return
+		}
+		for _, r := range excluded {
+			if r.begin <= d.Pos && d.Pos < r.end {
+				suppressSite(d, pass)
+				return
+			}
+		}
+		report(d)
+		if u := covering(d.Pos); u != nil && !reported[u] {
+			reported[u] = true
+			report(analysis.Diagnostic{
+				Pos:      u.comment.Pos(),
+				End:      u.comment.End(),
+				Category: KindIgnoreDirective,
+				Message:  u.name + " needs a reason for accepting the finding",
+			})
+		}
+	}
+}
+
+// IgnoreDirectives returns the ignore directives in a file's comments that apply to the
+// analyzer named linter, with or without a reason. Drivers use them to tell how much of
+// a file has been accepted rather than fixed.
+func IgnoreDirectives(file *ast.File, linter string) []*ast.Comment {
+	var directives []*ast.Comment
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			if _, _, ok := ignoreDirective(c.Text, linter); ok {
+				directives = append(directives, c)
+			}
+		}
+	}
+	return directives
+}
+
+// ignoreDirective parses an ignore directive comment, returning the directive as
+// written in messages and its reason, which is empty if none is given. linter is the
+// analyzer's name in //nolint lists.
+func ignoreDirective(text, linter string) (name, reason string, ok bool) {
+	if rest, found := strings.CutPrefix(text, IgnoreDirective); found && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+		return IgnoreDirective, strings.TrimSpace(rest), true
+	}
+
+	// //nolint applies to every linter, //nolint:a,b to those listed
+	rest, found := strings.CutPrefix(text, "//nolint")
+	if !found {
+		return "", "", false
+	}
+	name = "//nolint"
+	if linters, ok := strings.CutPrefix(rest, ":"); ok {
+		list, _, _ := strings.Cut(linters, " ")
+		if !containsString(strings.Split(list, ","), linter) {
+			return "", "", false
+		}
+		name, rest = "//nolint:"+linter, linters[len(list):]
+	} else if rest != "" && rest[0] != ' ' {
+		return "", "", false
+	}
+	if _, reason, ok := strings.Cut(rest, "//"); ok {
+		return name, strings.TrimSpace(reason), true
+	}
+	return name, "", true
+}
+
+// region is the source between an unchecked region's directives
+type region struct {
+	begin, end token.Pos
+}
+
+// uncheckedRegions returns the balanced unchecked regions of a file, reporting
+// directives without a partner. A begin inside a region is reported rather than
+// nested, so the end that follows it closes the outer region.
+func uncheckedRegions(file *ast.File, pass *analysis.Pass) []region {
+	var regions []region
+	var open *ast.Comment
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			switch directive(c.Text) {
+			case BeginUncheckedDirective:
+				if open != nil {
+					reportDirective(c, BeginUncheckedDirective+" inside the unchecked region begun at "+shortPosition(pass, open.Pos())+"; regions don't nest", pass)
+					continue
+				}
+				open = c
+			case EndUncheckedDirective:
+				if open == nil {
+					reportDirective(c, EndUncheckedDirective+" has no matching "+BeginUncheckedDirective, pass)
+					continue
+				}
+				regions = append(regions, region{begin: open.Pos(), end: c.End()})
+				open = nil
+			}
+		}
+	}
+	if open != nil {
+		reportDirective(open, BeginUncheckedDirective+" has no matching "+EndUncheckedDirective+"; nothing is excluded", pass)
+	}
+	return regions
+}
+
+// directive returns the directive a comment consists of, ignoring trailing notes after
+// a space, or ""
+func directive(text string) string {
+	name, _, _ := strings.Cut(text, " ")
+	if name == BeginUncheckedDirective || name == EndUncheckedDirective {
+		return name
+	}
+	return ""
+}
+
+func reportDirective(c *ast.Comment, message string, pass *analysis.Pass) {
+	pass.Report(analysis.Diagnostic{
+		Pos:      c.Pos(),
+		End:      c.End(),
+		Category: KindUncheckedRegion,
+		Message:  message,
+	})
+}
+
+// codeStarts maps each line of a file holding code to the position of its first token,
+// telling directives on a line of their own from those trailing a statement
+func codeStarts(file *ast.File, tf *token.File) map[int]token.Pos {
+	starts := make(map[int]token.Pos)
+	mark := func(pos token.Pos) {
+		line := tf.Line(pos)
+		if start, ok := starts[line]; !ok || pos < start {
+			starts[line] = pos
+		}
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n.(type) {
+		case nil, *ast.Comment, *ast.CommentGroup:
+			return false
+		}
+		mark(n.Pos())
+		mark(n.End() - 1)
+		return true
+	})
+	return starts
+}
+
+// literalStarts maps each line of a file to the outermost composite literals starting on
+// it, which an ignore directive covering the line covers entirely
+func literalStarts(file *ast.File, tf *token.File) map[int][]region {
+	starts := make(map[int][]region)
+	ast.Inspect(file, func(n ast.Node) bool {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		line := tf.Line(lit.Pos())
+		starts[line] = append(starts[line], region{begin: lit.Pos(), end: lit.End()})
+		return false
+	})
+	return starts
+}
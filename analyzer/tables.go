@@ -0,0 +1,118 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// nameTableEntries wraps pass.Report to name the entry a finding belongs to when it
+// is in a handler func registered in a map or a table of routes, so the endpoint is
+// known without reading the code around it:
+//
+//	handlers := map[string]func() *pb.UserResponse{
+//		"GetUser": func() *pb.UserResponse { ... }, // ...; in the handler for "GetUser"
+//	}
+//	routes := []route{
+//		{"GET", "/users", func() *pb.UserResponse { ... }}, // ...; in the handler for "GET /users"
+//	}
+//
+// Map entries are named by their key, table rows by their constant string fields.
+func nameTableEntries(pass *analysis.Pass) {
+	report := pass.Report
+	pass.Report = func(d analysis.Diagnostic) {
+		if name := tableEntryName(d.Pos, pass); name != "" {
+			d.Message += fmt.Sprintf("; in the handler for %q", name)
+		}
+		report(d)
+	}
+}
+
+// tableEntryName returns the name of the innermost map entry or table row holding a
+// function literal that contains pos, or ""
+func tableEntryName(pos token.Pos, pass *analysis.Pass) string {
+	for _, file := range pass.Files {
+		if pos < file.Pos() || pos > file.End() {
+			continue
+		}
+		path, _ := astutil.PathEnclosingInterval(file, pos, pos)
+		for i, n := range path {
+			if lit, ok := n.(*ast.FuncLit); ok && i+2 < len(path) {
+				if name := entryName(lit, path[i+1:], pass); name != "" {
+					return name
+				}
+			}
+		}
+		break
+	}
+	return ""
+}
+
+// entryName names the entry a function literal is the value of, given the nodes
+// enclosing it: the constant key of a map entry, or the constant strings of a struct
+// literal that is an element of a slice, array or map literal
+func entryName(fn *ast.FuncLit, parents []ast.Node, pass *analysis.Pass) string {
+	var row *ast.CompositeLit
+	switch parent := parents[0].(type) {
+	case *ast.KeyValueExpr:
+		outer, ok := parents[1].(*ast.CompositeLit)
+		if !ok || parent.Value != fn {
+			return ""
+		}
+		if _, ok := pass.TypesInfo.TypeOf(outer).Underlying().(*types.Map); ok {
+			return constantString(parent.Key, pass)
+		}
+		row, parents = outer, parents[2:]
+	case *ast.CompositeLit:
+		row, parents = parent, parents[1:]
+	default:
+		return ""
+	}
+
+	// The struct literal is a row when it is an element of another literal
+	if _, ok := pass.TypesInfo.TypeOf(row).Underlying().(*types.Struct); !ok || len(parents) == 0 {
+		return ""
+	}
+	var key ast.Expr
+	switch table := parents[0].(type) {
+	case *ast.KeyValueExpr:
+		if len(parents) < 2 || table.Value != row {
+			return ""
+		}
+		if _, ok := parents[1].(*ast.CompositeLit); !ok {
+			return ""
+		}
+		key = table.Key
+	case *ast.CompositeLit:
+	default:
+		return ""
+	}
+
+	var names []string
+	for _, elt := range row.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			elt = kv.Value
+		}
+		if s := constantString(elt, pass); s != "" {
+			names = append(names, s)
+		}
+	}
+	if len(names) == 0 && key != nil {
+		return constantString(key, pass)
+	}
+	return strings.Join(names, " ")
+}
+
+// constantString returns the value of a constant string expression, or ""
+func constantString(expr ast.Expr, pass *analysis.Pass) string {
+	if tv, ok := pass.TypesInfo.Types[expr]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+		return constant.StringVal(tv.Value)
+	}
+	return ""
+}
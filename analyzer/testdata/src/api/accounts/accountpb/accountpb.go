@@ -0,0 +1,47 @@
+// Package accountpb stands in for generated code of the api.accounts proto package,
+// whose schema marks the fields it requires:
+//
+//	message Account {
+//	  Owner owner = 1 [(api.annotations.required) = true];
+//	  Image avatar = 2;
+//	}
+//	message GetAccountResponse {
+//	  Account account = 1 [(api.annotations.required) = true];
+//	  Image banner = 2;
+//	}
+package accountpb
+
+import (
+	_ "api/annotations/schemapb"
+)
+
+// Serialized FileDescriptorProto: name "api/accounts/accounts.proto", package
+// "api.accounts", importing "api/annotations/schema.proto", and messages Owner, Image,
+// Account and GetAccountResponse
+const file_api_accounts_accounts_proto_rawDesc = "\x0a\x1bapi/accounts/accounts.proto\x12\x0capi.accounts\x1a\x1capi/annotations/schema.proto\"\x15\x0a\x05Owner\x12\x0c\x0a\x04name\x18\x01 \x01(\x09\"\x14\x0a\x05Image\x12\x0b\x0a\x03url\x18\x01 \x01(\x09\"X\x0a\x07Account\x12(\x0a\x05owner\x18\x01 \x01(\x0b2\x13.api.accounts.OwnerB\x04\x88\xb5\x18\x01\x12#\x0a\x06avatar\x18\x02 \x01(\x0b2\x13.api.accounts.Image\"g\x0a\x12GetAccountResponse\x12,\x0a\x07account\x18\x01 \x01(\x0b2\x15.api.accounts.AccountB\x04\x88\xb5\x18\x01\x12#\x0a\x06banner\x18\x02 \x01(\x0b2\x13.api.accounts.Imageb\x06proto3"
+
+type Owner struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (*Owner) ProtoMessage() {}
+
+type Image struct {
+	Url string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+}
+
+func (*Image) ProtoMessage() {}
+
+type Account struct {
+	Owner  *Owner `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	Avatar *Image `protobuf:"bytes,2,opt,name=avatar,proto3" json:"avatar,omitempty"`
+}
+
+func (*Account) ProtoMessage() {}
+
+type GetAccountResponse struct {
+	Account *Account `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
+	Banner  *Image   `protobuf:"bytes,2,opt,name=banner,proto3" json:"banner,omitempty"`
+}
+
+func (*GetAccountResponse) ProtoMessage() {}
@@ -0,0 +1,23 @@
+// Package schemapb stands in for generated code of the api.annotations proto package,
+// which declares the option marking required fields:
+//
+//	extend google.protobuf.FieldOptions {
+//	  bool required = 50001;
+//	}
+package schemapb
+
+// Serialized FileDescriptorProto: name "api/annotations/schema.proto", package
+// "api.annotations" and the required extension of google.protobuf.FieldOptions
+const file_api_annotations_schema_proto_rawDesc = "\x0a\x1capi/annotations/schema.proto\x12\x0fapi.annotations\x1a google/protobuf/descriptor.proto:1\x0a\x08required\x18\xd1\x86\x03 \x01(\x08\x12\x1d.google.protobuf.FieldOptionsb\x06proto3"
+
+// extensionInfo stands in for protoimpl.ExtensionInfo
+type extensionInfo struct {
+	Field int32
+	Name  string
+}
+
+var file_api_annotations_schema_proto_extTypes = []extensionInfo{
+	{Field: 50001, Name: "api.annotations.required"},
+}
+
+var E_Required = &file_api_annotations_schema_proto_extTypes[0]
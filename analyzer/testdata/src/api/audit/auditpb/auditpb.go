@@ -0,0 +1,52 @@
+// Package auditpb stands in for generated code of the api.audit proto package, which
+// declares extensions of AuditResponse:
+//
+//	extend AuditResponse {
+//	  Audit audit = 100;
+//	  string audit_note = 101;
+//	}
+//	message Audit {
+//	  extend AuditResponse { Audit previous = 102; }
+//	}
+package auditpb
+
+// Serialized FileDescriptorProto: name "api/audit/audit.proto", package "api.audit",
+// messages Audit (declaring the previous extension) and AuditResponse, and the audit
+// and audit_note extensions
+const file_api_audit_audit_proto_rawDesc = "\n\x15api/audit/audit.proto\x12\x09api.audit\"E\n\x05Audit2<\n\x08previous\x12\x18.api.audit.AuditResponse\x18f \x01(\x0b2\x10.api.audit.Audit\"\x0f\n\x0dAuditResponse:9\n\x05audit\x12\x18.api.audit.AuditResponse\x18d \x01(\x0b2\x10.api.audit.Audit:,\n\naudit_note\x12\x18.api.audit.AuditResponse\x18e \x01(\x09"
+
+// extensionInfo stands in for protoimpl.ExtensionInfo
+type extensionInfo struct {
+	Field int32
+	Name  string
+}
+
+var file_api_audit_audit_proto_extTypes = []extensionInfo{
+	{Field: 100, Name: "api.audit.audit"},
+	{Field: 101, Name: "api.audit.audit_note"},
+	{Field: 102, Name: "api.audit.Audit.previous"},
+}
+
+var (
+	E_Audit          = &file_api_audit_audit_proto_extTypes[0]
+	E_AuditNote      = &file_api_audit_audit_proto_extTypes[1]
+	E_Audit_Previous = &file_api_audit_audit_proto_extTypes[2]
+)
+
+type Actor struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (*Actor) ProtoMessage() {}
+
+type Audit struct {
+	Actor *Actor `protobuf:"bytes,1,opt,name=actor,proto3" json:"actor,omitempty"`
+}
+
+func (*Audit) ProtoMessage() {}
+
+type AuditResponse struct {
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (*AuditResponse) ProtoMessage() {}
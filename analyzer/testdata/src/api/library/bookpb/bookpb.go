@@ -0,0 +1,61 @@
+// Package bookpb stands in for generated code of the api.library proto package,
+// whose fields carry google.api.field_behavior annotations.
+package bookpb
+
+// Serialized FileDescriptorProto of api/library/book.proto:
+//
+//	message Book {
+//	  Author author = 1 [(google.api.field_behavior) = REQUIRED];
+//	  Timestamp create_time = 2 [(google.api.field_behavior) = OUTPUT_ONLY];
+//	  message Revision {
+//	    Author reviewer = 1 [(google.api.field_behavior) = OUTPUT_ONLY]; // packed with REQUIRED
+//	  }
+//	}
+//	message CreateBookRequest { Book book = 1 [(google.api.field_behavior) = REQUIRED]; }
+//	message GetBookResponse { Book book = 1; }
+const file_api_library_book_proto_rawDesc = "\x0a\x16api/library/book.proto\x12\x0bapi.library\x22\x0b\x0a\x09Timestamp\x22\x08\x0a\x06Author\x22\x9c\x01\x0a\x04Book\x12(\x0a\x06author\x18\x01 \x01(\x0b2\x13.api.library.AuthorB\x03\xe0A\x02\x120\x0a\x0bcreate_time\x18\x02 \x01(\x0b2\x16.api.library.TimestampB\x03\xe0A\x03\x1a8\x0a\x08Revision\x12,\x0a\x08reviewer\x18\x01 \x01(\x0b2\x13.api.library.AuthorB\x05\xe2A\x02\x02\x03\x229\x0a\x11CreateBookRequest\x12$\x0a\x04book\x18\x01 \x01(\x0b2\x11.api.library.BookB\x03\xe0A\x02\x222\x0a\x0fGetBookResponse\x12\x1f\x0a\x04book\x18\x01 \x01(\x0b2\x11.api.library.Book"
+
+type Timestamp struct{}
+
+func (*Timestamp) ProtoMessage() {}
+
+type Author struct{}
+
+func (*Author) ProtoMessage() {}
+
+type Book struct {
+	Author     *Author    `protobuf:"bytes,1,opt,name=author,proto3" json:"author,omitempty"`
+	CreateTime *Timestamp `protobuf:"bytes,2,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+}
+
+func (*Book) ProtoMessage() {}
+
+type Book_Revision struct {
+	Reviewer *Author `protobuf:"bytes,1,opt,name=reviewer,proto3" json:"reviewer,omitempty"`
+}
+
+func (*Book_Revision) ProtoMessage() {}
+
+type CreateBookRequest struct {
+	Book *Book `protobuf:"bytes,1,opt,name=book,proto3" json:"book,omitempty"`
+}
+
+func (*CreateBookRequest) ProtoMessage() {}
+
+type UpdateRevisionRequest struct {
+	Revision *Book_Revision `protobuf:"bytes,1,opt,name=revision,proto3" json:"revision,omitempty"`
+}
+
+func (*UpdateRevisionRequest) ProtoMessage() {}
+
+type GetBookRequest struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (*GetBookRequest) ProtoMessage() {}
+
+type GetBookResponse struct {
+	Book *Book `protobuf:"bytes,1,opt,name=book,proto3" json:"book,omitempty"`
+}
+
+func (*GetBookResponse) ProtoMessage() {}
@@ -0,0 +1,27 @@
+package bookpb
+
+import "context"
+
+// LibraryServiceServer stands in for the protoc-gen-go-grpc server interface of
+//
+//	service LibraryService {
+//	  rpc GetBook(GetBookRequest) returns (Book);
+//	  rpc CreateBook(CreateBookRequest) returns (Book);
+//	}
+type LibraryServiceServer interface {
+	GetBook(context.Context, *GetBookRequest) (*Book, error)
+	CreateBook(context.Context, *CreateBookRequest) (*Book, error)
+	mustEmbedUnimplementedLibraryServiceServer()
+}
+
+type UnimplementedLibraryServiceServer struct{}
+
+func (UnimplementedLibraryServiceServer) GetBook(context.Context, *GetBookRequest) (*Book, error) {
+	return nil, nil
+}
+
+func (UnimplementedLibraryServiceServer) CreateBook(context.Context, *CreateBookRequest) (*Book, error) {
+	return nil, nil
+}
+
+func (UnimplementedLibraryServiceServer) mustEmbedUnimplementedLibraryServiceServer() {}
@@ -0,0 +1,43 @@
+// Package orderpb stands in for generated code of the api.orders proto package, whose
+// service returns messages that aren't named like responses.
+package orderpb
+
+type Customer struct{}
+
+func (*Customer) ProtoMessage() {}
+
+type Money struct{}
+
+func (*Money) ProtoMessage() {}
+
+type Order struct {
+	Customer *Customer `protobuf:"bytes,1,opt,name=customer,proto3" json:"customer,omitempty"`
+	Total    *Money    `protobuf:"bytes,2,opt,name=total,proto3" json:"total,omitempty"`
+}
+
+func (*Order) ProtoMessage() {}
+
+type OrderEvent struct {
+	Order *Order `protobuf:"bytes,1,opt,name=order,proto3" json:"order,omitempty"`
+}
+
+func (*OrderEvent) ProtoMessage() {}
+
+type LookupOrder struct {
+	Customer *Customer `protobuf:"bytes,1,opt,name=customer,proto3" json:"customer,omitempty"`
+}
+
+func (*LookupOrder) ProtoMessage() {}
+
+type WatchOrdersRequest struct {
+	Customer *Customer `protobuf:"bytes,1,opt,name=customer,proto3" json:"customer,omitempty"`
+}
+
+func (*WatchOrdersRequest) ProtoMessage() {}
+
+// ArchiveOrderResponse is named like a response, but no RPC returns it
+type ArchiveOrderResponse struct {
+	Order *Order `protobuf:"bytes,1,opt,name=order,proto3" json:"order,omitempty"`
+}
+
+func (*ArchiveOrderResponse) ProtoMessage() {}
@@ -0,0 +1,33 @@
+package orderpb
+
+import "context"
+
+// OrderServiceServer stands in for the protoc-gen-go-grpc server interface of
+//
+//	service OrderService {
+//	  rpc GetOrder(LookupOrder) returns (Order);
+//	  rpc WatchOrders(WatchOrdersRequest) returns (stream OrderEvent);
+//	}
+type OrderServiceServer interface {
+	GetOrder(context.Context, *LookupOrder) (*Order, error)
+	WatchOrders(*WatchOrdersRequest, OrderService_WatchOrdersServer) error
+}
+
+type OrderService_WatchOrdersServer interface {
+	Send(*OrderEvent) error
+	Context() context.Context
+}
+
+func RegisterOrderServiceServer(s any, srv OrderServiceServer) {}
+
+// OrderServiceClient stands in for the client interface generated with it
+type OrderServiceClient interface {
+	GetOrder(ctx context.Context, in *LookupOrder) (*Order, error)
+	WatchOrders(ctx context.Context, in *WatchOrdersRequest) (OrderService_WatchOrdersClient, error)
+}
+
+type OrderService_WatchOrdersClient interface {
+	Recv() (*OrderEvent, error)
+}
+
+func NewOrderServiceClient(cc any) OrderServiceClient { return nil }
@@ -0,0 +1,32 @@
+// Package paymentpb stands in for generated code of a service the module only calls,
+// generated without its server interface.
+package paymentpb
+
+import "context"
+
+type Receipt struct {
+	Payment *Payment `protobuf:"bytes,1,opt,name=payment,proto3" json:"payment,omitempty"`
+}
+
+func (*Receipt) ProtoMessage() {}
+
+type Payment struct{}
+
+func (*Payment) ProtoMessage() {}
+
+type Charge struct {
+	Payment *Payment `protobuf:"bytes,1,opt,name=payment,proto3" json:"payment,omitempty"`
+}
+
+func (*Charge) ProtoMessage() {}
+
+// PaymentServiceClient stands in for the protoc-gen-go-grpc client interface of
+//
+//	service PaymentService {
+//	  rpc Pay(Charge) returns (Receipt);
+//	}
+type PaymentServiceClient interface {
+	Pay(ctx context.Context, in *Charge) (*Receipt, error)
+}
+
+func NewPaymentServiceClient(cc any) PaymentServiceClient { return nil }
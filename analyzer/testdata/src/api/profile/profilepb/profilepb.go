@@ -0,0 +1,38 @@
+// Package profilepb stands in for generated code of the api.profile proto package
+// whose proto3 optional field isn't marked as such in its struct tag; only the
+// descriptor says so.
+package profilepb
+
+// Serialized FileDescriptorProto of api/profile/profile.proto:
+//
+//	message Profile { string bio = 1; }
+//	message GetProfileResponse {
+//	  optional Profile profile = 1; // in the synthetic oneof _profile
+//	  Profile primary = 2;
+//	  oneof kind { Profile alt = 3; }
+//	}
+const file_api_profile_profile_proto_rawDesc = "\x0a\x19api/profile/profile.proto\x12\x0bapi.profile\x22\x16\x0a\x07Profile\x12\x0b\x0a\x03bio\x18\x01 \x01(\x09\x22\xa0\x01\x0a\x12GetProfileResponse\x12*\x0a\x07profile\x18\x01 \x01(\x0b2\x14.api.profile.ProfileH\x00\x88\x01\x01\x12%\x0a\x07primary\x18\x02 \x01(\x0b2\x14.api.profile.Profile\x12#\x0a\x03alt\x18\x03 \x01(\x0b2\x14.api.profile.ProfileH\x01B\x0a\x0a\x08_profileB\x06\x0a\x04kindb\x06proto3"
+
+type Profile struct {
+	Bio string `protobuf:"bytes,1,opt,name=bio,proto3" json:"bio,omitempty"`
+}
+
+func (*Profile) ProtoMessage() {}
+
+type GetProfileResponse struct {
+	Profile *Profile                  `protobuf:"bytes,1,opt,name=profile,proto3" json:"profile,omitempty"`
+	Primary *Profile                  `protobuf:"bytes,2,opt,name=primary,proto3" json:"primary,omitempty"`
+	Kind    isGetProfileResponse_Kind `protobuf_oneof:"kind"`
+}
+
+func (*GetProfileResponse) ProtoMessage() {}
+
+type isGetProfileResponse_Kind interface {
+	isGetProfileResponse_Kind()
+}
+
+type GetProfileResponse_Alt struct {
+	Alt *Profile `protobuf:"bytes,3,opt,name=alt,proto3,oneof"`
+}
+
+func (*GetProfileResponse_Alt) isGetProfileResponse_Kind() {}
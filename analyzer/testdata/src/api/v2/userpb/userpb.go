@@ -0,0 +1,17 @@
+// Package userpb stands in for generated code of the api.v2 proto package.
+package userpb
+
+// Serialized FileDescriptorProto: name "api/v2/user.proto", package "api.v2"
+const file_api_v2_user_proto_rawDesc = "\n\x11api/v2/user.proto\x12\x06api.v2"
+
+type User struct {
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (*User) ProtoMessage() {}
+
+type GetUserResponse struct {
+	User *User `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+}
+
+func (*GetUserResponse) ProtoMessage() {}
@@ -0,0 +1,44 @@
+// Package avrouser holds records as gogen-avro and hamba/avro's avrogen write them for
+// user.avsc
+package avrouser
+
+// Address and User are gogen-avro records
+type Address struct {
+	Street string `json:"street"`
+}
+
+func (r *Address) Schema() string     { return `{"type":"record","name":"Address"}` }
+func (r *Address) SchemaName() string { return "Address" }
+
+type UnionNullUserTypeEnum int
+
+type UnionNullUser struct {
+	User      *User
+	UnionType UnionNullUserTypeEnum
+}
+
+type User struct {
+	Address *Address       `json:"address"`
+	Manager *UnionNullUser `json:"manager"`
+}
+
+func (r *User) Schema() string     { return `{"type":"record","name":"User"}` }
+func (r *User) SchemaName() string { return "User" }
+
+type UserResponse struct {
+	User *User `json:"user"`
+}
+
+func (r *UserResponse) Schema() string     { return `{"type":"record","name":"UserResponse"}` }
+func (r *UserResponse) SchemaName() string { return "UserResponse" }
+
+// Profile and ProfileResponse are avrogen records; Manager is ["null", "Profile"]
+type Profile struct {
+	Name    string   `avro:"name"`
+	Manager *Profile `avro:"manager"`
+}
+
+type ProfileResponse struct {
+	Profile Profile  `avro:"profile"`
+	Backup  *Profile `avro:"backup"`
+}
@@ -0,0 +1,201 @@
+package branches
+
+import (
+	"errors"
+
+	"stubpb"
+)
+
+func newUser() *stubpb.User {
+	return &stubpb.User{Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+}
+
+// Set on one branch only: the report names the condition
+func setWhenFound(found bool) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	if found {
+		resp.User = newUser()
+	}
+	return resp // want "non-optional message field 'User' may be uninitialized on some paths in protobuf message 'stubpb.UserResponse': it is only set when found"
+}
+
+func setInElse(err error) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	if err != nil {
+		resp.LastLogin = stubpb.Now()
+	} else {
+		resp.User = newUser()
+	}
+	return resp // want `non-optional message field 'User' may be uninitialized on some paths in protobuf message 'stubpb.UserResponse': it is only set when !\(err != nil\)`
+}
+
+// Set on every branch
+func setOnBothBranches(cached bool) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	if cached {
+		resp.User = newUser()
+	} else {
+		resp.User = newUser()
+	}
+	return resp
+}
+
+func setOnEveryBranchOfChain(source int) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	if source == 0 {
+		resp.User = newUser()
+	} else if source == 1 {
+		resp.User = newUser()
+	} else {
+		resp.User = newUser()
+	}
+	return resp
+}
+
+func missingFromChain(source int) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	if source == 0 {
+		resp.User = newUser()
+	} else if source == 1 {
+		resp.LastLogin = stubpb.Now()
+	}
+	return resp // want `it is only set when source == 0`
+}
+
+func setInNestedChainBranch(source int) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	if source == 0 {
+		resp.LastLogin = stubpb.Now()
+	} else if source == 1 {
+		resp.User = newUser()
+	}
+	return resp // want `it is only set when !\(source == 0\) && source == 1`
+}
+
+// Branches that leave the function don't reach the return below them
+func setUnlessFailed(err error) (*stubpb.UserResponse, error) {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	if err == nil {
+		resp.User = newUser()
+	} else {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func setUnlessPanicking(ok bool) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	if !ok {
+		panic(errors.New("lookup failed"))
+	} else {
+		resp.User = newUser()
+	}
+	return resp
+}
+
+// Never set on any path
+func neverSet(ok bool) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	if ok {
+		resp.LastLogin = stubpb.Now()
+	}
+	return resp // want "non-optional message field 'User' not initialized in protobuf message 'stubpb.UserResponse'"
+}
+
+func setUnlessShadowedPanic(ok bool) *stubpb.UserResponse {
+	panic := func(error) {}
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	if !ok {
+		panic(errors.New("lookup failed"))
+	} else {
+		resp.User = newUser()
+	}
+	return resp // want "non-optional message field 'User' may be uninitialized on some paths in protobuf message 'stubpb.UserResponse': it is only set when !\\(!ok\\)"
+}
+
+// Switch clauses are branches; a switch without a default may take none of them
+func setInOneCase(kind string) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	switch kind {
+	case "user", "admin":
+		resp.User = newUser()
+	case "guest":
+	}
+	return resp // want "non-optional message field 'User' may be uninitialized on some paths in protobuf message 'stubpb.UserResponse': it is only set when kind == \"user\" \\|\\| kind == \"admin\""
+}
+
+func setInEveryCase(kind string) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	switch kind {
+	case "user":
+		resp.User = newUser()
+	case "admin":
+		fallthrough
+	case "root":
+		resp.User = newUser()
+		break
+	default:
+		resp.User = newUser()
+	}
+	return resp
+}
+
+func setInDefault(kind string) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	switch {
+	case kind == "guest":
+	default:
+		resp.User = newUser()
+	}
+	return resp // want "non-optional message field 'User' may be uninitialized on some paths in protobuf message 'stubpb.UserResponse': it is only set when !\\(kind == \"guest\"\\)"
+}
+
+func setInTypeSwitch(v any) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	switch u := v.(type) {
+	case *stubpb.User:
+		resp.User = u
+	case error:
+		return nil
+	}
+	return resp // want "non-optional message field 'User' may be uninitialized on some paths in protobuf message 'stubpb.UserResponse': it is only set when v is \\*stubpb.User"
+}
+
+func setInSelect(users <-chan *stubpb.User, done <-chan struct{}) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	select {
+	case resp.User = <-users:
+	case <-done:
+	}
+	return resp // want "non-optional message field 'User' may be uninitialized on some paths in protobuf message 'stubpb.UserResponse': it is only set when the select receives from users"
+}
+
+func setInBlock() *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	{
+		resp.User = newUser()
+	}
+	return resp
+}
+
+// A function literal may run anywhere before the return
+func setInClosure(load func(func())) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	load(func() {
+		resp.User = newUser()
+	})
+	return resp
+}
+
+// Named results are returned by naked returns
+func namedResult(ok bool) (resp *stubpb.UserResponse, err error) {
+	if !ok {
+		return
+	}
+	resp = &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	if ok {
+		return // want "non-optional message field 'User' not initialized in protobuf message 'stubpb.UserResponse'"
+	}
+	resp.User = newUser()
+	return
+}
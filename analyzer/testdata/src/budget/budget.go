@@ -0,0 +1,49 @@
+package budget
+
+import "stubpb"
+
+func user() *stubpb.User {
+	return &stubpb.User{Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+}
+
+// Within budget, the response is evaluated where it is returned
+func small() *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{}
+	resp.User = user()
+	resp.LastLogin = stubpb.Now()
+	return resp
+}
+
+// Over budget, the literal is checked where it is built, as without flow analysis
+func large(ids []string) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{} // want "non-optional message field 'User' not initialized" "non-optional message field 'LastLogin' not initialized"
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		if len(id) > 64 {
+			id = id[:64]
+		}
+	}
+	resp.User = user()
+	resp.LastLogin = stubpb.Now()
+	return resp
+}
+
+type server struct{ ids []string }
+
+// Methods fall back the same way, and are named with their receiver in the stats
+func (s *server) List() *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{} // want "non-optional message field 'User' not initialized" "non-optional message field 'LastLogin' not initialized"
+	for _, id := range s.ids {
+		if id == "" {
+			continue
+		}
+		if len(id) > 64 {
+			id = id[:64]
+		}
+	}
+	resp.User = user()
+	resp.LastLogin = stubpb.Now()
+	return resp
+}
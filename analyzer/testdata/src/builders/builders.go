@@ -0,0 +1,62 @@
+package builders
+
+import (
+	"context"
+	"errors"
+
+	"stubpb"
+)
+
+type server struct{}
+
+// The handler sets LastLogin, so the builder isn't reported for leaving it out
+func (s *server) buildResponse(ctx context.Context, req *stubpb.GetUserRequest) (*stubpb.UserResponse, error) { // want buildResponse:`returns\(initialized: User; unset: LastLogin\)`
+	if req.UserId == "" {
+		return nil, errors.New("missing user id")
+	}
+	resp := &stubpb.UserResponse{User: &stubpb.User{Id: req.UserId, Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}}
+	return resp, nil
+}
+
+func (s *server) GetUser(ctx context.Context, req *stubpb.GetUserRequest) (*stubpb.UserResponse, error) {
+	resp, err := s.buildResponse(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.LastLogin = stubpb.Now()
+	return resp, err
+}
+
+// Fields neither the builder nor the caller sets are reported where the caller returns
+func buildPartial(user *stubpb.User, recent bool) (*stubpb.UserResponse, error) { // want buildPartial:`returns\(initialized: ; unset: User\)`
+	if recent {
+		return &stubpb.UserResponse{LastLogin: stubpb.Now()}, nil
+	}
+	return &stubpb.UserResponse{}, nil
+}
+
+func (s *server) GetPartial(ctx context.Context, req *stubpb.GetUserRequest) (*stubpb.UserResponse, error) {
+	resp, err := buildPartial(nil, req.UserId != "")
+	return resp, err // want "non-optional message field 'User' not initialized in protobuf message 'stubpb.UserResponse' built by buildPartial\\(\\)" "field 'LastLogin' may be uninitialized on some paths in protobuf message 'stubpb.UserResponse' built by buildPartial\\(\\)$"
+}
+
+func (s *server) GetConditional(ctx context.Context, req *stubpb.GetUserRequest) (*stubpb.UserResponse, error) {
+	resp, err := buildPartial(nil, true)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserId != "" {
+		resp.User = &stubpb.User{Id: req.UserId, Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+	}
+	resp.LastLogin = stubpb.Now()
+	return resp, nil // want "field 'User' may be uninitialized on some paths in protobuf message 'stubpb.UserResponse' built by buildPartial\\(\\): it is only set when req.UserId != \"\""
+}
+
+// A helper whose result is forwarded is checked at its own returns
+func buildForwarded() (*stubpb.UserResponse, error) {
+	return &stubpb.UserResponse{LastLogin: stubpb.Now()}, nil // want "non-optional message field 'User' not initialized in protobuf message 'stubpb.UserResponse'"
+}
+
+func (s *server) GetForwarded(ctx context.Context, req *stubpb.GetUserRequest) (*stubpb.UserResponse, error) { // want GetForwarded:`returns\(initialized: LastLogin; unset: User; checked\)`
+	return buildForwarded()
+}
@@ -0,0 +1,25 @@
+package cgopkg
+
+// #include <stdlib.h>
+// static int answer(void) { return 42; }
+import "C"
+
+import "stubpb"
+
+func answer() int {
+	return int(C.answer())
+}
+
+func nilUser(resp *stubpb.UserResponse) {
+	resp.User = nil // want "nil assignment to non-optional message field 'User'"
+}
+
+func missing() *stubpb.UserResponse {
+	return &stubpb.UserResponse{LastLogin: stubpb.Now()} // want "non-optional message field 'User' not initialized"
+}
+
+func accepted(resp *stubpb.UserResponse) {
+	// Directives are read from cgo's copy, which keeps the comments
+	resp.User = nil //nonillinter:ignore filled in by the C side
+	_ = C.answer()
+}
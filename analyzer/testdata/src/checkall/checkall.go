@@ -0,0 +1,31 @@
+package checkall
+
+import "stubpb"
+
+func createUser() *stubpb.User {
+	return &stubpb.User{ // want "non-optional message field 'CreatedAt' not initialized in protobuf message 'stubpb.User'"
+		Id:      "123",
+		Address: nil, // want "nil assignment to non-optional message field 'Address' in protobuf message 'stubpb.User'"
+	}
+}
+
+func assignUser(u *stubpb.User) {
+	u.CreatedAt = nil // want "nil assignment to non-optional message field 'CreatedAt' in protobuf message 'stubpb.User'"
+}
+
+func trackedUser(addr *stubpb.Address) *stubpb.User {
+	u := &stubpb.User{Id: "1", Address: addr}
+	u.CreatedAt = stubpb.Now()
+	return u
+}
+
+// Nested literals are reported once, through the enclosing message
+func nested() *stubpb.UserResponse {
+	return &stubpb.UserResponse{
+		User: &stubpb.User{ // want "non-optional message field 'User.Address' not initialized in protobuf message '\\*stubpb.User'"
+			Id:        "1",
+			CreatedAt: stubpb.Now(),
+		},
+		LastLogin: stubpb.Now(),
+	}
+}
@@ -0,0 +1,14 @@
+package classify
+
+import "stubpb"
+
+func handler(req *stubpb.GetUserRequest) *stubpb.UserResponse {
+	return &stubpb.UserResponse{
+		User: &stubpb.User{
+			Id:        req.UserId,
+			Address:   &stubpb.Address{Location: &stubpb.Location{}},
+			CreatedAt: stubpb.Now(),
+		},
+		LastLogin: stubpb.Now(),
+	}
+}
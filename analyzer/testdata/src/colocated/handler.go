@@ -0,0 +1,18 @@
+// Package colocated generates its messages next to the handler using them
+package colocated
+
+type Server struct {
+	UnimplementedProfileServer
+}
+
+func (s *Server) GetProfile() (*GetProfileResponse, error) { // want GetProfile:`returns\(initialized: ; unset: Profile; checked\)`
+	return &GetProfileResponse{Profile: nil}, nil // want "nil assignment to non-optional message field 'Profile' in protobuf message 'GetProfileResponse'"
+}
+
+func (s *Server) Clear(resp *GetProfileResponse) {
+	resp.Profile = nil // want "nil assignment to non-optional message field 'Profile' in protobuf message 'GetProfileResponse'"
+}
+
+func (s *Server) GetDefault() *GetProfileResponse { // want GetDefault:`returns\(initialized: Profile; unset: ; checked\)`
+	return &GetProfileResponse{Profile: &Profile{Bio: "none"}}
+}
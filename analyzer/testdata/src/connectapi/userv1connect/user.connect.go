@@ -0,0 +1,24 @@
+package userv1connect
+
+import (
+	"context"
+
+	"api/library/bookpb"
+	"connectrpc.com/connect"
+	"stubpb"
+)
+
+// UserServiceHandler stands in for the protoc-gen-connect-go handler interface of
+//
+//	service UserService {
+//	  rpc GetUser(GetUserRequest) returns (UserResponse);
+//	  rpc GetBook(GetBookRequest) returns (Book);
+//	}
+type UserServiceHandler interface {
+	GetUser(context.Context, *connect.Request[stubpb.GetUserRequest]) (*connect.Response[stubpb.UserResponse], error)
+	GetBook(context.Context, *connect.Request[bookpb.GetBookRequest]) (*connect.Response[bookpb.Book], error)
+}
+
+func NewUserServiceHandler(svc UserServiceHandler) (string, any) {
+	return "/user.v1.UserService/", nil
+}
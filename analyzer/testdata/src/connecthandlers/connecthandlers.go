@@ -0,0 +1,41 @@
+package connecthandlers
+
+import (
+	"context"
+
+	"api/library/bookpb"
+	"connectapi/userv1connect"
+	"connectrpc.com/connect"
+	"stubpb"
+	"twirpapi/hatpb"
+)
+
+var _ userv1connect.UserServiceHandler = (*userServer)(nil)
+
+type userServer struct{}
+
+// Messages wrapped in a Connect response are checked where the response is returned
+func (s *userServer) GetUser(ctx context.Context, req *connect.Request[stubpb.GetUserRequest]) (*connect.Response[stubpb.UserResponse], error) {
+	if req.Msg.UserId == "" {
+		return connect.NewResponse(&stubpb.UserResponse{LastLogin: stubpb.Now()}), nil // want "non-optional message field 'User' not initialized in protobuf message 'stubpb.UserResponse'"
+	}
+	resp := &stubpb.UserResponse{}
+	resp.LastLogin = stubpb.Now()
+	return connect.NewResponse(resp), nil // want "non-optional message field 'User' not initialized in protobuf message 'stubpb.UserResponse'"
+}
+
+// Connect handlers are found through the generated interface, like gRPC ones
+func (s *userServer) GetBook(ctx context.Context, req *connect.Request[bookpb.GetBookRequest]) (*connect.Response[bookpb.Book], error) {
+	return &connect.Response[bookpb.Book]{Msg: &bookpb.Book{}}, nil // want "field 'Author' not initialized in protobuf message 'bookpb.Book'" "field 'CreateTime' not initialized in protobuf message 'bookpb.Book'"
+}
+
+// As are Twirp services
+type hatServer struct{}
+
+func (hatServer) GetBook(ctx context.Context, req *bookpb.GetBookRequest) (*bookpb.Book, error) { // want GetBook:`returns\(initialized: CreateTime; unset: Author; checked\)`
+	return &bookpb.Book{CreateTime: &bookpb.Timestamp{}}, nil // want "field 'Author' not initialized in protobuf message 'bookpb.Book'"
+}
+
+func serve() {
+	hatpb.NewLibraryServiceServer(hatServer{})
+}
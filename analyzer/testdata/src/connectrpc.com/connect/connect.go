@@ -0,0 +1,18 @@
+// Package connect stands in for the parts of connectrpc.com/connect that handlers use.
+package connect
+
+type Request[T any] struct {
+	Msg *T
+}
+
+type Response[T any] struct {
+	Msg *T
+}
+
+func NewRequest[T any](message *T) *Request[T] {
+	return &Request[T]{Msg: message}
+}
+
+func NewResponse[T any](message *T) *Response[T] {
+	return &Response[T]{Msg: message}
+}
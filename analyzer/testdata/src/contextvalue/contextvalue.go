@@ -0,0 +1,78 @@
+package contextvalue
+
+import (
+	"context"
+	"stubpb"
+	"time"
+)
+
+type userKey struct{}
+
+func ignoredOk(ctx context.Context) *stubpb.UserResponse {
+	u, _ := ctx.Value(userKey{}).(*stubpb.User)
+	return &stubpb.UserResponse{
+		User:      u, // want "variable 'u' from a context value assigned to non-optional message field 'User' in protobuf message 'stubpb.UserResponse' is nil when the key is missing; check ok or compare it against nil"
+		LastLogin: stubpb.Now(),
+	}
+}
+
+func uncheckedOk(ctx context.Context) {
+	u, ok := ctx.Value(userKey{}).(*stubpb.User)
+	_ = ok == ok
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	resp.User = u
+	_ = resp.User
+}
+
+func unusedOkAssignment(ctx context.Context) {
+	var u, _ = ctx.Value(userKey{}).(*stubpb.User)
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	resp.User = u // want "variable 'u' from a context value"
+	_ = resp.User
+}
+
+func okChecked(ctx context.Context) *stubpb.UserResponse {
+	u, ok := ctx.Value(userKey{}).(*stubpb.User)
+	if !ok {
+		return nil
+	}
+	return &stubpb.UserResponse{User: u, LastLogin: stubpb.Now()}
+}
+
+func nilChecked(ctx context.Context) *stubpb.UserResponse {
+	u, _ := ctx.Value(userKey{}).(*stubpb.User)
+	if u == nil {
+		return nil
+	}
+	return &stubpb.UserResponse{User: u, LastLogin: stubpb.Now()}
+}
+
+func singleValue(ctx context.Context) *stubpb.UserResponse {
+	// Panics on a missing key rather than producing nil
+	return &stubpb.UserResponse{User: ctx.Value(userKey{}).(*stubpb.User), LastLogin: stubpb.Now()}
+}
+
+// requestContext stands in for framework contexts that implement context.Context
+type requestContext struct {
+	values map[any]any
+}
+
+func (c *requestContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (c *requestContext) Done() <-chan struct{}       { return nil }
+func (c *requestContext) Err() error                  { return nil }
+func (c *requestContext) Value(key any) any           { return c.values[key] }
+
+func frameworkContext(c *requestContext) *stubpb.UserResponse {
+	u, _ := c.Value(userKey{}).(*stubpb.User)
+	return &stubpb.UserResponse{User: u, LastLogin: stubpb.Now()} // want "variable 'u' from a context value"
+}
+
+type cache struct{}
+
+func (cache) Value(key any) any { return nil }
+
+// Other values are reported as type assertions
+func notAContext(c cache) *stubpb.UserResponse {
+	u, _ := c.Value(userKey{}).(*stubpb.User)
+	return &stubpb.UserResponse{User: u, LastLogin: stubpb.Now()} // want "variable 'u' from a type assertion"
+}
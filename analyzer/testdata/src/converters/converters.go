@@ -0,0 +1,59 @@
+package converters
+
+import (
+	"internalpb"
+	"stubpb"
+)
+
+func toLocation(l *internalpb.Location) *stubpb.Location {
+	return &stubpb.Location{Latitude: l.Latitude, Longitude: l.Longitude}
+}
+
+func toAddress(a *internalpb.Address) *stubpb.Address {
+	return &stubpb.Address{Street: a.Street, Location: toLocation(a.GetLocation())}
+}
+
+func toUser(u *internalpb.User) *stubpb.User {
+	return &stubpb.User{Id: u.Id, Address: toAddress(u.Address), CreatedAt: u.CreatedAt}
+}
+
+// CreatedAt was added to both messages after the converter was written
+func toUserStale(u *internalpb.User) *stubpb.User { // want `converter toUserStale drops required field 'CreatedAt': 'internalpb.User' has it, but the returned 'stubpb.User' never sets it` toUserStale:`returns\(initialized: Address; unset: CreatedAt\)`
+	return &stubpb.User{Id: u.Id, Address: toAddress(u.Address)}
+}
+
+func toUserPlaceholder(u *internalpb.User) *stubpb.User {
+	return &stubpb.User{
+		Id:        u.Id,
+		Address:   &stubpb.Address{Location: &stubpb.Location{}}, // want `converter toUserPlaceholder sets required field 'Address' of 'stubpb.User' without reading u.Address`
+		CreatedAt: u.CreatedAt,
+	}
+}
+
+func toUserNil(u *internalpb.User) *stubpb.User { // want `converter toUserNil drops required field 'Address'` toUserNil:`returns\(initialized: CreatedAt; unset: Address\)`
+	return &stubpb.User{Id: u.Id, Address: nil, CreatedAt: u.CreatedAt}
+}
+
+func toUserByAssignment(u *internalpb.User) *stubpb.User {
+	out := &stubpb.User{Id: u.Id}
+	a := u.GetAddress()
+	out.Address = toAddress(a)
+	out.CreatedAt = u.CreatedAt
+	return out
+}
+
+func toUserWithError(u *internalpb.User) (*stubpb.User, error) {
+	if u == nil {
+		return nil, nil
+	}
+	return toUser(u), nil
+}
+
+// Not converters: the same type on both sides, and more than one parameter
+func clone(u *stubpb.User) *stubpb.User { // want clone:`returns\(initialized: ; unset: Address, CreatedAt\)`
+	return &stubpb.User{Id: u.Id}
+}
+
+func merge(u *internalpb.User, createdAt *stubpb.Timestamp) *stubpb.User {
+	return &stubpb.User{Id: u.Id, Address: toAddress(u.Address), CreatedAt: createdAt}
+}
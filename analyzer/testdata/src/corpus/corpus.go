@@ -0,0 +1,95 @@
+// Package corpus pins down the classification rules in messages.go and response.go.
+// It is part of the corpus used by the mutation-testing harness (mutation_test.go),
+// so every case here should fail if the corresponding check is inverted.
+package corpus
+
+import (
+	"google.golang.org/genproto/googleapis/type/date"
+	"stubpb"
+)
+
+// NotAMessage has the right name shape but no ProtoMessage method
+type NotAMessage struct {
+	Detail *stubpb.Location
+}
+
+// WrongSignature has a ProtoMessage method with the wrong signature
+type WrongSignature struct{}
+
+func (*WrongSignature) ProtoMessage(int) {}
+
+// StringValue mimics a scalar wrapper outside the well-known packages
+type StringValue struct {
+	Value string `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (*StringValue) ProtoMessage() {}
+
+type SearchResult struct {
+	Best     *stubpb.User            `protobuf:"bytes,1,opt,name=best,proto3" json:"best,omitempty"`
+	Day      *date.Date              `protobuf:"bytes,2,opt,name=day,proto3" json:"day,omitempty"`
+	Label    *StringValue            `protobuf:"bytes,3,opt,name=label,proto3" json:"label,omitempty"`
+	Others   []*stubpb.User          `protobuf:"bytes,4,rep,name=others,proto3" json:"others,omitempty"`
+	Plain    *NotAMessage            `json:"plain,omitempty"`
+	Odd      *WrongSignature         `json:"odd,omitempty"`
+	Optional **stubpb.Location       `json:"optional,omitempty"`
+	ByID     map[string]*stubpb.User `protobuf:"bytes,5,rep,name=by_id,proto3" json:"by_id,omitempty"`
+	hidden   *stubpb.User
+}
+
+func (*SearchResult) ProtoMessage() {}
+
+type SearchReply struct {
+	Result *SearchResult `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+}
+
+func (*SearchReply) ProtoMessage() {}
+
+// SearchRequest is not a response, so nothing in it is checked
+type SearchRequest struct {
+	Filter *stubpb.User `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+// NotAMessageResponse is response-named but not a message
+type NotAMessageResponse struct {
+	User *stubpb.User
+}
+
+func onlyRequiredFieldsReported() *SearchResult {
+	return &SearchResult{} // want "non-optional message field 'Best' not initialized" "non-optional message field 'Day' not initialized"
+}
+
+func nilRequiredFields() *SearchResult {
+	return &SearchResult{
+		Best:     nil, // want "nil assignment to non-optional message field 'Best'"
+		Day:      nil, // want "nil assignment to non-optional message field 'Day'"
+		Label:    nil,
+		Others:   nil,
+		Plain:    nil,
+		Odd:      nil,
+		Optional: nil,
+		ByID:     nil,
+	}
+}
+
+func replyIsResponse() *SearchReply {
+	return &SearchReply{} // want "non-optional message field 'Result' not initialized"
+}
+
+func requestNotChecked() *SearchRequest { // want requestNotChecked:`returns\(initialized: ; unset: Filter\)`
+	return &SearchRequest{}
+}
+
+func nonMessageResponseNotChecked() *NotAMessageResponse {
+	return &NotAMessageResponse{}
+}
+
+func assignmentToRequiredField(r *SearchReply) {
+	r.Result = nil // want "nil assignment to non-optional message field 'Result'"
+}
+
+func assignmentToRequestNotChecked(r *SearchRequest) {
+	r.Filter = nil
+}
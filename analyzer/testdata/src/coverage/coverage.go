@@ -0,0 +1,46 @@
+package coverage
+
+import "stubpb"
+
+func user() *stubpb.User {
+	return &stubpb.User{Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+}
+
+// Verified: a helper checked here counts like one with a fact
+func verified() *stubpb.UserResponse {
+	return &stubpb.UserResponse{User: user(), LastLogin: stubpb.Now()}
+}
+
+// Verified, reported all the same
+func verifiedMissing() *stubpb.UserResponse {
+	resp := new(stubpb.UserResponse)
+	resp.User = user()
+	return resp // want "non-optional message field 'LastLogin' not initialized"
+}
+
+// Trusted: a parameter can't be seen into
+func fromParameter(u *stubpb.User) *stubpb.UserResponse {
+	return &stubpb.UserResponse{User: u, LastLogin: stubpb.Now()}
+}
+
+// Trusted: a value assigned later from a channel
+func fromChannel(users chan *stubpb.User) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	resp.User = <-users
+	return resp
+}
+
+func fill(resp *stubpb.UserResponse) {}
+
+// Trusted: fill may set the fields
+func filled() *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{}
+	fill(resp)
+	return resp
+}
+
+// Suppressed
+func suppressed() *stubpb.UserResponse {
+	//nonillinter:ignore LastLogin is set by the interceptor
+	return &stubpb.UserResponse{User: user()}
+}
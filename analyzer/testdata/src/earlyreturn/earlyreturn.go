@@ -0,0 +1,93 @@
+package earlyreturn
+
+import (
+	"errors"
+
+	"stubpb"
+)
+
+func lookup(id string) (*stubpb.User, error) {
+	if id == "" {
+		return nil, errors.New("empty id")
+	}
+	return &stubpb.User{Id: id, Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}, nil
+}
+
+func earlyReturnBeforeAssignment(id string) (*stubpb.UserResponse, error) {
+	resp := &stubpb.UserResponse{}
+	u, err := lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	resp.User = u
+	resp.LastLogin = stubpb.Now()
+	return resp, nil
+}
+
+func returnBeforeAllFieldsSet(id string) (*stubpb.UserResponse, error) {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	u, err := lookup(id)
+	if err != nil {
+		return resp, err // want "non-optional message field 'User' not initialized"
+	}
+	resp.User = u
+	return resp, nil
+}
+
+func assignedOnlyInBranch(id string, ok bool) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	if ok {
+		u, _ := lookup(id)
+		resp.User = u
+	}
+	return resp // want "non-optional message field 'User' may be uninitialized on some paths in protobuf message 'stubpb.UserResponse': it is only set when ok"
+}
+
+func assignedInBranchBeforeReturn(id string, ok bool) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	if ok {
+		u, _ := lookup(id)
+		resp.User = u
+		return resp
+	}
+	resp.User = &stubpb.User{Id: id, Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+	return resp
+}
+
+func filledByHelper() *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{}
+	fill(resp)
+	return resp
+}
+
+func fill(resp *stubpb.UserResponse) {}
+
+func neverReturned() {
+	resp := &stubpb.UserResponse{} // want "non-optional message field 'LastLogin' not initialized"
+	resp.User = &stubpb.User{Id: "1", Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+	_ = resp.User
+}
+
+// new(T) builds a response field by field just like an empty literal
+func builtWithNew(id string) (*stubpb.UserResponse, error) {
+	resp := new(stubpb.UserResponse)
+	u, err := lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	resp.User = u
+	resp.LastLogin = stubpb.Now()
+	return resp, nil
+}
+
+func builtWithNewMissingField(id string) (*stubpb.UserResponse, error) {
+	resp := new(stubpb.UserResponse)
+	resp.LastLogin = stubpb.Now()
+	return resp, nil // want "non-optional message field 'User' not initialized in protobuf message 'stubpb.UserResponse'"
+}
+
+func neverReturnedNew() {
+	resp := new(stubpb.UserResponse) // want "non-optional message field 'User' not initialized"
+	resp.LastLogin = stubpb.Now()
+	_ = resp.LastLogin
+}
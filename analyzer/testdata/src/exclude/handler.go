@@ -0,0 +1,7 @@
+package exclude
+
+import "stubpb"
+
+func getUser() *stubpb.UserResponse {
+	return &stubpb.UserResponse{User: nil, LastLogin: stubpb.Now()} // want "nil assignment to non-optional message field 'User' in protobuf message 'stubpb.UserResponse'"
+}
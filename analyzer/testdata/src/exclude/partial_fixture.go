@@ -0,0 +1,8 @@
+package exclude
+
+import "stubpb"
+
+// Fixtures build partial responses on purpose; -exclude skips this file
+func partialResponse() *stubpb.UserResponse { // want partialResponse:`returns\(initialized: ; unset: LastLogin, User\)`
+	return &stubpb.UserResponse{User: nil}
+}
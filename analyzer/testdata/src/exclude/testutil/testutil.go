@@ -0,0 +1,8 @@
+// Package testutil is skipped by -exclude as a whole
+package testutil
+
+import "stubpb"
+
+func EmptyResponse() *stubpb.UserResponse { // want EmptyResponse:`returns\(initialized: ; unset: LastLogin, User\)`
+	return &stubpb.UserResponse{User: nil}
+}
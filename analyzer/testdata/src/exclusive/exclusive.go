@@ -0,0 +1,87 @@
+package exclusive
+
+import "stubpb"
+
+type Status struct {
+	Code    int32  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (*Status) ProtoMessage() {}
+
+type LookupResponse struct {
+	User      *stubpb.User      `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Error     *Status           `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	FetchedAt *stubpb.Timestamp `protobuf:"bytes,3,opt,name=fetched_at,json=fetchedAt,proto3" json:"fetched_at,omitempty"`
+}
+
+func (*LookupResponse) ProtoMessage() {}
+
+func newUser() *stubpb.User {
+	return &stubpb.User{Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+}
+
+// Either side of the pair alone is fine, and neither is required on its own
+func found() *LookupResponse {
+	return &LookupResponse{User: newUser(), FetchedAt: stubpb.Now()}
+}
+
+func notFound() *LookupResponse {
+	return &LookupResponse{Error: &Status{Code: 5}, FetchedAt: stubpb.Now()}
+}
+
+func both() *LookupResponse {
+	return &LookupResponse{User: newUser(), Error: &Status{}, FetchedAt: stubpb.Now()} // want "fields 'User' and 'Error' of protobuf message 'LookupResponse' are both set; exactly one should be set at each return"
+}
+
+func neither() *LookupResponse {
+	return &LookupResponse{FetchedAt: stubpb.Now()} // want "fields 'User' and 'Error' of protobuf message 'LookupResponse' are neither set"
+}
+
+// A nil value doesn't count as set
+func nilError() *LookupResponse {
+	return &LookupResponse{User: newUser(), Error: nil, FetchedAt: stubpb.Now()}
+}
+
+// Variables are judged by what is assigned on the path to each return
+func lookup(id string) (*LookupResponse, error) {
+	resp := &LookupResponse{FetchedAt: stubpb.Now()}
+	if id == "" {
+		resp.Error = &Status{Code: 3}
+		return resp, nil
+	}
+	if id == "missing" {
+		return resp, nil // want "fields 'User' and 'Error' of protobuf message 'LookupResponse' are neither set"
+	}
+	resp.User = newUser()
+	if id == "stale" {
+		resp.Error = &Status{Code: 9}
+		return resp, nil // want "fields 'User' and 'Error' of protobuf message 'LookupResponse' are both set"
+	}
+	return resp, nil
+}
+
+// Required fields outside the pair are still checked
+func missingFetchedAt() *LookupResponse {
+	return &LookupResponse{User: newUser()} // want "non-optional message field 'FetchedAt' not initialized"
+}
+
+// Responses filled in by another function can't be judged
+func fill(resp *LookupResponse) {}
+
+func filled() *LookupResponse {
+	resp := &LookupResponse{FetchedAt: stubpb.Now()}
+	fill(resp)
+	return resp
+}
+
+// One field per branch sets exactly one on every path
+func split(u *stubpb.User) *LookupResponse {
+	resp := &LookupResponse{FetchedAt: stubpb.Now()}
+	if u != nil {
+		resp.User = newUser()
+	} else {
+		resp.Error = &Status{Code: 5}
+	}
+	return resp
+}
@@ -0,0 +1,56 @@
+// Package exotic holds legal but unusual forms of the stores and values the checks
+// trace. It is the regression corpus FuzzExoticForms starts from.
+package exotic
+
+import "stubpb"
+
+type Users []*stubpb.User
+
+func parenthesized(resp *stubpb.UserResponse) {
+	(resp).User = nil      // want "nil assignment to non-optional message field 'User'"
+	(resp.User) = nil      // want "nil assignment to non-optional message field 'User'"
+	resp.LastLogin = (nil) // want "nil assignment to non-optional message field 'LastLogin'"
+}
+
+func converted(resp *stubpb.UserResponse, p *stubpb.UserResponse) {
+	resp.User = (*stubpb.User)(nil)      // want "nil assignment to non-optional message field 'User'"
+	(*stubpb.UserResponse)(p).User = nil // want "nil assignment to non-optional message field 'User'"
+	resp.User = ((*stubpb.User)((nil)))  // want "nil assignment to non-optional message field 'User'"
+	resp.RelatedUsers = Users{}
+}
+
+func indexed(resps []*stubpb.UserResponse, byID map[string]*stubpb.UserResponse) {
+	resps[0].User = nil       // want "nil assignment to non-optional message field 'User'"
+	(resps[1]).User = nil     // want "nil assignment to non-optional message field 'User'"
+	byID["a"].LastLogin = nil // want "nil assignment to non-optional message field 'LastLogin'"
+}
+
+// A call with a nil argument isn't a typed nil
+func newUser(template *stubpb.User) *stubpb.User {
+	return &stubpb.User{Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+}
+
+func notNil(resp *stubpb.UserResponse) {
+	resp.User = newUser(nil)
+}
+
+// Stores through parentheses count for the response built field by field
+func tracked(u *stubpb.User) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{}
+	(resp).User = u
+	(resp.LastLogin) = stubpb.Now()
+	return (resp)
+}
+
+func trackedMissing(u *stubpb.User) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{}
+	(resp).User = u
+	return (resp) // want "non-optional message field 'LastLogin' not initialized"
+}
+
+func nestedConverted() *stubpb.UserResponse {
+	return &stubpb.UserResponse{
+		User:      (*stubpb.User)(&stubpb.User{Address: (nil)}), // want "nil assignment to non-optional message field 'User.Address'" "non-optional message field 'User.CreatedAt' not initialized"
+		LastLogin: stubpb.Now(),
+	}
+}
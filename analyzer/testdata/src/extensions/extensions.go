@@ -0,0 +1,25 @@
+package extensions
+
+import (
+	"api/audit/auditpb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func nilExtension(resp *auditpb.AuditResponse) {
+	proto.SetExtension(resp, auditpb.E_Audit, nil) // want "nil assignment to message extension 'api.audit.audit' of protobuf message 'auditpb.AuditResponse'"
+}
+
+func nilNestedExtension(resp *auditpb.AuditResponse) {
+	var previous *auditpb.Audit
+	proto.SetExtension(resp, auditpb.E_Audit_Previous, previous) // want "nil assignment to message extension 'api.audit.Audit.previous'"
+}
+
+func incompleteExtension(resp *auditpb.AuditResponse) {
+	proto.SetExtension(resp, auditpb.E_Audit, &auditpb.Audit{}) // want "non-optional message field 'api.audit.audit.Actor' not initialized"
+}
+
+func validExtensions(resp *auditpb.AuditResponse) {
+	proto.SetExtension(resp, auditpb.E_Audit, &auditpb.Audit{Actor: &auditpb.Actor{Name: "svc"}})
+	proto.SetExtension(resp, auditpb.E_AuditNote, "migrated")
+}
@@ -0,0 +1,15 @@
+package factoryclient
+
+import (
+	"stubpb"
+	"userfactory"
+)
+
+// With -check-all-messages, NewUser's literal would have been reported in userfactory,
+// but userfactory is outside -include-packages, so its fact sends the report here
+func getUser() *stubpb.UserResponse {
+	return &stubpb.UserResponse{
+		User:      userfactory.NewUser("1"), // want "non-optional message field 'User.CreatedAt' not initialized in protobuf message '\\*stubpb.User' returned by userfactory.NewUser\\(\\)"
+		LastLogin: stubpb.Now(),
+	}
+}
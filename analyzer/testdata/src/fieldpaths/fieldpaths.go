@@ -0,0 +1,24 @@
+package fieldpaths
+
+import "stubpb"
+
+func nested() *stubpb.UserResponse {
+	return &stubpb.UserResponse{
+		User: &stubpb.User{
+			Id:        "x",
+			Address:   &stubpb.Address{Street: "Main"}, // want "non-optional message field 'User.Address.Location' not initialized"
+			CreatedAt: stubpb.Now(),
+		},
+		LastLogin: stubpb.Now(),
+	}
+}
+
+func usedVariable() *stubpb.UserResponse {
+	u := &stubpb.User{Id: "x", Address: nil, CreatedAt: stubpb.Now()}
+	return &stubpb.UserResponse{User: u, LastLogin: stubpb.Now()} // want "variable used in 'User' has nil in non-optional message field 'Address'"
+}
+
+func nilVariable() *stubpb.UserResponse {
+	var u *stubpb.User
+	return &stubpb.UserResponse{User: u, LastLogin: stubpb.Now()} // want "nil assignment to non-optional message field 'User'"
+}
@@ -0,0 +1,15 @@
+package findings
+
+import "stubpb"
+
+func assign(resp *stubpb.UserResponse) {
+	resp.User = nil // want "nil assignment to non-optional message field 'User'"
+}
+
+func keyValue() *stubpb.UserResponse {
+	return &stubpb.UserResponse{User: nil, LastLogin: stubpb.Now()} // want "nil assignment to non-optional message field 'User'"
+}
+
+func missing() *stubpb.UserResponse {
+	return &stubpb.UserResponse{LastLogin: stubpb.Now()} // want "non-optional message field 'User' not initialized"
+}
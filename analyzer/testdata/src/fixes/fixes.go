@@ -0,0 +1,40 @@
+package fixes
+
+import "stubpb"
+
+// Required sub-messages of the inserted message are filled in too
+func missingUser() *stubpb.UserResponse {
+	return &stubpb.UserResponse{LastLogin: stubpb.Now()} // want "non-optional message field 'User' not initialized"
+}
+
+func missingLocation() *stubpb.UserResponse {
+	return &stubpb.UserResponse{
+		User: &stubpb.User{
+			Address:   &stubpb.Address{}, // want "non-optional message field 'User.Address.Location' not initialized"
+			CreatedAt: stubpb.Now(),
+		},
+		LastLogin: stubpb.Now(),
+	}
+}
+
+// Multi-line literals get the field on a line of its own
+func missingLastLogin() *stubpb.UserResponse {
+	return &stubpb.UserResponse{ // want "non-optional message field 'LastLogin' not initialized"
+		User: &stubpb.User{
+			Address:   &stubpb.Address{Location: &stubpb.Location{}},
+			CreatedAt: stubpb.Now(),
+		},
+	}
+}
+
+// Fields filled in after the literal are fixed in the literal
+func missingAtReturn() *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{}
+	resp.User = &stubpb.User{Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+	return resp // want "non-optional message field 'LastLogin' not initialized"
+}
+
+// An empty well-known message is a placeholder, not a fix
+func missingDay() *stubpb.EventResponse {
+	return &stubpb.EventResponse{CreatedAt: stubpb.Now()} // want "non-optional message field 'Day' not initialized"
+}
@@ -0,0 +1,18 @@
+package gateway
+
+import "stubpb"
+
+func nilUser() *stubpb.UserResponse {
+	return &stubpb.UserResponse{User: nil, LastLogin: stubpb.Now()} // want `nil assignment to non-optional message field 'User' in protobuf message 'stubpb.UserResponse'; REST clients of the gRPC-Gateway will get no "user" key in the JSON response \(null with EmitUnpopulated\)`
+}
+
+func missingLogin(u *stubpb.User) *stubpb.UserResponse {
+	return &stubpb.UserResponse{User: u} // want `non-optional message field 'LastLogin' not initialized in protobuf message 'stubpb.UserResponse'; REST clients of the gRPC-Gateway will get no "lastLogin" key`
+}
+
+func nestedCreatedAt(addr *stubpb.Address) *stubpb.UserResponse {
+	return &stubpb.UserResponse{
+		User:      &stubpb.User{Id: "1", Address: addr}, // want `non-optional message field 'User.CreatedAt' not initialized in protobuf message '\*stubpb.User'; REST clients of the gRPC-Gateway will get no "createdAt" key`
+		LastLogin: stubpb.Now(),
+	}
+}
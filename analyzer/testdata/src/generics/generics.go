@@ -0,0 +1,67 @@
+package generics
+
+import "stubpb"
+
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+func Ok[T any](v T) Result[T] {
+	return Result[T]{Value: v}
+}
+
+type Pair[K comparable, V any] struct {
+	Key K
+	Val V
+}
+
+// Literals are checked wherever they appear, generic containers included
+func literals() []Pair[string, *stubpb.UserResponse] {
+	return []Pair[string, *stubpb.UserResponse]{{Key: "a", Val: &stubpb.UserResponse{LastLogin: stubpb.Now()}}} // want "non-optional message field 'User' not initialized in protobuf message 'stubpb.UserResponse'"
+}
+
+func wrapped() Result[*stubpb.UserResponse] {
+	return Ok(&stubpb.UserResponse{User: nil, LastLogin: stubpb.Now()}) // want "nil assignment to non-optional message field 'User'"
+}
+
+// A response handed to a wrapper isn't filled in there, so it is evaluated where the
+// wrapper is returned
+func viaCall(u *stubpb.User) Result[*stubpb.UserResponse] {
+	resp := &stubpb.UserResponse{}
+	resp.User = u
+	return Ok(resp) // want "non-optional message field 'LastLogin' not initialized in protobuf message 'stubpb.UserResponse'"
+}
+
+func viaLiteral(u *stubpb.User, fresh bool) Result[*stubpb.UserResponse] {
+	resp := &stubpb.UserResponse{User: u}
+	if fresh {
+		resp.LastLogin = stubpb.Now()
+		return Result[*stubpb.UserResponse]{Value: resp}
+	}
+	return Result[*stubpb.UserResponse]{Value: resp} // want "non-optional message field 'LastLogin' not initialized in protobuf message 'stubpb.UserResponse'"
+}
+
+// Functions that may fill the response in aren't wrappers
+type message interface {
+	ProtoMessage()
+}
+
+func Stamped[T message](v T) Result[T] {
+	return Result[T]{Value: v}
+}
+
+func fill(resp *stubpb.UserResponse) {
+	resp.LastLogin = stubpb.Now()
+}
+
+func filled(u *stubpb.User) Result[*stubpb.UserResponse] {
+	resp := &stubpb.UserResponse{User: u}
+	fill(resp)
+	return Ok(resp)
+}
+
+func stamped(u *stubpb.User) Result[*stubpb.UserResponse] {
+	resp := &stubpb.UserResponse{User: u}
+	return Stamped(resp)
+}
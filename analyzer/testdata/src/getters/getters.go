@@ -0,0 +1,55 @@
+package getters
+
+import "stubpb"
+
+func address() *stubpb.Address {
+	return &stubpb.Address{Location: &stubpb.Location{}}
+}
+
+func setThroughField() *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{User: &stubpb.User{Id: "1", CreatedAt: stubpb.Now()}, LastLogin: stubpb.Now()}
+	resp.User.Address = address()
+	return resp
+}
+
+// Generated accessors return the field itself, so the User literal gets its Address
+func setThroughGetter() *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{User: &stubpb.User{Id: "1", CreatedAt: stubpb.Now()}, LastLogin: stubpb.Now()}
+	resp.GetUser().Address = address()
+	return resp
+}
+
+func setThroughAlias() *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{User: &stubpb.User{Id: "1", CreatedAt: stubpb.Now()}, LastLogin: stubpb.Now()}
+	u := resp.GetUser()
+	u.Address = address()
+	return resp
+}
+
+func setDeeper() *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{User: &stubpb.User{Id: "1", Address: &stubpb.Address{}, CreatedAt: stubpb.Now()}, LastLogin: stubpb.Now()}
+	a := resp.GetUser().GetAddress()
+	a.Location = &stubpb.Location{}
+	return resp
+}
+
+func setToNil() *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{User: &stubpb.User{Id: "1", CreatedAt: stubpb.Now()}, LastLogin: stubpb.Now()} // want "non-optional message field 'User.Address' not initialized"
+	resp.GetUser().Address = nil
+	return resp
+}
+
+// u no longer aliases resp.User once it is reassigned
+func reassignedAlias(other *stubpb.User) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{User: &stubpb.User{Id: "1", CreatedAt: stubpb.Now()}, LastLogin: stubpb.Now()} // want "non-optional message field 'User.Address' not initialized"
+	u := resp.GetUser()
+	u = other
+	u.Address = address()
+	return resp
+}
+
+func otherResponse(other *stubpb.UserResponse) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{User: &stubpb.User{Id: "1", CreatedAt: stubpb.Now()}, LastLogin: stubpb.Now()} // want "non-optional message field 'User.Address' not initialized"
+	other.GetUser().Address = address()
+	return resp
+}
@@ -0,0 +1,10 @@
+// Package date is a minimal stand-in for the generated google.type.Date message.
+package date
+
+type Date struct {
+	Year  int32 `protobuf:"varint,1,opt,name=year,proto3" json:"year,omitempty"`
+	Month int32 `protobuf:"varint,2,opt,name=month,proto3" json:"month,omitempty"`
+	Day   int32 `protobuf:"varint,3,opt,name=day,proto3" json:"day,omitempty"`
+}
+
+func (*Date) ProtoMessage() {}
@@ -0,0 +1,21 @@
+// Package proto is a minimal stand-in for google.golang.org/protobuf/proto. The full
+// package depends on protobuf internals the testdata doesn't carry.
+package proto
+
+// Message is protoreflect.ProtoMessage in the full package
+type Message interface {
+	ProtoMessage()
+}
+
+// ExtensionType is protoreflect.ExtensionType in the full package
+type ExtensionType interface{}
+
+func SetExtension(m Message, xt ExtensionType, v interface{}) {}
+
+func GetExtension(m Message, xt ExtensionType) interface{} { return nil }
+
+func Clone(m Message) Message { return m }
+
+func Merge(dst, src Message) {}
+
+func Reset(m Message) {}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+
+	"api/library/bookpb"
+)
+
+// Generated server interfaces are found without -service-interfaces
+type libraryServer struct {
+	bookpb.UnimplementedLibraryServiceServer
+}
+
+func newBook() *bookpb.Book { // want newBook:`returns\(initialized: ; unset: Author, CreateTime\)`
+	return &bookpb.Book{}
+}
+
+func (s *libraryServer) build(req *bookpb.CreateBookRequest) (*bookpb.Book, error) { // want build:`returns\(initialized: ; unset: Author, CreateTime\)`
+	return &bookpb.Book{}, nil
+}
+
+// Responses built by helpers are checked where the handler returns them
+func (s *libraryServer) GetBook(ctx context.Context, req *bookpb.GetBookRequest) (*bookpb.Book, error) { // want GetBook:`returns\(initialized: ; unset: Author, CreateTime; checked\)`
+	return newBook(), nil // want "non-optional message field 'Author' not initialized in protobuf message 'bookpb.Book' \\(api.library\\) returned by newBook\\(\\) from handler GetBook" "field 'CreateTime' not initialized in protobuf message 'bookpb.Book' \\(api.library\\) returned by newBook"
+}
+
+// Fields the handler sets itself aren't reported
+func (s *libraryServer) CreateBook(ctx context.Context, req *bookpb.CreateBookRequest) (*bookpb.Book, error) {
+	book, err := s.build(req)
+	if err != nil {
+		return nil, err
+	}
+	book.CreateTime = &bookpb.Timestamp{}
+	return book, nil // want "non-optional message field 'Author' not initialized in protobuf message 'bookpb.Book' \\(api.library\\) returned by build\\(\\) from handler CreateBook"
+}
+
+// Results handed to another function may be filled in there
+type archiveServer struct {
+	bookpb.UnimplementedLibraryServiceServer
+}
+
+func fill(book *bookpb.Book) {
+	book.Author = &bookpb.Author{}
+	book.CreateTime = &bookpb.Timestamp{}
+}
+
+func (archiveServer) GetBook(ctx context.Context, req *bookpb.GetBookRequest) (*bookpb.Book, error) {
+	book := newBook()
+	fill(book)
+	return book, nil
+}
@@ -0,0 +1,32 @@
+package handlersonly
+
+import (
+	"context"
+
+	"handlersonly/userpb"
+	"stubpb"
+)
+
+type userServer struct {
+	userpb.UnimplementedUserServiceServer
+}
+
+// Findings outside handlers are dropped, so the helper's are reported at the handler
+func newResponse() *stubpb.UserResponse { // want newResponse:`returns\(initialized: ; unset: LastLogin, User; checked\)`
+	return &stubpb.UserResponse{}
+}
+
+func (s *userServer) GetUser(ctx context.Context, req *stubpb.GetUserRequest) (*stubpb.UserResponse, error) {
+	resp := newResponse()
+	resp.LastLogin = stubpb.Now()
+	return resp, nil // want "non-optional message field 'User' not initialized in protobuf message 'stubpb.UserResponse' returned by newResponse\\(\\) from handler GetUser"
+}
+
+// Findings in handlers are reported as usual
+func (s *userServer) ListUsers(ctx context.Context, req *stubpb.GetUserRequest) (*stubpb.UserResponse, error) { // want ListUsers:`returns\(initialized: User; unset: LastLogin; checked\)`
+	return &stubpb.UserResponse{User: &stubpb.User{}}, nil // want "non-optional message field 'LastLogin' not initialized in protobuf message 'stubpb.UserResponse'" "field 'User.Address' not initialized" "field 'User.CreatedAt' not initialized"
+}
+
+func audit() *stubpb.AuditResponse { // want audit:`returns\(initialized: ; unset: RecordedAt; checked\)`
+	return &stubpb.AuditResponse{}
+}
@@ -0,0 +1,28 @@
+package userpb
+
+import (
+	"context"
+
+	"stubpb"
+)
+
+// UserServiceServer stands in for the protoc-gen-go-grpc server interface of
+//
+//	service UserService {
+//	  rpc GetUser(GetUserRequest) returns (UserResponse);
+//	  rpc ListUsers(GetUserRequest) returns (UserResponse);
+//	}
+type UserServiceServer interface {
+	GetUser(context.Context, *stubpb.GetUserRequest) (*stubpb.UserResponse, error)
+	ListUsers(context.Context, *stubpb.GetUserRequest) (*stubpb.UserResponse, error)
+}
+
+type UnimplementedUserServiceServer struct{}
+
+func (UnimplementedUserServiceServer) GetUser(context.Context, *stubpb.GetUserRequest) (*stubpb.UserResponse, error) {
+	return nil, nil
+}
+
+func (UnimplementedUserServiceServer) ListUsers(context.Context, *stubpb.GetUserRequest) (*stubpb.UserResponse, error) {
+	return nil, nil
+}
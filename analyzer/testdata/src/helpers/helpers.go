@@ -0,0 +1,89 @@
+package helpers
+
+import (
+	"errors"
+
+	"stubpb"
+	"userfactory"
+)
+
+func createUser() *stubpb.User { // want createUser:`returns\(initialized: CreatedAt; unset: Address\)`
+	return &stubpb.User{Id: "1", CreatedAt: stubpb.Now()}
+}
+
+func completeUser() *stubpb.User {
+	return &stubpb.User{Address: &stubpb.Address{}, CreatedAt: stubpb.Now()}
+}
+
+// Fields are only reported when no return sets them
+func sometimesComplete(full bool) *stubpb.User { // want sometimesComplete:`returns\(initialized: ; unset: CreatedAt\)`
+	if full {
+		return &stubpb.User{Address: &stubpb.Address{}}
+	}
+	return &stubpb.User{Address: nil}
+}
+
+func builtUser() *stubpb.User { // want builtUser:`returns\(initialized: CreatedAt; unset: Address\)`
+	u := &stubpb.User{}
+	u.CreatedAt = stubpb.Now()
+	return u
+}
+
+func wrappedUser() *stubpb.User { // want wrappedUser:`returns\(initialized: CreatedAt; unset: Address\)`
+	return createUser()
+}
+
+func lookupUser(id string) (*stubpb.User, error) { // want lookupUser:`returns\(initialized: CreatedAt; unset: Address\)`
+	if id == "" {
+		return nil, errors.New("missing id")
+	}
+	return &stubpb.User{Id: id, CreatedAt: stubpb.Now()}, nil
+}
+
+// A parameter can't be followed, so nothing is known about the result
+func passThrough(u *stubpb.User) *stubpb.User {
+	return u
+}
+
+func getUser() *stubpb.UserResponse {
+	return &stubpb.UserResponse{
+		User:      createUser(), // want "non-optional message field 'User.Address' not initialized in protobuf message '\\*stubpb.User' returned by createUser\\(\\)"
+		LastLogin: stubpb.Now(),
+	}
+}
+
+func getCompleteUser() *stubpb.UserResponse {
+	return &stubpb.UserResponse{
+		User:      completeUser(),
+		LastLogin: stubpb.Now(),
+	}
+}
+
+func getWrappedUser() *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	resp.User = wrappedUser() // want "non-optional message field 'User.Address' not initialized in protobuf message '\\*stubpb.User' returned by wrappedUser\\(\\)"
+	return resp
+}
+
+func getLookedUpUser() (*stubpb.UserResponse, error) {
+	u, err := lookupUser("1")
+	if err != nil {
+		return nil, err
+	}
+	return &stubpb.UserResponse{User: u, LastLogin: stubpb.Now()}, nil // want "non-optional message field 'User.Address' not initialized in protobuf message '\\*stubpb.User' returned by lookupUser\\(\\)"
+}
+
+func getVariableUser() *stubpb.UserResponse {
+	u := builtUser()
+	return &stubpb.UserResponse{
+		User:      u, // want "non-optional message field 'User.Address' not initialized in protobuf message '\\*stubpb.User' returned by builtUser\\(\\)"
+		LastLogin: stubpb.Now(),
+	}
+}
+
+func getFactoryUser() *stubpb.UserResponse {
+	return &stubpb.UserResponse{
+		User:      userfactory.NewUser("1"), // want "non-optional message field 'User.CreatedAt' not initialized in protobuf message '\\*stubpb.User' returned by userfactory.NewUser\\(\\)"
+		LastLogin: stubpb.Now(),
+	}
+}
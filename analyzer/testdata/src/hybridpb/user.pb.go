@@ -0,0 +1,31 @@
+// Package hybridpb stands in for code protoc-gen-go generates with the hybrid API, whose
+// fields are exported next to the accessors of the opaque API. Debug was made optional
+// in the .proto file; User is still required.
+package hybridpb
+
+type User struct{}
+
+func (*User) ProtoMessage() {}
+
+type Debug struct{}
+
+func (*Debug) ProtoMessage() {}
+
+type UserResponse struct {
+	User  *User  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Debug *Debug `protobuf:"bytes,2,opt,name=debug" json:"debug,omitempty"`
+}
+
+func (*UserResponse) ProtoMessage() {}
+
+func (x *UserResponse) GetUser() *User { return x.User }
+
+func (x *UserResponse) HasUser() bool { return x.User != nil }
+
+func (x *UserResponse) ClearUser() { x.User = nil }
+
+func (x *UserResponse) GetDebug() *Debug { return x.Debug }
+
+func (x *UserResponse) HasDebug() bool { return x.Debug != nil }
+
+func (x *UserResponse) ClearDebug() { x.Debug = nil }
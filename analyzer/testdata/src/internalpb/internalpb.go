@@ -0,0 +1,41 @@
+// Package internalpb is an internal version of some stubpb messages, with the same
+// shape, for converter tests
+package internalpb
+
+import "stubpb"
+
+type Location struct {
+	Latitude  float64 `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude float64 `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"`
+}
+
+func (*Location) ProtoMessage() {}
+
+type Address struct {
+	Street   string    `protobuf:"bytes,1,opt,name=street,proto3" json:"street,omitempty"`
+	Location *Location `protobuf:"bytes,2,opt,name=location,proto3" json:"location,omitempty"`
+}
+
+func (*Address) ProtoMessage() {}
+
+func (x *Address) GetLocation() *Location {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+type User struct {
+	Id        string            `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Address   *Address          `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	CreatedAt *stubpb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) GetAddress() *Address {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package kinds
+
+import (
+	"google.golang.org/genproto/googleapis/type/date"
+	"stubpb"
+)
+
+func newUser() *stubpb.User { // want newUser:`returns\(initialized: ; unset: Address, CreatedAt\)`
+	return &stubpb.User{Id: "1"}
+}
+
+func nilLiteral(resp *stubpb.UserResponse) {
+	resp.User = nil // want "nil assignment to non-optional message field 'User'"
+}
+
+func nilConversion(resp *stubpb.UserResponse) {
+	resp.User = (*stubpb.User)(nil) // want "nil assignment to non-optional message field 'User'"
+}
+
+func nilVariable(resp *stubpb.UserResponse) {
+	var u *stubpb.User
+	resp.User = u // want "nil assignment to non-optional message field 'User'"
+}
+
+func nilZeroValue(resp *stubpb.UserResponse) {
+	var ts *stubpb.Timestamp
+	resp.LastLogin = ts // want "nil assignment to non-optional message field 'LastLogin'"
+}
+
+func missingField() *stubpb.UserResponse {
+	return &stubpb.UserResponse{LastLogin: stubpb.Now()} // want "non-optional message field 'User' not initialized"
+}
+
+func nestedNil(resp *stubpb.UserResponse) {
+	resp.User = &stubpb.User{Address: nil, CreatedAt: stubpb.Now()} // want "nil assignment to non-optional message field 'User.Address'"
+}
+
+func nestedMissing(resp *stubpb.UserResponse) {
+	resp.User = newUser() // want "non-optional message field 'User.Address' not initialized" "non-optional message field 'User.CreatedAt' not initialized"
+}
+
+func zeroValueLiteral() *stubpb.EventResponse {
+	return &stubpb.EventResponse{
+		Day:       &date.Date{}, // want "non-optional message field 'Day' in protobuf message 'stubpb.EventResponse' is set to an empty '\\*date.Date'"
+		CreatedAt: stubpb.Now(),
+	}
+}
+
+func zeroValueNew(resp *stubpb.EventResponse) {
+	resp.Day = new(date.Date) // want "non-optional message field 'Day' in protobuf message 'stubpb.EventResponse' is set to an empty '\\*date.Date'"
+}
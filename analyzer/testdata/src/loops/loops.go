@@ -0,0 +1,159 @@
+package loops
+
+import "stubpb"
+
+func newUser(id string) *stubpb.User {
+	return &stubpb.User{Id: id, Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+}
+
+func assignedInRangeOverConstant() *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	for i := range 3 {
+		resp.User = newUser(string(rune('a' + i)))
+	}
+	return resp
+}
+
+func assignedInRangeOverVariable(n int) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	for i := range n {
+		resp.User = newUser(string(rune('a' + i)))
+	}
+	return resp // want "non-optional message field 'User' may be uninitialized on some paths in protobuf message 'stubpb.UserResponse': it is only set when n > 0"
+}
+
+func assignedInRangeOverSlice(ids []string) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	for _, id := range ids {
+		resp.User = newUser(id)
+	}
+	return resp // want "non-optional message field 'User' may be uninitialized on some paths in protobuf message 'stubpb.UserResponse': it is only set when len\\(ids\\) > 0"
+}
+
+func assignedInForLoop(more func() bool) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	for more() {
+		resp.User = newUser("a")
+	}
+	return resp // want "non-optional message field 'User' may be uninitialized on some paths in protobuf message 'stubpb.UserResponse': it is only set when more\\(\\)"
+}
+
+func assignedInEndlessLoop(next func() (*stubpb.User, bool)) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	for {
+		u, ok := next()
+		resp.User = u
+		if !ok {
+			break
+		}
+	}
+	return resp
+}
+
+func assignedAfterBreak() *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	for range 3 {
+		break
+		resp.User = newUser("a")
+	}
+	return resp // want "non-optional message field 'User' not initialized"
+}
+
+func perIterationResponses(users []*stubpb.User) []*stubpb.UserResponse {
+	var out []*stubpb.UserResponse
+	for _, u := range users {
+		resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+		resp.User = u
+		out = append(out, resp)
+	}
+	return out
+}
+
+func lastFromRange(users []*stubpb.User) *stubpb.UserResponse {
+	var last *stubpb.User
+	for _, last = range users {
+	}
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	resp.User = last
+	return resp
+}
+
+func assignedInLoopBody(users []*stubpb.User) *stubpb.UserResponse {
+	var found *stubpb.User
+	for i := range len(users) {
+		if users[i].Id == "x" {
+			found = users[i]
+		}
+	}
+	return &stubpb.UserResponse{User: found, LastLogin: stubpb.Now()}
+}
+
+func neverAssigned() *stubpb.UserResponse {
+	var found *stubpb.User
+	for i := range 3 {
+		_ = i
+	}
+	return &stubpb.UserResponse{User: found, LastLogin: stubpb.Now()} // want "nil assignment to non-optional message field 'User'"
+}
+
+func breakBeforeAssignment(n int, u *stubpb.User) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	for {
+		if n > 0 {
+			break
+		}
+		resp.User = u
+		break
+	}
+	return resp // want "non-optional message field 'User' may be uninitialized on some paths in protobuf message 'stubpb.UserResponse': it is only set when the break at loops.go:106 is taken"
+}
+
+func breakOuterLoop(xs []int, u *stubpb.User) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+outer:
+	for range 3 {
+		for range xs {
+			break outer
+		}
+		resp.User = u
+	}
+	return resp // want "non-optional message field 'User' may be uninitialized on some paths in protobuf message 'stubpb.UserResponse': it is only set when the loop runs to the end"
+}
+
+func continueBeforeAssignment(n int, u *stubpb.User) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	for range 3 {
+		if n > 0 {
+			continue
+		}
+		resp.User = u
+	}
+	return resp // want "non-optional message field 'User' may be uninitialized on some paths in protobuf message 'stubpb.UserResponse': it is only set when the loop runs to the end"
+}
+
+func assignedBeforeEveryBreak(n int, u *stubpb.User) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	for {
+		if n > 0 {
+			resp.User = u
+			break
+		}
+		resp.User = u
+		break
+	}
+	return resp
+}
+
+func breakInSwitchCase(kind string, n int, u *stubpb.User) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	switch kind {
+	case "user":
+		if n > 0 {
+			break
+		}
+		resp.User = u
+	default:
+		resp.User = u
+	}
+	return resp // want "non-optional message field 'User' may be uninitialized on some paths in protobuf message 'stubpb.UserResponse': it is only set when kind == \"user\""
+}
@@ -0,0 +1,37 @@
+package mapfields
+
+import "stubpb"
+
+func validUser() *stubpb.User {
+	return &stubpb.User{Id: "1", Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+}
+
+func nilEntry() *stubpb.TeamResponse {
+	return &stubpb.TeamResponse{Members: map[string]*stubpb.User{
+		"lead": validUser(),
+		"new":  nil, // want `nil value for entry 'Members\["new"\]' of map field in protobuf message 'stubpb.TeamResponse'`
+	}}
+}
+
+func nilVariableEntry(resp *stubpb.TeamResponse) {
+	var u *stubpb.User
+	resp.Members = map[string]*stubpb.User{"lead": u} // want `nil value for entry 'Members\["lead"\]' of map field`
+}
+
+func incompleteEntry() *stubpb.TeamResponse {
+	return &stubpb.TeamResponse{Members: map[string]*stubpb.User{
+		"lead": {Id: "1", CreatedAt: stubpb.Now()}, // want `non-optional message field 'Members\["lead"\].Address' not initialized`
+	}}
+}
+
+func validEntries(lead *stubpb.User) *stubpb.TeamResponse {
+	return &stubpb.TeamResponse{
+		Members: map[string]*stubpb.User{"lead": lead, "first": validUser()},
+		Labels:  map[string]string{"team": ""},
+	}
+}
+
+// Map fields are optional unless -require-map-entries
+func noEntries() *stubpb.TeamResponse {
+	return &stubpb.TeamResponse{Id: "1", Members: map[string]*stubpb.User{}}
+}
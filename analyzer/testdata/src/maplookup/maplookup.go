@@ -0,0 +1,39 @@
+package maplookup
+
+import "stubpb"
+
+var usersByID = map[string]*stubpb.User{}
+
+func directLookup(id string) *stubpb.UserResponse {
+	return &stubpb.UserResponse{
+		User:      usersByID[id], // want "advisory: map lookup assigned to non-optional message field 'User'"
+		LastLogin: stubpb.Now(),
+	}
+}
+
+func lookupIntoVariable(id string) {
+	u := usersByID[id]
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	resp.User = u // want "advisory: variable 'u' from a map lookup assigned to non-optional message field 'User'"
+	_ = resp.User
+}
+
+func lookupWithNilCheck(id string) *stubpb.UserResponse {
+	u := usersByID[id]
+	if u == nil {
+		return nil
+	}
+	return &stubpb.UserResponse{User: u, LastLogin: stubpb.Now()}
+}
+
+func lookupWithCommaOk(id string) *stubpb.UserResponse {
+	u, ok := usersByID[id]
+	if !ok {
+		return nil
+	}
+	return &stubpb.UserResponse{User: u, LastLogin: stubpb.Now()}
+}
+
+func sliceIndex(users []*stubpb.User) *stubpb.UserResponse {
+	return &stubpb.UserResponse{User: users[0], LastLogin: stubpb.Now()}
+}
@@ -0,0 +1,13 @@
+package maxdepth
+
+import "stubpb"
+
+// With -max-depth 1, only the first nested literal is validated
+func shallow() *stubpb.UserResponse {
+	return &stubpb.UserResponse{
+		User: &stubpb.User{ // want "non-optional message field 'User.CreatedAt' not initialized"
+			Address: &stubpb.Address{},
+		},
+		LastLogin: stubpb.Now(),
+	}
+}
@@ -0,0 +1,36 @@
+package messagecopy
+
+import (
+	"fmt"
+	"stubpb"
+)
+
+func snapshot(resp *stubpb.UserResponse) *stubpb.UserResponse {
+	copied := *resp // want `protobuf message 'stubpb.UserResponse' is copied by value; copies share internal state and hide nil fields from analysis, use proto.Clone`
+	return &copied
+}
+
+func declared(u *stubpb.User) stubpb.User {
+	var v = *u // want `protobuf message 'stubpb.User' is copied by value`
+	return v
+}
+
+func returned(u *stubpb.User) stubpb.User {
+	return *u // want `protobuf message 'stubpb.User' is copied by value`
+}
+
+func argument(u *stubpb.User) {
+	fmt.Println(*u) // want `protobuf message 'stubpb.User' is copied by value`
+}
+
+func fieldAccess(resp *stubpb.UserResponse) *stubpb.User {
+	// Selecting through a dereference and assigning to one are not copies
+	*resp = stubpb.UserResponse{User: (*resp).User, LastLogin: stubpb.Now()}
+	return (*resp).User
+}
+
+type plain struct{ Name string }
+
+func plainStruct(p *plain) plain {
+	return *p
+}
@@ -0,0 +1,48 @@
+package mixedrpc
+
+import (
+	"avrouser"
+	"stubpb"
+	"thriftuser"
+)
+
+// Thrift fields declared required must be set; others may be nil
+func thriftResponse() *thriftuser.GetUserResponse {
+	return &thriftuser.GetUserResponse{Backup: nil} // want "non-optional message field 'User' not initialized in protobuf message 'thriftuser.GetUserResponse'"
+}
+
+func thriftNil() *thriftuser.GetUserResponse {
+	return &thriftuser.GetUserResponse{User: nil} // want "nil assignment to non-optional message field 'User' in protobuf message 'thriftuser.GetUserResponse'"
+}
+
+func thriftAssigned(u *thriftuser.User) *thriftuser.GetUserResponse {
+	resp := &thriftuser.GetUserResponse{User: u}
+	resp.User = nil // want "nil assignment to non-optional message field 'User'"
+	return resp
+}
+
+func thriftResult() *thriftuser.UserServiceGetUserResult {
+	return &thriftuser.UserServiceGetUserResult{}
+}
+
+// gogen-avro records need their record fields; unions with null may be nil
+func avroResponse() *avrouser.UserResponse {
+	return &avrouser.UserResponse{ // want "non-optional message field 'User' not initialized in protobuf message 'avrouser.UserResponse'"
+	}
+}
+
+func avroNested() *avrouser.UserResponse {
+	return &avrouser.UserResponse{
+		User: &avrouser.User{Manager: nil}, // want "non-optional message field 'User.Address' not initialized"
+	}
+}
+
+// avrogen writes unions with null as pointers, and required records as values
+func avrogenResponse() *avrouser.ProfileResponse {
+	return &avrouser.ProfileResponse{Backup: nil}
+}
+
+// Protobuf messages are checked as before
+func protobufResponse() *stubpb.UserResponse {
+	return &stubpb.UserResponse{LastLogin: stubpb.Now()} // want "non-optional message field 'User' not initialized"
+}
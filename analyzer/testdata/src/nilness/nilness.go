@@ -0,0 +1,66 @@
+package nilness
+
+import "stubpb"
+
+func lookup(id string) *stubpb.User {
+	if id == "" {
+		return nil
+	}
+	return &stubpb.User{Id: id, Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+}
+
+func storedUnderNilCheck(resp *stubpb.UserResponse, id string) {
+	u := lookup(id)
+	if u == nil {
+		resp.User = u // want "nil assignment to non-optional message field 'User'"
+	}
+}
+
+func storedAfterNonNilReturn(resp *stubpb.UserResponse, id string) {
+	u := lookup(id)
+	if u != nil {
+		return
+	}
+	resp.User = u // want "nil assignment to non-optional message field 'User'"
+}
+
+func nilOnLeft(resp *stubpb.UserResponse, id string) {
+	u := lookup(id)
+	if nil == u {
+		resp.User = u // want "nil assignment to non-optional message field 'User'"
+	}
+}
+
+func storedUnderNonNilCheck(resp *stubpb.UserResponse, id string) {
+	u := lookup(id)
+	if u != nil {
+		resp.User = u
+	}
+}
+
+func replacedWhenNil(resp *stubpb.UserResponse, id string) {
+	u := lookup(id)
+	if u == nil {
+		u = &stubpb.User{Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+	}
+	resp.User = u
+}
+
+// After the branches join, the check no longer decides the value
+func storedAfterJoin(resp *stubpb.UserResponse, id string, log func()) {
+	u := lookup(id)
+	if u == nil {
+		log()
+	}
+	resp.User = u
+}
+
+func allocatedOnEveryPath(resp *stubpb.UserResponse, admin bool) {
+	var u *stubpb.User
+	if admin {
+		u = &stubpb.User{Id: "admin", Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+	} else {
+		u = &stubpb.User{Id: "guest", Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+	}
+	resp.User = u
+}
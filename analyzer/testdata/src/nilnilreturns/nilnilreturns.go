@@ -0,0 +1,39 @@
+package nilnilreturns
+
+import (
+	"errors"
+
+	"stubpb"
+)
+
+func validResponse() *stubpb.UserResponse {
+	return &stubpb.UserResponse{
+		User:      &stubpb.User{Id: "1", Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()},
+		LastLogin: stubpb.Now(),
+	}
+}
+
+func GetUser(id string) (*stubpb.UserResponse, error) { // want GetUser:`returns\(initialized: LastLogin, User; unset: ; checked\)`
+	if id == "" {
+		return nil, nil // want `nil response 'stubpb.UserResponse' returned with a nil error; return a response or a non-nil error`
+	}
+	if id == "missing" {
+		return nil, errors.New("not found")
+	}
+	return validResponse(), nil
+}
+
+func handler() func() (*stubpb.UserResponse, error) {
+	return func() (*stubpb.UserResponse, error) {
+		return nil, nil // want `nil response 'stubpb.UserResponse' returned with a nil error`
+	}
+}
+
+// Only responses are held to the contract
+func findUser(id string) (*stubpb.User, error) {
+	return nil, nil
+}
+
+func lookup(id string) (*stubpb.UserResponse, bool) {
+	return nil, false
+}
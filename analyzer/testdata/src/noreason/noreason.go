@@ -0,0 +1,17 @@
+package noreason
+
+import "stubpb"
+
+func ignored(resp *stubpb.UserResponse) {
+	//nonillinter:ignore
+	resp.User = nil
+	resp.LastLogin = nil //nolint:nonillinter
+}
+
+func bareNolint(resp *stubpb.UserResponse) {
+	resp.User = nil //nolint
+}
+
+func notIgnored(resp *stubpb.UserResponse) {
+	resp.User = nil // want `nil assignment to non-optional message field 'User'`
+}
@@ -0,0 +1,35 @@
+package oneofs
+
+import "stubpb"
+
+func validUser() *stubpb.User {
+	return &stubpb.User{Id: "1", Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+}
+
+func nilCase() *stubpb.SearchResponse {
+	return &stubpb.SearchResponse{Result: &stubpb.SearchResponse_User{User: nil}} // want `nil assignment to message field 'User' of oneof case 'stubpb.SearchResponse_User' in protobuf message 'stubpb.SearchResponse'`
+}
+
+func nilVariableCase(resp *stubpb.SearchResponse) {
+	var u *stubpb.User
+	resp.Result = &stubpb.SearchResponse_User{u} // want `nil assignment to message field 'User' of oneof case`
+}
+
+func emptyCase(resp *stubpb.SearchResponse) {
+	resp.Result = &stubpb.SearchResponse_User{} // want `message field 'User' of oneof case 'stubpb.SearchResponse_User' not initialized in protobuf message 'stubpb.SearchResponse'`
+}
+
+func incompleteCase(resp *stubpb.SearchResponse) {
+	resp.Result = &stubpb.SearchResponse_User{User: &stubpb.User{Id: "1", CreatedAt: stubpb.Now()}} // want `non-optional message field 'User.Address' not initialized`
+}
+
+func validCases(resp *stubpb.SearchResponse) {
+	resp.Result = &stubpb.SearchResponse_User{User: validUser()}
+	resp.Result = &stubpb.SearchResponse_Query{}
+	resp.Result = nil
+}
+
+// Oneofs are optional unless -require-oneofs
+func noCase() *stubpb.SearchResponse {
+	return &stubpb.SearchResponse{Id: "1"}
+}
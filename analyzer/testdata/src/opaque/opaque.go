@@ -0,0 +1,43 @@
+package opaque
+
+import "opaquepb"
+
+// Builder literals are checked as the message they build
+func built() *opaquepb.UserResponse {
+	return opaquepb.UserResponse_builder{ // want "non-optional message field 'LastLogin' not initialized in protobuf message 'opaquepb.UserResponse'"
+		User: opaquepb.User_builder{Id: "u1", CreatedAt: opaquepb.Now()}.Build(),
+	}.Build()
+}
+
+func builtNil() *opaquepb.UserResponse {
+	return opaquepb.UserResponse_builder{User: nil, LastLogin: opaquepb.Now()}.Build() // want "nil assignment to non-optional message field 'User' in protobuf message 'opaquepb.UserResponse'"
+}
+
+func builtComplete() *opaquepb.UserResponse {
+	return opaquepb.UserResponse_builder{
+		User:      opaquepb.User_builder{Id: "u1", CreatedAt: opaquepb.Now()}.Build(),
+		LastLogin: opaquepb.Now(),
+	}.Build()
+}
+
+// Fields set after Build count at the return
+func builtThenSet() *opaquepb.UserResponse {
+	resp := opaquepb.UserResponse_builder{LastLogin: opaquepb.Now()}.Build()
+	resp.SetUser(opaquepb.User_builder{CreatedAt: opaquepb.Now()}.Build())
+	return resp
+}
+
+// Setters stand for the hidden fields
+func setters(user *opaquepb.User) *opaquepb.UserResponse {
+	resp := &opaquepb.UserResponse{}
+	resp.SetUser(user)
+	resp.SetDebug(nil)
+	return resp // want "non-optional message field 'LastLogin' not initialized in protobuf message 'opaquepb.UserResponse'"
+}
+
+func nilSetter() *opaquepb.UserResponse {
+	resp := &opaquepb.UserResponse{}
+	resp.SetLastLogin(opaquepb.Now())
+	resp.SetUser(nil) // want "nil assignment to non-optional message field 'User' in protobuf message 'opaquepb.UserResponse'"
+	return resp       // want "non-optional message field 'User' not initialized"
+}
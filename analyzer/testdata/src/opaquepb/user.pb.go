@@ -0,0 +1,62 @@
+// Package opaquepb is what protoc-gen-go writes for user.proto with the opaque API
+// (edition 2024): fields are hidden behind accessors and builders.
+package opaquepb
+
+type Timestamp struct {
+	state              struct{}
+	xxx_hidden_Seconds int64 `protobuf:"varint,1,opt,name=seconds,proto3"`
+}
+
+func (*Timestamp) ProtoMessage() {}
+
+func Now() *Timestamp { return &Timestamp{} }
+
+type User struct {
+	state                struct{}
+	xxx_hidden_Id        string     `protobuf:"bytes,1,opt,name=id,proto3"`
+	xxx_hidden_CreatedAt *Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3"`
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) SetCreatedAt(v *Timestamp) { x.xxx_hidden_CreatedAt = v }
+
+type User_builder struct {
+	_ [0]func()
+
+	Id        string
+	CreatedAt *Timestamp
+}
+
+func (b0 User_builder) Build() *User {
+	return &User{xxx_hidden_Id: b0.Id, xxx_hidden_CreatedAt: b0.CreatedAt}
+}
+
+type UserResponse struct {
+	state                struct{}
+	xxx_hidden_User      *User      `protobuf:"bytes,1,opt,name=user,proto3"`
+	xxx_hidden_LastLogin *Timestamp `protobuf:"bytes,2,opt,name=last_login,json=lastLogin,proto3"`
+	xxx_hidden_Debug     *Timestamp `protobuf:"bytes,3,opt,name=debug,proto3,oneof"`
+}
+
+func (*UserResponse) ProtoMessage() {}
+
+func (x *UserResponse) GetUser() *User { return x.xxx_hidden_User }
+
+func (x *UserResponse) SetUser(v *User) { x.xxx_hidden_User = v }
+
+func (x *UserResponse) SetLastLogin(v *Timestamp) { x.xxx_hidden_LastLogin = v }
+
+func (x *UserResponse) SetDebug(v *Timestamp) { x.xxx_hidden_Debug = v }
+
+type UserResponse_builder struct {
+	_ [0]func()
+
+	User      *User
+	LastLogin *Timestamp
+	Debug     *Timestamp
+}
+
+func (b0 UserResponse_builder) Build() *UserResponse {
+	return &UserResponse{xxx_hidden_User: b0.User, xxx_hidden_LastLogin: b0.LastLogin, xxx_hidden_Debug: b0.Debug}
+}
@@ -0,0 +1,37 @@
+package optionalfields
+
+import (
+	"stubpb"
+)
+
+// LastLogin is exempted for UserResponse, and Location for every message
+func getUser() *stubpb.UserResponse {
+	return &stubpb.UserResponse{
+		User: &stubpb.User{
+			Id:        "1",
+			Address:   &stubpb.Address{Street: "Main St"},
+			CreatedAt: &stubpb.Timestamp{},
+		},
+	}
+}
+
+// Fields not exempted are still required
+func getAnonymous() *stubpb.UserResponse {
+	return &stubpb.UserResponse{ // want "non-optional message field 'User' not initialized in protobuf message 'stubpb.UserResponse'"
+		LastLogin: nil,
+	}
+}
+
+// Exempted fields may still be set
+func getLocated() *stubpb.UserResponse {
+	return &stubpb.UserResponse{
+		User: &stubpb.User{
+			Id: "1",
+			Address: &stubpb.Address{
+				Location: &stubpb.Location{Latitude: 1},
+			},
+			CreatedAt: &stubpb.Timestamp{},
+		},
+		LastLogin: &stubpb.Timestamp{},
+	}
+}
@@ -0,0 +1,38 @@
+package optionality
+
+import "stubpb"
+
+// Proto3Response has an implicit-presence field and a proto3 `optional` one
+type Proto3Response struct {
+	User     *stubpb.User      `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Previous *stubpb.User      `protobuf:"bytes,2,opt,name=previous,proto3,oneof" json:"previous,omitempty"`
+	Choice   isProto3_Choice   `protobuf_oneof:"choice"`
+	Seen     []*stubpb.User    `protobuf:"bytes,4,rep,name=seen,proto3" json:"seen,omitempty"`
+	Location **stubpb.Location `json:"location,omitempty"`
+}
+
+func (*Proto3Response) ProtoMessage() {}
+
+type isProto3_Choice interface{ isProto3_Choice() }
+
+// Proto2Response has proto2 required and optional fields
+type Proto2Response struct {
+	User     *stubpb.User `protobuf:"bytes,1,req,name=user" json:"user,omitempty"`
+	Previous *stubpb.User `protobuf:"bytes,2,opt,name=previous" json:"previous,omitempty"`
+}
+
+func (*Proto2Response) ProtoMessage() {}
+
+func proto3() *Proto3Response {
+	return &Proto3Response{} // want "non-optional message field 'User' not initialized in protobuf message 'Proto3Response'$"
+}
+
+func proto3Nil(u *stubpb.User) {
+	resp := &Proto3Response{User: u}
+	resp.Previous = nil
+	_ = resp
+}
+
+func proto2() *Proto2Response {
+	return &Proto2Response{Previous: nil} // want "non-optional message field 'User' not initialized in protobuf message 'Proto2Response'$"
+}
@@ -0,0 +1,29 @@
+package debugpb
+
+// DebugInfo is only attached to responses in development builds.
+// nonil:optional
+type DebugInfo struct {
+	Trace *TraceInfo `protobuf:"bytes,1,opt,name=trace,proto3" json:"trace,omitempty"`
+}
+
+func (*DebugInfo) ProtoMessage() {}
+
+type TraceInfo struct {
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (*TraceInfo) ProtoMessage() {}
+
+type Owner struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (*Owner) ProtoMessage() {}
+
+type StatusResponse struct {
+	Owner *Owner     `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	Debug *DebugInfo `protobuf:"bytes,2,opt,name=debug,proto3" json:"debug,omitempty"`
+	Trace *TraceInfo `protobuf:"bytes,3,opt,name=trace,proto3" json:"trace,omitempty"`
+}
+
+func (*StatusResponse) ProtoMessage() {}
@@ -0,0 +1,21 @@
+package optionaltypes
+
+import "optionaltypes/debugpb"
+
+// DebugInfo is optional through the directive in its doc comment, TraceInfo through
+// -optional-types
+func status(owner *debugpb.Owner) *debugpb.StatusResponse {
+	return &debugpb.StatusResponse{Owner: owner}
+}
+
+func release() *debugpb.StatusResponse {
+	resp := &debugpb.StatusResponse{Owner: &debugpb.Owner{}}
+	resp.Debug = nil
+	resp.Trace = nil
+	return resp
+}
+
+// Other fields are still required
+func anonymous() *debugpb.StatusResponse {
+	return &debugpb.StatusResponse{Debug: &debugpb.DebugInfo{}} // want "non-optional message field 'Owner' not initialized in protobuf message 'debugpb.StatusResponse'"
+}
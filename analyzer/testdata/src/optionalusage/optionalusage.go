@@ -0,0 +1,20 @@
+package optionalusage
+
+import "hybridpb"
+
+func strip(resp *hybridpb.UserResponse) {
+	resp.Debug = nil // want `optional field 'Debug' of protobuf message 'hybridpb.UserResponse' is cleared by assigning nil; call resp.ClearDebug\(\)`
+}
+
+func debugged(resp *hybridpb.UserResponse) bool {
+	return resp.Debug != nil // want `presence of optional field 'Debug' of protobuf message 'hybridpb.UserResponse' is tested against nil; use resp.HasDebug\(\)`
+}
+
+func plain(resp *hybridpb.UserResponse) bool {
+	return nil == resp.GetDebug() // want `presence of optional field 'Debug' of protobuf message 'hybridpb.UserResponse' is tested against nil; use !resp.HasDebug\(\)`
+}
+
+// User is still required, so its nil comparisons are left alone
+func required(resp *hybridpb.UserResponse) bool {
+	return resp.User != nil && resp.GetUser() != nil
+}
@@ -0,0 +1,72 @@
+package options
+
+import "stubpb"
+
+// Option configures a UserResponse
+type Option func(*stubpb.UserResponse)
+
+func WithUser(u *stubpb.User) Option {
+	return func(r *stubpb.UserResponse) {
+		r.User = u
+	}
+}
+
+func WithLogin(t *stubpb.Timestamp) Option {
+	return func(r *stubpb.UserResponse) {
+		r.LastLogin = t
+	}
+}
+
+func WithNothing() Option {
+	return func(r *stubpb.UserResponse) {}
+}
+
+func NewResponse(opts ...Option) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{}
+	for _, o := range opts {
+		o(resp)
+	}
+	return resp
+}
+
+// NewLoggedInResponse sets LastLogin itself, so only User has to come from an option
+func NewLoggedInResponse(opts ...func(*stubpb.UserResponse)) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	for _, o := range opts {
+		o(resp)
+	}
+	return resp
+}
+
+func allOptions(u *stubpb.User) *stubpb.UserResponse {
+	return NewResponse(WithUser(u), WithLogin(stubpb.Now()))
+}
+
+func missingOption(u *stubpb.User) *stubpb.UserResponse {
+	return NewResponse(WithUser(u)) // want "non-optional message field 'LastLogin' not initialized in protobuf message '\\*stubpb.UserResponse' by NewResponse or the options passed to it"
+}
+
+func noOptions() *stubpb.UserResponse {
+	return NewResponse(WithNothing()) // want "field 'User' not initialized in protobuf message '\\*stubpb.UserResponse' by NewResponse" "field 'LastLogin' not initialized in protobuf message '\\*stubpb.UserResponse' by NewResponse"
+}
+
+func nilOption() *stubpb.UserResponse {
+	return NewResponse(WithUser(nil), WithLogin(stubpb.Now())) // want "nil assignment to non-optional message field 'User' in protobuf message '\\*stubpb.UserResponse' through option WithUser"
+}
+
+func constructorDefaults(u *stubpb.User) *stubpb.UserResponse {
+	return NewLoggedInResponse(WithUser(u))
+}
+
+func constructorDefaultsMissing() *stubpb.UserResponse {
+	return NewLoggedInResponse() // want "field 'User' not initialized in protobuf message '\\*stubpb.UserResponse' by NewLoggedInResponse"
+}
+
+func spread(opts []Option) *stubpb.UserResponse {
+	// Options we can't see are trusted
+	return NewResponse(opts...)
+}
+
+func opaqueOption(opt Option) *stubpb.UserResponse {
+	return NewResponse(opt)
+}
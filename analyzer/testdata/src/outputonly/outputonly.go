@@ -0,0 +1,49 @@
+package outputonly
+
+import "api/library/bookpb"
+
+// Clients leave OUTPUT_ONLY fields unset, so requests don't need them
+func createBook() *bookpb.CreateBookRequest {
+	return &bookpb.CreateBookRequest{
+		Book: &bookpb.Book{Author: &bookpb.Author{}},
+	}
+}
+
+// Required fields that aren't OUTPUT_ONLY are still checked in requests
+func createBookWithoutAuthor() *bookpb.CreateBookRequest {
+	return &bookpb.CreateBookRequest{
+		Book: &bookpb.Book{}, // want "non-optional message field 'Book.Author' not initialized"
+	}
+}
+
+// The server has to set them in responses
+func getBook() *bookpb.GetBookResponse {
+	return &bookpb.GetBookResponse{
+		Book: &bookpb.Book{Author: &bookpb.Author{}}, // want "non-optional message field 'Book.CreateTime' not initialized"
+	}
+}
+
+func createBookWithTime() *bookpb.CreateBookRequest {
+	return &bookpb.CreateBookRequest{
+		Book: &bookpb.Book{
+			Author:     &bookpb.Author{},
+			CreateTime: &bookpb.Timestamp{}, // want "OUTPUT_ONLY field 'CreateTime' of protobuf message 'bookpb.Book' \\(api.library\\) is set in a request"
+		},
+	}
+}
+
+// Packed field_behavior values on nested messages
+func updateRevision(author *bookpb.Author) *bookpb.UpdateRevisionRequest {
+	req := &bookpb.UpdateRevisionRequest{
+		Revision: &bookpb.Book_Revision{
+			Reviewer: author, // want "OUTPUT_ONLY field 'Reviewer' of protobuf message 'bookpb.Book_Revision' \\(api.library\\) is set in a request"
+		},
+	}
+	return req
+}
+
+func assignOutputOnly(req *bookpb.UpdateRevisionRequest, rev *bookpb.Book_Revision) {
+	req.Revision = rev
+	// Not recognizably part of a request
+	req.Revision.Reviewer = nil // want "nil assignment to non-optional message field 'Reviewer'"
+}
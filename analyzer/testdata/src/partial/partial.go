@@ -0,0 +1,73 @@
+package partial
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/multierr"
+	"stubpb"
+)
+
+func lookup(id string) (*stubpb.User, error) {
+	if id == "" {
+		return nil, errors.New("empty id")
+	}
+	return &stubpb.User{Id: id, Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}, nil
+}
+
+func joinedErrors(ids []string) (*stubpb.UserResponse, error) {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	var errs []error
+	for _, id := range ids {
+		u, err := lookup(id)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		resp.User = u
+	}
+	if err := errors.Join(errs...); err != nil {
+		return resp, err
+	}
+	return resp, nil // want "non-optional message field 'User' may be uninitialized on some paths in protobuf message 'stubpb.UserResponse': it is only set when len\\(ids\\) > 0"
+}
+
+func literalWithNewError() (*stubpb.UserResponse, error) {
+	return &stubpb.UserResponse{LastLogin: stubpb.Now()}, fmt.Errorf("user not found")
+}
+
+func literalWithJoinedError(err error) (*stubpb.UserResponse, error) {
+	return &stubpb.UserResponse{LastLogin: stubpb.Now()}, errors.Join(err, errors.New("lookup failed"))
+}
+
+func multierrCombine(a, b error) (*stubpb.UserResponse, error) {
+	combined := multierr.Combine(a, errors.New("partial results"))
+	return &stubpb.UserResponse{LastLogin: stubpb.Now()}, combined
+}
+
+func multierrAppendUnknown(a, b error) (*stubpb.UserResponse, error) {
+	// Both errors may be nil, so the response may be all the client gets
+	return &stubpb.UserResponse{LastLogin: stubpb.Now()}, multierr.Append(a, b) // want "non-optional message field 'User' not initialized"
+}
+
+func unknownError(err error) (*stubpb.UserResponse, error) {
+	return &stubpb.UserResponse{LastLogin: stubpb.Now()}, err // want "non-optional message field 'User' not initialized"
+}
+
+func nilError() (*stubpb.UserResponse, error) {
+	return &stubpb.UserResponse{LastLogin: stubpb.Now()}, nil // want "non-optional message field 'User' not initialized"
+}
+
+func explicitNilStillReported(err error) (*stubpb.UserResponse, error) {
+	if err != nil {
+		// Nil fields are wrong whatever the error; only unset fields are tolerated
+		return &stubpb.UserResponse{User: nil, LastLogin: stubpb.Now()}, err // want "nil assignment to non-optional message field 'User'"
+	}
+	return nil, errors.New("no user")
+}
+
+func reassignedError(a error) (*stubpb.UserResponse, error) {
+	err := errors.New("partial")
+	err = a
+	return &stubpb.UserResponse{LastLogin: stubpb.Now()}, err // want "non-optional message field 'User' not initialized"
+}
@@ -0,0 +1,82 @@
+package pooled
+
+import (
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"stubpb"
+)
+
+var respPool = sync.Pool{New: func() any { return new(stubpb.UserResponse) }}
+
+func user() *stubpb.User {
+	return &stubpb.User{Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+}
+
+// Without a reset, LastLogin is whatever the last request left there
+func notReset() *stubpb.UserResponse {
+	resp := respPool.Get().(*stubpb.UserResponse) // want "response 'resp' of type 'stubpb.UserResponse' taken from sync.Pool 'respPool' is not reset and keeps the fields of its last use, stale or nil; call resp.Reset\\(\\) and set each required field, or give the reason it is safe in an ignore directive"
+	resp.User = user()
+	return resp
+}
+
+// After a reset every required field is set again
+func reset() *stubpb.UserResponse {
+	resp := respPool.Get().(*stubpb.UserResponse)
+	resp.Reset()
+	resp.User = user()
+	resp.SetLastLogin(stubpb.Now())
+	return resp
+}
+
+func resetMissing() *stubpb.UserResponse {
+	resp := respPool.Get().(*stubpb.UserResponse) // want "non-optional message fields 'LastLogin' of response 'resp' of type 'stubpb.UserResponse' taken from sync.Pool 'respPool' are not set again after Reset"
+	proto.Reset(resp)
+	resp.User = user()
+	return resp
+}
+
+// Fields set before the reset are cleared by it
+func setBeforeReset(p *sync.Pool) *stubpb.UserResponse {
+	resp := p.Get().(*stubpb.UserResponse) // want "non-optional message fields 'User', 'LastLogin' of response 'resp' of type 'stubpb.UserResponse' taken from sync.Pool 'p' are not set again after Reset"
+	resp.User = user()
+	resp.LastLogin = stubpb.Now()
+	resp.Reset()
+	return resp
+}
+
+// A literal stored over the response is checked as a literal
+func overwritten() *stubpb.UserResponse {
+	resp := respPool.Get().(*stubpb.UserResponse)
+	*resp = stubpb.UserResponse{User: user()} // want "non-optional message field 'LastLogin' not initialized"
+	return resp
+}
+
+func fill(resp *stubpb.UserResponse) {
+	resp.User = user()
+	resp.LastLogin = stubpb.Now()
+}
+
+// A function it is handed to after the reset may fill it in
+func filledByHelper() *stubpb.UserResponse {
+	resp := respPool.Get().(*stubpb.UserResponse)
+	resp.Reset()
+	fill(resp)
+	return resp
+}
+
+// Putting it back doesn't fill it in
+func putBack() {
+	resp := respPool.Get().(*stubpb.UserResponse) // want "fields 'User', 'LastLogin' of response 'resp'"
+	defer respPool.Put(resp)
+	resp.Reset()
+}
+
+// A documented exception
+func documented() *stubpb.UserResponse {
+	//nonillinter:ignore the pool's New function and release() reset every field
+	resp := respPool.Get().(*stubpb.UserResponse)
+	resp.User = user()
+	resp.LastLogin = stubpb.Now()
+	return resp
+}
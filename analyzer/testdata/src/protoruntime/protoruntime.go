@@ -0,0 +1,68 @@
+package protoruntime
+
+import (
+	"google.golang.org/protobuf/proto"
+
+	"stubpb"
+)
+
+var defaultUser = &stubpb.User{Id: "guest", Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+
+// A template is completed by its clones
+var defaultResponse = &stubpb.UserResponse{User: defaultUser}
+
+// A clone starts out with the fields of its template
+func cloned() *stubpb.UserResponse {
+	resp := proto.Clone(defaultResponse).(*stubpb.UserResponse)
+	resp.LastLogin = stubpb.Now()
+	return resp
+}
+
+func clonedIncomplete() *stubpb.UserResponse {
+	resp := proto.Clone(defaultResponse).(*stubpb.UserResponse)
+	return resp // want "non-optional message field 'LastLogin' not initialized in protobuf message 'stubpb.UserResponse'"
+}
+
+// Templates that change may not hold their literal
+var changing = &stubpb.UserResponse{} // want "non-optional message field 'User' not initialized" "field 'LastLogin' not initialized"
+
+func reset() {
+	changing.User = nil // want "nil assignment to non-optional message field 'User'"
+}
+
+func clonedChanging() *stubpb.UserResponse {
+	return proto.Clone(changing).(*stubpb.UserResponse)
+}
+
+// Merging a known template sets its fields
+func merged() *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	proto.Merge(resp, defaultResponse)
+	return resp
+}
+
+func mergedIncomplete() *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{}
+	proto.Merge(resp, defaultResponse)
+	return resp // want "non-optional message field 'LastLogin' not initialized"
+}
+
+// A response only cloned from still has to be complete
+func clonedFrom() (*stubpb.UserResponse, *stubpb.UserResponse) {
+	resp := &stubpb.UserResponse{User: defaultUser}
+	return resp, proto.Clone(resp).(*stubpb.UserResponse) // want "non-optional message field 'LastLogin' not initialized"
+}
+
+// Generated setters count as assignments
+func withSetters(user *stubpb.User) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{}
+	resp.SetUser(user)
+	resp.SetLastLogin(stubpb.Now())
+	return resp
+}
+
+func withNilSetter() *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	resp.SetUser(nil) // want "nil assignment to non-optional message field 'User' in protobuf message 'stubpb.UserResponse'"
+	return resp       // want "non-optional message field 'User' not initialized"
+}
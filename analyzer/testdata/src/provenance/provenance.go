@@ -0,0 +1,31 @@
+package provenance
+
+import "stubpb"
+
+func declaredWithoutInitializer() *stubpb.UserResponse {
+	var u *stubpb.User
+	return &stubpb.UserResponse{
+		User:      u, // want `nil assignment to non-optional message field 'User' in protobuf message 'stubpb.UserResponse'; nil introduced at provenance.go:6 via declaration of u without initializer$`
+		LastLogin: stubpb.Now(),
+	}
+}
+
+func flowedThroughVariables(resp *stubpb.UserResponse) {
+	var u *stubpb.User = nil
+	var u2 = u
+	var u3 = u2
+	resp.User = u3 // want `; nil introduced at provenance.go:14 via nil initializer of u, flowed through u2 at provenance.go:15, flowed through u3 at provenance.go:16$`
+}
+
+func nestedVariable() *stubpb.UserResponse {
+	var created *stubpb.Timestamp
+	user := &stubpb.User{Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: created}
+	return &stubpb.UserResponse{
+		User:      user, // want `has nil in non-optional message field 'CreatedAt' of type 'stubpb.User'; nil introduced at provenance.go:21 via declaration of created without initializer$`
+		LastLogin: stubpb.Now(),
+	}
+}
+
+func nilLiteral(resp *stubpb.UserResponse) {
+	resp.User = nil // want `nil assignment to non-optional message field 'User' in protobuf message 'stubpb.UserResponse'$`
+}
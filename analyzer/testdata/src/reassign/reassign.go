@@ -0,0 +1,63 @@
+package reassign
+
+import "stubpb"
+
+func buildUser() *stubpb.User {
+	return &stubpb.User{Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+}
+
+func reassignedAfterNilDeclaration(resp *stubpb.UserResponse) {
+	var u *stubpb.User
+	u = buildUser()
+	resp.User = u
+}
+
+func nilAfterValidInit(resp *stubpb.UserResponse) {
+	u := buildUser()
+	u = nil
+	resp.User = u // want "nil assignment to non-optional message field 'User'"
+}
+
+func nilAfterDeclaredNil(resp *stubpb.UserResponse) {
+	var u *stubpb.User
+	u = buildUser()
+	u = nil
+	resp.User = u // want "nil assignment to non-optional message field 'User'"
+}
+
+// Only the literal that reaches the field is validated
+func replacedLiteral() *stubpb.UserResponse {
+	u := &stubpb.User{}
+	u = &stubpb.User{Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+	return &stubpb.UserResponse{User: u, LastLogin: stubpb.Now()}
+}
+
+func replacedWithIncompleteLiteral() *stubpb.UserResponse {
+	u := buildUser()
+	u = &stubpb.User{CreatedAt: stubpb.Now()}
+	return &stubpb.UserResponse{User: u, LastLogin: stubpb.Now()} // want "variable used in 'User' has uninitialized non-optional message field 'Address'"
+}
+
+func setOnOnePath(resp *stubpb.UserResponse, ok bool) {
+	var u *stubpb.User
+	if ok {
+		u = buildUser()
+	}
+	resp.User = u
+}
+
+func nilOnEveryPath(resp *stubpb.UserResponse, ok bool) {
+	var u *stubpb.User
+	if ok {
+		u = nil
+	}
+	resp.User = u // want "nil assignment to non-optional message field 'User'"
+}
+
+func setInLoop(resp *stubpb.UserResponse, users []*stubpb.User) {
+	var u *stubpb.User
+	for _, candidate := range users {
+		u = candidate
+	}
+	resp.User = u
+}
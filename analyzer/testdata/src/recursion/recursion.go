@@ -0,0 +1,59 @@
+package recursion
+
+import "stubpb"
+
+// Employee is required to have a manager, who is an Employee too
+type Employee struct {
+	Name    string       `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Manager *Employee    `protobuf:"bytes,2,opt,name=manager,proto3" json:"manager,omitempty"`
+	User    *stubpb.User `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`
+}
+
+func (*Employee) ProtoMessage() {}
+
+// Team leads back to itself through its lead's team
+type Team struct {
+	Lead *Lead `protobuf:"bytes,1,opt,name=lead,proto3" json:"lead,omitempty"`
+}
+
+func (*Team) ProtoMessage() {}
+
+type Lead struct {
+	Team *Team `protobuf:"bytes,1,opt,name=team,proto3" json:"team,omitempty"`
+}
+
+func (*Lead) ProtoMessage() {}
+
+type EmployeeResponse struct {
+	Employee *Employee `protobuf:"bytes,1,opt,name=employee,proto3" json:"employee,omitempty"`
+	Team     *Team     `protobuf:"bytes,2,opt,name=team,proto3" json:"team,omitempty"`
+}
+
+func (*EmployeeResponse) ProtoMessage() {}
+
+func newUser() *stubpb.User {
+	return &stubpb.User{Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+}
+
+// The chain of managers is reported once, where it ends
+func chain() *EmployeeResponse {
+	return &EmployeeResponse{
+		Employee: &Employee{
+			Name: "ic",
+			User: newUser(),
+			Manager: &Employee{ // want "recursive non-optional message field 'Employee.Manager.Manager' in protobuf message '\\*Employee'"
+				Name: "lead",
+				User: newUser(),
+			},
+		},
+		Team: &Team{Lead: &Lead{Team: nil}}, // want "recursive non-optional message field 'Team.Lead.Team' in protobuf message '\\*Lead': every message needs another through it"
+	}
+}
+
+// Other fields of a recursive message are still required
+func missingUser() *EmployeeResponse {
+	return &EmployeeResponse{
+		Employee: &Employee{Manager: nil}, // want "recursive non-optional message field 'Employee.Manager'" "non-optional message field 'Employee.User' not initialized"
+		Team:     &Team{Lead: &Lead{}},    // want "recursive non-optional message field 'Team.Lead.Team'"
+	}
+}
@@ -0,0 +1,34 @@
+package reflection
+
+import (
+	"reflect"
+	"stubpb"
+)
+
+func setByName(u *stubpb.User) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{User: u, LastLogin: stubpb.Now()}
+	reflect.ValueOf(resp).Elem().FieldByName("User").Set(reflect.ValueOf(u)) // want `advisory: reflective Set of field 'User' in protobuf message '\*stubpb.UserResponse' bypasses nil-safety checks; the value can't be verified`
+	return resp
+}
+
+func setThroughVariable(u *stubpb.User) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{User: u, LastLogin: stubpb.Now()}
+	v := reflect.Indirect(reflect.ValueOf(resp))
+	f := v.Field(1)
+	f.SetZero() // want `advisory: reflective SetZero of a field in protobuf message '\*stubpb.UserResponse'`
+	return resp
+}
+
+func nonResponse(u *stubpb.User) {
+	// Reflection on messages that aren't responses is not checked
+	reflect.ValueOf(u).Elem().FieldByName("Address").SetZero()
+}
+
+type config struct {
+	Name string
+}
+
+func plainStruct(c *config) {
+	reflect.ValueOf(c).Elem().FieldByName("Name").SetString("x")
+	reflect.ValueOf(c).Elem().Field(0).Set(reflect.ValueOf("y"))
+}
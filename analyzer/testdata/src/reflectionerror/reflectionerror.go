@@ -0,0 +1,11 @@
+package reflectionerror
+
+import (
+	"reflect"
+	"stubpb"
+)
+
+func clearUser(resp *stubpb.UserResponse) *stubpb.UserResponse {
+	reflect.ValueOf(resp).Elem().FieldByName("User").SetZero() // want `^reflective SetZero of field 'User' in protobuf message '\*stubpb.UserResponse'`
+	return resp
+}
@@ -0,0 +1,11 @@
+package reflectionerror
+
+import (
+	"reflect"
+	"stubpb"
+)
+
+// Tests poke at messages reflectively on purpose, so they stay advisory
+func clearUserForTest(resp *stubpb.UserResponse) {
+	reflect.ValueOf(resp).Elem().FieldByName("User").SetZero() // want `^advisory: reflective SetZero of field 'User'`
+}
@@ -0,0 +1,62 @@
+package repeated
+
+import "stubpb"
+
+func validUser() *stubpb.User {
+	return &stubpb.User{Id: "1", Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+}
+
+func nilElement() *stubpb.UserResponse {
+	return &stubpb.UserResponse{
+		User:         validUser(),
+		LastLogin:    stubpb.Now(),
+		RelatedUsers: []*stubpb.User{validUser(), nil}, // want `nil element 'RelatedUsers\[1\]' of repeated field in protobuf message 'stubpb.UserResponse'`
+	}
+}
+
+func nilVariableElement(resp *stubpb.UserResponse) {
+	var u *stubpb.User
+	resp.RelatedUsers = []*stubpb.User{u} // want `nil element 'RelatedUsers\[0\]' of repeated field`
+}
+
+func indexedElement(resp *stubpb.UserResponse) {
+	resp.RelatedUsers = []*stubpb.User{2: validUser(), nil} // want `nil element 'RelatedUsers\[3\]' of repeated field`
+}
+
+func incompleteElement(resp *stubpb.UserResponse) {
+	resp.RelatedUsers = []*stubpb.User{
+		validUser(),
+		{Id: "2", CreatedAt: stubpb.Now()}, // want `non-optional message field 'RelatedUsers\[1\].Address' not initialized`
+	}
+}
+
+// Repeated fields are optional, so empty and missing slices are fine
+func validElements(u *stubpb.User) *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{User: u, LastLogin: stubpb.Now(), RelatedUsers: []*stubpb.User{}}
+	resp.RelatedUsers = []*stubpb.User{u, validUser()}
+	resp.RelatedUsers = nil
+	return resp
+}
+
+func appendNil(resp *stubpb.UserResponse) {
+	resp.RelatedUsers = append(resp.RelatedUsers, validUser(), nil) // want `nil element appended to repeated field 'RelatedUsers' of protobuf message 'stubpb.UserResponse'`
+}
+
+func appendNilVariable(resp *stubpb.UserResponse) {
+	var u *stubpb.User
+	resp.RelatedUsers = append(resp.RelatedUsers, u) // want `nil element appended to repeated field 'RelatedUsers'`
+}
+
+func appendIncomplete(resp *stubpb.UserResponse) {
+	resp.RelatedUsers = append(resp.RelatedUsers, &stubpb.User{Id: "2", CreatedAt: stubpb.Now()}) // want `non-optional message field 'RelatedUsers\[\].Address' not initialized`
+}
+
+func appendSpread(resp *stubpb.UserResponse) {
+	resp.RelatedUsers = append(resp.RelatedUsers, []*stubpb.User{nil}...) // want `nil element 'RelatedUsers\[0\]' of repeated field`
+}
+
+func appendValid(resp *stubpb.UserResponse, others []*stubpb.User) *stubpb.UserResponse {
+	resp.RelatedUsers = append(resp.RelatedUsers, validUser())
+	resp.RelatedUsers = append(resp.RelatedUsers, others...)
+	return &stubpb.UserResponse{User: validUser(), LastLogin: stubpb.Now(), RelatedUsers: append(others, validUser())}
+}
@@ -0,0 +1,17 @@
+package requiredmaps
+
+import "stubpb"
+
+func noMap() *stubpb.TeamResponse {
+	return &stubpb.TeamResponse{Id: "1"} // want `non-optional message field 'Members' not initialized in protobuf message 'stubpb.TeamResponse'`
+}
+
+func emptyMap() *stubpb.TeamResponse {
+	return &stubpb.TeamResponse{Members: map[string]*stubpb.User{}} // want `map field 'Members' has no entries in protobuf message 'stubpb.TeamResponse'`
+}
+
+func mapSetLater(members map[string]*stubpb.User) *stubpb.TeamResponse {
+	resp := &stubpb.TeamResponse{Id: "1"}
+	resp.Members = members
+	return resp
+}
@@ -0,0 +1,25 @@
+package requiredoneofs
+
+import "stubpb"
+
+func noCase() *stubpb.SearchResponse {
+	return &stubpb.SearchResponse{Id: "1"} // want `non-optional message field 'Result' not initialized in protobuf message 'stubpb.SearchResponse'`
+}
+
+func queryCase() *stubpb.SearchResponse {
+	return &stubpb.SearchResponse{Result: &stubpb.SearchResponse_Query{Query: "name:ada"}}
+}
+
+func caseSetLater(query string) *stubpb.SearchResponse {
+	resp := &stubpb.SearchResponse{}
+	resp.Result = &stubpb.SearchResponse_Query{Query: query}
+	return resp
+}
+
+func caseSetOnSomePaths(query string) *stubpb.SearchResponse {
+	resp := &stubpb.SearchResponse{}
+	if query != "" {
+		resp.Result = &stubpb.SearchResponse_Query{Query: query}
+	}
+	return resp // want `non-optional message field 'Result' may be uninitialized on some paths`
+}
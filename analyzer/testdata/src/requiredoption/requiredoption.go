@@ -0,0 +1,32 @@
+package requiredoption
+
+import (
+	"api/accounts/accountpb"
+	"stubpb"
+)
+
+// Only the fields marked (api.annotations.required) = true are required
+func getAccount() *accountpb.GetAccountResponse {
+	return &accountpb.GetAccountResponse{
+		Account: &accountpb.Account{Owner: &accountpb.Owner{Name: "ops"}},
+	}
+}
+
+func getMissingAccount() *accountpb.GetAccountResponse {
+	return &accountpb.GetAccountResponse{ // want "non-optional message field 'Account' not initialized in protobuf message 'accountpb.GetAccountResponse'"
+		Banner: &accountpb.Image{},
+	}
+}
+
+func getOwnerless() *accountpb.GetAccountResponse {
+	return &accountpb.GetAccountResponse{
+		Account: &accountpb.Account{}, // want "non-optional message field 'Account.Owner' not initialized in protobuf message '\\*accountpb.Account'"
+	}
+}
+
+// Messages without an embedded descriptor keep the default
+func getUser() *stubpb.UserResponse {
+	return &stubpb.UserResponse{ // want "non-optional message field 'User' not initialized in protobuf message 'stubpb.UserResponse'"
+		LastLogin: &stubpb.Timestamp{},
+	}
+}
@@ -0,0 +1,35 @@
+package requirereason
+
+import "stubpb"
+
+func noReason(resp *stubpb.UserResponse) {
+	/* want `//nonillinter:ignore needs a reason` */ //nonillinter:ignore
+	resp.User = nil // want `nil assignment to non-optional message field 'User'`
+}
+
+func nolintNoReason(resp *stubpb.UserResponse) {
+	resp.User = nil /* want `nil assignment to non-optional message field 'User'` `//nolint:nonillinter needs a reason` */ //nolint:nonillinter
+}
+
+func literalNoReason() *stubpb.UserResponse {
+	/* want `//nonillinter:ignore needs a reason` */ //nonillinter:ignore
+	return &stubpb.UserResponse{
+		User:      nil, // want `nil assignment to non-optional message field 'User'`
+		LastLogin: nil, // want `nil assignment to non-optional message field 'LastLogin'`
+	}
+}
+
+// Directives without a reason are only reported when they cover a finding
+func nothingToSuppress(resp *stubpb.UserResponse) {
+	//nonillinter:ignore
+	resp.LastLogin = stubpb.Now()
+}
+
+// A bare //nolint covers every linter; whether it needs a reason is nolintlint's call
+func bareNolint(resp *stubpb.UserResponse) {
+	resp.User = nil //nolint
+}
+
+func withReason(resp *stubpb.UserResponse) {
+	resp.User = nil //nolint:nonillinter // the gateway fills in User
+}
@@ -0,0 +1,28 @@
+package responsenames
+
+import "stubpb"
+
+type GetUserOutput struct {
+	User *stubpb.User `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+}
+
+func (*GetUserOutput) ProtoMessage() {}
+
+type ListUsersOut struct {
+	First *stubpb.User `protobuf:"bytes,1,opt,name=first,proto3" json:"first,omitempty"`
+}
+
+func (*ListUsersOut) ProtoMessage() {}
+
+func output() *GetUserOutput {
+	return &GetUserOutput{User: nil} // want "nil assignment to non-optional message field 'User' in protobuf message 'GetUserOutput'"
+}
+
+func matchedByPattern() *ListUsersOut {
+	return &ListUsersOut{} // want "non-optional message field 'First' not initialized in protobuf message 'ListUsersOut'"
+}
+
+func noLongerResponse() *stubpb.UserResponse { // want noLongerResponse:`returns\(initialized: ; unset: LastLogin, User\)`
+	// Response is not among the configured suffixes
+	return &stubpb.UserResponse{User: nil}
+}
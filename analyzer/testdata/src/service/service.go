@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+
+	"api/library/bookpb"
+)
+
+type libraryServer struct {
+	bookpb.UnimplementedLibraryServiceServer
+}
+
+// Book isn't response-named, but GetBook returns it to clients
+func (s *libraryServer) GetBook(ctx context.Context, req *bookpb.GetBookRequest) (*bookpb.Book, error) { // want GetBook:`returns\(initialized: CreateTime; unset: Author; checked\)`
+	return &bookpb.Book{CreateTime: &bookpb.Timestamp{}}, nil // want "non-optional message field 'Author' not initialized in protobuf message 'bookpb.Book'"
+}
+
+func (s *libraryServer) CreateBook(ctx context.Context, req *bookpb.CreateBookRequest) (*bookpb.Book, error) { // want CreateBook:`returns\(initialized: Author; unset: CreateTime; checked\)`
+	book := &bookpb.Book{Author: &bookpb.Author{}}
+	if req.Book == nil {
+		return nil, nil
+	}
+	return book, nil // want "non-optional message field 'CreateTime' not initialized in protobuf message 'bookpb.Book'"
+}
+
+// Other methods returning the same message aren't service handlers
+func (s *libraryServer) draft() *bookpb.Book { // want draft:`returns\(initialized: ; unset: Author, CreateTime\)`
+	return &bookpb.Book{}
+}
+
+func newDraft() *bookpb.Book { // want newDraft:`returns\(initialized: ; unset: Author, CreateTime\)`
+	return &bookpb.Book{}
+}
+
+// Handlers are found through the interface, even when only registered through it
+type archiveServer struct {
+	bookpb.UnimplementedLibraryServiceServer
+}
+
+func (archiveServer) GetBook(ctx context.Context, req *bookpb.GetBookRequest) (*bookpb.Book, error) { // want GetBook:`returns\(initialized: CreateTime; unset: Author; checked\)`
+	book := &bookpb.Book{Author: nil, CreateTime: &bookpb.Timestamp{}} // want "nil assignment to non-optional message field 'Author' in protobuf message 'bookpb.Book'"
+	return book, nil
+}
+
+func register(srv bookpb.LibraryServiceServer) {}
+
+func init() {
+	register(archiveServer{})
+}
@@ -0,0 +1,37 @@
+package serviceroots
+
+import (
+	"api/orders/orderpb"
+	"api/payments/paymentpb"
+	"stubpb"
+)
+
+// Order isn't named like a response, but GetOrder returns it
+func order() *orderpb.Order {
+	return &orderpb.Order{Customer: &orderpb.Customer{}} // want "non-optional message field 'Total' not initialized in protobuf message 'orderpb.Order'"
+}
+
+// OrderEvent is sent on the WatchOrders stream
+func event() *orderpb.OrderEvent {
+	return &orderpb.OrderEvent{} // want "non-optional message field 'Order' not initialized in protobuf message 'orderpb.OrderEvent'"
+}
+
+// The client of a service the module only calls receives Receipts
+func receipt() *paymentpb.Receipt {
+	return &paymentpb.Receipt{} // want "non-optional message field 'Payment' not initialized in protobuf message 'paymentpb.Receipt'"
+}
+
+// No RPC returns an ArchiveOrderResponse, whatever its name
+func archived() *orderpb.ArchiveOrderResponse { // want archived:`returns\(initialized: ; unset: Order\)`
+	return &orderpb.ArchiveOrderResponse{}
+}
+
+// Requests aren't responses
+func lookup() *orderpb.LookupOrder { // want lookup:`returns\(initialized: ; unset: Customer\)`
+	return &orderpb.LookupOrder{}
+}
+
+// No service uses the messages of stubpb, so they are still classified by name
+func user() *stubpb.UserResponse {
+	return &stubpb.UserResponse{} // want "non-optional message field 'User' not initialized in protobuf message 'stubpb.UserResponse'" "non-optional message field 'LastLogin' not initialized in protobuf message 'stubpb.UserResponse'"
+}
@@ -0,0 +1,47 @@
+package setters
+
+import "stubpb"
+
+func Set[T any](dst *T, v T) { // want Set:`setter\(dst: 0, value: 1\)`
+	*dst = v
+}
+
+func SetUser(v *stubpb.User, dst **stubpb.User) { // want SetUser:`setter\(dst: 1, value: 0\)`
+	*dst = v
+}
+
+// setIfMissing only stores on some calls, so it isn't modeled
+func setIfMissing[T comparable](dst *T, v T) {
+	var zero T
+	if *dst == zero {
+		*dst = v
+	}
+}
+
+func validUser() *stubpb.User {
+	return &stubpb.User{Id: "1", Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+}
+
+func nilThroughSetter(resp *stubpb.UserResponse) {
+	Set(&resp.User, nil)                         // want "nil assignment to non-optional message field 'User'"
+	Set[*stubpb.Timestamp](&resp.LastLogin, nil) // want "nil assignment to non-optional message field 'LastLogin'"
+	SetUser(nil, &resp.User)                     // want "nil assignment to non-optional message field 'User'"
+}
+
+func nilVariableThroughSetter(resp *stubpb.UserResponse) {
+	var u *stubpb.User
+	Set(&resp.User, u) // want "nil assignment to non-optional message field 'User'"
+}
+
+func validThroughSetter(resp *stubpb.UserResponse) {
+	Set(&resp.User, validUser())
+	Set(&resp.LastLogin, stubpb.Now())
+	setIfMissing(&resp.User, nil)
+}
+
+func initializedBySetters() *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{}
+	Set(&resp.User, validUser())
+	Set(&resp.LastLogin, stubpb.Now())
+	return resp
+}
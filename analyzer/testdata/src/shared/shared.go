@@ -0,0 +1,35 @@
+package shared
+
+import "stubpb"
+
+var defaultResp = &stubpb.UserResponse{LastLogin: stubpb.Now(), User: anonymousUser()}
+
+func anonymousUser() *stubpb.User { return nil }
+
+type server struct {
+	cachedResp *stubpb.UserResponse
+}
+
+func (s *server) GetUser(u *stubpb.User) (*stubpb.UserResponse, error) {
+	s.cachedResp.User = u // want "response held in struct field 's.cachedResp' is mutated and returned on every call"
+	return s.cachedResp, nil
+}
+
+func GetDefault(ts *stubpb.Timestamp) *stubpb.UserResponse {
+	defaultResp.LastLogin = ts // want "response held in package-level variable 'defaultResp' is mutated and returned on every call"
+	return defaultResp
+}
+
+func (s *server) ReadOnly() *stubpb.UserResponse {
+	return s.cachedResp
+}
+
+func (s *server) Warm(u *stubpb.User) {
+	s.cachedResp.User = u
+}
+
+func (s *server) Fresh(u *stubpb.User) *stubpb.UserResponse { // want Fresh:`returns\(initialized: LastLogin, User; unset: ; checked\)`
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	resp.User = u
+	return resp
+}
@@ -0,0 +1,8 @@
+package skiptests
+
+import "stubpb"
+
+// Production code is checked as usual
+func lookup() *stubpb.UserResponse {
+	return &stubpb.UserResponse{User: &stubpb.User{Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}} // want "non-optional message field 'LastLogin' not initialized"
+}
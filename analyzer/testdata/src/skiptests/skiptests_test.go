@@ -0,0 +1,26 @@
+package skiptests
+
+import (
+	"context"
+	"testing"
+
+	"handlersonly/userpb"
+	"stubpb"
+)
+
+// A test building a response without its user on purpose
+func TestMissingUser(t *testing.T) {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	if resp.User != nil {
+		t.Fatal("expected no user")
+	}
+}
+
+type fakeServer struct {
+	userpb.UnimplementedUserServiceServer
+}
+
+// Fake servers in test files are skipped too
+func (fakeServer) GetUser(context.Context, *stubpb.GetUserRequest) (*stubpb.UserResponse, error) { // want GetUser:`returns\(initialized: ; unset: LastLogin, User\)`
+	return &stubpb.UserResponse{}, nil
+}
@@ -0,0 +1,127 @@
+// Package stubpb is a hand-written stand-in for protoc-gen-go output. It has the
+// same shape as generated messages (ProtoMessage methods, protobuf struct tags)
+// without depending on the protobuf runtime, so analysistest can load it.
+package stubpb
+
+import "google.golang.org/genproto/googleapis/type/date"
+
+type Timestamp struct {
+	Seconds int64 `protobuf:"varint,1,opt,name=seconds,proto3" json:"seconds,omitempty"`
+	Nanos   int32 `protobuf:"varint,2,opt,name=nanos,proto3" json:"nanos,omitempty"`
+}
+
+func (*Timestamp) ProtoMessage() {}
+
+func Now() *Timestamp { return &Timestamp{Seconds: 1} }
+
+type Location struct {
+	Latitude  float64 `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude float64 `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"`
+}
+
+func (*Location) ProtoMessage() {}
+
+type Address struct {
+	Street    string    `protobuf:"bytes,1,opt,name=street,proto3" json:"street,omitempty"`
+	Location  *Location `protobuf:"bytes,2,opt,name=location,proto3" json:"location,omitempty"`
+	Apartment *string   `protobuf:"bytes,3,opt,name=apartment,proto3,oneof" json:"apartment,omitempty"`
+}
+
+func (*Address) ProtoMessage() {}
+
+type User struct {
+	Id        string     `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Address   *Address   `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	CreatedAt *Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) GetAddress() *Address {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
+
+type UserResponse struct {
+	User         *User      `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	LastLogin    *Timestamp `protobuf:"bytes,2,opt,name=last_login,json=lastLogin,proto3" json:"last_login,omitempty"`
+	RelatedUsers []*User    `protobuf:"bytes,3,rep,name=related_users,json=relatedUsers,proto3" json:"related_users,omitempty"`
+}
+
+func (*UserResponse) ProtoMessage() {}
+
+func (x *UserResponse) Reset() { *x = UserResponse{} }
+
+func (x *UserResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+// Setters are generated by protoc-gen-go's builder API
+func (x *UserResponse) SetUser(v *User) {
+	x.User = v
+}
+
+func (x *UserResponse) SetLastLogin(v *Timestamp) {
+	x.LastLogin = v
+}
+
+type GetUserRequest struct {
+	UserId string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+}
+
+func (*GetUserRequest) ProtoMessage() {}
+
+type EventResponse struct {
+	Id        string     `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Day       *date.Date `protobuf:"bytes,2,opt,name=day,proto3" json:"day,omitempty"`
+	CreatedAt *Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+}
+
+func (*EventResponse) ProtoMessage() {}
+
+type AuditResponse struct {
+	Id         string     `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	RecordedAt *Timestamp `protobuf:"bytes,2,opt,name=recorded_at,json=recordedAt,proto3" json:"recorded_at,omitempty"`
+}
+
+func (*AuditResponse) ProtoMessage() {}
+
+type SearchResponse struct {
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Types that are assignable to Result:
+	//
+	//	*SearchResponse_User
+	//	*SearchResponse_Query
+	Result isSearchResponse_Result `protobuf_oneof:"result"`
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+type TeamResponse struct {
+	Id      string            `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Members map[string]*User  `protobuf:"bytes,2,rep,name=members,proto3" json:"members,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Labels  map[string]string `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (*TeamResponse) ProtoMessage() {}
+
+type isSearchResponse_Result interface {
+	isSearchResponse_Result()
+}
+
+type SearchResponse_User struct {
+	User *User `protobuf:"bytes,2,opt,name=user,proto3,oneof"`
+}
+
+type SearchResponse_Query struct {
+	Query string `protobuf:"bytes,3,opt,name=query,proto3,oneof"`
+}
+
+func (*SearchResponse_User) isSearchResponse_Result() {}
+
+func (*SearchResponse_Query) isSearchResponse_Result() {}
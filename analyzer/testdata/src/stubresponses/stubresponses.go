@@ -0,0 +1,30 @@
+package stubresponses
+
+import "stubpb"
+
+// Scaffolding: every field is a zero value or an empty message
+func getUser() (*stubpb.UserResponse, error) {
+	return &stubpb.UserResponse{User: &stubpb.User{}, LastLogin: &stubpb.Timestamp{}}, nil // want "stub response 'stubpb.UserResponse' returned: every field is a zero value or an empty message" "field 'User.Address' not initialized" "field 'User.CreatedAt' not initialized"
+}
+
+func getEvent() *stubpb.EventResponse {
+	return &stubpb.EventResponse{Id: "", CreatedAt: new(stubpb.Timestamp), Day: nil} // want "stub response 'stubpb.EventResponse' returned" "nil assignment to non-optional message field 'Day'"
+}
+
+// An empty response is reported as missing its fields already
+func getTeam() *stubpb.TeamResponse {
+	return &stubpb.TeamResponse{Id: "", Members: map[string]*stubpb.User{}}
+}
+
+// A real value anywhere means the response was filled in
+func getLoggedIn(user *stubpb.User) (*stubpb.UserResponse, error) {
+	return &stubpb.UserResponse{User: user, LastLogin: &stubpb.Timestamp{}}, nil
+}
+
+func getNamedEvent() *stubpb.EventResponse {
+	return &stubpb.EventResponse{Id: "launch", CreatedAt: &stubpb.Timestamp{}} // want "field 'Day' not initialized"
+}
+
+func getFilledUser() *stubpb.UserResponse {
+	return &stubpb.UserResponse{User: &stubpb.User{Id: "u1", Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}, LastLogin: &stubpb.Timestamp{}}
+}
@@ -0,0 +1,8 @@
+package stubresponses
+
+import "stubpb"
+
+// Tests build stub responses on purpose
+func fakeUser() (*stubpb.UserResponse, error) {
+	return &stubpb.UserResponse{User: &stubpb.User{}, LastLogin: &stubpb.Timestamp{}}, nil // want "field 'User.Address' not initialized" "field 'User.CreatedAt' not initialized"
+}
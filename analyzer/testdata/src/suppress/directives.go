@@ -0,0 +1,35 @@
+package suppress
+
+import "stubpb"
+
+func analyzerName(resp *stubpb.UserResponse) {
+	//nonillinter:ignore the gateway fills in User
+	resp.User = nil
+}
+
+func nolint(resp *stubpb.UserResponse) {
+	resp.User = nil      //nolint:nonillinter // the gateway fills in User
+	resp.LastLogin = nil //nolint:errcheck,nonillinter // set by the audit interceptor
+}
+
+func nolintOtherLinter(resp *stubpb.UserResponse) {
+	resp.User = nil //nolint:errcheck // want `nil assignment to non-optional message field 'User'`
+}
+
+func literal() *stubpb.UserResponse {
+	//nonillinter:ignore placeholder response for the migration, removed in the v2 handler
+	return &stubpb.UserResponse{
+		User: &stubpb.User{
+			Address: nil,
+		},
+		LastLogin: nil,
+	}
+}
+
+func literalAfterDirective() *stubpb.UserResponse {
+	resp := &stubpb.UserResponse{User: nil} //nonillinter:ignore the literal on this line only
+	_ = resp
+	return &stubpb.UserResponse{ // want `non-optional message field 'User' not initialized`
+		LastLogin: nil, // want `nil assignment to non-optional message field 'LastLogin'`
+	}
+}
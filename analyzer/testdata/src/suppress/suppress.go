@@ -0,0 +1,35 @@
+package suppress
+
+import "stubpb"
+
+func ownLine(resp *stubpb.UserResponse) {
+	//nonillinter:ignore User is filled in by the gateway before the response is sent
+	resp.User = nil
+}
+
+func trailing(resp *stubpb.UserResponse) {
+	resp.User = nil //nonillinter:ignore legacy clients expect an empty user here
+}
+
+func trailingDoesNotCoverNextLine(resp *stubpb.UserResponse) {
+	resp.LastLogin = stubpb.Now() //nonillinter:ignore only this line
+	resp.User = nil // want `nil assignment to non-optional message field 'User'`
+}
+
+func otherDirective(resp *stubpb.UserResponse) {
+	//nonillinter:ignored a different directive
+	resp.User = nil // want `nil assignment to non-optional message field 'User'`
+}
+
+func uncheckedRegion(resp *stubpb.UserResponse, kind int) {
+	resp.LastLogin = nil // want `nil assignment to non-optional message field 'LastLogin'`
+	//nonil:begin-unchecked legacy switch, tracked in the cleanup backlog
+	switch kind {
+	case 1:
+		resp.User = nil
+	case 2:
+		resp.LastLogin = nil
+	}
+	//nonil:end-unchecked
+	resp.User = nil // want `nil assignment to non-optional message field 'User'`
+}
@@ -0,0 +1,21 @@
+package suppress
+
+import "stubpb"
+
+func strayEnd(resp *stubpb.UserResponse) {
+	/* want `//nonil:end-unchecked has no matching //nonil:begin-unchecked` */ //nonil:end-unchecked
+	resp.User = nil // want `nil assignment to non-optional message field 'User'`
+}
+
+func nested(resp *stubpb.UserResponse) {
+	//nonil:begin-unchecked
+	/* want `//nonil:begin-unchecked inside the unchecked region begun at unbalanced.go:11; regions don't nest` */ //nonil:begin-unchecked
+	resp.User = nil
+	//nonil:end-unchecked
+	resp.User = nil // want `nil assignment to non-optional message field 'User'`
+}
+
+func unclosed(resp *stubpb.UserResponse) {
+	/* want `//nonil:begin-unchecked has no matching //nonil:end-unchecked; nothing is excluded` */ //nonil:begin-unchecked
+	resp.User = nil // want `nil assignment to non-optional message field 'User'`
+}
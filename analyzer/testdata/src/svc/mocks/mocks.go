@@ -0,0 +1,13 @@
+// Package mocks mimics a mockery-generated package that builds canned responses.
+package mocks
+
+import "stubpb"
+
+type MockUserService struct {
+	GetUserFunc func(id string) *stubpb.UserResponse
+}
+
+func (m *MockUserService) GetUser(id string) *stubpb.UserResponse { // want GetUser:`returns\(initialized: LastLogin, User; unset: ; checked\)`
+	user := &stubpb.User{Id: id}
+	return &stubpb.UserResponse{User: user, LastLogin: stubpb.Now()}
+}
@@ -0,0 +1,21 @@
+package syntheticoneof
+
+import "api/profile/profilepb"
+
+// Profile is proto3 optional, which only the descriptor says; Primary is required
+func missingPrimary() *profilepb.GetProfileResponse {
+	return &profilepb.GetProfileResponse{} // want "non-optional message field 'Primary' not initialized in protobuf message 'profilepb.GetProfileResponse' \\(api.profile\\)"
+}
+
+func withoutProfile() *profilepb.GetProfileResponse {
+	return &profilepb.GetProfileResponse{Primary: &profilepb.Profile{}}
+}
+
+func clearProfile(resp *profilepb.GetProfileResponse) {
+	resp.Profile = nil
+	resp.Kind = nil
+}
+
+func clearPrimary(resp *profilepb.GetProfileResponse) {
+	resp.Primary = nil // want "nil assignment to non-optional message field 'Primary' in protobuf message 'profilepb.GetProfileResponse' \\(api.profile\\)"
+}
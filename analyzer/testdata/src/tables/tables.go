@@ -0,0 +1,41 @@
+package tables
+
+import "stubpb"
+
+const getUser = "GetUser"
+
+type handler func() *stubpb.UserResponse
+
+type route struct {
+	method, path string
+	handle       handler
+}
+
+var handlers = map[string]handler{
+	getUser: func() *stubpb.UserResponse {
+		return &stubpb.UserResponse{User: nil, LastLogin: stubpb.Now()} // want `nil assignment to non-optional message field 'User' in protobuf message 'stubpb.UserResponse'; in the handler for "GetUser"`
+	},
+}
+
+var routes = []route{
+	{"GET", "/users", func() *stubpb.UserResponse {
+		return &stubpb.UserResponse{LastLogin: stubpb.Now()} // want `non-optional message field 'User' not initialized in protobuf message 'stubpb.UserResponse'; in the handler for "GET /users"`
+	}},
+	{method: "POST", path: "/users", handle: func() *stubpb.UserResponse {
+		resp := &stubpb.UserResponse{User: &stubpb.User{Id: "1", Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}}
+		resp.LastLogin = nil // want `nil assignment to non-optional message field 'LastLogin' .*; in the handler for "POST /users"`
+		return resp // want `'LastLogin' not initialized .*; in the handler for "POST /users"`
+	}},
+}
+
+var routesByName = map[string]route{
+	"list": {handle: func() *stubpb.UserResponse {
+		return &stubpb.UserResponse{User: nil, LastLogin: stubpb.Now()} // want `; in the handler for "list"`
+	}},
+}
+
+func notATable() handler {
+	return func() *stubpb.UserResponse {
+		return &stubpb.UserResponse{User: nil, LastLogin: stubpb.Now()} // want `nil assignment to non-optional message field 'User' in protobuf message 'stubpb.UserResponse'$`
+	}
+}
@@ -0,0 +1,36 @@
+package taggedstructs
+
+// Hand-written DTOs with gogo-compatible protobuf tags and no ProtoMessage method
+
+type Money struct {
+	Units int64 `protobuf:"varint,1,opt,name=units,proto3" json:"units,omitempty"`
+}
+
+type Invoice struct {
+	Id    string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Total *Money `protobuf:"bytes,2,opt,name=total,proto3" json:"total,omitempty"`
+}
+
+type InvoiceResponse struct {
+	Invoice *Invoice `proto:"invoice"`
+}
+
+type plain struct {
+	Name *string `json:"name"`
+}
+
+func build() *InvoiceResponse {
+	return &InvoiceResponse{
+		Invoice: &Invoice{ // want "non-optional message field 'Invoice.Total' not initialized"
+			Id: "inv-1",
+		},
+	}
+}
+
+func missing() *InvoiceResponse {
+	return &InvoiceResponse{} // want "non-optional message field 'Invoice' not initialized"
+}
+
+func notAMessage() plain {
+	return plain{}
+}
@@ -0,0 +1,37 @@
+package teststrict
+
+import (
+	"context"
+	"testing"
+
+	"handlersonly/userpb"
+	"stubpb"
+)
+
+// A test building a response without its user on purpose
+func TestMissingUser(t *testing.T) {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	if resp.User != nil {
+		t.Fatal("expected no user")
+	}
+}
+
+type fakeServer struct {
+	userpb.UnimplementedUserServiceServer
+}
+
+// The fake server's responses are checked like a real server's
+func (fakeServer) GetUser(context.Context, *stubpb.GetUserRequest) (*stubpb.UserResponse, error) { // want GetUser:`returns\(initialized: LastLogin; unset: User\)`
+	return &stubpb.UserResponse{LastLogin: stubpb.Now()}, nil // want "non-optional message field 'User' not initialized"
+}
+
+// Helpers building its responses are reported where it returns them
+func partialResponse() *stubpb.UserResponse { // want partialResponse:`returns\(initialized: ; unset: LastLogin, User\)`
+	return &stubpb.UserResponse{}
+}
+
+func (fakeServer) ListUsers(context.Context, *stubpb.GetUserRequest) (*stubpb.UserResponse, error) {
+	resp := partialResponse()
+	resp.User = &stubpb.User{Address: &stubpb.Address{Location: &stubpb.Location{}}, CreatedAt: stubpb.Now()}
+	return resp, nil // want "non-optional message field 'LastLogin' not initialized in protobuf message 'stubpb.UserResponse' returned by partialResponse\\(\\) from handler ListUsers"
+}
@@ -0,0 +1,25 @@
+// Package thriftuser is what the Thrift compiler writes for user.thrift:
+//
+//	struct User { 1: string id, 2: optional User manager }
+//	struct GetUserResponse { 1: required User user, 2: User backup, 3: optional User manager }
+package thriftuser
+
+type User struct {
+	ID      string `thrift:"id,1" db:"id" json:"id"`
+	Manager *User  `thrift:"manager,2" db:"manager" json:"manager,omitempty"`
+}
+
+func (p *User) IsSetManager() bool { return p.Manager != nil }
+
+type GetUserResponse struct {
+	User    *User `thrift:"user,1,required" db:"user" json:"user"`
+	Backup  *User `thrift:"backup,2" db:"backup" json:"backup"`
+	Manager *User `thrift:"manager,3,optional" db:"manager" json:"manager,omitempty"`
+}
+
+func (p *GetUserResponse) IsSetManager() bool { return p.Manager != nil }
+
+// UserServiceGetUserResult wraps the result of UserService.getUser on the wire
+type UserServiceGetUserResult struct {
+	Success *GetUserResponse `thrift:"success,0" db:"success" json:"success,omitempty"`
+}
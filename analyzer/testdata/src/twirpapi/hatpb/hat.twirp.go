@@ -0,0 +1,24 @@
+package hatpb
+
+import (
+	"context"
+
+	"api/library/bookpb"
+)
+
+// LibraryService stands in for the protoc-gen-twirp service interface of
+//
+//	service LibraryService {
+//	  rpc GetBook(GetBookRequest) returns (Book);
+//	}
+type LibraryService interface {
+	GetBook(context.Context, *bookpb.GetBookRequest) (*bookpb.Book, error)
+}
+
+type TwirpServer interface {
+	PathPrefix() string
+}
+
+func NewLibraryServiceServer(svc LibraryService, opts ...interface{}) TwirpServer {
+	return nil
+}
@@ -0,0 +1,63 @@
+package typeassert
+
+import (
+	"context"
+	"sync"
+
+	"stubpb"
+)
+
+var cache sync.Map
+
+func ignoredOk(id string) *stubpb.UserResponse {
+	v, _ := cache.Load(id)
+	u, _ := v.(*stubpb.User)
+	return &stubpb.UserResponse{
+		User:      u, // want "variable 'u' from a type assertion to \\*stubpb.User assigned to non-optional message field 'User' in protobuf message 'stubpb.UserResponse' is nil when the assertion fails; check ok first"
+		LastLogin: stubpb.Now(),
+	}
+}
+
+func assigned(item interface{}) {
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	u, _ := item.(*stubpb.User)
+	resp.User = u // want "variable 'u' from a type assertion"
+	_ = resp.User
+}
+
+func varDeclaration(item any) {
+	var u, _ = item.(*stubpb.User)
+	resp := &stubpb.UserResponse{LastLogin: stubpb.Now()}
+	resp.User = u // want "variable 'u' from a type assertion"
+	_ = resp.User
+}
+
+func okChecked(item any) *stubpb.UserResponse {
+	u, ok := item.(*stubpb.User)
+	if !ok {
+		return nil
+	}
+	return &stubpb.UserResponse{User: u, LastLogin: stubpb.Now()}
+}
+
+func nilChecked(item any) *stubpb.UserResponse {
+	u, _ := item.(*stubpb.User)
+	if u == nil {
+		return nil
+	}
+	return &stubpb.UserResponse{User: u, LastLogin: stubpb.Now()}
+}
+
+// The single-value form panics instead of yielding nil
+func singleValue(item any) *stubpb.UserResponse {
+	u := item.(*stubpb.User)
+	return &stubpb.UserResponse{User: u, LastLogin: stubpb.Now()}
+}
+
+type userKey struct{}
+
+// Context values are reported under their own category
+func contextValue(ctx context.Context) *stubpb.UserResponse {
+	u, _ := ctx.Value(userKey{}).(*stubpb.User)
+	return &stubpb.UserResponse{User: u, LastLogin: stubpb.Now()} // want "variable 'u' from a context value"
+}
@@ -0,0 +1,22 @@
+package typefilter
+
+import (
+	"api/library/bookpb"
+	"api/v1/userpb"
+	"stubpb"
+)
+
+// Included by its proto full name, api.v1.GetUserResponse
+func getUser() *userpb.GetUserResponse {
+	return &userpb.GetUserResponse{} // want "non-optional message field 'User' not initialized in protobuf message 'userpb.GetUserResponse' \\(api.v1\\)"
+}
+
+// Included by its Go type name, but excluded by name
+func user() *stubpb.UserResponse { // want user:`returns\(initialized: ; unset: LastLogin, User\)`
+	return &stubpb.UserResponse{}
+}
+
+// Not included
+func getBook() *bookpb.GetBookResponse { // want getBook:`returns\(initialized: ; unset: Book\)`
+	return &bookpb.GetBookResponse{}
+}
@@ -0,0 +1,29 @@
+package typenames
+
+import (
+	userv1 "api/v1/userpb"
+	userv2 "api/v2/userpb"
+	"stubpb"
+)
+
+func v1() *userv1.GetUserResponse {
+	return &userv1.GetUserResponse{User: nil} // want `nil assignment to non-optional message field 'User' in protobuf message 'v1/userpb.GetUserResponse' \(api.v1\)$`
+}
+
+func v2() *userv2.GetUserResponse {
+	return &userv2.GetUserResponse{User: nil} // want `nil assignment to non-optional message field 'User' in protobuf message 'v2/userpb.GetUserResponse' \(api.v2\)$`
+}
+
+func unique() *stubpb.UserResponse {
+	return &stubpb.UserResponse{User: nil, LastLogin: stubpb.Now()} // want `nil assignment to non-optional message field 'User' in protobuf message 'stubpb.UserResponse'$`
+}
+
+type LocalResponse struct {
+	User *userv1.User `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+}
+
+func (*LocalResponse) ProtoMessage() {}
+
+func local() *LocalResponse {
+	return &LocalResponse{User: nil} // want `nil assignment to non-optional message field 'User' in protobuf message 'LocalResponse'$`
+}
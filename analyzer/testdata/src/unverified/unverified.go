@@ -0,0 +1,48 @@
+package unverified
+
+import "stubpb"
+
+func loadUser() *stubpb.User { return nil }
+
+// A function value can't be followed to its returns
+var now = stubpb.Now
+
+func fromCall() *stubpb.UserResponse {
+	return &stubpb.UserResponse{
+		User:      loadUser(), // want "info: value of non-optional message field 'User' in protobuf message 'stubpb.UserResponse' comes from a function call"
+		LastLogin: now(),      // want "info: value of non-optional message field 'LastLogin' .* comes from a function call"
+	}
+}
+
+func fromParameter(u *stubpb.User, ts *stubpb.Timestamp) *stubpb.UserResponse {
+	return &stubpb.UserResponse{
+		User:      u,  // want "comes from parameter 'u'"
+		LastLogin: ts, // want "comes from parameter 'ts'"
+	}
+}
+
+func fromChannel(users chan *stubpb.User, ts *stubpb.Timestamp) {
+	resp := &stubpb.UserResponse{LastLogin: ts} // want "comes from parameter 'ts'"
+	resp.User = <-users                         // want "comes from a channel receive"
+	_ = resp.User
+}
+
+func fromVariable(ts *stubpb.Timestamp) *stubpb.UserResponse {
+	u := loadUser()
+	return &stubpb.UserResponse{
+		User:      u,  // want "comes from variable 'u' assigned from a function call"
+		LastLogin: ts, // want "comes from parameter 'ts'"
+	}
+}
+
+func verifiedLiteral() *stubpb.UserResponse {
+	ts := &stubpb.Timestamp{Seconds: 1}
+	return &stubpb.UserResponse{
+		User: &stubpb.User{
+			Id:        "1",
+			Address:   &stubpb.Address{Location: &stubpb.Location{}},
+			CreatedAt: ts,
+		},
+		LastLogin: ts,
+	}
+}
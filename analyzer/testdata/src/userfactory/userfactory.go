@@ -0,0 +1,9 @@
+// Package userfactory builds users for other packages; its helpers are summarized
+// by facts that travel to importers.
+package userfactory
+
+import "stubpb"
+
+func NewUser(id string) *stubpb.User { // want NewUser:`returns\(initialized: Address; unset: CreatedAt\)`
+	return &stubpb.User{Id: id, Address: &stubpb.Address{}}
+}
@@ -0,0 +1,27 @@
+package zerovalue
+
+import (
+	"google.golang.org/genproto/googleapis/type/date"
+	"stubpb"
+)
+
+func emptyLiteral() *stubpb.EventResponse {
+	return &stubpb.EventResponse{
+		Day:       &date.Date{}, // want "non-optional message field 'Day' in protobuf message 'stubpb.EventResponse' is set to an empty '\\*date.Date'"
+		CreatedAt: &stubpb.Timestamp{},
+	}
+}
+
+func newBuiltin() {
+	resp := &stubpb.EventResponse{
+		Day:       &date.Date{Year: 2024, Month: 1, Day: 1},
+		CreatedAt: stubpb.Now(),
+	}
+	resp.Day = new(date.Date) // want "non-optional message field 'Day' in protobuf message 'stubpb.EventResponse' is set to an empty"
+}
+
+func missingAndNil() {
+	_ = &stubpb.EventResponse{ // want "non-optional message field 'CreatedAt' not initialized"
+		Day: nil, // want "nil assignment to non-optional message field 'Day'"
+	}
+}
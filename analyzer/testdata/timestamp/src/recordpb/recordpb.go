@@ -0,0 +1,12 @@
+// Package recordpb is a hand-written stand-in for protoc-gen-go output with a
+// google.protobuf.Timestamp field, in the shape stubpb gives its messages.
+package recordpb
+
+import "google.golang.org/protobuf/types/known/timestamppb"
+
+type AuditResponse struct {
+	Id         string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	RecordedAt *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=recorded_at,json=recordedAt,proto3" json:"recorded_at,omitempty"`
+}
+
+func (*AuditResponse) ProtoMessage() {}
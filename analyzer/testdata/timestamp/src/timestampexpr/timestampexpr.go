@@ -0,0 +1,17 @@
+package timestampexpr
+
+import (
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+	"recordpb"
+)
+
+// The expression uses the file's name for timestamppb and imports time
+func nilInLiteral(id string) *recordpb.AuditResponse {
+	return &recordpb.AuditResponse{Id: id, RecordedAt: nil} // want "nil assignment to non-optional message field 'RecordedAt'"
+}
+
+// Only the nil literal is replaced; a nil variable needs a look at where it came from
+func nilVariable(id string) *recordpb.AuditResponse {
+	var recorded *tspb.Timestamp
+	return &recordpb.AuditResponse{Id: id, RecordedAt: recorded} // want "nil assignment to non-optional message field 'RecordedAt'.*nil introduced at"
+}
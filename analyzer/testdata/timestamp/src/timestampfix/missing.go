@@ -0,0 +1,8 @@
+package timestampfix
+
+import "recordpb"
+
+// The fix adds the timestamppb import
+func missingTimestamp(id string) *recordpb.AuditResponse {
+	return &recordpb.AuditResponse{Id: id} // want "non-optional message field 'RecordedAt' not initialized"
+}
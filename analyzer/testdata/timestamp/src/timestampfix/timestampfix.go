@@ -0,0 +1,18 @@
+package timestampfix
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"recordpb"
+)
+
+func nilInLiteral(id string) *recordpb.AuditResponse {
+	return &recordpb.AuditResponse{Id: id, RecordedAt: nil} // want "nil assignment to non-optional message field 'RecordedAt'"
+}
+
+func nilAssigned(resp *recordpb.AuditResponse) {
+	resp.RecordedAt = nil // want "nil assignment to non-optional message field 'RecordedAt'"
+}
+
+func alreadySet(id string) *recordpb.AuditResponse {
+	return &recordpb.AuditResponse{Id: id, RecordedAt: timestamppb.Now()}
+}
@@ -0,0 +1,90 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var (
+	// skipTests skips _test.go files, set via -skip-tests
+	skipTests bool
+
+	// testsStrict skips them apart from the fake servers they declare, set via -tests-strict
+	testsStrict bool
+)
+
+func init() {
+	Analyzer.Flags.BoolVar(&skipTests, "skip-tests", false,
+		"don't check _test.go files, whose tests often build partial messages on purpose")
+	Analyzer.Flags.BoolVar(&testsStrict, "tests-strict", false,
+		"don't check _test.go files apart from the methods of the fake and stub gRPC servers they declare, whose responses are checked like a real server's")
+}
+
+// Tests build partial messages on purpose: a request missing the field under test, or
+// the response a mocked dependency returns. With -skip-tests the package's _test.go
+// files are skipped like its generated files; see generated.go. The fake servers tests
+// declare stand in for a real service, though, and handing a client a response the
+// real server would never send makes the test pass for the wrong reason. With
+// -tests-strict the test files are skipped apart from the methods through which their
+// types implement a service interface; see service.go.
+
+// testFiles returns the _test.go files of the package when -skip-tests or -tests-strict
+// skips them, or none
+func testFiles(pass *analysis.Pass) map[*token.File]bool {
+	tests := make(map[*token.File]bool)
+	if !skipTests && !testsStrict {
+		return tests
+	}
+	for _, file := range pass.Files {
+		tf := pass.Fset.File(file.Pos())
+		if tf != nil && strings.HasSuffix(tf.Name(), "_test.go") {
+			tests[tf] = true
+		}
+	}
+	return tests
+}
+
+// keepFakeServers wraps pass.Report under -tests-strict to drop the findings in test
+// files outside the methods of their fake servers. Helpers building those servers'
+// responses aren't checked on their own, so the fields they leave unset are reported
+// where a method returns the result, as under -grpc-handlers-only; see handlers.go.
+func keepFakeServers(pass *analysis.Pass, tests map[*token.File]bool) {
+	if !testsStrict || len(tests) == 0 {
+		return
+	}
+	methods := serviceMethods(pass)
+	var fakes []ast.Node
+	for _, file := range pass.Files {
+		if !tests[pass.Fset.File(file.Pos())] {
+			continue
+		}
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			if obj, ok := pass.TypesInfo.Defs[fn.Name].(*types.Func); ok && methods[obj] {
+				log().Debug("checking fake server method in test file", "method", obj.FullName())
+				fakes = append(fakes, fn)
+			}
+		}
+	}
+
+	report := pass.Report
+	pass.Report = func(d analysis.Diagnostic) {
+		if !tests[pass.Fset.File(d.Pos)] {
+			report(d)
+			return
+		}
+		for _, fn := range fakes {
+			if fn.Pos() <= d.Pos && d.Pos < fn.End() {
+				report(d)
+				return
+			}
+		}
+	}
+}
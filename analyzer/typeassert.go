@@ -0,0 +1,76 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// checkTypeAssertion reports a message taken out of an interface with the comma-ok type
+// assertion and assigned to a required field without checking it:
+//
+//	u, _ := v.(*pb.User)
+//	resp.User = u
+//
+// A failed assertion yields the zero value, a nil *pb.User. The value is considered
+// checked when it is compared against nil or the ok result is used. Assertions on
+// ctx.Value are reported as context values instead; see contextvalue.go. The
+// single-value form v.(*pb.User) panics and is not reported.
+func checkTypeAssertion(value ast.Expr, field fieldRef, msgType types.Type, pass *analysis.Pass) {
+	ident, ok := ast.Unparen(value).(*ast.Ident)
+	if !ok {
+		return
+	}
+	obj, ok := pass.TypesInfo.Uses[ident].(*types.Var)
+	if !ok {
+		return
+	}
+	assert := uncheckedAssertion(obj, pass)
+	if assert == nil {
+		return
+	}
+	if call, ok := ast.Unparen(assert.X).(*ast.CallExpr); ok && isContextValueCall(call, pass) {
+		return
+	}
+
+	reportField(pass, field, analysis.Diagnostic{
+		Pos:      value.Pos(),
+		Category: KindTypeAssertion,
+		Message: fmt.Sprintf("variable '%s' from a type assertion to %s assigned to non-optional message field '%s' in protobuf message %s is nil when the assertion fails; check ok first",
+			ident.Name, types.ExprString(assert.Type), field, describeType(pass, msgType)),
+	})
+}
+
+// uncheckedAssertion returns the type assertion declaring a variable as v, ok := x.(T)
+// (or v, _ := ...) when neither v is compared against nil nor ok is used, or nil
+func uncheckedAssertion(obj *types.Var, pass *analysis.Pass) *ast.TypeAssertExpr {
+	index := declarations(pass)
+	if index.nilCompared[obj] {
+		return nil
+	}
+	var lhs, rhs []ast.Expr
+	if assign := index.defines[obj]; assign != nil {
+		lhs, rhs = assign.Lhs, assign.Rhs
+	} else if spec := index.specs[obj]; spec != nil {
+		for _, name := range spec.Names {
+			lhs = append(lhs, name)
+		}
+		rhs = spec.Values
+	}
+	if len(lhs) != 2 || len(rhs) != 1 || lhs[0].Pos() != obj.Pos() {
+		return nil
+	}
+	assert, ok := ast.Unparen(rhs[0]).(*ast.TypeAssertExpr)
+	if !ok || assert.Type == nil {
+		return nil
+	}
+	// The ok result is checked when it is used at all
+	if id, ok := lhs[1].(*ast.Ident); ok {
+		if okObj := pass.TypesInfo.ObjectOf(id); okObj != nil && index.used[okObj] {
+			return nil
+		}
+	}
+	return assert
+}
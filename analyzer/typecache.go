@@ -0,0 +1,66 @@
+package analyzer
+
+import (
+	"go/types"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// The required fields of a message type are asked for every literal, assignment and
+// return the checks meet, and again at each level of a nested literal, each time
+// classifying every field of the struct. They depend only on the type and the flags,
+// so getMessageFields computes them once per type. Classifying a single type or field
+// is cheaper than a cache lookup; see BenchmarkMessageFields.
+//
+// Types are shared between the passes running at once, like descriptorCache's
+// packages, and the flags are set before the first pass. A message type declared
+// optional by a directive is known before any pass that can see it; see
+// optionaltypes.go. A pass holds the packages it can see while it runs, and the entries
+// for the types of a package are dropped once the last pass holding it finishes, so a
+// long-lived process doesn't keep the types of earlier loads and a dependency shared by
+// the passes running at once stays cached until they are done with it.
+
+// messageFieldLists holds the result of getMessageFields by *types.Struct
+var messageFieldLists sync.Map
+
+// messageFieldHolders counts, for each *types.Package, the passes running that can see
+// it (see holdMessageFields)
+var (
+	messageFieldHoldersMu sync.Mutex
+	messageFieldHolders   = make(map[*types.Package]int)
+)
+
+// holdMessageFields records the packages a pass can see, keeping the cached fields of
+// their types until it finishes
+func holdMessageFields(pass *analysis.Pass) {
+	messageFieldHoldersMu.Lock()
+	defer messageFieldHoldersMu.Unlock()
+	for pkg := range visiblePackages(pass.Pkg) {
+		messageFieldHolders[pkg]++
+	}
+}
+
+// releaseMessageFields releases the packages a pass could see, and drops the cached
+// fields of the struct types declared in those no other pass running holds
+func releaseMessageFields(pass *analysis.Pass) {
+	messageFieldHoldersMu.Lock()
+	defer messageFieldHoldersMu.Unlock()
+	released := make(map[*types.Package]bool)
+	for pkg := range visiblePackages(pass.Pkg) {
+		if messageFieldHolders[pkg]--; messageFieldHolders[pkg] <= 0 {
+			delete(messageFieldHolders, pkg)
+			released[pkg] = true
+		}
+	}
+	if len(released) == 0 {
+		return
+	}
+	messageFieldLists.Range(func(key, _ interface{}) bool {
+		structType := key.(*types.Struct)
+		if structType.NumFields() == 0 || released[structType.Field(0).Pkg()] {
+			messageFieldLists.Delete(key)
+		}
+		return true
+	})
+}
@@ -0,0 +1,145 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+// nestedMessages type-checks a chain of depth messages, each requiring the next, and
+// returns their struct types, outermost first
+func nestedMessages(tb testing.TB, depth int) []*types.Struct {
+	var src strings.Builder
+	src.WriteString("package pb\n")
+	for i := 0; i < depth; i++ {
+		fmt.Fprintf(&src, "type Level%d struct {\n", i)
+		fmt.Fprintf(&src, "\tName string `protobuf:\"bytes,1,opt,name=name,proto3\"`\n")
+		fmt.Fprintf(&src, "\tTags []string `protobuf:\"bytes,2,rep,name=tags,proto3\"`\n")
+		if i+1 < depth {
+			fmt.Fprintf(&src, "\tNext *Level%d `protobuf:\"bytes,3,opt,name=next,proto3\"`\n", i+1)
+			fmt.Fprintf(&src, "\tPrev *Level%d `protobuf:\"bytes,4,opt,name=prev,proto3,oneof\"`\n", i+1)
+		}
+		src.WriteString("}\n")
+		fmt.Fprintf(&src, "func (*Level%d) ProtoMessage() {}\n", i)
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "pb.go", src.String(), 0)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	pkg, err := new(types.Config).Check("example.com/pb", fset, []*ast.File{file}, nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	structs := make([]*types.Struct, depth)
+	for i := range structs {
+		structs[i] = pkg.Scope().Lookup(fmt.Sprintf("Level%d", i)).Type().Underlying().(*types.Struct)
+	}
+	return structs
+}
+
+// The cached fields are those computed, and appending to them leaves the cache intact
+func TestMessageFieldsCached(t *testing.T) {
+	structs := nestedMessages(t, 3)
+	for i, structType := range structs {
+		want := collectMessageFields(structType)
+		got := getMessageFields(structType)
+		if len(got) != len(want) || (len(want) == 1 && got[0] != getMessageFields(structType)[0]) {
+			t.Errorf("Level%d: got %v, want %v", i, got, want)
+		}
+		_ = append(got, types.NewVar(token.NoPos, nil, "Extra", types.Typ[types.Int]))
+		if again := getMessageFields(structType); len(again) != len(want) {
+			t.Errorf("Level%d: cached fields changed to %v", i, again)
+		}
+	}
+	if fields := getMessageFields(structs[0]); len(fields) != 1 || fields[0].Name() != "Next" {
+		t.Errorf("Level0: got %v, want [Next]", fields)
+	}
+}
+
+// The caches keyed by type don't outlive the passes that fill them
+func TestTypeCachesEvicted(t *testing.T) {
+	Analyzer.Flags.Set("check-all-messages", "true")
+	Analyzer.Flags.Set("forbid-output-only", "true")
+	defer Analyzer.Flags.Set("check-all-messages", "false")
+	defer Analyzer.Flags.Set("forbid-output-only", "false")
+	for _, result := range analysistest.Run(t, analysistest.TestData(), Analyzer, "outputonly") {
+		visible := visiblePackages(result.Pass.Pkg)
+		descriptorCache.Range(func(key, _ interface{}) bool {
+			if visible[key.(*types.Package)] {
+				t.Errorf("descriptorCache still holds %s", key.(*types.Package).Path())
+			}
+			return true
+		})
+		messageFieldLists.Range(func(key, _ interface{}) bool {
+			if structType := key.(*types.Struct); structType.NumFields() > 0 && visible[structType.Field(0).Pkg()] {
+				t.Errorf("messageFieldLists still holds %s", structType)
+			}
+			return true
+		})
+	}
+	if len(messageFieldHolders) != 0 {
+		t.Errorf("messageFieldHolders still holds %d packages", len(messageFieldHolders))
+	}
+
+	Analyzer.Flags.Set("optional-types", "TraceInfo")
+	defer Analyzer.Flags.Set("optional-types", "")
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "optionaltypes")
+	if len(optionalTypes) != 0 {
+		t.Errorf("optionalTypes still holds %d types", len(optionalTypes))
+	}
+}
+
+// The fields of a dependency's types stay cached while another pass that sees it runs
+func TestMessageFieldsHeldByRunningPasses(t *testing.T) {
+	structType := nestedMessages(t, 2)[0]
+	dep := structType.Field(0).Pkg()
+	passOver := func(path string) *analysis.Pass {
+		pkg := types.NewPackage(path, path[strings.LastIndex(path, "/")+1:])
+		pkg.SetImports([]*types.Package{dep})
+		return &analysis.Pass{Pkg: pkg}
+	}
+	first, second := passOver("example.com/a"), passOver("example.com/b")
+
+	holdMessageFields(first)
+	holdMessageFields(second)
+	getMessageFields(structType)
+	releaseMessageFields(first)
+	if _, ok := messageFieldLists.Load(structType); !ok {
+		t.Errorf("Expected the fields of %s to stay cached while the second pass runs", structType)
+	}
+	releaseMessageFields(second)
+	if _, ok := messageFieldLists.Load(structType); ok {
+		t.Errorf("Expected the fields of %s to be dropped once both passes finished", structType)
+	}
+	if len(messageFieldHolders) != 0 {
+		t.Errorf("messageFieldHolders still holds %d packages", len(messageFieldHolders))
+	}
+}
+
+// Validating a nested literal asks for the required fields of each level it reaches
+func BenchmarkMessageFields(b *testing.B) {
+	structs := nestedMessages(b, 64)
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, structType := range structs {
+				getMessageFields(structType)
+			}
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, structType := range structs {
+				collectMessageFields(structType)
+			}
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package analyzer
+
+import (
+	"go/types"
+	"strings"
+	"sync"
+)
+
+var (
+	// includeTypes holds the comma-separated message type patterns set via -include-types
+	includeTypes string
+
+	// excludeTypes holds the comma-separated message type patterns set via -exclude-types
+	excludeTypes string
+)
+
+func init() {
+	Analyzer.Flags.StringVar(&includeTypes, "include-types", "",
+		"comma-separated patterns of the message types to check, by proto full name (e.g. 'example.v1.UserResponse') or Go type name (e.g. 'example.com/gen/userpb.UserResponse'); '*' matches within a name element and '**' any number of elements; empty checks every type")
+	Analyzer.Flags.StringVar(&excludeTypes, "exclude-types", "",
+		"comma-separated patterns of message types not to check (e.g. '**.DebugResponse'), matched like -include-types")
+}
+
+// Enforcement is often rolled out one message at a time. -include-types limits the
+// messages checked to those it names, and -exclude-types leaves out the ones it names,
+// like -include-packages and -exclude do for packages. A pattern is matched against the
+// proto full name of a message, example.v1.UserResponse or example.v1.Outer.Inner, and
+// against its Go type name, example.com/gen/userpb.UserResponse, both split into
+// elements at dots and slashes. Like package patterns, a pattern also matches any name
+// ending in it, so UserResponse matches every message of that name and v1.* every
+// message of a v1 package.
+//
+// The patterns pick the messages checking starts from, those shouldCheckType accepts;
+// the messages nested in one are checked through it. A response left out is still
+// summarized for its callers, but nothing is reported on it.
+
+// isTypeSelected checks if a message type passes -include-types and -exclude-types
+func isTypeSelected(t types.Type) bool {
+	if includeTypes == "" && excludeTypes == "" {
+		return true
+	}
+	obj := namedTypeName(t)
+	if obj == nil || obj.Pkg() == nil {
+		return true
+	}
+	names := messageNames(obj)
+	if patterns := splitPatterns(includeTypes); len(patterns) > 0 && !matchTypeNames(patterns, names) {
+		return false
+	}
+	return !matchTypeNames(splitPatterns(excludeTypes), names)
+}
+
+// matchTypeNames checks if one of the names matches one of the patterns
+func matchTypeNames(patterns []string, names [][]string) bool {
+	for _, pattern := range patterns {
+		elems := typeNameElems(pattern)
+		for _, name := range names {
+			for i := range name {
+				if matchElems(elems, name[i:]) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// messageNameCache holds the messageNames of each *types.TypeName
+var messageNameCache sync.Map
+
+// messageNames returns the names a message type is matched by, split into elements:
+// its Go type name and, when the generated package embeds its descriptor, its proto
+// full name
+func messageNames(obj *types.TypeName) [][]string {
+	if cached, ok := messageNameCache.Load(obj); ok {
+		return cached.([][]string)
+	}
+	names := [][]string{typeNameElems(obj.Pkg().Path() + "." + obj.Name())}
+	if protoPkg := protoPackageOf(obj.Pkg()); protoPkg != "" {
+		// protoc-gen-go names nested messages Outer_Inner
+		names = append(names, typeNameElems(protoPkg+"."+strings.ReplaceAll(obj.Name(), "_", ".")))
+	}
+	cached, _ := messageNameCache.LoadOrStore(obj, names)
+	return cached.([][]string)
+}
+
+// typeNameElems splits a type name or pattern into elements at dots and slashes
+func typeNameElems(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool { return r == '.' || r == '/' })
+}
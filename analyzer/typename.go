@@ -0,0 +1,101 @@
+package analyzer
+
+import (
+	"go/constant"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// describeType renders a message type for diagnostics as its shortest unique
+// import-qualified name in quotes, followed by the proto package when it is known:
+//
+//	'*examplev1.User' (example.v1)
+//
+// Types from the package being analyzed are unqualified. When two imported packages
+// share a name (v1 and v2 of an API), enough of their import paths is kept to tell
+// them apart, e.g. 'v1/userpb.User' and 'v2/userpb.User'.
+func describeType(pass *analysis.Pass, t types.Type) string {
+	s := "'" + types.TypeString(t, shortQualifier(pass)) + "'"
+	if obj := namedTypeName(t); obj != nil && obj.Pkg() != nil {
+		if protoPkg := protoPackageOf(obj.Pkg()); protoPkg != "" {
+			s += " (" + protoPkg + ")"
+		}
+	}
+	return s
+}
+
+// shortQualifier returns a types.Qualifier that names packages by the shortest suffix of
+// their import path that is unique among the packages the analyzed package imports
+func shortQualifier(pass *analysis.Pass) types.Qualifier {
+	return func(pkg *types.Package) string {
+		if pkg == pass.Pkg {
+			return ""
+		}
+
+		var clashes []*types.Package
+		for _, imp := range pass.Pkg.Imports() {
+			if imp != pkg && imp.Name() == pkg.Name() {
+				clashes = append(clashes, imp)
+			}
+		}
+		if len(clashes) == 0 {
+			return pkg.Name()
+		}
+
+		// Grow the path suffix until no clashing package shares it
+		elems := strings.Split(pkg.Path(), "/")
+		for n := 2; n <= len(elems); n++ {
+			suffix := strings.Join(elems[len(elems)-n:], "/")
+			unique := true
+			for _, other := range clashes {
+				if other.Path() == suffix || strings.HasSuffix(other.Path(), "/"+suffix) {
+					unique = false
+					break
+				}
+			}
+			if unique {
+				// Keep the package name as the last element when it differs from the directory
+				if elems[len(elems)-1] != pkg.Name() {
+					return strings.Join(elems[len(elems)-n:len(elems)-1], "/") + "/" + pkg.Name()
+				}
+				return suffix
+			}
+		}
+		return pkg.Path()
+	}
+}
+
+// protoPackageOf returns the proto package declared by a generated Go package, read from
+// the serialized file descriptor that protoc-gen-go emits as a file_*_rawDesc string
+// constant. It returns "" when the package has no such constant (for example older
+// generators that emit a []byte variable instead).
+func protoPackageOf(pkg *types.Package) string {
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		if !strings.HasPrefix(name, "file_") || !strings.HasSuffix(name, "_rawDesc") {
+			continue
+		}
+		c, ok := scope.Lookup(name).(*types.Const)
+		if !ok || c.Val().Kind() != constant.String {
+			continue
+		}
+		if protoPkg := descriptorPackage(constant.StringVal(c.Val())); protoPkg != "" {
+			return protoPkg
+		}
+	}
+	return ""
+}
+
+// descriptorPackage extracts the package field (number 2) from a serialized
+// google.protobuf.FileDescriptorProto, or returns "" if it can't be read
+func descriptorPackage(desc string) string {
+	var protoPkg string
+	walkDescriptor(desc, func(field, _ uint64, value string) {
+		if field == 2 && protoPkg == "" {
+			protoPkg = value
+		}
+	})
+	return protoPkg
+}
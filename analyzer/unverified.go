@@ -0,0 +1,86 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// reportUnverified reports, under -report-unverified, a required field whose value comes
+// from a source the analyzer trusts without checking: a function call, a parameter or a
+// channel receive. These are informational, to help audit the analyzer's blind spots.
+// Either way, the response holding the field counts as trusted; see coverage.go.
+func reportUnverified(value ast.Expr, field fieldRef, msgType types.Type, pass *analysis.Pass) {
+	source := opaqueSource(value, pass)
+	if source == "" {
+		return
+	}
+	trustSite(value, pass)
+	if !reportUnverifiedValues {
+		return
+	}
+
+	severity := severityAt(pass, KindUnverified, value.Pos())
+	reportField(pass, field, analysis.Diagnostic{
+		Pos:      value.Pos(),
+		Category: KindUnverified,
+		Message: fmt.Sprintf("%svalue of non-optional message field '%s' in protobuf message %s comes from %s and could not be verified",
+			severity.Prefix(), field, describeType(pass, msgType), source),
+	})
+}
+
+// opaqueSource describes where a value comes from when the analyzer can't see inside it,
+// following a local variable back to its initializer once. It returns "" for values the
+// analyzer does verify.
+func opaqueSource(expr ast.Expr, pass *analysis.Pass) string {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.CallExpr:
+		// Conversions like (*T)(nil) are not calls
+		if tv, ok := pass.TypesInfo.Types[e.Fun]; ok && tv.IsType() {
+			return ""
+		}
+		// Functions summarized by a returnFact are validated at the call, and helpers
+		// checked here at their own returns; see returns.go
+		if fn := typeutil.StaticCallee(pass.TypesInfo, e); fn != nil && (pass.ImportObjectFact(fn, new(returnFact)) || isCheckedHelper(fn, pass)) {
+			return ""
+		}
+		return "a function call"
+
+	case *ast.UnaryExpr:
+		if e.Op == token.ARROW {
+			return "a channel receive"
+		}
+
+	case *ast.Ident:
+		obj, ok := pass.TypesInfo.ObjectOf(e).(*types.Var)
+		if !ok {
+			return ""
+		}
+		if isParameter(obj, pass) {
+			return fmt.Sprintf("parameter '%s'", e.Name)
+		}
+		// Follow v := f() or v := <-ch, but not chains of variables
+		if init := localInitializer(obj, pass); init != nil {
+			if _, isIdent := ast.Unparen(init).(*ast.Ident); !isIdent {
+				if source := opaqueSource(init, pass); source != "" {
+					return fmt.Sprintf("variable '%s' assigned from %s", e.Name, source)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// isParameter checks if a variable is a parameter or receiver of a function in the package
+func isParameter(obj *types.Var, pass *analysis.Pass) bool {
+	return declarations(pass).params[obj]
+}
+
+// localInitializer returns the single-value initializer of a variable declared with := or var
+func localInitializer(obj *types.Var, pass *analysis.Pass) ast.Expr {
+	return declarations(pass).initializer(obj)
+}
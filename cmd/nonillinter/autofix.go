@@ -0,0 +1,29 @@
+package main
+
+import (
+	"github.com/nickheyer/go_no_nil_linter/analyzer"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+)
+
+// filterAutofixes keeps only the suggested fixes -autofix-rules enables in the findings
+// of the analyses, for -fix, which applies every fix it is given. Without -fix all of
+// them are kept, for JSON output and editors.
+func filterAutofixes(actions []*checker.Action) {
+	for _, act := range actions {
+		for i, d := range act.Diagnostics {
+			act.Diagnostics[i].SuggestedFixes = autofixes(d.SuggestedFixes)
+		}
+	}
+}
+
+// autofixes returns the fixes -autofix-rules enables
+func autofixes(fixes []analysis.SuggestedFix) []analysis.SuggestedFix {
+	var enabled []analysis.SuggestedFix
+	for _, fix := range fixes {
+		if analyzer.AutofixEnabled(fix) {
+			enabled = append(enabled, fix)
+		}
+	}
+	return enabled
+}
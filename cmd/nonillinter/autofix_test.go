@@ -0,0 +1,23 @@
+package main
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+)
+
+func TestFilterAutofixes(t *testing.T) {
+	act := &checker.Action{Diagnostics: []analysis.Diagnostic{{
+		Message: "finding",
+		SuggestedFixes: []analysis.SuggestedFix{
+			{Message: "Initialize 'User' with an empty message"},
+			{Message: "Replace nil with timestamppb.Now()"},
+			{Message: "Replace the copy with proto.Clone"},
+		},
+	}}}
+	filterAutofixes([]*checker.Action{act})
+	if got := act.Diagnostics[0].SuggestedFixes; len(got) != 1 || got[0].Message != "Replace nil with timestamppb.Now()" {
+		t.Errorf("Expected only the timestamp fix to be kept, got %v", got)
+	}
+}
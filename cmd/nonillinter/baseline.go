@@ -0,0 +1,348 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/types"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nickheyer/go_no_nil_linter/analyzer"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+var (
+	baselineFlag      = flag.String("baseline", "", "JSON file of accepted findings to suppress; entries that no longer match a finding are reported as stale")
+	writeBaselineFlag = flag.Bool("write-baseline", false, "record every finding in the -baseline file instead of reporting it")
+	pruneBaselineFlag = flag.Bool("prune-baseline", false, "remove stale entries from the -baseline file instead of only reporting them")
+)
+
+// baselineEntry is an accepted finding. File is slash-separated and relative to the
+// directory of the baseline file so baselines can be committed. Entries are matched by
+// Fingerprint, which doesn't depend on file or line, so they survive unrelated edits
+// and code moving between files of the package; File, Line and FieldPath record where
+// the finding was when the baseline was written, for reviewers. Entries without a fingerprint, from older
+// baselines, are matched by file, line and message.
+type baselineEntry struct {
+	Package     string `json:"package,omitempty"`
+	Function    string `json:"function,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+	FieldPath   string `json:"fieldPath,omitempty"`
+	Category    string `json:"category,omitempty"`
+	Message     string `json:"message"`
+}
+
+// matches reports whether an entry accepts a finding
+func (e baselineEntry) matches(finding baselineEntry) bool {
+	if e.Fingerprint != "" {
+		return e.Fingerprint == finding.Fingerprint
+	}
+	return e.File == finding.File && e.Line == finding.Line && fingerprintCategory(e.Category) == fingerprintCategory(finding.Category) && e.Message == finding.Message
+}
+
+// fingerprintCategory returns the category a finding is fingerprinted with. The core
+// checks were reported without one before they had kinds; their messages already tell
+// them apart, so leaving the kind out keeps existing baselines matching.
+func fingerprintCategory(category string) string {
+	switch category {
+	case analyzer.KindNilLiteral, analyzer.KindNilVariable, analyzer.KindMissingField, analyzer.KindNestedNil:
+		return ""
+	}
+	return category
+}
+
+// inPackage reports whether an entry belongs to a package with the given path and files
+func (e baselineEntry) inPackage(pkgPath string, files map[string]bool) bool {
+	if e.Package != "" {
+		return e.Package == pkgPath
+	}
+	return files[e.File]
+}
+
+type baselineFile struct {
+	Entries []baselineEntry `json:"entries"`
+}
+
+// baseline implements -baseline, -write-baseline and -prune-baseline. Once the run is
+// done, the findings of each package named are matched against the entries for its
+// files. Entries for those files that match nothing are stale: the code was fixed or
+// moved, and leaving them would hide a regression that lands on the same line later.
+// Dependencies are analyzed for their facts only, so their findings are left alone.
+type baseline struct {
+	out io.Writer
+
+	path string
+	dir  string
+
+	entries []baselineEntry
+	loaded  []baselineEntry
+}
+
+func newBaseline(out io.Writer) *baseline {
+	return &baseline{out: out}
+}
+
+// apply filters the findings of the analyses of the packages named through the
+// baseline, or records them in it with -write-baseline. The test variant of a package
+// re-analyzes its files, so each variant matches its findings afresh; an entry is stale
+// when no variant matches it, and a finding is recorded once however many report it.
+func (b *baseline) apply(actions []*checker.Action) error {
+	if *baselineFlag == "" {
+		return nil
+	}
+	if err := b.load(*baselineFlag); err != nil {
+		return err
+	}
+
+	variants := make(map[string][]*checker.Action)
+	var pkgPaths []string
+	for _, act := range actions {
+		pkgPath := act.Package.PkgPath
+		if variants[pkgPath] == nil {
+			pkgPaths = append(pkgPaths, pkgPath)
+		}
+		variants[pkgPath] = append(variants[pkgPath], act)
+	}
+	sort.Strings(pkgPaths)
+
+	var stale []baselineEntry
+	for _, pkgPath := range pkgPaths {
+		files := make(map[string]bool)
+		for _, act := range variants[pkgPath] {
+			for _, file := range act.Package.Syntax {
+				files[b.relative(act.Package.Fset.Position(file.Pos()).Filename)] = true
+			}
+		}
+		var candidates []baselineEntry
+		for _, e := range b.entries {
+			if e.inPackage(pkgPath, files) {
+				candidates = append(candidates, e)
+			}
+		}
+
+		accepted := make([]bool, len(candidates))
+		found := make(map[baselineEntry]int)
+		for _, act := range variants[pkgPath] {
+			// Match each finding against a distinct entry
+			matched := make([]bool, len(candidates))
+			counts := make(map[baselineEntry]int)
+			kept := act.Diagnostics[:0]
+		findings:
+			for _, d := range act.Diagnostics {
+				entry := b.entryFor(act, d)
+				if *writeBaselineFlag {
+					counts[entry]++
+					continue
+				}
+				for i, candidate := range candidates {
+					if !matched[i] && candidate.matches(entry) {
+						matched[i], accepted[i] = true, true
+						continue findings
+					}
+				}
+				kept = append(kept, d)
+			}
+			act.Diagnostics = kept
+			for entry, n := range counts {
+				found[entry] = max(found[entry], n)
+			}
+		}
+
+		if *writeBaselineFlag {
+			b.replace(pkgPath, files, found)
+			continue
+		}
+		for i, candidate := range candidates {
+			if !accepted[i] {
+				stale = append(stale, candidate)
+			}
+		}
+	}
+
+	if *writeBaselineFlag {
+		return b.save()
+	}
+	return b.reportStale(stale)
+}
+
+// load reads the baseline file; a missing file is an empty baseline
+func (b *baseline) load(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	b.path, b.dir = abs, filepath.Dir(abs)
+
+	data, err := os.ReadFile(abs)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var file baselineFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("reading -baseline %s: %v", path, err)
+	}
+	b.entries = file.Entries
+	b.loaded = append([]baselineEntry(nil), file.Entries...)
+	return nil
+}
+
+// relative returns filename relative to the baseline file, slash-separated
+func (b *baseline) relative(filename string) string {
+	if rel, err := filepath.Rel(b.dir, filename); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(filename)
+}
+
+// replace swaps the entries for a package with found, which counts each finding
+// (-write-baseline)
+func (b *baseline) replace(pkgPath string, files map[string]bool, found map[baselineEntry]int) {
+	var kept []baselineEntry
+	for e, n := range found {
+		for i := 0; i < n; i++ {
+			kept = append(kept, e)
+		}
+	}
+	for _, e := range b.entries {
+		if !e.inPackage(pkgPath, files) {
+			kept = append(kept, e)
+		}
+	}
+	b.entries = kept
+}
+
+// reportStale prints stale entries once each and, with -prune-baseline, removes them
+func (b *baseline) reportStale(stale []baselineEntry) error {
+	if len(stale) == 0 {
+		return nil
+	}
+
+	reported := make(map[baselineEntry]bool)
+	for _, e := range stale {
+		if reported[e] {
+			continue
+		}
+		reported[e] = true
+		action := "remove it or run with -prune-baseline"
+		if *pruneBaselineFlag {
+			action = "pruned"
+		}
+		fmt.Fprintf(b.out, "nonillinter: stale baseline entry %s:%d %q no longer matches a finding; %s\n",
+			e.File, e.Line, e.Message, action)
+	}
+
+	if !*pruneBaselineFlag {
+		return nil
+	}
+	kept := b.entries[:0:0]
+	for _, e := range b.entries {
+		if !reported[e] {
+			kept = append(kept, e)
+		}
+	}
+	b.entries = kept
+	return b.save()
+}
+
+// save writes the entries sorted by file and line, so baseline diffs stay readable.
+// Entries on the same line are ordered by their content rather than by when they were
+// found.
+func (b *baseline) save() error {
+	sort.SliceStable(b.entries, func(i, j int) bool {
+		x, y := b.entries[i], b.entries[j]
+		switch {
+		case x.File != y.File:
+			return x.File < y.File
+		case x.Line != y.Line:
+			return x.Line < y.Line
+		case x.Category != y.Category:
+			return x.Category < y.Category
+		case x.Message != y.Message:
+			return x.Message < y.Message
+		}
+		return x.Fingerprint < y.Fingerprint
+	})
+	data, err := json.MarshalIndent(baselineFile{Entries: b.entries}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(b.path, append(data, '\n'), 0o644)
+}
+
+// entryFor builds the baseline entry for a diagnostic of an analysis. The fingerprint
+// hashes the package, enclosing function, category and the message type and field path
+// the analyzer recorded with the source of the enclosing statement, whitespace and
+// comments removed. Findings that aren't about a field are keyed on their message
+// instead; messages also name lines, of the nil a field was given for example, which
+// move with unrelated edits.
+func (b *baseline) entryFor(act *checker.Action, d analysis.Diagnostic) baselineEntry {
+	fset, files := act.Package.Fset, act.Package.Syntax
+	pos := fset.Position(d.Pos)
+	entry := baselineEntry{
+		Package:  act.Package.PkgPath,
+		File:     b.relative(pos.Filename),
+		Line:     pos.Line,
+		Category: d.Category,
+		Message:  d.Message,
+	}
+	messageType, fieldPath := recordedField(act, d)
+	entry.FieldPath = fieldPath
+	key := entry.Message
+	if fieldPath != "" {
+		key = messageType + " " + fieldPath
+	}
+
+	var statement string
+	for _, file := range files {
+		if d.Pos < file.Pos() || d.Pos > file.End() {
+			continue
+		}
+		path, _ := astutil.PathEnclosingInterval(file, d.Pos, d.Pos)
+		for _, n := range path {
+			switch node := n.(type) {
+			case *ast.BlockStmt:
+			case ast.Stmt, ast.Spec:
+				if statement == "" {
+					var buf bytes.Buffer
+					printer.Fprint(&buf, fset, node)
+					statement = strings.Join(strings.Fields(buf.String()), " ")
+				}
+			case *ast.FuncDecl:
+				entry.Function = node.Name.Name
+				if node.Recv != nil && len(node.Recv.List) == 1 {
+					entry.Function = types.ExprString(node.Recv.List[0].Type) + "." + entry.Function
+				}
+			}
+			if entry.Function != "" {
+				break
+			}
+		}
+		break
+	}
+
+	h := sha256.New()
+	for _, part := range []string{entry.Package, entry.Function, fingerprintCategory(entry.Category), key, statement} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	entry.Fingerprint = hex.EncodeToString(h.Sum(nil))[:16]
+	return entry
+}
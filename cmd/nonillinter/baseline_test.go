@@ -0,0 +1,327 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nickheyer/go_no_nil_linter/analyzer"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+)
+
+// baselinePass applies the baseline to findings with the message at each listed line of
+// a one-file package, returning the diagnostics that got through
+func baselinePass(t *testing.T, b *baseline, filename string, lines map[int]string) []analysis.Diagnostic {
+	t.Helper()
+	return baselinePassSource(t, b, filename, "package p\n"+strings.Repeat("\n", 20), lines)
+}
+
+func baselinePassSource(t *testing.T, b *baseline, filename, src string, lines map[int]string) []analysis.Diagnostic {
+	t.Helper()
+	return baselineVariants(t, b, filename, src, lines)[0].Diagnostics
+}
+
+// baselineVariants applies the baseline to the findings of a one-file package, each
+// map of lines to messages those of a variant of it, and returns their analyses
+func baselineVariants(t *testing.T, b *baseline, filename, src string, variants ...map[int]string) []*checker.Action {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokFile := fset.File(file.Pos())
+
+	var actions []*checker.Action
+	for _, lines := range variants {
+		res := new(analyzer.Result)
+		act := &checker.Action{Package: &packages.Package{
+			PkgPath: "example.com/p",
+			Fset:    fset,
+			Syntax:  []*ast.File{file},
+			Types:   types.NewPackage("example.com/p", "p"),
+		}, Result: res}
+		for line, message := range lines {
+			// Report on the first token of the line
+			text := strings.Split(src, "\n")[line-1]
+			indent := len(text) - len(strings.TrimLeft(text, " \t"))
+			d := analysis.Diagnostic{Pos: tokFile.LineStart(line) + token.Pos(indent), Message: message}
+			act.Diagnostics = append(act.Diagnostics, d)
+			recordFinding(res, d)
+		}
+		actions = append(actions, act)
+	}
+	if err := b.apply(actions); err != nil {
+		t.Fatal(err)
+	}
+	return actions
+}
+
+// recordedPaths are the field paths the analyzer records for the findings of these tests
+var recordedPaths = map[string]string{
+	userNil:     "UserResponse.User",
+	userLookup:  "UserResponse.User",
+	loginLookup: "UserResponse.LastLogin",
+}
+
+// recordedSeverities are the severities the analyzer records for the advisory findings
+// of these tests
+var recordedSeverities = map[string]analyzer.Severity{
+	userLookup:  analyzer.SeverityAdvisory,
+	loginLookup: analyzer.SeverityAdvisory,
+}
+
+const userNil = "nil assignment to non-optional message field 'User' in protobuf message 'pb.UserResponse'"
+
+// recordFinding records d in res as the analyzer does, with its field path and severity
+// if it's one of recordedPaths and recordedSeverities
+func recordFinding(res *analyzer.Result, d analysis.Diagnostic) {
+	f := analyzer.Finding{Diagnostic: d, Severity: recordedSeverities[d.Message]}
+	if path, ok := recordedPaths[d.Message]; ok {
+		f.MessageType, f.FieldPath = "pb.UserResponse", path
+	}
+	res.Findings = append(res.Findings, f)
+}
+
+func readBaseline(t *testing.T, path string) []baselineEntry {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file baselineFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatal(err)
+	}
+	return file.Entries
+}
+
+func TestBaseline(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".nonillinter-baseline.json")
+	source := filepath.Join(dir, "svc", "handler.go")
+	flag.Set("baseline", path)
+	defer flag.Set("baseline", "")
+
+	// Record the current findings
+	flag.Set("write-baseline", "true")
+	if got := baselinePass(t, newBaseline(&bytes.Buffer{}), source, map[int]string{3: "old finding", 7: "fixed later"}); len(got) != 0 {
+		t.Fatalf("-write-baseline should not report findings, got %v", got)
+	}
+	flag.Set("write-baseline", "false")
+
+	entries := readBaseline(t, path)
+	if len(entries) != 2 || entries[0].File != "svc/handler.go" || entries[0].Line != 3 || entries[1].Line != 7 {
+		t.Fatalf("Unexpected baseline entries %+v", entries)
+	}
+
+	// Line 7 was fixed and a new finding appeared on line 9
+	var out bytes.Buffer
+	got := baselinePass(t, newBaseline(&out), source, map[int]string{3: "old finding", 9: "new finding"})
+	if len(got) != 1 || got[0].Message != "new finding" {
+		t.Errorf("Expected only the new finding to be reported, got %v", got)
+	}
+	if want := `stale baseline entry svc/handler.go:7 "fixed later" no longer matches a finding; remove it or run with -prune-baseline`; !strings.Contains(out.String(), want) {
+		t.Errorf("Expected stale entry report %q, got:\n%s", want, out.String())
+	}
+	if len(readBaseline(t, path)) != 2 {
+		t.Error("The baseline should not change without -prune-baseline")
+	}
+
+	// Prune the stale entry
+	flag.Set("prune-baseline", "true")
+	defer flag.Set("prune-baseline", "false")
+	out.Reset()
+	baselinePass(t, newBaseline(&out), source, map[int]string{3: "old finding"})
+	if !strings.Contains(out.String(), "; pruned") {
+		t.Errorf("Expected pruned stale entry, got:\n%s", out.String())
+	}
+	if entries := readBaseline(t, path); len(entries) != 1 || entries[0].Line != 3 {
+		t.Errorf("Expected only the line 3 entry to remain, got %+v", entries)
+	}
+}
+
+func TestBaselineTestVariants(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "baseline.json")
+	source := filepath.Join(dir, "p.go")
+	src := "package p\n" + strings.Repeat("\n", 20)
+	flag.Set("baseline", path)
+	defer flag.Set("baseline", "")
+
+	// The test variant repeats the package's finding and has one of its own
+	flag.Set("write-baseline", "true")
+	baselineVariants(t, newBaseline(&bytes.Buffer{}), source, src, map[int]string{3: "shared"}, map[int]string{3: "shared", 5: "test only"})
+	flag.Set("write-baseline", "false")
+	if entries := readBaseline(t, path); len(entries) != 2 {
+		t.Fatalf("Expected each finding recorded once, got %+v", entries)
+	}
+
+	// An entry matched by one variant only is not stale
+	var out bytes.Buffer
+	actions := baselineVariants(t, newBaseline(&out), source, src, map[int]string{3: "shared"}, map[int]string{3: "shared", 5: "test only"})
+	for _, act := range actions {
+		if len(act.Diagnostics) != 0 {
+			t.Errorf("Expected every finding to match the baseline, got %v", act.Diagnostics)
+		}
+	}
+	if out.Len() != 0 {
+		t.Errorf("Unexpected stale report:\n%s", out.String())
+	}
+}
+
+func TestBaselineDisabled(t *testing.T) {
+	got := baselinePass(t, newBaseline(&bytes.Buffer{}), "/tmp/p.go", map[int]string{2: "finding"})
+	if len(got) != 1 {
+		t.Errorf("Without -baseline every finding should be reported, got %v", got)
+	}
+}
+
+func TestBaselineFingerprints(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "baseline.json")
+	flag.Set("baseline", path)
+	defer flag.Set("baseline", "")
+
+	const before = `package p
+
+func handler() {
+	resp.User = nil
+}
+`
+	// A function was added above and the finding moved to another file of the package
+	const after = `package p
+
+func helper() {}
+
+// handler builds the response
+func handler() {
+	resp.User   =   nil
+}
+`
+	flag.Set("write-baseline", "true")
+	baselinePassSource(t, newBaseline(&bytes.Buffer{}), filepath.Join(dir, "a.go"), before, map[int]string{4: userNil})
+	flag.Set("write-baseline", "false")
+
+	entries := readBaseline(t, path)
+	if len(entries) != 1 || entries[0].Function != "handler" || entries[0].Package != "example.com/p" || entries[0].Fingerprint == "" ||
+		entries[0].FieldPath != "UserResponse.User" {
+		t.Fatalf("Unexpected baseline entries %+v", entries)
+	}
+
+	var out bytes.Buffer
+	if got := baselinePassSource(t, newBaseline(&out), filepath.Join(dir, "b.go"), after, map[int]string{7: userNil}); len(got) != 0 {
+		t.Errorf("Moved finding should still match its baseline entry, got %v", got)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Unexpected stale report:\n%s", out.String())
+	}
+
+	// A different statement at the same place is a new finding
+	const changed = `package p
+
+func handler() {
+	resp.User = other
+}
+`
+	out.Reset()
+	if got := baselinePassSource(t, newBaseline(&out), filepath.Join(dir, "a.go"), changed, map[int]string{4: userNil}); len(got) != 1 {
+		t.Errorf("Changed statement should not match the baseline, got %v", got)
+	}
+	if !strings.Contains(out.String(), "stale baseline entry a.go:4") {
+		t.Errorf("Expected the old entry to be stale, got:\n%s", out.String())
+	}
+}
+
+// TestBaselineCoreKinds tests that baselines written before the core checks had kinds
+// still match their findings
+// TestBaselineNestedFields tests that the findings about the fields of a nested message,
+// reported together on the variable holding it, each get an entry keyed on their path
+// from the response
+func TestBaselineNestedFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	flag.Set("baseline", path)
+	defer flag.Set("baseline", "")
+
+	flag.Set("write-baseline", "true")
+	runExamples(t)
+	flag.Set("write-baseline", "false")
+
+	fingerprints := make(map[string]bool)
+	var paths []string
+	for _, entry := range readBaseline(t, path) {
+		fingerprints[entry.Fingerprint] = true
+		if entry.Function == "badVariableUsage" {
+			paths = append(paths, entry.FieldPath)
+		}
+	}
+	want := []string{"UserResponse.User.Address", "UserResponse.User.ContactInfo", "UserResponse.User.CreatedAt"}
+	if !reflect.DeepEqual(paths, want) || len(fingerprints) != 5 {
+		t.Fatalf("Expected an entry for each field, got paths %q and %d fingerprints", paths, len(fingerprints))
+	}
+
+	if code, out := runExamples(t); code != 0 {
+		t.Errorf("Expected the baseline to accept every finding, got exit status %d:\n%s", code, out)
+	}
+}
+
+func TestBaselineCoreKinds(t *testing.T) {
+	old := baselineEntry{File: "a.go", Line: 4, Message: "nil assignment"}
+	finding := old
+	finding.Category = analyzer.KindNilLiteral
+	if !old.matches(finding) {
+		t.Error("Expected an entry without a category to match the core finding")
+	}
+	if got := fingerprintCategory(analyzer.KindMissingField); got != "" {
+		t.Errorf("Expected core kinds to be left out of fingerprints, got %q", got)
+	}
+	if got := fingerprintCategory("map-lookup"); got != "map-lookup" {
+		t.Errorf("Expected opt-in rule categories in fingerprints, got %q", got)
+	}
+}
+
+func TestBaselineOrder(t *testing.T) {
+	dir := t.TempDir()
+	b := newBaseline(&bytes.Buffer{})
+	b.path = filepath.Join(dir, "baseline.json")
+	entries := []baselineEntry{
+		{File: "b.go", Line: 1, Message: "x"},
+		{File: "a.go", Line: 2, Message: "y"},
+		{File: "a.go", Line: 2, Message: "x"},
+		{File: "a.go", Line: 1, Category: "map-lookup", Message: "z"},
+	}
+
+	// Whichever order packages finish in, the file is the same
+	var saved []string
+	for _, order := range [][]int{{0, 1, 2, 3}, {3, 2, 1, 0}} {
+		b.entries = nil
+		for _, i := range order {
+			b.entries = append(b.entries, entries[i])
+		}
+		if err := b.save(); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(b.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		saved = append(saved, string(data))
+	}
+	if saved[0] != saved[1] {
+		t.Errorf("Baseline depends on the order entries were found in:\n%s\n%s", saved[0], saved[1])
+	}
+	if got := readBaseline(t, b.path); got[1].Message != "x" || got[2].Message != "y" {
+		t.Errorf("Entries on the same line should be sorted by message, got %+v", got)
+	}
+}
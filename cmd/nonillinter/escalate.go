@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/token"
+	"strings"
+
+	"github.com/nickheyer/go_no_nil_linter/analyzer"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+)
+
+var (
+	escalateBaselinedFlag  = flag.Int("escalate-baselined", 0, "report advisory findings as errors when more than this many -baseline entries are for the same field path; 0 disables")
+	escalateSuppressedFlag = flag.Int("escalate-suppressed", 0, "report advisory findings as errors in files with more than this many ignore directives; 0 disables")
+)
+
+// escalation implements -escalate-baselined and -escalate-suppressed. Advisory findings
+// are warnings, which are easy to leave be; in the places that keep collecting accepted
+// findings they are reported as errors instead, so the hotspots get cleaned up rather
+// than baselined once more. It runs on the findings the baseline lets through, so
+// accepted findings keep matching their entries.
+type escalation struct {
+	baseline *baseline
+	paths    map[string]int
+}
+
+func newEscalation(b *baseline) *escalation {
+	return &escalation{baseline: b}
+}
+
+// apply escalates the advisory findings of the analyses of the packages named
+func (e *escalation) apply(actions []*checker.Action) {
+	if *escalateBaselinedFlag <= 0 && *escalateSuppressedFlag <= 0 {
+		return
+	}
+	e.paths = e.baselinedPaths()
+	for _, act := range actions {
+		fset := act.Package.Fset
+		suppressions := make(map[*token.File]int)
+		for _, file := range act.Package.Syntax {
+			suppressions[fset.File(file.Pos())] = len(analyzer.IgnoreDirectives(file, analyzer.Analyzer.Name))
+		}
+		for i, d := range act.Diagnostics {
+			if recordedSeverity(act, d) != analyzer.SeverityAdvisory {
+				continue
+			}
+			_, path := recordedField(act, d)
+			if reason := e.reason(path, suppressions[fset.File(d.Pos)]); reason != "" {
+				message := strings.TrimPrefix(d.Message, analyzer.SeverityAdvisory.Prefix())
+				act.Diagnostics[i].Message = fmt.Sprintf("%s (escalated: %s)", message, reason)
+				escalateFinding(act, d, act.Diagnostics[i].Message)
+			}
+		}
+	}
+}
+
+// reason says why an advisory finding about the field path is escalated, or is empty
+// when it isn't
+func (e *escalation) reason(path string, suppressions int) string {
+	if limit := *escalateBaselinedFlag; limit > 0 {
+		if path != "" {
+			if n := e.paths[path]; n > limit {
+				return fmt.Sprintf("%s has %d baseline entries", path, n)
+			}
+		}
+	}
+	if limit := *escalateSuppressedFlag; limit > 0 && suppressions > limit {
+		return fmt.Sprintf("the file has %d ignore directives", suppressions)
+	}
+	return ""
+}
+
+// escalateFinding gives the analyzer's record of an escalated diagnostic its new message
+// and raises its severity, so the reports written later still find the field path
+// recorded for it and report it as an error
+func escalateFinding(act *checker.Action, d analysis.Diagnostic, message string) {
+	res, ok := act.Result.(*analyzer.Result)
+	if !ok {
+		return
+	}
+	for i, f := range res.Findings {
+		if f.Diagnostic.Pos == d.Pos && f.Diagnostic.Category == d.Category && f.Diagnostic.Message == d.Message {
+			res.Findings[i].Diagnostic.Message = message
+			res.Findings[i].Severity = analyzer.SeverityError
+			return
+		}
+	}
+}
+
+// baselinedPaths counts the baseline entries for each field path, as the baseline file
+// had them before -write-baseline or -prune-baseline changed it
+func (e *escalation) baselinedPaths() map[string]int {
+	paths := make(map[string]int)
+	for _, entry := range e.baseline.loaded {
+		if entry.FieldPath != "" {
+			paths[entry.FieldPath]++
+		}
+	}
+	return paths
+}
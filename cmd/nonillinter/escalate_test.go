@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nickheyer/go_no_nil_linter/analyzer"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+)
+
+// escalationPass applies the baseline and the escalation to findings with messages at
+// the start of a one-file package, returning the messages that got through
+func escalationPass(t *testing.T, b *baseline, filename, src string, messages ...string) []string {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := new(analyzer.Result)
+	act := &checker.Action{Package: &packages.Package{
+		PkgPath: "example.com/p",
+		Fset:    fset,
+		Syntax:  []*ast.File{file},
+		Types:   types.NewPackage("example.com/p", "p"),
+	}, Result: res}
+	for _, message := range messages {
+		d := analysis.Diagnostic{Pos: file.Name.Pos(), Message: message}
+		act.Diagnostics = append(act.Diagnostics, d)
+		recordFinding(res, d)
+	}
+	if err := b.apply([]*checker.Action{act}); err != nil {
+		t.Fatal(err)
+	}
+	newEscalation(b).apply([]*checker.Action{act})
+	var reported []string
+	for _, d := range act.Diagnostics {
+		reported = append(reported, d.Message)
+	}
+	return reported
+}
+
+const (
+	userLookup  = "advisory: map lookup assigned to non-optional message field 'User' in protobuf message 'pb.UserResponse' may be nil for a missing key; check it or use the comma-ok form"
+	loginLookup = "advisory: map lookup assigned to non-optional message field 'LastLogin' in protobuf message 'pb.UserResponse' may be nil for a missing key; check it or use the comma-ok form"
+)
+
+func TestEscalateBaselined(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".nonillinter-baseline.json")
+	var entries []baselineEntry
+	for i := 0; i < 3; i++ {
+		entries = append(entries, baselineEntry{Package: "example.com/other", Fingerprint: "f" + string(rune('0'+i)), File: "other/a.go", Line: i + 1, FieldPath: "UserResponse.User", Message: "accepted"})
+	}
+	data, err := json.Marshal(baselineFile{Entries: entries})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	flag.Set("baseline", path)
+	defer flag.Set("baseline", "")
+	flag.Set("escalate-baselined", "2")
+	defer flag.Set("escalate-baselined", "0")
+
+	reported := escalationPass(t, newBaseline(new(bytes.Buffer)), filepath.Join(dir, "p", "p.go"), "package p\n", userLookup, loginLookup)
+	if len(reported) != 2 {
+		t.Fatalf("Expected 2 findings, got %q", reported)
+	}
+	if want := strings.TrimPrefix(userLookup, "advisory: ") + " (escalated: UserResponse.User has 3 baseline entries)"; reported[0] != want {
+		t.Errorf("Expected the User finding to be escalated, got %q", reported[0])
+	}
+	if reported[1] != loginLookup {
+		t.Errorf("Expected the LastLogin finding to stay advisory, got %q", reported[1])
+	}
+
+	flag.Set("escalate-baselined", "3")
+	reported = escalationPass(t, newBaseline(new(bytes.Buffer)), filepath.Join(dir, "p", "p.go"), "package p\n", userLookup)
+	if len(reported) != 1 || reported[0] != userLookup {
+		t.Errorf("Expected no escalation at the limit, got %q", reported)
+	}
+}
+
+func TestEscalateSuppressed(t *testing.T) {
+	src := `package p
+
+//nonillinter:ignore filled in by the gateway
+var a = 1
+
+var b = 2 //nolint:nonillinter // set by the caller
+
+//nonillinter:ignore legacy response
+var c = 3
+
+// nonillinter:ignore is only mentioned here
+var d = 4
+`
+	flag.Set("escalate-suppressed", "2")
+	defer flag.Set("escalate-suppressed", "0")
+
+	reported := escalationPass(t, newBaseline(new(bytes.Buffer)), "p.go", src, userLookup, "non-optional message field 'User' not initialized in protobuf message 'pb.UserResponse'")
+	if len(reported) != 2 {
+		t.Fatalf("Expected 2 findings, got %q", reported)
+	}
+	if want := strings.TrimPrefix(userLookup, "advisory: ") + " (escalated: the file has 3 ignore directives)"; reported[0] != want {
+		t.Errorf("Expected the advisory finding to be escalated, got %q", reported[0])
+	}
+	if strings.Contains(reported[1], "escalated") {
+		t.Errorf("Expected errors to be left alone, got %q", reported[1])
+	}
+
+	flag.Set("escalate-suppressed", "3")
+	if reported := escalationPass(t, newBaseline(new(bytes.Buffer)), "p.go", src, userLookup); len(reported) != 1 || reported[0] != userLookup {
+		t.Errorf("Expected no escalation at the limit, got %q", reported)
+	}
+}
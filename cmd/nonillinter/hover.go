@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/nickheyer/go_no_nil_linter/analyzer"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+)
+
+// runHover implements `nonillinter hover [-json] [analyzer flags] file.go:line:col`
+func runHover(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("hover", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	asJSON := flags.Bool("json", false, "print the explanation as JSON instead of Markdown")
+	// Analyzer flags such as -optional-types and -config decide what is required
+	analyzer.Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		flags.Var(f.Value, f.Name, f.Usage)
+	})
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: nonillinter hover [-json] [analyzer flags] file.go:line:column")
+		fmt.Fprintln(stderr, "Explains whether the protobuf field at the position is required, and why, as")
+		fmt.Fprintln(stderr, "hover text for editors. The column counts bytes from 1, as in diagnostics.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	// Positions take the form diagnostics print them in; see triage.go
+	posn := &triageFinding{Posn: flags.Arg(0)}
+	if err := posn.parsePosn(); err != nil || posn.line < 1 || posn.column < 1 {
+		fmt.Fprintf(stderr, "hover: position %q must have the form file.go:line:column\n", flags.Arg(0))
+		return 2
+	}
+	req, err := explainFieldAt(posn.file, posn.line, posn.column)
+	if err != nil {
+		fmt.Fprintf(stderr, "hover: %v\n", err)
+		return 1
+	}
+
+	if *asJSON {
+		data, err := json.MarshalIndent(req, "", "  ")
+		if err != nil {
+			fmt.Fprintf(stderr, "hover: %v\n", err)
+			return 1
+		}
+		stdout.Write(append(data, '\n'))
+		return 0
+	}
+	fmt.Fprint(stdout, hoverMarkdown(req))
+	return 0
+}
+
+// explainFieldAt loads the package of file and explains the field named at line:column,
+// in its declaration, a selector or a composite literal key
+func explainFieldAt(file string, line, column int) (analyzer.FieldRequirement, error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return analyzer.FieldRequirement{}, err
+	}
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax | packages.NeedImports | packages.NeedDeps,
+		Dir:  filepath.Dir(abs),
+	}, "file="+abs)
+	if err != nil {
+		return analyzer.FieldRequirement{}, err
+	}
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Syntax {
+			tf := pkg.Fset.File(f.Pos())
+			if tf == nil || tf.Name() != abs {
+				continue
+			}
+			if line > tf.LineCount() {
+				return analyzer.FieldRequirement{}, fmt.Errorf("%s has only %d lines", file, tf.LineCount())
+			}
+			pos := tf.LineStart(line) + token.Pos(column-1)
+			field := fieldAt(f, pos, pkg.TypesInfo)
+			if field == nil {
+				return analyzer.FieldRequirement{}, fmt.Errorf("no struct field at %s:%d:%d", file, line, column)
+			}
+			msg := declaringType(field)
+			if msg == nil {
+				return analyzer.FieldRequirement{}, fmt.Errorf("field %s isn't declared by a named type", field.Name())
+			}
+			req, ok := analyzer.ExplainField(msg, field)
+			if !ok {
+				return analyzer.FieldRequirement{}, fmt.Errorf("%s isn't a protobuf message", msg.Name())
+			}
+			return req, nil
+		}
+	}
+	return analyzer.FieldRequirement{}, errors.New("no package holds " + file)
+}
+
+// fieldAt returns the struct field the identifier at pos declares or refers to, or nil
+func fieldAt(file *ast.File, pos token.Pos, info *types.Info) *types.Var {
+	path, _ := astutil.PathEnclosingInterval(file, pos, pos)
+	for _, n := range path {
+		id, ok := n.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		if v, ok := info.ObjectOf(id).(*types.Var); ok && v.IsField() {
+			return v
+		}
+	}
+	return nil
+}
+
+// declaringType returns the named struct type of the package declaring field, or nil
+func declaringType(field *types.Var) *types.TypeName {
+	if field.Pkg() == nil {
+		return nil
+	}
+	scope := field.Pkg().Scope()
+	for _, name := range scope.Names() {
+		obj, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		structType, ok := obj.Type().Underlying().(*types.Struct)
+		if !ok {
+			continue
+		}
+		for i := 0; i < structType.NumFields(); i++ {
+			if structType.Field(i) == field {
+				return obj
+			}
+		}
+	}
+	return nil
+}
+
+// hoverMarkdown renders an explanation as hover text
+func hoverMarkdown(req analyzer.FieldRequirement) string {
+	var b strings.Builder
+	verdict := "optional"
+	if req.Required {
+		verdict = "required"
+	}
+	fmt.Fprintf(&b, "**%s.%s** is %s for nonillinter: %s.\n\n", req.Message, req.Field, verdict, req.Reason)
+	if req.Checked {
+		fmt.Fprintf(&b, "%s is checked wherever it is built.\n\n", req.Message)
+	} else {
+		fmt.Fprintf(&b, "%s is checked only when nested in a checked message.\n\n", req.Message)
+	}
+	fmt.Fprintf(&b, "[Rule documentation](%s)\n", req.Rule)
+	return b.String()
+}
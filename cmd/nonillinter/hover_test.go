@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nickheyer/go_no_nil_linter/analyzer"
+)
+
+func TestRunHover(t *testing.T) {
+	// The generated example code is its own module, so load it from there
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir("../../gen"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	for _, tt := range []struct {
+		posn     string
+		field    string
+		required bool
+		reason   string
+	}{
+		// Address.Location, a singular message field
+		{"example/v1/service.pb.go:84:2", "example.v1.Address.Location", true, "singular message field"},
+		// ContactInfo.MailingAddress, proto3 optional
+		{"example/v1/service.pb.go:160:2", "example.v1.ContactInfo.MailingAddress", false, "declared optional in the .proto file"},
+		// ListUsersResponse.Users, repeated
+		{"example/v1/service.pb.go:373:2", "example.v1.ListUsersResponse.Users", false, "repeated fields"},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := runHover([]string{"-json", tt.posn}, &stdout, &stderr); code != 0 {
+			t.Fatalf("hover %s exited with %d: %s", tt.posn, code, stderr.String())
+		}
+		var req analyzer.FieldRequirement
+		if err := json.Unmarshal(stdout.Bytes(), &req); err != nil {
+			t.Fatal(err)
+		}
+		if req.Message+"."+req.Field != tt.field || req.Required != tt.required || !strings.Contains(req.Reason, tt.reason) {
+			t.Errorf("Expected %s required=%v because of %s, got %+v", tt.field, tt.required, tt.reason, req)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runHover([]string{"example/v1/service.pb.go:84:2"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("hover exited with %d: %s", code, stderr.String())
+	}
+	if !strings.HasPrefix(stdout.String(), "**example.v1.Address.Location** is required") || !strings.Contains(stdout.String(), "[Rule documentation](https://") {
+		t.Errorf("Unexpected hover text:\n%s", stdout.String())
+	}
+}
+
+func TestRunHoverUsage(t *testing.T) {
+	for _, args := range [][]string{nil, {"service.pb.go"}, {"service.pb.go:x:1"}} {
+		var stdout, stderr bytes.Buffer
+		if code := runHover(args, &stdout, &stderr); code != 2 {
+			t.Errorf("Expected exit code 2 for %q, got %d", args, code)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+)
+
+var jsonReportFlag = flag.String("json-report", "", "also write the findings to this JSON file, each with its package, position, violation kind, message type and field path, and the verification coverage of each package")
+
+// jsonReport is the -json-report file
+type jsonReport struct {
+	Findings []reportFinding   `json:"findings"`
+	Coverage []packageCoverage `json:"coverage"`
+}
+
+func newJSONReport() *reportFile {
+	return newReportFile(jsonReportFlag, writeJSONReport)
+}
+
+func writeJSONReport(path string, findings []reportFinding, coverage []packageCoverage) error {
+	report := jsonReport{Findings: findings, Coverage: coverage}
+	if report.Findings == nil {
+		report.Findings = []reportFinding{}
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
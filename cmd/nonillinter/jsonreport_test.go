@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestJSONReport(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "findings.json")
+	flag.Set("json-report", path)
+	defer flag.Set("json-report", "")
+	saveReportFile(t, newJSONReport(), dir)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report jsonReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Findings) != 3 {
+		t.Fatalf("Expected the test variant's repeats to be dropped, got:\n%s", data)
+	}
+
+	first := report.Findings[0]
+	want := reportFinding{
+		Package:     "example.com/svc",
+		File:        "svc/handler.go",
+		Line:        3,
+		Column:      1,
+		EndLine:     3,
+		EndColumn:   10,
+		Kind:        "nested-nil",
+		Message:     "nil assignment to non-optional message field 'User.Address' in protobuf message 'stubpb.UserResponse'",
+		Fingerprint: first.Fingerprint,
+	}
+	if first != want || first.Fingerprint == "" {
+		t.Errorf("Unexpected first finding\n%+v\nwant\n%+v", first, want)
+	}
+	if f := report.Findings[1]; f.Kind != "map-lookup" {
+		t.Errorf("Expected the map lookup, got %+v", f)
+	}
+	if f := report.Findings[2]; f.Kind != "nil-variable" {
+		t.Errorf("Expected the nil variable, got %+v", f)
+	}
+
+	wantCoverage := []packageCoverage{{Package: "example.com/svc", Sites: 5, Verified: 4, Trusted: 1, VerifiedFraction: 0.8}}
+	if !reflect.DeepEqual(report.Coverage, wantCoverage) {
+		t.Errorf("Expected the test variant's coverage, got %+v", report.Coverage)
+	}
+}
+
+// TestJSONReportFieldPaths tests that findings are written with the message type and field
+// path the analyzer recorded, from the response down to fields of nested messages
+func TestJSONReportFieldPaths(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "findings.json")
+	flag.Set("json-report", path)
+	defer flag.Set("json-report", "")
+	runExamples(t)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report jsonReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range report.Findings {
+		got = append(got, f.MessageType+" "+f.FieldPath)
+	}
+	// The fields of the User variable are reported with the path from the response
+	want := []string{
+		"examplev1.UserResponse UserResponse.User",
+		"examplev1.UserResponse UserResponse.User.Address",
+		"examplev1.UserResponse UserResponse.User.ContactInfo",
+		"examplev1.UserResponse UserResponse.User.CreatedAt",
+		"examplev1.UserResponse UserResponse.User",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected field paths %q, got %q", want, got)
+	}
+}
@@ -1,10 +1,109 @@
 package main
 
 import (
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/nickheyer/go_no_nil_linter/analyzer"
-	"golang.org/x/tools/go/analysis/singlechecker"
+	"github.com/nickheyer/go_no_nil_linter/driver"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
 )
 
 func main() {
-	singlechecker.Main(analyzer.Analyzer)
-}
\ No newline at end of file
+	// Subcommands are handled before the driver takes over flag parsing
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate-testdata":
+			os.Exit(runMigrateTestdata(os.Args[2:], os.Stdout, os.Stderr))
+		case "export-policy":
+			os.Exit(runExportPolicy(os.Args[2:], os.Stdout, os.Stderr))
+		case "triage":
+			os.Exit(runTriage(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+		case "print-problem-matcher":
+			os.Exit(runPrintProblemMatcher(os.Args[2:], os.Stdout, os.Stderr))
+		case "hover":
+			os.Exit(runHover(os.Args[2:], os.Stdout, os.Stderr))
+		}
+	}
+
+	os.Args = expandVerboseFlag(os.Args)
+	// go vet runs the analyzer alone, once per package; the flags of the run as a whole,
+	// such as -baseline and -summary, only apply when nonillinter runs on its own
+	driver.Command(analyzer.Analyzer, func(patterns []string, opts *driver.Options) int {
+		return run(patterns, opts)
+	})
+}
+
+// run analyzes the packages named. The findings of those packages go through -baseline,
+// -escalate-baselined, -escalate-suppressed and -autofix-rules before the driver prints
+// and fixes them, and the report files and the summary follow once it's done.
+func run(patterns []string, opts *driver.Options) int {
+	tracker := newRunTracker(opts.Stderr, os.Exit)
+	baseline := newBaseline(opts.Stderr)
+	a := tracker.wrap(analyzer.Analyzer)
+	opts.Loaded = tracker.begin
+	opts.Analyzed = func(graph *checker.Graph) error {
+		actions := rootActions(graph, a)
+		if err := baseline.apply(actions); err != nil {
+			return err
+		}
+		newEscalation(baseline).apply(actions)
+		if opts.Fix {
+			filterAutofixes(actions)
+		}
+		return nil
+	}
+
+	graph, code := driver.Run(patterns, []*analysis.Analyzer{a}, opts)
+	if graph == nil {
+		return code
+	}
+	actions := rootActions(graph, a)
+	for _, report := range []*reportFile{newSarifReport(), newJSONReport()} {
+		if err := report.save(actions); err != nil {
+			fmt.Fprintf(opts.Stderr, "nonillinter: %v\n", err)
+			return 1
+		}
+	}
+	if *summaryFlag && !opts.JSON {
+		fmt.Fprintln(opts.Stderr, newRunSummary(actions).line())
+	}
+	return code
+}
+
+// rootActions returns the analyses by a of the packages named on the command line, whose
+// findings the driver prints. Generated test main packages and packages the driver
+// couldn't analyze are left out.
+func rootActions(graph *checker.Graph, a *analysis.Analyzer) []*checker.Action {
+	var actions []*checker.Action
+	for _, act := range graph.Roots {
+		if act.Analyzer == a && act.Err == nil && !strings.HasSuffix(act.Package.ID, ".test") {
+			actions = append(actions, act)
+		}
+	}
+	return actions
+}
+
+// expandVerboseFlag rewrites -v to the analyzer's -verbose flag. The analysis driver
+// registers -v itself, as a no-op kept for legacy vet scripts, so the analyzer can't.
+// Arguments after the first non-flag argument are left alone.
+func expandVerboseFlag(args []string) []string {
+	expanded := append([]string(nil), args...)
+	for i := 1; i < len(expanded); i++ {
+		arg := expanded[i]
+		if arg == "--" || !strings.HasPrefix(arg, "-") {
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if name != "v" {
+			continue
+		}
+		expanded[i] = "-verbose"
+		if hasValue {
+			expanded[i] += "=" + value
+		}
+	}
+	return expanded
+}
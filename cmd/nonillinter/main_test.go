@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nickheyer/go_no_nil_linter/analyzer"
+	"github.com/nickheyer/go_no_nil_linter/driver"
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestExpandVerboseFlag(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"nonillinter", "-v", "./..."}, []string{"nonillinter", "-verbose", "./..."}},
+		{[]string{"nonillinter", "--v=false", "./..."}, []string{"nonillinter", "-verbose=false", "./..."}},
+		{[]string{"nonillinter", "-vv", "./..."}, []string{"nonillinter", "-vv", "./..."}},
+		{[]string{"nonillinter", "./...", "-v"}, []string{"nonillinter", "./...", "-v"}},
+	}
+
+	for _, tt := range tests {
+		if got := expandVerboseFlag(tt.args); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expandVerboseFlag(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+// runExamples runs the linter on the examples module, whose findings are in its own
+// package and whose dependencies are analyzed for facts, and returns the exit status
+// and what it printed to stderr
+func runExamples(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir("../../examples"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	fs := flag.NewFlagSet("nonillinter", flag.ContinueOnError)
+	opts := driver.RegisterFlags(fs, []*analysis.Analyzer{analyzer.Analyzer})
+	if err := fs.Parse(append(args, "./...")); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	opts.Stdout, opts.Stderr = &stdout, &stderr
+	return run(fs.Args(), opts), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	code, out := runExamples(t)
+	if code != 3 {
+		t.Errorf("exit status = %d, want 3:\n%s", code, out)
+	}
+	// The summary follows the findings it counts
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	want := "nonillinter: 3 nested-nil, 1 missing-field, 1 nil-variable across 1 package"
+	if len(lines) != 6 || lines[len(lines)-1] != want {
+		t.Errorf("got output\n%s\nwant 5 findings followed by %q", out, want)
+	}
+}
+
+func TestRunRootPackages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	flag.Set("baseline", path)
+	defer flag.Set("baseline", "")
+	flag.Set("progress", "true")
+	defer flag.Set("progress", "false")
+
+	// Dependencies are analyzed for their facts, but only the examples package and its
+	// test variant are tracked and recorded
+	flag.Set("write-baseline", "true")
+	code, out := runExamples(t)
+	flag.Set("write-baseline", "false")
+	if code != 0 || !strings.Contains(out, "[2/2] github.com/nickheyer/go_no_nil_linter/examples ") || strings.Contains(out, "[3/") {
+		t.Errorf("exit status = %d with progress\n%s\nwant 0 and the examples package twice", code, out)
+	}
+	entries := readBaseline(t, path)
+	for _, e := range entries {
+		if e.Package != "github.com/nickheyer/go_no_nil_linter/examples" {
+			t.Errorf("Baseline entry for a dependency: %+v", e)
+		}
+	}
+	if len(entries) != 5 {
+		t.Errorf("Expected the 5 findings of the examples package, got %d entries", len(entries))
+	}
+
+	if code, out := runExamples(t); code != 0 || strings.Contains(out, "stale") {
+		t.Errorf("exit status = %d with output\n%s\nwant the baseline to accept every finding", code, out)
+	}
+}
+
+func TestRunJSONReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "findings.json")
+	flag.Set("json-report", path)
+	defer flag.Set("json-report", "")
+	runExamples(t)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report jsonReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	// Dependencies are analyzed for their facts, but only the examples package is reported
+	if len(report.Findings) != 5 || len(report.Coverage) != 1 {
+		t.Errorf("Expected the 5 findings and the coverage of the examples package, got:\n%s", data)
+	}
+	for _, f := range report.Findings {
+		if f.Package != "github.com/nickheyer/go_no_nil_linter/examples" {
+			t.Errorf("Finding of a dependency: %+v", f)
+		}
+	}
+	for _, c := range report.Coverage {
+		if c.Package != "github.com/nickheyer/go_no_nil_linter/examples" {
+			t.Errorf("Coverage of a dependency: %+v", c)
+		}
+	}
+}
+
+func TestRunSarif(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.sarif")
+	flag.Set("sarif", path)
+	defer flag.Set("sarif", "")
+	runExamples(t)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatal(err)
+	}
+	// Dependencies are analyzed for their facts, but only the examples package is reported
+	if len(log.Runs) != 1 || len(log.Runs[0].Results) != 5 {
+		t.Fatalf("Expected the 5 findings of the examples package, got:\n%s", data)
+	}
+	for _, result := range log.Runs[0].Results {
+		if uri := result.Locations[0].PhysicalLocation.ArtifactLocation.URI; strings.Contains(uri, "/") {
+			t.Errorf("Result outside the examples package: %s", uri)
+		}
+	}
+}
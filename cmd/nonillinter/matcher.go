@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+)
+
+// Problem matchers turn lines of a CI job's log into annotations on the lines of code
+// they name. Findings are printed as file:line:column: message, with advisory: and
+// info: prefixes for the findings that don't fail the run, so each kind gets its own
+// matcher and severity. JavaScript regexps are used, as the runner evaluates them.
+const (
+	findingPrefix = `^(.+?\.go):(\d+):(\d+): `
+
+	// errorPattern leaves prefixed findings to the other matchers, so no line is
+	// annotated twice
+	errorPattern    = findingPrefix + `(?!advisory: |info: )(.*)$`
+	advisoryPattern = findingPrefix + `advisory: (.*)$`
+	infoPattern     = findingPrefix + `info: (.*)$`
+)
+
+// problemMatcherFile is the file format read by GitHub's ::add-matcher:: command
+type problemMatcherFile struct {
+	ProblemMatcher []problemMatcher `json:"problemMatcher"`
+}
+
+type problemMatcher struct {
+	Owner    string                  `json:"owner"`
+	Severity string                  `json:"severity"`
+	Pattern  []problemMatcherPattern `json:"pattern"`
+}
+
+type problemMatcherPattern struct {
+	Regexp  string `json:"regexp"`
+	File    int    `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message int    `json:"message"`
+}
+
+// problemMatchers returns the matchers for the default text output, with owners
+// starting with owner, which ::remove-matcher:: takes to turn them off again
+func problemMatchers(owner string) problemMatcherFile {
+	matcher := func(suffix, severity, regexp string) problemMatcher {
+		return problemMatcher{
+			Owner:    owner + suffix,
+			Severity: severity,
+			Pattern:  []problemMatcherPattern{{Regexp: regexp, File: 1, Line: 2, Column: 3, Message: 4}},
+		}
+	}
+	return problemMatcherFile{ProblemMatcher: []problemMatcher{
+		matcher("", "error", errorPattern),
+		matcher("-advisory", "warning", advisoryPattern),
+		matcher("-info", "notice", infoPattern),
+	}}
+}
+
+// runPrintProblemMatcher implements `nonillinter print-problem-matcher [-owner name]`
+func runPrintProblemMatcher(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("print-problem-matcher", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	owner := flags.String("owner", "nonillinter", "owner of the matchers, with -advisory and -info appended for those findings")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: nonillinter print-problem-matcher [-owner name]")
+		fmt.Fprintln(stderr, "Writes a problem matcher for the text output as JSON, for CI systems that annotate")
+		fmt.Fprintln(stderr, "code from log lines, such as GitHub Actions with ::add-matcher::.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return 2
+	}
+
+	data, err := json.MarshalIndent(problemMatchers(*owner), "", "  ")
+	if err != nil {
+		fmt.Fprintf(stderr, "print-problem-matcher: %v\n", err)
+		return 2
+	}
+	fmt.Fprintln(stdout, string(data))
+	return 0
+}
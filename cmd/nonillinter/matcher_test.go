@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestPrintProblemMatcher(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := runPrintProblemMatcher([]string{"-owner", "nonil"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	var file problemMatcherFile
+	if err := json.Unmarshal(stdout.Bytes(), &file); err != nil {
+		t.Fatal(err)
+	}
+	severities := make(map[string]string)
+	for _, m := range file.ProblemMatcher {
+		severities[m.Owner] = m.Severity
+	}
+	if severities["nonil"] != "error" || severities["nonil-advisory"] != "warning" || severities["nonil-info"] != "notice" {
+		t.Errorf("Unexpected matchers %v", severities)
+	}
+}
+
+// The runner evaluates the patterns as JavaScript regexps; RE2 has no lookahead, so
+// the error pattern is checked without it and the lookahead checked on its own
+func TestProblemMatcherPatterns(t *testing.T) {
+	lines := map[string]string{
+		"/src/svc/handler.go:42:3: nil assignment to non-optional message field 'User'":         "error",
+		"/src/svc/handler.go:42:3: advisory: map lookup assigned to non-optional message field": "advisory",
+		"/src/svc/handler.go:42:3: info: 'User' is set from a function call":                    "info",
+	}
+	patterns := map[string]*regexp.Regexp{
+		"error":    regexp.MustCompile(strings.Replace(errorPattern, "(?!advisory: |info: )", "", 1)),
+		"advisory": regexp.MustCompile(advisoryPattern),
+		"info":     regexp.MustCompile(infoPattern),
+	}
+	for line, kind := range lines {
+		m := patterns[kind].FindStringSubmatch(line)
+		if m == nil {
+			t.Errorf("Expected the %s pattern to match %q", kind, line)
+			continue
+		}
+		if m[1] != "/src/svc/handler.go" || m[2] != "42" || m[3] != "3" || strings.HasPrefix(m[4], kind+": ") {
+			t.Errorf("Unexpected groups %q for %q", m[1:], line)
+		}
+	}
+	if !strings.Contains(errorPattern, "(?!advisory: |info: )") {
+		t.Error("Expected the error pattern to leave advisory and info findings to their matchers")
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// messageRewrite rewrites the text of a `// want` expectation from an old diagnostic format to a new one
+type messageRewrite struct {
+	from *regexp.Regexp
+	to   string
+}
+
+// messageMigrations lists every diagnostic wording change, oldest first.
+// Add an entry here whenever a diagnostic message changes so that adopters can
+// run `nonillinter migrate-testdata` against their own analysistest suites.
+var messageMigrations = []messageRewrite{
+	// Types are rendered by their shortest unique qualified name instead of the full
+	// import path: '*github.com/acme/api/userpb.User' -> '*userpb.User'. The package
+	// name can differ from the last path element, so the qualifier is left open.
+	{
+		from: regexp.MustCompile(`'((?:\\\*)*)(?:[\w.\-]+/)+\w+\.(\w+)'`),
+		to:   `'$1(?:\w+/)*\w+\.$2'`,
+	},
+}
+
+// rewriteFlag collects -rewrite 'old=>new' flags
+type rewriteFlag []messageRewrite
+
+func (r *rewriteFlag) String() string { return "" }
+
+func (r *rewriteFlag) Set(value string) error {
+	from, to, ok := strings.Cut(value, "=>")
+	if !ok {
+		return fmt.Errorf("rewrite %q must have the form 'old=>new'", value)
+	}
+	re, err := regexp.Compile(from)
+	if err != nil {
+		return fmt.Errorf("rewrite %q: %v", value, err)
+	}
+	*r = append(*r, messageRewrite{from: re, to: to})
+	return nil
+}
+
+// runMigrateTestdata implements `nonillinter migrate-testdata [-w] [-rewrite old=>new] paths...`
+func runMigrateTestdata(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("migrate-testdata", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	write := flags.Bool("w", false, "write changes back to the files instead of listing them")
+	var extra rewriteFlag
+	flags.Var(&extra, "rewrite", "additional 'old=>new' regexp rewrite applied after the built-in migrations (repeatable)")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: nonillinter migrate-testdata [-w] [-rewrite 'old=>new'] path...")
+		fmt.Fprintln(stderr, "Rewrites `// want` expectations in testdata to the current diagnostic formats.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	rewrites := append(append([]messageRewrite{}, messageMigrations...), extra...)
+
+	exitCode := 0
+	for _, root := range flags.Args() {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !strings.HasSuffix(path, ".go") {
+				return nil
+			}
+
+			src, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			out, changes, err := migrateWantComments(path, src, rewrites)
+			if err != nil {
+				return err
+			}
+			if changes == 0 {
+				return nil
+			}
+
+			if *write {
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(path, out, info.Mode().Perm()); err != nil {
+					return err
+				}
+			}
+			fmt.Fprintf(stdout, "%s: %d expectation(s) migrated\n", path, changes)
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(stderr, "migrate-testdata: %v\n", err)
+			exitCode = 1
+		}
+	}
+	return exitCode
+}
+
+// migrateWantComments rewrites the expectations in every `// want` comment of a Go source file.
+// Only the comment text is touched; the rest of the file is preserved byte for byte.
+func migrateWantComments(filename string, src []byte, rewrites []messageRewrite) ([]byte, int, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var out bytes.Buffer
+	last := 0
+	changes := 0
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			text, ok := strings.CutPrefix(c.Text, "//")
+			if !ok || !strings.HasPrefix(strings.TrimSpace(text), "want ") {
+				continue
+			}
+			// The expectations follow the first "want " after the comment marker
+			bodyOffset := strings.Index(c.Text, "want ") + len("want ")
+			body := strings.TrimRight(c.Text[bodyOffset:], " \t")
+
+			migrated, n, err := migrateExpectations(body, rewrites)
+			if err != nil {
+				return nil, 0, fmt.Errorf("%s: %v", fset.Position(c.Pos()), err)
+			}
+			if n == 0 {
+				continue
+			}
+
+			bodyStart := fset.Position(c.Pos()).Offset + bodyOffset
+			out.Write(src[last:bodyStart])
+			out.WriteString(migrated)
+			last = bodyStart + len(body)
+			changes += n
+		}
+	}
+	out.Write(src[last:])
+	return out.Bytes(), changes, nil
+}
+
+// migrateExpectations rewrites each quoted expectation in the body of a `// want` comment,
+// keeping the original quoting style. It returns the number of expectations changed.
+func migrateExpectations(body string, rewrites []messageRewrite) (string, int, error) {
+	var out strings.Builder
+	changes := 0
+	rest := body
+	for {
+		trimmed := strings.TrimLeft(rest, " \t")
+		out.WriteString(rest[:len(rest)-len(trimmed)])
+		rest = trimmed
+		if rest == "" {
+			break
+		}
+
+		quoted, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			// Not an expectation (e.g. a trailing note); keep the remainder untouched
+			out.WriteString(rest)
+			break
+		}
+		rest = rest[len(quoted):]
+
+		pattern, err := strconv.Unquote(quoted)
+		if err != nil {
+			return "", 0, err
+		}
+		migrated := pattern
+		for _, rw := range rewrites {
+			migrated = rw.from.ReplaceAllString(migrated, rw.to)
+		}
+		if migrated == pattern {
+			out.WriteString(quoted)
+			continue
+		}
+
+		changes++
+		if strings.HasPrefix(quoted, "`") && !strings.Contains(migrated, "`") {
+			out.WriteString("`" + migrated + "`")
+		} else {
+			out.WriteString(strconv.Quote(migrated))
+		}
+	}
+	return out.String(), changes, nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestMigrateWantComments(t *testing.T) {
+	rewrites := []messageRewrite{
+		{from: regexp.MustCompile(`^nil assignment`), to: "[nil-assignment] nil assignment"},
+	}
+
+	src := "package a\n\n" +
+		"func f() {\n" +
+		"\tx = nil // want \"nil assignment to non-optional message field 'User'\"\n" +
+		"\ty = nil //want `nil assignment to 'X'` \"not initialized\"\n" +
+		"\t// an ordinary comment mentioning nil assignment\n" +
+		"}\n"
+	want := "package a\n\n" +
+		"func f() {\n" +
+		"\tx = nil // want \"[nil-assignment] nil assignment to non-optional message field 'User'\"\n" +
+		"\ty = nil //want `[nil-assignment] nil assignment to 'X'` \"not initialized\"\n" +
+		"\t// an ordinary comment mentioning nil assignment\n" +
+		"}\n"
+
+	got, changes, err := migrateWantComments("a.go", []byte(src), rewrites)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes != 2 {
+		t.Errorf("Expected 2 changes, got %d", changes)
+	}
+	if string(got) != want {
+		t.Errorf("Unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestMigrateWantCommentsNoChanges(t *testing.T) {
+	src := "package a\n\nvar x = 1 // want \"something\"\n"
+	got, changes, err := migrateWantComments("a.go", []byte(src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes != 0 || string(got) != src {
+		t.Errorf("Expected file to be unchanged, got %d changes:\n%s", changes, got)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/types"
+	"io"
+	"os"
+
+	"github.com/nickheyer/go_no_nil_linter/analyzer"
+	"golang.org/x/tools/go/packages"
+)
+
+// runExportPolicy implements `nonillinter export-policy [-o file] [analyzer flags] packages...`
+func runExportPolicy(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("export-policy", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	output := flags.String("o", "", "write the policy to this file instead of stdout")
+	// Analyzer flags such as -response-suffixes and -config decide what is required
+	analyzer.Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		flags.Var(f.Value, f.Name, f.Usage)
+	})
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: nonillinter export-policy [-o file] [analyzer flags] package...")
+		fmt.Fprintln(stderr, "Writes the required-field policy of the messages declared in the packages as JSON,")
+		fmt.Fprintln(stderr, "using proto full names and field numbers, for linters in other languages.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedName | packages.NeedTypes | packages.NeedSyntax | packages.NeedImports | packages.NeedDeps}, flags.Args()...)
+	if err != nil {
+		fmt.Fprintf(stderr, "export-policy: %v\n", err)
+		return 1
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		return 1
+	}
+	typesPkgs := make([]*types.Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		typesPkgs = append(typesPkgs, pkg.Types)
+	}
+
+	data, err := json.MarshalIndent(analyzer.ExportPolicy(typesPkgs), "", "  ")
+	if err != nil {
+		fmt.Fprintf(stderr, "export-policy: %v\n", err)
+		return 1
+	}
+	data = append(data, '\n')
+
+	if *output == "" {
+		stdout.Write(data)
+		return 0
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		fmt.Fprintf(stderr, "export-policy: %v\n", err)
+		return 1
+	}
+	return 0
+}
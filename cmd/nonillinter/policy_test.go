@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/nickheyer/go_no_nil_linter/analyzer"
+)
+
+func TestRunExportPolicy(t *testing.T) {
+	// The generated example code is its own module, so load it from there
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir("../../gen"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	var stdout, stderr bytes.Buffer
+	code := runExportPolicy([]string{"-response-suffixes=Response", "./example/v1"}, &stdout, &stderr)
+	defer analyzer.Analyzer.Flags.Set("response-suffixes", "Response,Reply,Result")
+	if code != 0 {
+		t.Fatalf("export-policy exited with %d: %s", code, stderr.String())
+	}
+
+	var policy analyzer.Policy
+	if err := json.Unmarshal(stdout.Bytes(), &policy); err != nil {
+		t.Fatal(err)
+	}
+	required := make(map[string][]analyzer.PolicyField)
+	for _, msg := range policy.Messages {
+		required[msg.Name] = msg.RequiredFields
+	}
+
+	fields := required["example.v1.Address"]
+	want := analyzer.PolicyField{Name: "location", Number: 4, JSONName: "location", Type: "example.v1.Location"}
+	if len(fields) != 1 || fields[0] != want {
+		t.Errorf("Expected example.v1.Address to require %+v, got %+v", want, fields)
+	}
+	for _, field := range required["example.v1.ListUsersResponse"] {
+		if field.Name == "fetched_at" && field.Type != "google.protobuf.Timestamp" {
+			t.Errorf("Expected fetched_at to be a google.protobuf.Timestamp, got %q", field.Type)
+		}
+	}
+}
+
+func TestRunExportPolicyUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := runExportPolicy(nil, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2 without packages, got %d", code)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/types"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nickheyer/go_no_nil_linter/analyzer"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+var (
+	timeoutFlag        = flag.Duration("timeout", 0, "abort the whole run after this long, listing the packages still being analyzed (0 disables)")
+	packageTimeoutFlag = flag.Duration("package-timeout", 0, "abort the run when analyzing a single package takes longer than this (0 disables)")
+	progressFlag       = flag.Bool("progress", false, "print each analyzed package with a completed/total count to stderr")
+)
+
+// runTracker wraps an analyzer's Run to implement -timeout, -package-timeout and -progress.
+// Timeouts exit the process with status 2 after printing which packages were still running,
+// so CI jobs fail fast instead of hanging silently. Only the packages named on the command
+// line are tracked: dependencies are analyzed for their facts, as many as they import.
+type runTracker struct {
+	out  io.Writer
+	exit func(int)
+
+	start time.Time
+	total int
+	roots map[*types.Package]bool
+
+	mu        sync.Mutex
+	completed int
+	running   map[string]time.Time
+}
+
+// newRunTracker starts the -timeout deadline, which counts from the start of the
+// command, so a run stuck loading packages is aborted too
+func newRunTracker(out io.Writer, exit func(int)) *runTracker {
+	t := &runTracker{out: out, exit: exit, start: time.Now(), running: make(map[string]time.Time)}
+	if *timeoutFlag > 0 {
+		time.AfterFunc(*timeoutFlag, func() {
+			t.abort(fmt.Sprintf("run exceeded -timeout %s", *timeoutFlag))
+		})
+	}
+	return t
+}
+
+// wrap returns a copy of a with its Run instrumented
+func (t *runTracker) wrap(a *analysis.Analyzer) *analysis.Analyzer {
+	wrapped := *a
+	run := a.Run
+	wrapped.Run = func(pass *analysis.Pass) (interface{}, error) {
+		if !t.roots[pass.Pkg] {
+			return run(pass)
+		}
+
+		pkgPath := pass.Pkg.Path()
+		t.started(pkgPath)
+
+		var timer *time.Timer
+		if *packageTimeoutFlag > 0 {
+			timer = time.AfterFunc(*packageTimeoutFlag, func() {
+				t.abort(fmt.Sprintf("analysis of %s exceeded -package-timeout %s", pkgPath, *packageTimeoutFlag))
+			})
+		}
+		result, err := run(pass)
+		if timer != nil {
+			timer.Stop()
+		}
+
+		var stats analyzer.Stats
+		if r, ok := result.(*analyzer.Result); ok {
+			stats = r.Stats
+		}
+		t.finished(pkgPath, stats)
+		return result, err
+	}
+	return &wrapped
+}
+
+// begin runs once the packages named are loaded, before the analysis starts. The total
+// counts them with their test variants; generated test main packages and packages with
+// errors aren't analyzed.
+func (t *runTracker) begin(initial []*packages.Package) {
+	roots := make(map[*types.Package]bool)
+	for _, pkg := range initial {
+		if !strings.HasSuffix(pkg.ID, ".test") && !pkg.IllTyped {
+			roots[pkg.Types] = true
+		}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.roots = roots
+	t.total = len(roots)
+}
+
+func (t *runTracker) started(pkgPath string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.running[pkgPath] = time.Now()
+}
+
+// finished records a package as done. -progress shows how many of its response sites
+// were verified, and how many of its functions got only the shallow checks under
+// -analysis-budget so the budget can be tuned.
+func (t *runTracker) finished(pkgPath string, stats analyzer.Stats) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	began := t.running[pkgPath]
+	delete(t.running, pkgPath)
+	t.completed++
+	if *progressFlag {
+		total := "?"
+		if t.total >= t.completed {
+			total = strconv.Itoa(t.total)
+		}
+		details := ""
+		if c := stats.Coverage; c.Sites() > 0 {
+			details += fmt.Sprintf(", %d/%d response site(s) verified, %d trusted, %d suppressed", c.Verified, c.Sites(), c.Trusted, c.Suppressed)
+		}
+		if stats.BudgetExceeded > 0 {
+			details += fmt.Sprintf(", analysis budget exceeded in %d function(s)", stats.BudgetExceeded)
+		}
+		fmt.Fprintf(t.out, "nonillinter: [%d/%s] %s (%s%s)\n",
+			t.completed, total, pkgPath, time.Since(began).Round(time.Millisecond), details)
+	}
+}
+
+// abort reports the packages still being analyzed and exits with status 2
+func (t *runTracker) abort(reason string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fmt.Fprintf(t.out, "nonillinter: %s after %s; %d package(s) completed\n",
+		reason, time.Since(t.start).Round(time.Millisecond), t.completed)
+	if t.roots == nil {
+		fmt.Fprintln(t.out, "nonillinter:   still loading packages")
+	}
+
+	running := make([]string, 0, len(t.running))
+	for pkgPath := range t.running {
+		running = append(running, pkgPath)
+	}
+	sort.Strings(running)
+	for _, pkgPath := range running {
+		fmt.Fprintf(t.out, "nonillinter:   still analyzing %s (%s)\n",
+			pkgPath, time.Since(t.running[pkgPath]).Round(time.Millisecond))
+	}
+	t.exit(2)
+}
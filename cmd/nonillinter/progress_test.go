@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"go/types"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nickheyer/go_no_nil_linter/analyzer"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+// beginTracking starts tracking packages with the paths given, which are named on the
+// command line, and returns their types
+func beginTracking(tracker *runTracker, paths ...string) []*types.Package {
+	var initial []*packages.Package
+	var pkgs []*types.Package
+	for _, path := range paths {
+		pkg := types.NewPackage(path, "p")
+		initial = append(initial, &packages.Package{ID: path, PkgPath: path, Types: pkg})
+		pkgs = append(pkgs, pkg)
+	}
+	// Generated test main packages aren't analyzed
+	initial = append(initial, &packages.Package{ID: "example.com/a.test", Types: types.NewPackage("example.com/a.test", "main")})
+	tracker.begin(initial)
+	return pkgs
+}
+
+func TestRunTrackerProgress(t *testing.T) {
+	flag.Set("progress", "true")
+	defer flag.Set("progress", "false")
+
+	var out bytes.Buffer
+	tracker := newRunTracker(&out, func(int) { t.Error("unexpected exit") })
+	roots := beginTracking(tracker, "example.com/a", "example.com/b")
+
+	wrapped := tracker.wrap(&analysis.Analyzer{
+		Name: "fake",
+		Run:  func(*analysis.Pass) (interface{}, error) { return "ok", nil },
+	})
+
+	// Dependencies are analyzed for facts first, and aren't counted
+	pkgs := append([]*types.Package{types.NewPackage("example.com/dep", "dep")}, roots...)
+	for _, pkg := range pkgs {
+		result, err := wrapped.Run(&analysis.Pass{Pkg: pkg})
+		if err != nil || result != "ok" {
+			t.Fatalf("Run returned %v, %v", result, err)
+		}
+	}
+
+	if !strings.Contains(out.String(), "[1/2] example.com/a") || !strings.Contains(out.String(), "[2/2] example.com/b") || strings.Contains(out.String(), "dep") {
+		t.Errorf("Unexpected progress output:\n%s", out.String())
+	}
+}
+
+func TestRunTrackerBudgetStats(t *testing.T) {
+	flag.Set("progress", "true")
+	defer flag.Set("progress", "false")
+
+	var out bytes.Buffer
+	tracker := newRunTracker(&out, func(int) { t.Error("unexpected exit") })
+	big := beginTracking(tracker, "example.com/big")[0]
+
+	wrapped := tracker.wrap(&analysis.Analyzer{
+		Name: "fake",
+		Run: func(*analysis.Pass) (interface{}, error) {
+			return &analyzer.Result{Stats: analyzer.Stats{
+				BudgetExceeded: 3,
+				Coverage:       analyzer.Coverage{Verified: 7, Trusted: 2, Suppressed: 1},
+			}}, nil
+		},
+	})
+	wrapped.Run(&analysis.Pass{Pkg: big})
+
+	if !strings.Contains(out.String(), "7/10 response site(s) verified, 2 trusted, 1 suppressed, analysis budget exceeded in 3 function(s)") {
+		t.Errorf("Unexpected progress output:\n%s", out.String())
+	}
+}
+
+func TestRunTrackerPackageTimeout(t *testing.T) {
+	flag.Set("package-timeout", "10ms")
+	defer flag.Set("package-timeout", "0")
+
+	var out bytes.Buffer
+	exited := make(chan int, 1)
+	tracker := newRunTracker(&out, func(code int) { exited <- code })
+	roots := beginTracking(tracker, "example.com/slow")
+
+	release := make(chan struct{})
+	wrapped := tracker.wrap(&analysis.Analyzer{
+		Name: "fake",
+		Run: func(*analysis.Pass) (interface{}, error) {
+			<-release
+			return nil, nil
+		},
+	})
+
+	// A dependency analyzed for facts has no deadline
+	go wrapped.Run(&analysis.Pass{Pkg: types.NewPackage("example.com/dep", "dep")})
+	go wrapped.Run(&analysis.Pass{Pkg: roots[0]})
+	defer close(release)
+
+	select {
+	case code := <-exited:
+		if code != 2 {
+			t.Errorf("Expected exit code 2, got %d", code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("package timeout did not fire")
+	}
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	if !strings.Contains(out.String(), "exceeded -package-timeout") || !strings.Contains(out.String(), "still analyzing example.com/slow") ||
+		strings.Contains(out.String(), "dep") {
+		t.Errorf("Unexpected timeout output:\n%s", out.String())
+	}
+}
+
+// The -timeout deadline counts from the start of the command, so it covers loading
+func TestRunTrackerTimeoutWhileLoading(t *testing.T) {
+	flag.Set("timeout", "10ms")
+	defer flag.Set("timeout", "0")
+
+	var out bytes.Buffer
+	exited := make(chan int, 1)
+	tracker := newRunTracker(&out, func(code int) { exited <- code })
+
+	select {
+	case code := <-exited:
+		if code != 2 {
+			t.Errorf("Expected exit code 2, got %d", code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout did not fire before the packages were loaded")
+	}
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	if !strings.Contains(out.String(), "run exceeded -timeout 10ms") || !strings.Contains(out.String(), "still loading packages") {
+		t.Errorf("Unexpected timeout output:\n%s", out.String())
+	}
+}
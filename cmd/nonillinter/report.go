@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/nickheyer/go_no_nil_linter/analyzer"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+)
+
+// reportFinding is a finding as written to the -sarif and -json-report files
+type reportFinding struct {
+	Package     string `json:"package"`
+	Function    string `json:"function,omitempty"`
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+	Column      int    `json:"column"`
+	EndLine     int    `json:"endLine,omitempty"`
+	EndColumn   int    `json:"endColumn,omitempty"`
+	Kind        string `json:"kind"`
+	MessageType string `json:"messageType,omitempty"`
+	FieldPath   string `json:"fieldPath,omitempty"`
+	Message     string `json:"message"`
+	Fingerprint string `json:"fingerprint"`
+
+	// Severity decides the SARIF level
+	Severity analyzer.Severity `json:"-"`
+}
+
+// packageCoverage is a package's verification coverage as written to the -json-report
+// file: its response construction sites by how far they were verified
+type packageCoverage struct {
+	Package          string  `json:"package"`
+	Sites            int     `json:"sites"`
+	Verified         int     `json:"verified"`
+	Trusted          int     `json:"trusted"`
+	Suppressed       int     `json:"suppressed"`
+	VerifiedFraction float64 `json:"verifiedFraction"`
+}
+
+// reportFile writes the findings of the packages named on the command line to the file
+// named by a flag, in the format of write, once the run is done. Dependencies are
+// analyzed for their facts only, so their findings and coverage are left out. Paths are
+// relative to the working directory, which code scanning and dashboards take to be the
+// repository root.
+type reportFile struct {
+	path  *string
+	write func(path string, findings []reportFinding, coverage []packageCoverage) error
+}
+
+func newReportFile(path *string, write func(string, []reportFinding, []packageCoverage) error) *reportFile {
+	return &reportFile{path: path, write: write}
+}
+
+// save writes the findings and coverage of the analyses of the packages named; call it
+// after the baseline is applied so suppressed findings are left out. A package's test
+// variant repeats the findings of the package, so they are kept once, and its coverage
+// counts the package's sites too, so the variant with the most sites is kept.
+func (r *reportFile) save(actions []*checker.Action) error {
+	if *r.path == "" {
+		return nil
+	}
+	root, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	fingerprints := &baseline{dir: root}
+
+	var findings []reportFinding
+	seen := make(map[string]bool)
+	coverage := make(map[string]analyzer.Coverage)
+	for _, act := range actions {
+		pkg := act.Package
+		if res, ok := act.Result.(*analyzer.Result); ok && res.Stats.Coverage.Sites() > coverage[pkg.PkgPath].Sites() {
+			coverage[pkg.PkgPath] = res.Stats.Coverage
+		}
+		for _, d := range act.Diagnostics {
+			f := newReportFinding(act, d, fingerprints.entryFor(act, d))
+			key := fmt.Sprintf("%s:%d:%d\x00%s", f.File, f.Line, f.Column, f.Message)
+			if !seen[key] {
+				seen[key] = true
+				findings = append(findings, f)
+			}
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		x, y := findings[i], findings[j]
+		switch {
+		case x.File != y.File:
+			return x.File < y.File
+		case x.Line != y.Line:
+			return x.Line < y.Line
+		case x.Column != y.Column:
+			return x.Column < y.Column
+		}
+		return x.Message < y.Message
+	})
+	return r.write(*r.path, findings, packageCoverages(coverage))
+}
+
+// newReportFinding describes a diagnostic of an analysis, using its baseline entry for
+// the relative path, the enclosing function and a fingerprint that survives unrelated
+// edits
+func newReportFinding(act *checker.Action, d analysis.Diagnostic, entry baselineEntry) reportFinding {
+	fset := act.Package.Fset
+	start := fset.Position(d.Pos)
+	messageType, fieldPath := recordedField(act, d)
+	f := reportFinding{
+		Package:     entry.Package,
+		Function:    entry.Function,
+		File:        entry.File,
+		Line:        start.Line,
+		Column:      start.Column,
+		Kind:        analyzer.Kind(d),
+		MessageType: messageType,
+		FieldPath:   fieldPath,
+		Message:     d.Message,
+		Fingerprint: entry.Fingerprint,
+		Severity:    recordedSeverity(act, d),
+	}
+	if d.End > d.Pos {
+		end := fset.Position(d.End)
+		f.EndLine, f.EndColumn = end.Line, end.Column
+	}
+	return f
+}
+
+// recordedField returns the message type and field path the analyzer recorded for a
+// diagnostic of an analysis, if any
+func recordedField(act *checker.Action, d analysis.Diagnostic) (messageType, path string) {
+	if res, ok := act.Result.(*analyzer.Result); ok {
+		return res.FieldPath(d)
+	}
+	return "", ""
+}
+
+// recordedSeverity returns the severity the analyzer recorded for a diagnostic of an
+// analysis, or that of its kind
+func recordedSeverity(act *checker.Action, d analysis.Diagnostic) analyzer.Severity {
+	if res, ok := act.Result.(*analyzer.Result); ok {
+		return res.Severity(d)
+	}
+	if kind, ok := analyzer.LookupKind(analyzer.Kind(d)); ok {
+		return kind.Severity
+	}
+	return analyzer.SeverityError
+}
+
+// packageCoverages lists the coverage of the packages with response sites, by path
+func packageCoverages(byPath map[string]analyzer.Coverage) []packageCoverage {
+	coverage := make([]packageCoverage, 0, len(byPath))
+	for pkgPath, c := range byPath {
+		coverage = append(coverage, packageCoverage{
+			Package:          pkgPath,
+			Sites:            c.Sites(),
+			Verified:         c.Verified,
+			Trusted:          c.Trusted,
+			Suppressed:       c.Suppressed,
+			VerifiedFraction: c.VerifiedFraction(),
+		})
+	}
+	sort.Slice(coverage, func(i, j int) bool { return coverage[i].Package < coverage[j].Package })
+	return coverage
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"sort"
+
+	"github.com/nickheyer/go_no_nil_linter/analyzer"
+)
+
+var sarifFlag = flag.String("sarif", "", "also write the findings to this file as SARIF 2.1.0, for code scanning uploads")
+
+// sarifLevels are the SARIF result levels of the severities of findings
+var sarifLevels = map[analyzer.Severity]string{
+	analyzer.SeverityError:    "error",
+	analyzer.SeverityAdvisory: "warning",
+	analyzer.SeverityInfo:     "note",
+}
+
+// sarifLog is the subset of SARIF 2.1.0 that code scanning reads
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+func newSarifReport() *reportFile {
+	return newReportFile(sarifFlag, writeSarif)
+}
+
+// writeSarif writes findings as a SARIF log, with a rule for each kind found
+func writeSarif(path string, findings []reportFinding, _ []packageCoverage) error {
+	results := make([]sarifResult, 0, len(findings))
+	ids := make(map[string]bool)
+	for _, f := range findings {
+		results = append(results, sarifResult{
+			RuleID:  f.Kind,
+			Level:   sarifLevels[f.Severity],
+			Message: sarifMessage{Text: f.Message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: f.File, URIBaseID: "%SRCROOT%"},
+				Region:           sarifRegion{StartLine: f.Line, StartColumn: f.Column, EndLine: f.EndLine, EndColumn: f.EndColumn},
+			}}},
+			PartialFingerprints: map[string]string{"nonillinter/v1": f.Fingerprint},
+		})
+		ids[f.Kind] = true
+	}
+
+	rules := make([]sarifRule, 0, len(ids))
+	for id := range ids {
+		description := id
+		if kind, ok := analyzer.LookupKind(id); ok {
+			description = kind.Description
+		}
+		rules = append(rules, sarifRule{ID: id, ShortDescription: sarifMessage{Text: description}})
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           analyzer.Analyzer.Name,
+				InformationURI: "https://github.com/nickheyer/go_no_nil_linter",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
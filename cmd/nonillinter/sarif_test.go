@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nickheyer/go_no_nil_linter/analyzer"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+)
+
+// saveReportFile saves three findings in svc/handler.go under dir to a report file,
+// twice as for a package and its test variant
+func saveReportFile(t *testing.T, r *reportFile, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	fset := token.NewFileSet()
+	src := "package svc\n\nvar a = 1\nvar b = 2\nvar c = 3\n"
+	file, err := parser.ParseFile(fset, filepath.Join(dir, "svc", "handler.go"), src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokFile := fset.File(file.Pos())
+	diagnostics := []analysis.Diagnostic{
+		{Pos: tokFile.LineStart(5), Category: "nil-variable", Message: "variable 'u' used for field 'User' is nil (zero value)"},
+		{Pos: tokFile.LineStart(3), End: tokFile.LineStart(3) + 9, Category: "nested-nil", Message: "nil assignment to non-optional message field 'User.Address' in protobuf message 'stubpb.UserResponse'"},
+		{Pos: tokFile.LineStart(4), Category: "map-lookup", Message: "advisory: map lookup assigned to non-optional message field 'User' in protobuf message 'stubpb.UserResponse' may be nil for a missing key"},
+	}
+
+	// The test variant's coverage counts the package's sites and its tests'
+	var actions []*checker.Action
+	for i := 0; i < 2; i++ {
+		actions = append(actions, &checker.Action{
+			Package: &packages.Package{
+				PkgPath: "example.com/svc",
+				Fset:    fset,
+				Syntax:  []*ast.File{file},
+				Types:   types.NewPackage("example.com/svc", "svc"),
+			},
+			Result:      &analyzer.Result{Stats: analyzer.Stats{Coverage: analyzer.Coverage{Verified: 3 + i, Trusted: 1}}},
+			Diagnostics: diagnostics,
+		})
+	}
+	if err := r.save(actions); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSarifReport(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "results.sarif")
+	flag.Set("sarif", path)
+	defer flag.Set("sarif", "")
+	saveReportFile(t, newSarifReport(), dir)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("Unexpected SARIF log:\n%s", data)
+	}
+	run := log.Runs[0]
+
+	var rules []string
+	for _, rule := range run.Tool.Driver.Rules {
+		rules = append(rules, rule.ID)
+	}
+	if got := strings.Join(rules, ","); got != "map-lookup,nested-nil,nil-variable" {
+		t.Errorf("Expected a rule per kind found, got %s", got)
+	}
+
+	if len(run.Results) != 3 {
+		t.Fatalf("Expected the test variant's repeats to be dropped, got %d results", len(run.Results))
+	}
+	first := run.Results[0]
+	loc := first.Locations[0].PhysicalLocation
+	if first.RuleID != "nested-nil" || first.Level != "error" || loc.ArtifactLocation.URI != "svc/handler.go" ||
+		loc.Region.StartLine != 3 || loc.Region.StartColumn != 1 || loc.Region.EndColumn != 10 {
+		t.Errorf("Unexpected first result %+v", first)
+	}
+	if first.PartialFingerprints["nonillinter/v1"] == "" {
+		t.Errorf("Expected a fingerprint on %+v", first)
+	}
+	if run.Results[1].RuleID != "map-lookup" || run.Results[1].Level != "warning" {
+		t.Errorf("Expected the advisory map lookup as a warning, got %+v", run.Results[1])
+	}
+	if run.Results[2].RuleID != "nil-variable" {
+		t.Errorf("Expected a nil-variable result, got %+v", run.Results[2])
+	}
+}
+
+// The SARIF level is the severity the analyzer recorded, which escalation raises
+func TestSarifLevels(t *testing.T) {
+	d := analysis.Diagnostic{Pos: 1, Category: analyzer.KindMapLookup, Message: "advisory: map lookup"}
+	act := &checker.Action{Result: &analyzer.Result{Findings: []analyzer.Finding{{Diagnostic: d, Severity: analyzer.SeverityAdvisory}}}}
+	if got := sarifLevels[recordedSeverity(act, d)]; got != "warning" {
+		t.Errorf("Expected the advisory finding as a warning, got %q", got)
+	}
+	escalateFinding(act, d, "map lookup (escalated)")
+	d.Message = "map lookup (escalated)"
+	if got := sarifLevels[recordedSeverity(act, d)]; got != "error" {
+		t.Errorf("Expected the escalated finding as an error, got %q", got)
+	}
+
+	// Without a record, the kind decides
+	if got := sarifLevels[recordedSeverity(&checker.Action{}, analysis.Diagnostic{Category: analyzer.KindUnverified})]; got != "note" {
+		t.Errorf("Expected an unverified finding as a note, got %q", got)
+	}
+	for _, kind := range analyzer.Kinds() {
+		if sarifLevels[kind.Severity] == "" {
+			t.Errorf("Kind %s has severity %q, which has no SARIF level", kind.Kind, kind.Severity)
+		}
+	}
+}
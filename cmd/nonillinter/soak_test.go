@@ -0,0 +1,197 @@
+//go:build soak
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Soak test over open-source gRPC services, run before releases.
+//
+// It clones a pinned set of services and runs the linter over each, counting the
+// findings by violation kind. A crash, a timeout or an analysis error fails the test.
+// The exact findings aren't asserted: the code isn't ours. Counts recorded with
+// -soak.update are compared on later runs, so a change that adds findings (possibly
+// false positives) or loses them (possibly missed nils) shows up as a diff to review.
+//
+// Run with:
+//
+//	go test -tags soak -run TestSoak -v ./cmd/nonillinter
+//	go test -tags soak -run TestSoak -v ./cmd/nonillinter -soak.dir=$HOME/.cache/nonillinter-soak -soak.counts=soak.json -soak.update
+//
+// Cloning needs network access, and the services' modules are downloaded by go.
+
+var (
+	soakDir      = flag.String("soak.dir", "", "directory to clone the services into and reuse on later runs; empty clones into a temporary directory")
+	soakServices = flag.String("soak.services", "", "comma-separated names of the services to run; empty runs them all")
+	soakCounts   = flag.String("soak.counts", "", "JSON file of finding counts by service and violation kind to compare against")
+	soakUpdate   = flag.Bool("soak.update", false, "write the counts to -soak.counts instead of comparing")
+)
+
+// soakService is a Go module of a service, at a pinned release of its repository
+type soakService struct {
+	name     string
+	repo     string
+	tag      string
+	dir      string
+	patterns []string
+}
+
+var soakCorpus = []soakService{
+	{"grpc-go-examples", "https://github.com/grpc/grpc-go", "v1.67.1", "examples", []string{"./..."}},
+	{"grpc-gateway-examples", "https://github.com/grpc-ecosystem/grpc-gateway", "v2.22.0", ".", []string{"./examples/..."}},
+	{"boutique-checkout", "https://github.com/GoogleCloudPlatform/microservices-demo", "v0.10.1", "src/checkoutservice", []string{"./..."}},
+	{"boutique-frontend", "https://github.com/GoogleCloudPlatform/microservices-demo", "v0.10.1", "src/frontend", []string{"./..."}},
+	{"boutique-productcatalog", "https://github.com/GoogleCloudPlatform/microservices-demo", "v0.10.1", "src/productcatalogservice", []string{"./..."}},
+	{"boutique-shipping", "https://github.com/GoogleCloudPlatform/microservices-demo", "v0.10.1", "src/shippingservice", []string{"./..."}},
+}
+
+func TestSoak(t *testing.T) {
+	root := *soakDir
+	if root == "" {
+		root = t.TempDir()
+	}
+	bin := filepath.Join(t.TempDir(), "nonillinter")
+	if out, err := exec.Command("go", "build", "-o", bin, ".").CombinedOutput(); err != nil {
+		t.Fatalf("building the linter: %v\n%s", err, out)
+	}
+
+	selected := make(map[string]bool)
+	for _, name := range strings.Split(*soakServices, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			selected[name] = true
+		}
+	}
+
+	counts := make(map[string]map[string]int)
+	for _, service := range soakCorpus {
+		if len(selected) > 0 && !selected[service.name] {
+			continue
+		}
+		t.Run(service.name, func(t *testing.T) {
+			checkout, err := cloneService(root, service)
+			if err != nil {
+				t.Fatal(err)
+			}
+			kinds, err := soakRun(bin, filepath.Join(checkout, service.dir), service.patterns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			counts[service.name] = kinds
+			t.Logf("%s@%s: %v", service.repo, service.tag, kinds)
+		})
+	}
+
+	switch {
+	case *soakCounts == "":
+	case *soakUpdate:
+		data, err := json.MarshalIndent(counts, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(*soakCounts, append(data, '\n'), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	default:
+		data, err := os.ReadFile(*soakCounts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var recorded map[string]map[string]int
+		if err := json.Unmarshal(data, &recorded); err != nil {
+			t.Fatalf("%s: %v", *soakCounts, err)
+		}
+		for _, diff := range diffCounts(recorded, counts) {
+			t.Error(diff)
+		}
+	}
+}
+
+// cloneService checks out the service's release under root, once, and returns the
+// checkout
+func cloneService(root string, service soakService) (string, error) {
+	name := strings.TrimSuffix(filepath.Base(service.repo), ".git") + "@" + service.tag
+	checkout := filepath.Join(root, name)
+	if _, err := os.Stat(filepath.Join(checkout, ".git")); err == nil {
+		return checkout, nil
+	}
+	cmd := exec.Command("git", "clone", "--quiet", "--depth=1", "--branch", service.tag, service.repo, checkout)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("cloning %s@%s: %v\n%s", service.repo, service.tag, err, out)
+	}
+	return checkout, nil
+}
+
+// soakRun runs the linter over patterns in dir and counts its findings by violation
+// kind. The driver exits with 3 when there are findings; any other failure, and any
+// panic in its output, is an error.
+func soakRun(bin, dir string, patterns []string) (map[string]int, error) {
+	report := filepath.Join(os.TempDir(), fmt.Sprintf("nonillinter-soak-%d.json", os.Getpid()))
+	defer os.Remove(report)
+
+	args := append([]string{"-json-report=" + report, "-package-timeout=5m"}, patterns...)
+	cmd := exec.Command(bin, args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	var exit *exec.ExitError
+	if err != nil && !(errors.As(err, &exit) && exit.ExitCode() == 3) {
+		return nil, fmt.Errorf("linter failed in %s: %v\n%s", dir, err, out)
+	}
+	if strings.Contains(string(out), "panic:") {
+		return nil, fmt.Errorf("linter panicked in %s:\n%s", dir, out)
+	}
+
+	kinds := make(map[string]int)
+	data, err := os.ReadFile(report)
+	if errors.Is(err, os.ErrNotExist) {
+		// The report is written after a package with findings
+		return kinds, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var findings jsonReport
+	if err := json.Unmarshal(data, &findings); err != nil {
+		return nil, err
+	}
+	for _, finding := range findings.Findings {
+		kinds[finding.Kind]++
+	}
+	return kinds, nil
+}
+
+// diffCounts describes how the counts changed from those recorded, for the services
+// run
+func diffCounts(recorded, counts map[string]map[string]int) []string {
+	var diffs []string
+	for service, kinds := range counts {
+		before, ok := recorded[service]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("%s: no recorded counts; run with -soak.update", service))
+			continue
+		}
+		all := make(map[string]bool)
+		for kind := range kinds {
+			all[kind] = true
+		}
+		for kind := range before {
+			all[kind] = true
+		}
+		for kind := range all {
+			if before[kind] != kinds[kind] {
+				diffs = append(diffs, fmt.Sprintf("%s: %d %s findings, recorded %d", service, kinds[kind], kind, before[kind]))
+			}
+		}
+	}
+	sort.Strings(diffs)
+	return diffs
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nickheyer/go_no_nil_linter/analyzer"
+	"golang.org/x/tools/go/analysis/checker"
+)
+
+var summaryFlag = flag.Bool("summary", true, "print a one-line count of the findings by violation kind to stderr after the findings (text output only)")
+
+// runSummary implements -summary: a line counting the findings of the packages named on
+// the command line by violation kind, printed once the driver has listed them:
+//
+//	nonillinter: 12 nil-literal, 7 missing-field, 3 nested-nil across 5 packages
+//
+// Dependencies are analyzed for their facts only; their findings aren't printed, so
+// they aren't counted. Findings suppressed by the baseline aren't either, and packages
+// the driver couldn't analyze are left out.
+//
+// Functions too large for -analysis-budget, which got the shallow checks only, are
+// counted after the findings so that a clean run doesn't hide them.
+type runSummary struct {
+	analyzed map[string]bool
+	kinds    map[string]int
+	shallow  map[string]bool
+}
+
+// newRunSummary counts the findings of the analyses of the packages named
+func newRunSummary(actions []*checker.Action) *runSummary {
+	s := &runSummary{analyzed: make(map[string]bool), kinds: make(map[string]int), shallow: make(map[string]bool)}
+	seen := make(map[string]bool)
+	for _, act := range actions {
+		pkgPath := act.Package.Types.Path()
+		s.analyzed[pkgPath] = true
+		for _, d := range act.Diagnostics {
+			// The driver prints a finding repeated by a test variant once
+			key := act.Package.Fset.Position(d.Pos).String() + ": " + d.Message
+			if !seen[key] {
+				seen[key] = true
+				s.kinds[analyzer.Kind(d)]++
+			}
+		}
+		if r, ok := act.Result.(*analyzer.Result); ok {
+			for _, fn := range r.Stats.ShallowFunctions {
+				s.shallow[pkgPath+"."+fn] = true
+			}
+		}
+	}
+	return s
+}
+
+// line formats the summary, the most frequent kinds first
+func (s *runSummary) line() string {
+	packages := fmt.Sprintf("%d packages", len(s.analyzed))
+	if len(s.analyzed) == 1 {
+		packages = "1 package"
+	}
+	shallow := ""
+	switch len(s.shallow) {
+	case 0:
+	case 1:
+		shallow = "; 1 function checked shallowly (-analysis-budget)"
+	default:
+		shallow = fmt.Sprintf("; %d functions checked shallowly (-analysis-budget)", len(s.shallow))
+	}
+	if len(s.kinds) == 0 {
+		return fmt.Sprintf("nonillinter: no findings across %s%s", packages, shallow)
+	}
+	kinds := make([]string, 0, len(s.kinds))
+	for kind := range s.kinds {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool {
+		if s.kinds[kinds[i]] != s.kinds[kinds[j]] {
+			return s.kinds[kinds[i]] > s.kinds[kinds[j]]
+		}
+		return kinds[i] < kinds[j]
+	})
+	counts := make([]string, len(kinds))
+	for i, kind := range kinds {
+		counts[i] = fmt.Sprintf("%d %s", s.kinds[kind], kind)
+	}
+	return fmt.Sprintf("nonillinter: %s across %s%s", strings.Join(counts, ", "), packages, shallow)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/nickheyer/go_no_nil_linter/analyzer"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+)
+
+// summaryAction is the analysis of a package with the findings and result given
+func summaryAction(fset *token.FileSet, path string, result interface{}, findings ...analysis.Diagnostic) *checker.Action {
+	return &checker.Action{
+		Package:     &packages.Package{PkgPath: path, Fset: fset, Types: types.NewPackage(path, "p")},
+		Result:      result,
+		Diagnostics: findings,
+	}
+}
+
+func TestRunSummary(t *testing.T) {
+	fset := token.NewFileSet()
+	file := fset.AddFile("a.go", -1, 100)
+	file.SetLinesForContent(make([]byte, 100))
+	a := []analysis.Diagnostic{
+		{Pos: file.Pos(1), Category: analyzer.KindNilLiteral, Message: "nil"},
+		{Pos: file.Pos(2), Category: analyzer.KindMissingField, Message: "missing"},
+		{Pos: file.Pos(3), Category: analyzer.KindMissingField, Message: "missing"},
+	}
+
+	// The package, its test variant repeating its findings and another package
+	summary := newRunSummary([]*checker.Action{
+		summaryAction(fset, "example.com/a", nil, a...),
+		summaryAction(fset, "example.com/a", nil, a...),
+		summaryAction(fset, "example.com/b", nil, analysis.Diagnostic{Pos: file.Pos(4), Category: "map-lookup", Message: "lookup"}),
+	})
+
+	want := "nonillinter: 2 missing-field, 1 map-lookup, 1 nil-literal across 2 packages"
+	if got := summary.line(); got != want {
+		t.Errorf("Got summary %q, want %q", got, want)
+	}
+}
+
+func TestRunSummaryNoFindings(t *testing.T) {
+	summary := newRunSummary([]*checker.Action{summaryAction(token.NewFileSet(), "example.com/clean", nil)})
+	if want := "nonillinter: no findings across 1 package"; summary.line() != want {
+		t.Errorf("Got summary %q, want %q", summary.line(), want)
+	}
+}
+
+func TestRunSummaryShallowFunctions(t *testing.T) {
+	result := &analyzer.Result{Stats: analyzer.Stats{BudgetExceeded: 2, ShallowFunctions: []string{"handle", "*server.List"}}}
+	// The test variant runs out of budget in the same functions
+	summary := newRunSummary([]*checker.Action{
+		summaryAction(token.NewFileSet(), "example.com/big", result),
+		summaryAction(token.NewFileSet(), "example.com/big", result),
+	})
+	if want := "nonillinter: no findings across 1 package; 2 functions checked shallowly (-analysis-budget)"; summary.line() != want {
+		t.Errorf("Got summary %q, want %q", summary.line(), want)
+	}
+}
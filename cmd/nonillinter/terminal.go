@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// ANSI sequences used by the triage screen
+const (
+	ansiReset     = "\x1b[0m"
+	ansiBold      = "\x1b[1m"
+	ansiDim       = "\x1b[2m"
+	ansiReverse   = "\x1b[7m"
+	ansiRed       = "\x1b[31m"
+	ansiGreen     = "\x1b[32m"
+	ansiYellow    = "\x1b[33m"
+	ansiCyan      = "\x1b[36m"
+	ansiClear     = "\x1b[H\x1b[2J"
+	ansiAltScreen = "\x1b[?1049h"
+	ansiMainScr   = "\x1b[?1049l"
+	ansiHideCur   = "\x1b[?25l"
+	ansiShowCur   = "\x1b[?25h"
+)
+
+// Keys other than printable characters, as returned by keyReader.read
+const (
+	keyEnter     = "enter"
+	keyEscape    = "esc"
+	keyBackspace = "backspace"
+	keyInterrupt = "ctrl-c"
+	keyUp        = "up"
+	keyDown      = "down"
+	keyLeft      = "left"
+	keyRight     = "right"
+)
+
+// keyReader decodes key presses from a terminal in raw mode. Input that is not a
+// terminal decodes the same way, with a newline read as Enter.
+type keyReader struct {
+	in *bufio.Reader
+}
+
+func newKeyReader(in io.Reader) *keyReader {
+	return &keyReader{in: bufio.NewReader(in)}
+}
+
+// read returns the next key: a printable character as itself, or one of the key
+// constants. Keys it has no name for are returned as "". It returns false at the end of
+// input.
+func (k *keyReader) read() (string, bool) {
+	r, _, err := k.in.ReadRune()
+	if err != nil {
+		return "", false
+	}
+	switch r {
+	case '\r', '\n':
+		return keyEnter, true
+	case 0x7f, '\b':
+		return keyBackspace, true
+	case 0x03, 0x04:
+		return keyInterrupt, true
+	case 0x1b:
+		// A terminal writes an escape sequence at once, so a lone Escape is one with
+		// nothing buffered after it
+		if k.in.Buffered() == 0 {
+			return keyEscape, true
+		}
+		return k.escapeSequence(), true
+	}
+	if r == utf8.RuneError || r < ' ' {
+		return "", true
+	}
+	return string(r), true
+}
+
+// escapeSequence reads the rest of a CSI or SS3 sequence after its escape, naming the
+// arrow keys
+func (k *keyReader) escapeSequence() string {
+	intro, err := k.in.ReadByte()
+	if err != nil || (intro != '[' && intro != 'O') {
+		return keyEscape
+	}
+	for k.in.Buffered() > 0 {
+		b, err := k.in.ReadByte()
+		if err != nil {
+			break
+		}
+		// Parameters and intermediates come before the final byte
+		if b >= 0x40 && b <= 0x7e {
+			switch b {
+			case 'A':
+				return keyUp
+			case 'B':
+				return keyDown
+			case 'C':
+				return keyRight
+			case 'D':
+				return keyLeft
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+// terminal is the state of the controlling terminal while the triage screen is shown
+type terminal struct {
+	fd       int
+	restore  *term.State
+	isScreen bool
+}
+
+// openTerminal puts stdin in raw mode when it and stdout are a terminal. Otherwise the
+// screen is still drawn, for a fixed size, and keys are read from the input as it comes.
+func openTerminal(stdin io.Reader, stdout io.Writer) (*terminal, error) {
+	in, ok1 := stdin.(*os.File)
+	out, ok2 := stdout.(*os.File)
+	if !ok1 || !ok2 || !term.IsTerminal(int(in.Fd())) || !term.IsTerminal(int(out.Fd())) {
+		return &terminal{fd: -1}, nil
+	}
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return nil, err
+	}
+	io.WriteString(stdout, ansiAltScreen+ansiHideCur)
+	return &terminal{fd: int(out.Fd()), restore: state, isScreen: true}, nil
+}
+
+// size returns the width and height of the terminal, or 80x24 when there is none
+func (t *terminal) size() (width, height int) {
+	if t.fd >= 0 {
+		if w, h, err := term.GetSize(t.fd); err == nil && w > 0 && h > 0 {
+			return w, h
+		}
+	}
+	return 80, 24
+}
+
+// close leaves the alternate screen and restores the terminal's mode
+func (t *terminal) close(stdin io.Reader, stdout io.Writer) {
+	if !t.isScreen {
+		return
+	}
+	io.WriteString(stdout, ansiShowCur+ansiMainScr)
+	term.Restore(int(stdin.(*os.File).Fd()), t.restore)
+}
+
+// displayLine expands tabs and cuts a line of source to width columns
+func displayLine(s string, width int) string {
+	var b strings.Builder
+	col := 0
+	for _, r := range strings.TrimRight(s, "\r") {
+		if r == '\t' {
+			n := 4 - col%4
+			if col+n > width {
+				break
+			}
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+			continue
+		}
+		if col+1 > width {
+			break
+		}
+		b.WriteRune(r)
+		col++
+	}
+	return b.String()
+}
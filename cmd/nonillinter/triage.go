@@ -0,0 +1,671 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nickheyer/go_no_nil_linter/analyzer"
+)
+
+// triageFinding is a diagnostic as printed by the driver's -json mode
+type triageFinding struct {
+	Category string      `json:"category"`
+	Posn     string      `json:"posn"`
+	Message  string      `json:"message"`
+	Fixes    []triageFix `json:"suggested_fixes"`
+
+	file         string
+	line, column int
+}
+
+type triageFix struct {
+	Message string       `json:"message"`
+	Edits   []triageEdit `json:"edits"`
+}
+
+// triageEdit replaces the bytes [Start, End) of a file, as offsets into its original content
+type triageEdit struct {
+	Filename string `json:"filename"`
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+	New      string `json:"new"`
+}
+
+// runTriage implements `nonillinter triage [analyzer flags] packages...`
+func runTriage(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		fmt.Fprintln(stderr, "usage: nonillinter triage [flags] package...")
+		fmt.Fprintln(stderr, "Opens a full-screen view of the findings in the packages, one at a time, to apply a")
+		fmt.Fprintln(stderr, "suggested fix, suppress the finding with a //nonillinter:ignore comment giving a")
+		fmt.Fprintln(stderr, "reason, or skip it; the edits are written on quit. Flags are the same as for")
+		fmt.Fprintln(stderr, "nonillinter itself, so -config and -baseline narrow the findings.")
+		return 2
+	}
+
+	findings, err := loadTriageFindings(args, stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "triage: %v\n", err)
+		return 1
+	}
+	if len(findings) == 0 {
+		fmt.Fprintln(stdout, "No findings to triage.")
+		return 0
+	}
+
+	screen, err := openTerminal(stdin, stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "triage: %v\n", err)
+		return 1
+	}
+	t := newTriage(findings, stdin, stdout)
+	t.size = screen.size
+	t.run()
+	screen.close(stdin, stdout)
+	fmt.Fprintln(stdout)
+
+	written, err := t.write()
+	if err != nil {
+		fmt.Fprintf(stderr, "triage: %v\n", err)
+		return 1
+	}
+	fixed, suppressed, skipped := t.counts()
+	fmt.Fprintf(stdout, "%d fixed, %d suppressed, %d skipped; %d file(s) written\n", fixed, suppressed, skipped, written)
+	return 0
+}
+
+// loadTriageFindings runs the linter on the packages in -json mode and returns its
+// findings, ordered by position
+func loadTriageFindings(args []string, stderr io.Writer) ([]*triageFinding, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(exe, append([]string{"-json"}, args...)...)
+	cmd.Stderr = stderr
+	out, runErr := cmd.Output()
+	findings, err := parseTriageFindings(out)
+	if err != nil {
+		if runErr != nil {
+			return nil, runErr
+		}
+		return nil, err
+	}
+	return findings, nil
+}
+
+// parseTriageFindings reads the driver's -json output: diagnostics by package and then
+// by analyzer. A package's test variant repeats the diagnostics of the package, so
+// findings at the same position with the same message are kept once.
+func parseTriageFindings(data []byte) ([]*triageFinding, error) {
+	var tree map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("reading -json output: %v", err)
+	}
+
+	// Packages go in order, so a package's findings are kept over the repeats of its test
+	// variant, "pkg [pkg.test]"
+	pkgs := make([]string, 0, len(tree))
+	for pkg := range tree {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+
+	var findings []*triageFinding
+	seen := make(map[string]bool)
+	for _, pkg := range pkgs {
+		for name, raw := range tree[pkg] {
+			var failed struct {
+				Err string `json:"error"`
+			}
+			if json.Unmarshal(raw, &failed) == nil && failed.Err != "" {
+				return nil, fmt.Errorf("%s: %s: %s", pkg, name, failed.Err)
+			}
+			var diagnostics []*triageFinding
+			if err := json.Unmarshal(raw, &diagnostics); err != nil {
+				return nil, fmt.Errorf("%s: %s: %v", pkg, name, err)
+			}
+			for _, f := range diagnostics {
+				key := f.Posn + "\x00" + f.Message
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				if err := f.parsePosn(); err != nil {
+					return nil, err
+				}
+				findings = append(findings, f)
+			}
+		}
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		x, y := findings[i], findings[j]
+		switch {
+		case x.file != y.file:
+			return x.file < y.file
+		case x.line != y.line:
+			return x.line < y.line
+		case x.column != y.column:
+			return x.column < y.column
+		}
+		return x.Message < y.Message
+	})
+	return findings, nil
+}
+
+// parsePosn splits a "file:line:column" position; the file name may itself contain colons
+func (f *triageFinding) parsePosn() error {
+	rest, col, ok1 := cutLast(f.Posn, ":")
+	file, line, ok2 := cutLast(rest, ":")
+	l, err1 := strconv.Atoi(line)
+	c, err2 := strconv.Atoi(col)
+	if !ok1 || !ok2 || err1 != nil || err2 != nil {
+		return fmt.Errorf("malformed position %q", f.Posn)
+	}
+	f.file, f.line, f.column = file, l, c
+	return nil
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// triageAction is the decision taken on a finding
+type triageAction int
+
+const (
+	undecided triageAction = iota
+	fixedAction
+	suppressedAction
+	skippedAction
+)
+
+func (a triageAction) String() string {
+	switch a {
+	case fixedAction:
+		return "fixed"
+	case suppressedAction:
+		return "suppressed"
+	case skippedAction:
+		return "skipped"
+	}
+	return "undecided"
+}
+
+// triageDecision is what was decided for a finding, with the edits that carry it out:
+// those of the fix applied, or the comment that suppresses it
+type triageDecision struct {
+	action triageAction
+	edits  []triageEdit
+}
+
+// triage is an interactive screen over a list of findings. Each decision holds its
+// edits against the original file contents, which are only written at the end, so
+// offsets from the driver stay valid throughout and a decision can be undone.
+type triage struct {
+	findings  []*triageFinding
+	decisions []triageDecision
+	keys      *keyReader
+	out       io.Writer
+
+	// size returns the width and height of the screen
+	size func() (width, height int)
+
+	sources map[string][]byte
+
+	current int
+	// editing is set while the reason for a suppression is typed into reason
+	editing bool
+	reason  string
+	// status is a message shown until the next key
+	status string
+	help   bool
+}
+
+func newTriage(findings []*triageFinding, in io.Reader, out io.Writer) *triage {
+	return &triage{
+		findings:  findings,
+		decisions: make([]triageDecision, len(findings)),
+		keys:      newKeyReader(in),
+		out:       out,
+		size:      func() (int, int) { return 80, 24 },
+		sources:   make(map[string][]byte),
+	}
+}
+
+const triageKeys = "f fix  s suppress  n skip  u undo  ←/→ move  ? help  q write and quit"
+
+var triageHelp = []string{
+	"f, 1-9    apply the suggested fix, or fix n when there are several",
+	"s         suppress with a //nonillinter:ignore comment giving a reason",
+	"n, space  skip this finding",
+	"u         undo the decision on this finding",
+	"←/→, k/j  move to the previous or next finding",
+	"q         write the edits decided so far and quit",
+	"?         hide this help",
+}
+
+// run draws the screen and handles keys until q is pressed or input ends
+func (t *triage) run() {
+	for {
+		t.draw()
+		key, ok := t.keys.read()
+		if !ok || !t.handle(key) {
+			return
+		}
+	}
+}
+
+// handle acts on a key; it returns false to quit
+func (t *triage) handle(key string) bool {
+	if t.editing {
+		t.editReason(key)
+		return true
+	}
+	t.status = ""
+	switch key {
+	case "q", keyInterrupt:
+		return false
+	case "?":
+		t.help = !t.help
+	case "j", keyDown, keyRight:
+		t.current = min(t.current+1, len(t.findings)-1)
+	case "k", keyUp, keyLeft:
+		t.current = max(t.current-1, 0)
+	case "n", " ":
+		t.decide(triageDecision{action: skippedAction})
+	case "u":
+		t.decisions[t.current] = triageDecision{}
+	case "s":
+		t.editing, t.reason = true, ""
+	case "f":
+		t.fix(1)
+	default:
+		if len(key) == 1 && key[0] >= '1' && key[0] <= '9' {
+			t.fix(int(key[0] - '0'))
+		}
+	}
+	return true
+}
+
+// editReason handles a key while the reason for a suppression is typed
+func (t *triage) editReason(key string) {
+	switch key {
+	case keyEnter:
+		reason := strings.Join(strings.Fields(t.reason), " ")
+		if reason == "" {
+			t.status = "a reason is required"
+			return
+		}
+		t.editing = false
+		d, err := t.suppress(t.findings[t.current], reason)
+		if err != nil {
+			t.status = err.Error()
+			return
+		}
+		t.decide(d)
+	case keyEscape, keyInterrupt:
+		t.editing = false
+	case keyBackspace:
+		if _, n := utf8.DecodeLastRuneInString(t.reason); n > 0 {
+			t.reason = t.reason[:len(t.reason)-n]
+		}
+	default:
+		if utf8.RuneCountInString(key) == 1 {
+			t.reason += key
+		}
+	}
+}
+
+// fix applies the n'th suggested fix of the current finding, unless it overlaps an edit
+// decided for another finding
+func (t *triage) fix(n int) {
+	f := t.findings[t.current]
+	if n > len(f.Fixes) {
+		if len(f.Fixes) == 0 {
+			t.status = "this finding has no suggested fix"
+		} else {
+			t.status = fmt.Sprintf("no fix %d; this finding has %d", n, len(f.Fixes))
+		}
+		return
+	}
+	edits := f.Fixes[n-1].Edits
+	accepted := t.accepted(t.current)
+	for _, e := range edits {
+		for _, prev := range accepted[e.Filename] {
+			if prev != e && e.Start < prev.End && prev.Start < e.End {
+				t.status = fmt.Sprintf("this fix overlaps an earlier change in %s; skip it and triage again after the edits are written", e.Filename)
+				return
+			}
+		}
+	}
+	t.decide(triageDecision{action: fixedAction, edits: edits})
+}
+
+// decide records the decision on the current finding and moves to the next undecided one
+func (t *triage) decide(d triageDecision) {
+	t.decisions[t.current] = d
+	for i := 1; i < len(t.findings); i++ {
+		next := (t.current + i) % len(t.findings)
+		if t.decisions[next].action == undecided {
+			t.current = next
+			return
+		}
+	}
+	t.status = "every finding is triaged; q writes the edits"
+}
+
+// suppress returns the decision to insert a //nonillinter:ignore comment above the
+// finding, indented like its line
+func (t *triage) suppress(f *triageFinding, reason string) (triageDecision, error) {
+	src, err := t.source(f.file)
+	if err != nil {
+		return triageDecision{}, err
+	}
+	start := lineOffset(src, f.line)
+	if start < 0 {
+		return triageDecision{}, fmt.Errorf("%s has no line %d", f.file, f.line)
+	}
+	end := start
+	for end < len(src) && (src[end] == ' ' || src[end] == '\t') {
+		end++
+	}
+	comment := fmt.Sprintf("%s%s %s\n", src[start:end], analyzer.IgnoreDirective, reason)
+	return triageDecision{action: suppressedAction, edits: []triageEdit{{Filename: f.file, Start: start, End: start, New: comment}}}, nil
+}
+
+// accepted returns the edits of every decision but the one on finding skip, by file.
+// Identical edits, such as two fixes adding the same import, are kept once, and a line
+// with several suppressed findings gets the comment of the first.
+func (t *triage) accepted(skip int) map[string][]triageEdit {
+	edits := make(map[string][]triageEdit)
+	suppressed := make(map[string]bool)
+	for i, d := range t.decisions {
+		if i == skip {
+			continue
+		}
+		if d.action == suppressedAction {
+			f := t.findings[i]
+			key := fmt.Sprintf("%s:%d", f.file, f.line)
+			if suppressed[key] {
+				continue
+			}
+			suppressed[key] = true
+		}
+	next:
+		for _, e := range d.edits {
+			for _, prev := range edits[e.Filename] {
+				if prev == e {
+					continue next
+				}
+			}
+			edits[e.Filename] = append(edits[e.Filename], e)
+		}
+	}
+	return edits
+}
+
+// counts returns the number of findings fixed, suppressed and skipped; those left
+// undecided count as skipped
+func (t *triage) counts() (fixed, suppressed, skipped int) {
+	for _, d := range t.decisions {
+		switch d.action {
+		case fixedAction:
+			fixed++
+		case suppressedAction:
+			suppressed++
+		default:
+			skipped++
+		}
+	}
+	return fixed, suppressed, skipped
+}
+
+// draw redraws the screen for the current finding: its source, its suggested fixes with
+// the lines they change, and the status and key lines at the bottom
+func (t *triage) draw() {
+	width, height := t.size()
+	f := t.findings[t.current]
+	d := t.decisions[t.current]
+	var lines []string
+	add := func(style, text string) {
+		text = displayLine(text, width)
+		if style != "" && text != "" {
+			text = style + text + ansiReset
+		}
+		lines = append(lines, text)
+	}
+
+	fixed, suppressed, skipped := t.counts()
+	add(ansiBold, fmt.Sprintf("nonillinter triage  %d/%d  %d fixed  %d suppressed  %d skipped",
+		t.current+1, len(t.findings), fixed, suppressed, skipped-t.undecided()))
+	add("", "")
+	posn := f.Posn
+	if d.action != undecided {
+		posn += "  [" + d.action.String() + "]"
+	}
+	add(ansiCyan, posn)
+	if f.Category != "" {
+		add(ansiBold, f.Category+": "+f.Message)
+	} else {
+		add(ansiBold, f.Message)
+	}
+
+	var fixLines []string
+	for n, fix := range f.Fixes {
+		fixLines = append(fixLines, fmt.Sprintf("%d %s", n+1, fix.Message))
+		fixLines = append(fixLines, t.preview(f.file, fix.Edits)...)
+	}
+	help := 0
+	if t.help {
+		help = len(triageHelp) + 1
+	}
+
+	// The source takes the room the rest leaves, two lines either side at least
+	if src, err := t.lines(f.file); err == nil {
+		context := max(2, min(6, (height-10-len(fixLines)-help)/2))
+		add("", "")
+		for n := max(1, f.line-context); n <= min(len(src), f.line+context); n++ {
+			if n == f.line {
+				add(ansiReverse, fmt.Sprintf("> %4d | %s", n, displayLine(src[n-1], width)))
+			} else {
+				add(ansiDim, fmt.Sprintf("  %4d | %s", n, displayLine(src[n-1], width)))
+			}
+		}
+	}
+	if len(fixLines) > 0 {
+		add("", "")
+		for _, l := range fixLines {
+			switch {
+			case strings.HasPrefix(l, "- "):
+				add(ansiRed, "    "+l)
+			case strings.HasPrefix(l, "+ "):
+				add(ansiGreen, "    "+l)
+			default:
+				add("", "  fix "+l)
+			}
+		}
+	}
+	if t.help {
+		add("", "")
+		for _, l := range triageHelp {
+			add("", "  "+l)
+		}
+	}
+
+	var bottom []string
+	switch {
+	case t.editing:
+		bottom = append(bottom, displayLine("reason: "+t.reason, width-1)+"_")
+	case t.status != "":
+		bottom = append(bottom, ansiYellow+displayLine(t.status, width)+ansiReset)
+	default:
+		bottom = append(bottom, "")
+	}
+	bottom = append(bottom, ansiDim+displayLine(triageKeys, width)+ansiReset)
+	if len(lines) > height-len(bottom) {
+		lines = lines[:max(0, height-len(bottom))]
+	}
+	for len(lines) < height-len(bottom) {
+		lines = append(lines, "")
+	}
+	io.WriteString(t.out, ansiClear+strings.Join(append(lines, bottom...), "\r\n"))
+}
+
+// undecided returns the number of findings with no decision yet
+func (t *triage) undecided() int {
+	n := 0
+	for _, d := range t.decisions {
+		if d.action == undecided {
+			n++
+		}
+	}
+	return n
+}
+
+// preview returns the lines of file a fix changes, before and after, as "- " and "+ "
+// lines; edits of other files are only named
+func (t *triage) preview(file string, edits []triageEdit) []string {
+	const maxLines = 4
+	var out []string
+	for _, e := range edits {
+		if e.Filename != file {
+			out = append(out, "  also edits "+e.Filename)
+			continue
+		}
+		src, err := t.source(file)
+		if err != nil || e.Start < 0 || e.End < e.Start || e.End > len(src) {
+			continue
+		}
+		start := bytes.LastIndexByte(src[:e.Start], '\n') + 1
+		end := len(src)
+		if i := bytes.IndexByte(src[e.End:], '\n'); i >= 0 {
+			end = e.End + i
+		}
+		before := strings.Split(string(src[start:end]), "\n")
+		after := strings.Split(string(src[start:e.Start])+e.New+string(src[e.End:end]), "\n")
+		for i, l := range before {
+			if i == maxLines {
+				out = append(out, "- ...")
+				break
+			}
+			out = append(out, "- "+displayLine(l, 1<<10))
+		}
+		for i, l := range after {
+			if i == maxLines {
+				out = append(out, "+ ...")
+				break
+			}
+			out = append(out, "+ "+displayLine(l, 1<<10))
+		}
+	}
+	return out
+}
+
+// write applies the accepted edits to each file and returns the number of files written
+func (t *triage) write() (int, error) {
+	edits := t.accepted(-1)
+	files := make([]string, 0, len(edits))
+	for file := range edits {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var errs []error
+	written := 0
+	for _, file := range files {
+		src, err := t.source(file)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		out, err := applyEdits(src, edits[file])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", file, err))
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := os.WriteFile(file, out, info.Mode().Perm()); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		written++
+	}
+	return written, errors.Join(errs...)
+}
+
+// applyEdits applies non-overlapping edits given as offsets into src. An insertion goes
+// before an edit replacing text at the same offset, and insertions at the same offset
+// keep the order they were accepted in.
+func applyEdits(src []byte, edits []triageEdit) ([]byte, error) {
+	sorted := append([]triageEdit(nil), edits...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		x, y := sorted[i], sorted[j]
+		if x.Start != y.Start {
+			return x.Start < y.Start
+		}
+		return x.End == x.Start && y.End != y.Start
+	})
+
+	var out bytes.Buffer
+	last := 0
+	for _, e := range sorted {
+		if e.Start < last || e.End < e.Start || e.End > len(src) {
+			return nil, fmt.Errorf("edit [%d,%d) is out of range or overlaps another", e.Start, e.End)
+		}
+		out.Write(src[last:e.Start])
+		out.WriteString(e.New)
+		last = e.End
+	}
+	out.Write(src[last:])
+	return out.Bytes(), nil
+}
+
+// source returns the original content of a file, read once
+func (t *triage) source(file string) ([]byte, error) {
+	if src, ok := t.sources[file]; ok {
+		return src, nil
+	}
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	t.sources[file] = src
+	return src, nil
+}
+
+func (t *triage) lines(file string) ([]string, error) {
+	src, err := t.source(file)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(string(src), "\n"), nil
+}
+
+// lineOffset returns the offset of the start of a 1-based line, or -1
+func lineOffset(src []byte, line int) int {
+	offset := 0
+	for n := 1; n < line; n++ {
+		i := bytes.IndexByte(src[offset:], '\n')
+		if i < 0 {
+			return -1
+		}
+		offset += i + 1
+	}
+	return offset
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseTriageFindings(t *testing.T) {
+	data := `{
+	"example.com/a": {"nonillinter": [
+		{"posn": "/src/a/b.go:12:3", "message": "second"},
+		{"posn": "/src/a/a.go:7:9", "category": "map-lookup", "message": "first",
+		 "suggested_fixes": [{"message": "Replace nil with timestamppb.Now()", "edits": [{"filename": "/src/a/a.go", "start": 10, "end": 13, "new": "timestamppb.Now()"}]}]}
+	]},
+	"example.com/a [example.com/a.test]": {"nonillinter": [
+		{"posn": "/src/a/a.go:7:9", "category": "map-lookup", "message": "first"}
+	]}
+}`
+	findings, err := parseTriageFindings([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings after dropping the test variant's repeat, got %d", len(findings))
+	}
+	first := findings[0]
+	if first.file != "/src/a/a.go" || first.line != 7 || first.column != 9 || first.Category != "map-lookup" {
+		t.Errorf("Unexpected first finding %+v", first)
+	}
+	if len(first.Fixes) != 1 || first.Fixes[0].Edits[0].New != "timestamppb.Now()" {
+		t.Errorf("Expected the suggested fix to be read, got %+v", first.Fixes)
+	}
+
+	if _, err := parseTriageFindings([]byte(`{"example.com/a": {"nonillinter": {"error": "type errors"}}}`)); err == nil || !strings.Contains(err.Error(), "type errors") {
+		t.Errorf("Expected the analysis error to be returned, got %v", err)
+	}
+}
+
+func TestTriage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.go")
+	src := "package a\n\nfunc f(resp *Resp) {\n\tresp.User = nil\n\tresp.CreatedAt = nil\n\tresp.Account = nil\n}\n"
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	offset := strings.Index(src, "nil\n\tresp.Account")
+	findings := []*triageFinding{
+		{Posn: path + ":4:2", Message: "nil assignment to 'User'", file: path, line: 4, column: 2},
+		{Posn: path + ":5:2", Message: "nil assignment to 'CreatedAt'", file: path, line: 5, column: 2,
+			Fixes: []triageFix{{Message: "Replace nil with timestamppb.Now()", Edits: []triageEdit{{Filename: path, Start: offset, End: offset + 3, New: "timestamppb.Now()"}}}}},
+		{Posn: path + ":6:2", Message: "nil assignment to 'Account'", file: path, line: 6, column: 2},
+	}
+
+	// An empty reason is refused and the reason typed after it is taken; q quits once
+	// every finding is triaged
+	input := "s\rfilled in by the gateway\rfnq"
+	var out bytes.Buffer
+	session := newTriage(findings, strings.NewReader(input), &out)
+	session.run()
+	written, err := session.write()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixed, suppressed, skipped := session.counts()
+	if written != 1 || fixed != 1 || suppressed != 1 || skipped != 1 {
+		t.Errorf("Expected 1 file written, 1 fixed, 1 suppressed and 1 skipped, got %d, %d, %d, %d",
+			written, fixed, suppressed, skipped)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package a\n\nfunc f(resp *Resp) {\n\t//nonillinter:ignore filled in by the gateway\n\tresp.User = nil\n\tresp.CreatedAt = timestamppb.Now()\n\tresp.Account = nil\n}\n"
+	if string(got) != want {
+		t.Errorf("Unexpected file after triage:\n%s\nwant:\n%s", got, want)
+	}
+	for _, text := range []string{
+		fmt.Sprintf("> %4d |     resp.CreatedAt = nil", 5),
+		"resp.CreatedAt = timestamppb.Now()",
+		"a reason is required",
+		"every finding is triaged",
+	} {
+		if !strings.Contains(out.String(), text) {
+			t.Errorf("Expected the screen to show %q, got:\n%s", text, out.String())
+		}
+	}
+}
+
+func TestTriageUndo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.go")
+	src := "package a\n\nfunc f(resp *Resp) {\n\tresp.User, resp.Account = nil, nil\n}\n"
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	findings := []*triageFinding{
+		{Posn: path + ":4:2", Message: "nil assignment to 'User'", file: path, line: 4, column: 2},
+		{Posn: path + ":4:13", Message: "nil assignment to 'Account'", file: path, line: 4, column: 13},
+	}
+
+	// Both findings are suppressed, then the left arrow goes back to the first and its
+	// suppression is undone, so the line keeps the comment of the second
+	input := "sfirst\rssecond\r\x1b[Duq"
+	session := newTriage(findings, strings.NewReader(input), io.Discard)
+	session.run()
+	if _, err := session.write(); err != nil {
+		t.Fatal(err)
+	}
+	if _, suppressed, skipped := session.counts(); suppressed != 1 || skipped != 1 {
+		t.Errorf("Expected 1 suppressed and 1 skipped, got %d and %d", suppressed, skipped)
+	}
+	want := "package a\n\nfunc f(resp *Resp) {\n\t//nonillinter:ignore second\n\tresp.User, resp.Account = nil, nil\n}\n"
+	if got, _ := os.ReadFile(path); string(got) != want {
+		t.Errorf("Unexpected file after triage:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTriageQuitAndOverlap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.go")
+	src := "package a\n\nvar x = nil\n"
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	start := strings.Index(src, "nil")
+	edit := func(text string) []triageFix {
+		return []triageFix{{Message: "Replace nil", Edits: []triageEdit{{Filename: path, Start: start, End: start + 3, New: text}}}}
+	}
+	findings := []*triageFinding{
+		{Posn: path + ":3:9", Message: "one", file: path, line: 3, column: 9, Fixes: edit("a")},
+		{Posn: path + ":3:9", Message: "two", file: path, line: 3, column: 9, Fixes: edit("b")},
+		{Posn: path + ":3:9", Message: "three", file: path, line: 3, column: 9},
+	}
+
+	// The second fix overlaps the first, so it is refused and the session quits
+	var out bytes.Buffer
+	session := newTriage(findings, strings.NewReader("ffq"), &out)
+	session.run()
+	if _, err := session.write(); err != nil {
+		t.Fatal(err)
+	}
+	if fixed, _, skipped := session.counts(); fixed != 1 || skipped != 2 {
+		t.Errorf("Expected 1 fixed and 2 skipped, got %d and %d", fixed, skipped)
+	}
+	if !strings.Contains(out.String(), "overlaps an earlier change") {
+		t.Errorf("Expected the overlapping fix to be refused, got:\n%s", out.String())
+	}
+	if got, _ := os.ReadFile(path); string(got) != "package a\n\nvar x = a\n" {
+		t.Errorf("Unexpected file after triage:\n%s", got)
+	}
+}
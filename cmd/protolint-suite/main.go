@@ -0,0 +1,23 @@
+// Command protolint-suite runs nonillinter together with the companion protobuf
+// analyzers, so one binary covers proto hygiene as a standalone tool or a vettool:
+//
+//	go vet -vettool=$(which protolint-suite) ./...
+//
+// Each analyzer can be turned off with its flag, e.g. -protodeprecated=false, and its
+// own flags are prefixed with its name, e.g. -nonillinter.response-suffixes.
+package main
+
+import (
+	"github.com/nickheyer/go_no_nil_linter/analyzer"
+	"github.com/nickheyer/go_no_nil_linter/passes/protodeprecated"
+	"github.com/nickheyer/go_no_nil_linter/passes/protooneof"
+	"golang.org/x/tools/go/analysis/multichecker"
+)
+
+func main() {
+	multichecker.Main(
+		analyzer.Analyzer,
+		protooneof.Analyzer,
+		protodeprecated.Analyzer,
+	)
+}
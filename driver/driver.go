@@ -0,0 +1,470 @@
+// Package driver runs analyzers over the packages named on the command line, as the
+// nonillinter command does. It replaces singlechecker, whose main function owns flag
+// parsing, output and the exit, with one built on go/packages and the checker package
+// that commands can extend with formats, subcommands and configuration of their own.
+//
+// Main takes the place of singlechecker.Main for programs that embed the analyzer
+// today, with the same flags, output and exit codes:
+//
+//	func main() { driver.Main(analyzer.Analyzer) }
+//
+// Commands that do more with a run use Command and Run instead: Run returns the graph
+// of the analysis to report on, and hooks in Options see the packages once they are
+// loaded and the findings before they are printed.
+//
+// Like singlechecker, it is also a vet tool: go vet -vettool runs it once per package
+// with a .cfg file, and those runs are handed to unitchecker.
+package driver
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/analysis/unitchecker"
+	"golang.org/x/tools/go/packages"
+)
+
+// Options configures a Run. RegisterFlags binds them to the flags singlechecker has.
+type Options struct {
+	// Tests includes the packages' test files and test variants (-test)
+	Tests bool
+
+	// Fix applies the suggested fixes of the findings to the files (-fix)
+	Fix bool
+
+	// JSON prints the findings as JSON to Stdout instead of text to Stderr (-json), and
+	// Context the lines around each finding shown in text, or -1 for none (-c)
+	JSON    bool
+	Context int
+
+	// Debug is a subset of "fpstv": show facts as they are created, analyze packages
+	// one at a time, sanity-check facts, print timings and log progress (-debug)
+	Debug string
+
+	// CPUProfile, MemProfile and Trace name files to write profiles to
+	CPUProfile, MemProfile, Trace string
+
+	Stdout, Stderr io.Writer
+
+	// Loaded, if set, is called with the packages named once they are loaded, before
+	// any analysis starts
+	Loaded func(initial []*packages.Package)
+
+	// Analyzed, if set, is called with the graph of the analysis before fixes are
+	// applied and findings printed. The roots of the graph are the analyses of the
+	// packages named; their Diagnostics are what gets printed and fixed, so it may drop
+	// or rewrite them. An error fails the run.
+	Analyzed func(graph *checker.Graph) error
+}
+
+// debug checks if a -debug letter is set
+func (o *Options) debug(c byte) bool {
+	return strings.IndexByte(o.Debug, c) >= 0
+}
+
+// Main runs a as singlechecker.Main does: it parses the command line, runs the analyzer
+// on the packages named and exits with the status of Run
+func Main(a *analysis.Analyzer) {
+	Command(a, func(patterns []string, opts *Options) int {
+		_, code := Run(patterns, []*analysis.Analyzer{a}, opts)
+		return code
+	})
+}
+
+// Command parses the command line as Main does, then calls run with the packages named
+// and the options the flags set and exits with the status it returns. Commands that
+// add to a run, by setting hooks on the options or reporting once it returns, use it in
+// place of Main; go vet runs are still handed to unitchecker with a alone.
+func Command(a *analysis.Analyzer, run func(patterns []string, opts *Options) int) {
+	log.SetFlags(0)
+	log.SetPrefix(a.Name + ": ")
+
+	analyzers := []*analysis.Analyzer{a}
+	if err := analysis.Validate(analyzers); err != nil {
+		log.Fatal(err)
+	}
+
+	// go vet -vettool runs the tool once per package with a .cfg file, and flags such as
+	// -json that unitchecker reads from its own flag set; it parses them and exits
+	if n := len(os.Args); n > 1 && strings.HasSuffix(os.Args[n-1], ".cfg") {
+		unitchecker.Main(analyzers...)
+	}
+
+	opts := RegisterFlags(flag.CommandLine, analyzers)
+	printFlags := flag.Bool("flags", false, "print analyzer flags in JSON")
+	flag.Usage = func() {
+		paras := strings.Split(a.Doc, "\n\n")
+		fmt.Fprintf(os.Stderr, "%s: %s\n\n", a.Name, paras[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s [-flag] [package]\n\n", a.Name)
+		if len(paras) > 1 {
+			fmt.Fprintln(os.Stderr, strings.Join(paras[1:], "\n\n"))
+		}
+		fmt.Fprintln(os.Stderr, "\nFlags:")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	// go vet asks which flags it may pass on with -flags
+	if *printFlags {
+		if err := PrintFlags(flag.CommandLine, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	os.Exit(run(args, opts))
+}
+
+// RegisterFlags registers the analyzers' flags and the driver's on fs, and returns the
+// Options they set. Analyzer flags that would conflict with the driver's are skipped.
+func RegisterFlags(fs *flag.FlagSet, analyzers []*analysis.Analyzer) *Options {
+	opts := &Options{Tests: true, Context: -1, Stdout: os.Stdout, Stderr: os.Stderr}
+	fs.StringVar(&opts.Debug, "debug", "", `debug flags, any subset of "fpstv"`)
+	fs.StringVar(&opts.CPUProfile, "cpuprofile", "", "write CPU profile to this file")
+	fs.StringVar(&opts.MemProfile, "memprofile", "", "write memory profile to this file")
+	fs.StringVar(&opts.Trace, "trace", "", "write trace log to this file")
+	fs.BoolVar(&opts.Tests, "test", true, "indicates whether test files should be analyzed, too")
+	fs.BoolVar(&opts.Fix, "fix", false, "apply all suggested fixes")
+
+	for _, a := range analyzers {
+		a.Flags.VisitAll(func(f *flag.Flag) {
+			if fs.Lookup(f.Name) != nil {
+				log.Printf("%s flag -%s would conflict with driver; skipping", a.Name, f.Name)
+				return
+			}
+			fs.Var(f.Value, f.Name, f.Usage)
+		})
+	}
+
+	if fs.Lookup("V") == nil {
+		fs.Var(versionFlag{}, "V", "print version and exit")
+	}
+	fs.BoolVar(&opts.JSON, "json", false, "emit JSON output")
+	fs.IntVar(&opts.Context, "c", -1, "display offending line with this many lines of context")
+
+	// Shims for legacy vet flags, so existing scripts that run vet keep working
+	fs.Bool("source", false, "no effect (deprecated)")
+	fs.Bool("v", false, "no effect (deprecated)")
+	fs.Bool("all", false, "no effect (deprecated)")
+	fs.String("tags", "", "no effect (deprecated)")
+	return opts
+}
+
+// PrintFlags writes the flags of fs as JSON, in the form go vet reads to learn which
+// flags a vet tool accepts. The debugging flags and -fix are left out, since they have
+// no effect under go vet.
+func PrintFlags(fs *flag.FlagSet, w io.Writer) error {
+	type jsonFlag struct {
+		Name  string
+		Bool  bool
+		Usage string
+	}
+	var flags []jsonFlag
+	fs.VisitAll(func(f *flag.Flag) {
+		switch f.Name {
+		case "debug", "cpuprofile", "memprofile", "trace", "fix":
+			return
+		}
+		b, ok := f.Value.(interface{ IsBoolFlag() bool })
+		flags = append(flags, jsonFlag{f.Name, ok && b.IsBoolFlag(), f.Usage})
+	})
+	data, err := json.MarshalIndent(flags, "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// versionFlag implements -V=full, which go vet uses to tell vet tools apart in its
+// cache: it prints the executable's name and a hash of its contents and exits
+type versionFlag struct{}
+
+func (versionFlag) IsBoolFlag() bool { return true }
+func (versionFlag) Get() interface{} { return nil }
+func (versionFlag) String() string   { return "" }
+func (versionFlag) Set(s string) error {
+	if s != "full" {
+		return fmt.Errorf("unsupported flag value: -V=%s (use -V=full)", s)
+	}
+	progname, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(progname)
+	if err != nil {
+		return err
+	}
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s version devel comments-go-here buildID=%02x\n", progname, string(h.Sum(nil)))
+	os.Exit(0)
+	return nil
+}
+
+// Run loads the packages matching patterns with go/packages and runs the analyzers on
+// them, which must be valid and have their flags set. It prints the findings of the
+// packages named and returns the graph of the analysis, for callers to report on once
+// it's done, with the exit status singlechecker uses: 3 when there are findings, 1 when
+// an analysis or a package failed, and 0 otherwise. With JSON output findings don't
+// change the status. The graph is nil when loading or analysis failed outright.
+func Run(patterns []string, analyzers []*analysis.Analyzer, opts *Options) (*checker.Graph, int) {
+	logger := log.New(opts.Stderr, log.Prefix(), log.Flags())
+	stop, err := startProfiles(opts, logger)
+	if err != nil {
+		logger.Print(err)
+		return nil, 1
+	}
+	defer stop()
+
+	if opts.debug('v') {
+		logger.SetFlags(log.Lmicroseconds)
+		logger.Printf("load %s", patterns)
+	}
+	initial, err := load(patterns, analyzers, opts.Tests)
+	if err != nil {
+		logger.Print(err)
+		return nil, 1
+	}
+	// Package errors are printed, but the analyzers still run where they can
+	pkgsExitCode := 0
+	if printErrors(initial, opts.Stderr) > 0 {
+		pkgsExitCode = 1
+	}
+	if opts.Loaded != nil {
+		opts.Loaded(initial)
+	}
+
+	checkerOpts := &checker.Options{
+		SanityCheck: opts.debug('s'),
+		Sequential:  opts.debug('p'),
+	}
+	if opts.debug('f') {
+		checkerOpts.FactLog = opts.Stderr
+	}
+	if opts.debug('v') {
+		logger.Printf("building graph of analysis passes")
+	}
+	graph, err := checker.Analyze(analyzers, initial, checkerOpts)
+	if err != nil {
+		logger.Print(err)
+		return nil, 1
+	}
+	if opts.Analyzed != nil {
+		if err := opts.Analyzed(graph); err != nil {
+			logger.Print(err)
+			return graph, 1
+		}
+	}
+
+	if opts.Fix {
+		if err := applyFixes(graph.Roots); err != nil {
+			logger.Print(err)
+			return graph, 1
+		}
+	}
+
+	exitCode := printDiagnostics(graph, opts)
+	if opts.debug('t') {
+		printTimes(graph, opts)
+	}
+	if exitCode != 0 {
+		return graph, exitCode
+	}
+	return graph, pkgsExitCode
+}
+
+// load loads the packages matching patterns. Dependencies are loaded from source too
+// when an analyzer needs their facts.
+func load(patterns []string, analyzers []*analysis.Analyzer, tests bool) ([]*packages.Package, error) {
+	mode := packages.LoadSyntax
+	if needFacts(analyzers) {
+		mode = packages.LoadAllSyntax
+	}
+	initial, err := packages.Load(&packages.Config{Mode: mode | packages.NeedModule, Tests: tests}, patterns...)
+	if err == nil && len(initial) == 0 {
+		err = fmt.Errorf("%s matched no packages", strings.Join(patterns, " "))
+	}
+	return initial, err
+}
+
+// needFacts checks if any of the analyzers, or those they require, uses facts
+func needFacts(analyzers []*analysis.Analyzer) bool {
+	seen := make(map[*analysis.Analyzer]bool)
+	queue := append([]*analysis.Analyzer(nil), analyzers...)
+	for len(queue) > 0 {
+		a := queue[0]
+		queue = queue[1:]
+		if seen[a] {
+			continue
+		}
+		seen[a] = true
+		if len(a.FactTypes) > 0 {
+			return true
+		}
+		queue = append(queue, a.Requires...)
+	}
+	return false
+}
+
+// printErrors prints the errors of the packages and their dependencies, each once, and
+// returns how many there were
+func printErrors(pkgs []*packages.Package, w io.Writer) int {
+	n := 0
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if pkg.Module != nil && pkg.Module.Error != nil {
+			fmt.Fprintln(w, pkg.Module.Error.Err)
+			n++
+		}
+		for _, err := range pkg.Errors {
+			fmt.Fprintln(w, err)
+			n++
+		}
+	})
+	return n
+}
+
+// printDiagnostics prints the findings of the packages named, as JSON or text, and
+// returns the exit status they call for
+func printDiagnostics(graph *checker.Graph, opts *Options) int {
+	if opts.JSON {
+		if err := graph.PrintJSON(opts.Stdout); err != nil {
+			return 1
+		}
+		return 0
+	}
+	if err := graph.PrintText(opts.Stderr, opts.Context); err != nil {
+		return 1
+	}
+
+	var failed, found int
+	for _, act := range allActions(graph) {
+		if act.Err != nil {
+			failed++
+		} else if act.IsRoot {
+			found += len(act.Diagnostics)
+		}
+	}
+	switch {
+	case failed > 0:
+		return 1
+	case found > 0:
+		return 3
+	}
+	return 0
+}
+
+// printTimes prints the slowest analyses, those taking 90% of the total time
+func printTimes(graph *checker.Graph, opts *Options) {
+	if !opts.debug('p') {
+		fmt.Fprintln(opts.Stderr, "Warning: times are mostly GC/scheduler noise; use -debug=tp to disable parallelism")
+	}
+	actions := allActions(graph)
+	var total time.Duration
+	for _, act := range actions {
+		total += act.Duration
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i].Duration > actions[j].Duration })
+	var sum time.Duration
+	for _, act := range actions {
+		fmt.Fprintf(opts.Stderr, "%s\t%s\n", act.Duration, act)
+		sum += act.Duration
+		if sum >= total*9/10 {
+			break
+		}
+	}
+	if total > sum {
+		fmt.Fprintf(opts.Stderr, "%s\tall others\n", total-sum)
+	}
+}
+
+// allActions lists the actions of the graph, dependencies included
+func allActions(graph *checker.Graph) []*checker.Action {
+	var actions []*checker.Action
+	graph.All()(func(act *checker.Action) bool {
+		actions = append(actions, act)
+		return true
+	})
+	return actions
+}
+
+// startProfiles starts the profiles opts asks for, and returns the function writing them
+func startProfiles(opts *Options, logger *log.Logger) (func(), error) {
+	var stops []func()
+	stop := func() {
+		for i := len(stops) - 1; i >= 0; i-- {
+			stops[i]()
+		}
+	}
+	if opts.CPUProfile != "" {
+		f, err := os.Create(opts.CPUProfile)
+		if err != nil {
+			return stop, err
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return stop, err
+		}
+		stops = append(stops, func() {
+			pprof.StopCPUProfile()
+			f.Close()
+		})
+	}
+	if opts.Trace != "" {
+		f, err := os.Create(opts.Trace)
+		if err != nil {
+			stop()
+			return func() {}, err
+		}
+		if err := trace.Start(f); err != nil {
+			f.Close()
+			stop()
+			return func() {}, err
+		}
+		stops = append(stops, func() {
+			trace.Stop()
+			f.Close()
+			logger.Printf("To view the trace, run:\n$ go tool trace view %s", opts.Trace)
+		})
+	}
+	if opts.MemProfile != "" {
+		f, err := os.Create(opts.MemProfile)
+		if err != nil {
+			stop()
+			return func() {}, err
+		}
+		stops = append(stops, func() {
+			runtime.GC()
+			err := pprof.WriteHeapProfile(f)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				logger.Printf("writing memory profile: %v", err)
+			}
+		})
+	}
+	return stop, nil
+}
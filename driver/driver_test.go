@@ -0,0 +1,194 @@
+package driver
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"go/ast"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+)
+
+// renamedFact marks nothing; like nonillinter's facts, it makes the driver load the
+// dependencies from source
+type renamedFact struct{}
+
+func (*renamedFact) AFact() {}
+
+// renamer reports calls of old with a fix calling New instead
+var renamer = &analysis.Analyzer{
+	Name:      "renamer",
+	Doc:       "reports calls of old",
+	FactTypes: []analysis.Fact{new(renamedFact)},
+	Run: func(pass *analysis.Pass) (interface{}, error) {
+		for _, file := range pass.Files {
+			ast.Inspect(file, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				if id, ok := call.Fun.(*ast.Ident); ok && id.Name == "old" {
+					pass.Report(analysis.Diagnostic{
+						Pos:     id.Pos(),
+						End:     id.End(),
+						Message: "call of old",
+						SuggestedFixes: []analysis.SuggestedFix{{
+							Message:   "Call New",
+							TextEdits: []analysis.TextEdit{{Pos: id.Pos(), End: id.End(), NewText: []byte("New")}},
+						}},
+					})
+				}
+				return true
+			})
+		}
+		return nil, nil
+	},
+}
+
+// runMod runs the renamer on a copy of testdata/mod with the flags given, and returns
+// the exit status, the output and the directory of the copy
+func runMod(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	return runModWith(t, nil, args...)
+}
+
+// runModWith is runMod with setup, if set, called on the options before the run
+func runModWith(t *testing.T, setup func(*Options), args ...string) (int, string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range []string{"go.mod", "mod.go", "mod_test.go"} {
+		data, err := os.ReadFile(filepath.Join("testdata", "mod", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	fs := flag.NewFlagSet("renamer", flag.ContinueOnError)
+	opts := RegisterFlags(fs, []*analysis.Analyzer{renamer})
+	if err := fs.Parse(append(args, "./...")); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	opts.Stdout, opts.Stderr = &out, &out
+	if setup != nil {
+		setup(opts)
+	}
+	_, code := Run(fs.Args(), []*analysis.Analyzer{renamer}, opts)
+	return code, out.String(), dir
+}
+
+func TestRun(t *testing.T) {
+	code, out, _ := runMod(t)
+	if code != 3 {
+		t.Errorf("exit status = %d, want 3", code)
+	}
+	// The test variant repeats the findings, which are printed once
+	if n := strings.Count(out, "call of old"); n != 2 {
+		t.Errorf("got %d findings, want 2:\n%s", n, out)
+	}
+	if !strings.Contains(out, "mod.go:8:9: call of old") {
+		t.Errorf("output lacks the first finding:\n%s", out)
+	}
+
+	_, out, _ = runMod(t, "-c=0")
+	if !strings.Contains(out, "8\t\treturn old() + old()") {
+		t.Errorf("-c=0 output lacks the offending line:\n%s", out)
+	}
+}
+
+func TestRunHooks(t *testing.T) {
+	var loaded, roots int
+	code, out, _ := runModWith(t, func(opts *Options) {
+		opts.Loaded = func(initial []*packages.Package) { loaded = len(initial) }
+		opts.Analyzed = func(graph *checker.Graph) error {
+			// Dropping the findings of the roots leaves nothing to print
+			for _, act := range graph.Roots {
+				roots++
+				act.Diagnostics = nil
+			}
+			return nil
+		}
+	})
+	if loaded == 0 || roots == 0 {
+		t.Errorf("hooks saw %d packages and %d roots, want some", loaded, roots)
+	}
+	if code != 0 || strings.Contains(out, "call of old") {
+		t.Errorf("exit status = %d with output %q, want 0 and none", code, out)
+	}
+
+	code, out, _ = runModWith(t, func(opts *Options) {
+		opts.Analyzed = func(*checker.Graph) error { return errors.New("rejected") }
+	})
+	if code != 1 || !strings.Contains(out, "rejected") {
+		t.Errorf("exit status = %d with output %q, want 1 and the error", code, out)
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	code, out, _ := runMod(t, "-json")
+	if code != 0 {
+		t.Errorf("exit status = %d, want 0", code)
+	}
+	for _, want := range []string{`"renamer": [`, `"posn": `, `"message": "call of old"`, `"new": "New"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("JSON output lacks %s:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunFix(t *testing.T) {
+	_, _, dir := runMod(t, "-fix")
+	data, err := os.ReadFile(filepath.Join(dir, "mod.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Both variants of the package suggest each edit; it is applied once
+	if !strings.Contains(string(data), "return New() + New()") {
+		t.Errorf("fixes not applied once:\n%s", data)
+	}
+}
+
+func TestApplyEditsConflict(t *testing.T) {
+	_, err := applyEdits([]byte("return old()"), []edit{{7, 10, "New"}, {8, 12, "x()"}})
+	if !errors.Is(err, errConflict) {
+		t.Errorf("applyEdits() error = %v, want a conflict", err)
+	}
+}
+
+func TestPrintFlags(t *testing.T) {
+	fs := flag.NewFlagSet("renamer", flag.ContinueOnError)
+	RegisterFlags(fs, []*analysis.Analyzer{renamer})
+	var out bytes.Buffer
+	if err := PrintFlags(fs, &out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"Name": "json"`, `"Name": "c"`, `"Name": "V"`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("flags lack %s:\n%s", want, out.String())
+		}
+	}
+	// go vet passes on only the flags a vet tool lists, and the debugging ones and -fix
+	// have no effect there
+	for _, unwanted := range []string{`"Name": "debug"`, `"Name": "fix"`, `"Name": "cpuprofile"`} {
+		if strings.Contains(out.String(), unwanted) {
+			t.Errorf("flags list %s:\n%s", unwanted, out.String())
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package driver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"sort"
+
+	"golang.org/x/tools/go/analysis/checker"
+)
+
+// errConflict is returned when two suggested fixes edit the same part of a file
+var errConflict = errors.New("conflicting edits")
+
+// edit replaces the bytes [start, end) of a file with text
+type edit struct {
+	start, end int
+	text       string
+}
+
+// applyFixes applies every suggested fix of the findings of the actions and formats the
+// files edited. Edits are gathered by file first, so a package and its test variant,
+// which report the same fixes, edit a file once; nothing is written when two edits
+// overlap.
+func applyFixes(actions []*checker.Action) error {
+	edits := make(map[string][]edit)
+	for _, act := range actions {
+		for _, diag := range act.Diagnostics {
+			for _, fix := range diag.SuggestedFixes {
+				for _, e := range fix.TextEdits {
+					file := act.Package.Fset.File(e.Pos)
+					if file == nil {
+						return fmt.Errorf("analysis %q suggests invalid fix: missing file info for pos (%v)", act.Analyzer.Name, e.Pos)
+					}
+					end := e.End
+					if !end.IsValid() {
+						end = e.Pos
+					}
+					if e.Pos > end || end > token.Pos(file.Base()+file.Size()) {
+						return fmt.Errorf("analysis %q suggests invalid fix: edit [%v, %v) out of range of %s", act.Analyzer.Name, e.Pos, end, file.Name())
+					}
+					edits[file.Name()] = append(edits[file.Name()], edit{file.Offset(e.Pos), file.Offset(end), string(e.NewText)})
+				}
+			}
+		}
+	}
+
+	files := make([]string, 0, len(edits))
+	for file := range edits {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	out := make(map[string][]byte, len(files))
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		fixed, err := applyEdits(src, edits[file])
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if formatted, err := format.Source(fixed); err == nil {
+			fixed = formatted
+		}
+		out[file] = fixed
+	}
+
+	var errs []error
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err == nil {
+			err = os.WriteFile(file, out[file], info.Mode().Perm())
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// applyEdits applies edits given as offsets into src. Identical edits are applied once;
+// insertions at the same offset keep the order they were made in.
+func applyEdits(src []byte, edits []edit) ([]byte, error) {
+	var sorted []edit
+	seen := make(map[edit]bool, len(edits))
+	for _, e := range edits {
+		if !seen[e] {
+			seen[e] = true
+			sorted = append(sorted, e)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].start != sorted[j].start {
+			return sorted[i].start < sorted[j].start
+		}
+		return sorted[i].end < sorted[j].end
+	})
+
+	var out bytes.Buffer
+	last := 0
+	for _, e := range sorted {
+		if e.start < last || e.end > len(src) {
+			return nil, fmt.Errorf("%w at offset %d", errConflict, e.start)
+		}
+		out.Write(src[last:e.start])
+		out.WriteString(e.text)
+		last = e.end
+	}
+	out.Write(src[last:])
+	return out.Bytes(), nil
+}
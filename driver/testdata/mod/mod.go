@@ -0,0 +1,9 @@
+package mod
+
+func old() int { return 1 }
+
+func New() int { return 1 }
+
+func use() int {
+	return old() + old()
+}
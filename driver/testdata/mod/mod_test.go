@@ -0,0 +1,9 @@
+package mod
+
+import "testing"
+
+func TestUse(t *testing.T) {
+	if use() != 2 {
+		t.Fatal(use())
+	}
+}
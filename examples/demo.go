@@ -10,7 +10,7 @@ func createUser() *examplev1.User {
 	user := &examplev1.User{
 		Id:   "123",
 		Name: "John",
-		// Address: nil,  // This would NOT be flagged - User is not a Response message
+		// Address: nil,  // This would NOT be flagged - User is not a Response message (unless -check-all-messages)
 	}
 	return user
 }
@@ -0,0 +1,10 @@
+module github.com/nickheyer/go_no_nil_linter/examples
+
+go 1.22.0
+
+require (
+	github.com/nickheyer/go_no_nil_linter/gen v0.0.0
+	google.golang.org/protobuf v1.31.0
+)
+
+replace github.com/nickheyer/go_no_nil_linter/gen => ../gen
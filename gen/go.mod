@@ -0,0 +1,5 @@
+module github.com/nickheyer/go_no_nil_linter/gen
+
+go 1.22.0
+
+require google.golang.org/protobuf v1.31.0
@@ -3,11 +3,13 @@ module github.com/nickheyer/go_no_nil_linter
 go 1.22.0
 
 require (
+	github.com/golangci/plugin-module-register v0.1.1
+	golang.org/x/term v0.27.0
 	golang.org/x/tools v0.28.0
-	google.golang.org/protobuf v1.31.0
 )
 
 require (
 	golang.org/x/mod v0.22.0 // indirect
 	golang.org/x/sync v0.10.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
 )
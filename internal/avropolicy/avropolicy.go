@@ -0,0 +1,118 @@
+// Package avropolicy classifies the Go types Avro code generators write for .avsc
+// schemas, as an experimental schema next to protobuf's; see protopolicy.
+//
+// Two generators are recognized. gogen-avro writes a record as a struct with Schema()
+// and SchemaName() methods, a record field as a pointer to the record's struct, and a
+// union with null as a pointer to a union struct of its own:
+//
+//	type User struct {
+//		Address *Address          `json:"address"`
+//		Manager *UnionNullManager `json:"manager"`
+//	}
+//
+// So a pointer to a record is a field the schema requires. hamba/avro's avrogen tags
+// the fields of a record with avro:"..." and writes a union with null as a pointer to
+// the record, and a required record as a value, so its pointer fields are optional.
+package avropolicy
+
+import (
+	"go/types"
+	"reflect"
+	"strings"
+)
+
+// Policy is the Avro schema. It has no settings; records are named by the Go types the
+// generators write.
+type Policy struct{}
+
+// IsMessage checks if a named type is an Avro record: a struct with gogen-avro's
+// Schema() and SchemaName() methods, or with avro:"..." field tags
+func (p *Policy) IsMessage(t *types.Named) bool {
+	structType, ok := t.Underlying().(*types.Struct)
+	if !ok || isUnion(structType) {
+		return false
+	}
+	if hasStringMethod(t, "Schema") && hasStringMethod(t, "SchemaName") {
+		return true
+	}
+	for i := 0; i < structType.NumFields(); i++ {
+		if _, ok := reflect.StructTag(structType.Tag(i)).Lookup("avro"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isUnion checks if a struct is a union gogen-avro writes, which holds one of its
+// members and says which in UnionType
+func isUnion(structType *types.Struct) bool {
+	for i := 0; i < structType.NumFields(); i++ {
+		if structType.Field(i).Name() == "UnionType" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasStringMethod checks if a type, or a pointer to it, has a method name() string
+func hasStringMethod(t *types.Named, name string) bool {
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(t), false, t.Obj().Pkg(), name)
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	sig := fn.Type().(*types.Signature)
+	return sig.Params().Len() == 0 && sig.Results().Len() == 1 && types.Identical(sig.Results().At(0).Type(), types.Typ[types.String])
+}
+
+// IsMessageField checks if a struct field holds a single record through a pointer.
+// Records held as values can't be nil, and arrays and maps hold theirs in elements.
+func (p *Policy) IsMessageField(field *types.Var) bool {
+	ptr, ok := field.Type().(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	return ok && p.IsMessage(named)
+}
+
+// IsOptionalField checks if a field may be left nil: the fields avrogen tags, whose
+// pointers stand for unions with null
+func (p *Policy) IsOptionalField(structType *types.Struct, field *types.Var) bool {
+	for i := 0; i < structType.NumFields(); i++ {
+		if structType.Field(i) == field {
+			_, ok := reflect.StructTag(structType.Tag(i)).Lookup("avro")
+			return ok
+		}
+	}
+	return true
+}
+
+// FieldName returns the name code sets a field by. The generators export every field
+// they write.
+func (p *Policy) FieldName(field *types.Var) string {
+	if field.Exported() {
+		return field.Name()
+	}
+	return ""
+}
+
+// BuilderMessage returns nil: the generators write no builders
+func (p *Policy) BuilderMessage(t types.Type) types.Type {
+	return nil
+}
+
+// IsWellKnownType returns false: logical types such as timestamps aren't records
+func (p *Policy) IsWellKnownType(t types.Type) bool {
+	return false
+}
+
+// IsResponseName checks if a record type name is that of a response, *Response
+func (p *Policy) IsResponseName(typeName string) bool {
+	return strings.HasSuffix(typeName, "Response")
+}
+
+// IsRequestName checks if a record type name is that of a request, *Request
+func (p *Policy) IsRequestName(typeName string) bool {
+	return strings.HasSuffix(typeName, "Request")
+}
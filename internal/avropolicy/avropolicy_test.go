@@ -0,0 +1,54 @@
+package avropolicy
+
+import (
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+func TestIsMessage(t *testing.T) {
+	pkg := types.NewPackage("example.com/avro/user", "user")
+	named := func(name string, fields []*types.Var, tags []string) *types.Named {
+		return types.NewNamed(types.NewTypeName(token.NoPos, pkg, name, nil), types.NewStruct(fields, tags), nil)
+	}
+	stringMethod := func(recv *types.Named, name string) *types.Func {
+		results := types.NewTuple(types.NewVar(token.NoPos, pkg, "", types.Typ[types.String]))
+		sig := types.NewSignatureType(types.NewVar(token.NoPos, pkg, "r", types.NewPointer(recv)), nil, nil, nil, results, false)
+		return types.NewFunc(token.NoPos, pkg, name, sig)
+	}
+
+	gogen := named("User", nil, nil)
+	gogen.AddMethod(stringMethod(gogen, "Schema"))
+	gogen.AddMethod(stringMethod(gogen, "SchemaName"))
+	avrogen := named("Profile", []*types.Var{types.NewField(token.NoPos, pkg, "Name", types.Typ[types.String], false)}, []string{`avro:"name"`})
+	union := named("UnionNullUser", []*types.Var{types.NewField(token.NoPos, pkg, "UnionType", types.Typ[types.Int], false)}, nil)
+	union.AddMethod(stringMethod(union, "Schema"))
+	union.AddMethod(stringMethod(union, "SchemaName"))
+
+	var p Policy
+	for typ, want := range map[*types.Named]bool{
+		gogen:                    true,
+		avrogen:                  true,
+		union:                    false,
+		named("Plain", nil, nil): false,
+	} {
+		if got := p.IsMessage(typ); got != want {
+			t.Errorf("IsMessage(%s) = %v, want %v", typ, got, want)
+		}
+	}
+}
+
+func TestIsOptionalField(t *testing.T) {
+	pkg := types.NewPackage("example.com/avro/user", "user")
+	var p Policy
+	for tag, want := range map[string]bool{
+		`json:"address"`: false,
+		`avro:"address"`: true,
+	} {
+		field := types.NewField(token.NoPos, pkg, "Address", types.NewPointer(types.Typ[types.String]), false)
+		structType := types.NewStruct([]*types.Var{field}, []string{tag})
+		if got := p.IsOptionalField(structType, field); got != want {
+			t.Errorf("IsOptionalField(%s) = %v, want %v", tag, got, want)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+// Package protopolicy classifies the Go types protoc-gen-go and compatible generators
+// write for .proto files: which named types are messages, which of their fields hold
+// messages and may be left nil, and which messages are responses.
+//
+// The analyzer's engine works on named types and struct fields and asks a schema which
+// of them it checks; see schema.go in the analyzer package for the interface. Policy is
+// the protobuf schema. Another schema system, such as Thrift or Avro generated code,
+// would get a package of its own implementing the same interface, without changes to
+// the engine.
+package protopolicy
+
+import (
+	"go/types"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Policy holds the settings of the protobuf schema. The analyzer binds its flags to the
+// fields, so the zero value only recognizes messages with a ProtoMessage method and
+// treats no message as a response.
+type Policy struct {
+	// ResponseSuffixes holds the comma-separated type name suffixes of response messages
+	ResponseSuffixes string
+
+	// ResponsePattern matches further response message type names, if set
+	ResponsePattern *regexp.Regexp
+
+	// TaggedStructs treats plain structs with protobuf field tags as messages
+	TaggedStructs bool
+}
+
+// IsMessage checks if a named type is treated as a protobuf message: it has the
+// ProtoMessage() method or, with TaggedStructs, it is a plain struct with protobuf field
+// tags
+func (p *Policy) IsMessage(t *types.Named) bool {
+	if hasProtoMessageMethod(t) {
+		return true
+	}
+	return p.TaggedStructs && hasProtobufTags(t)
+}
+
+// hasProtobufTags checks if a named struct type has at least one field tagged with
+// protobuf:"..." (as generated by gogo) or proto:"..."
+func hasProtobufTags(t *types.Named) bool {
+	structType, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return false
+	}
+	for i := 0; i < structType.NumFields(); i++ {
+		tag := reflect.StructTag(structType.Tag(i))
+		if _, ok := tag.Lookup("protobuf"); ok {
+			return true
+		}
+		if _, ok := tag.Lookup("proto"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hasProtoMessageMethod checks if a type has the ProtoMessage() method
+func hasProtoMessageMethod(t *types.Named) bool {
+	for i := 0; i < t.NumMethods(); i++ {
+		method := t.Method(i)
+		if method.Name() != "ProtoMessage" {
+			continue
+		}
+		// ProtoMessage() has no params and no results
+		if sig, ok := method.Type().(*types.Signature); ok && sig.Params().Len() == 0 && sig.Results().Len() == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// scalarWrappers are the wrapper messages of google/protobuf/wrappers.proto. They stand
+// for optional scalars, so a field holding one may be nil.
+var scalarWrappers = map[string]bool{
+	"StringValue": true,
+	"Int32Value":  true,
+	"Int64Value":  true,
+	"UInt32Value": true,
+	"UInt64Value": true,
+	"FloatValue":  true,
+	"DoubleValue": true,
+	"BoolValue":   true,
+	"BytesValue":  true,
+}
+
+// IsMessageField checks if a struct field holds a message, not a scalar. Repeated
+// fields hold their messages in elements, and scalar wrappers are optional by nature,
+// so neither counts.
+func (p *Policy) IsMessageField(field *types.Var) bool {
+	fieldType := field.Type()
+	if _, ok := fieldType.(*types.Slice); ok {
+		return false
+	}
+	if ptr, ok := fieldType.(*types.Pointer); ok {
+		fieldType = ptr.Elem()
+	}
+	named, ok := fieldType.(*types.Named)
+	if !ok || !p.IsMessage(named) {
+		return false
+	}
+	if p.IsWellKnownType(named) {
+		return true
+	}
+	return !scalarWrappers[named.Obj().Name()]
+}
+
+// IsOptionalField checks if a field is optional in the .proto definition, as recorded
+// in the struct tags protoc-gen-go generates:
+//
+//   - protobuf_oneof:"..." marks a oneof wrapper
+//   - "oneof" in the protobuf tag marks a proto3 optional field (a synthetic oneof) or a oneof member
+//   - "opt" without "proto3" marks a proto2 optional field, "req" a proto2 required one
+//   - "rep" marks a repeated field
+//
+// Fields without a protobuf tag fall back to the double-pointer heuristic (**Type).
+func (p *Policy) IsOptionalField(structType *types.Struct, field *types.Var) bool {
+	for i := 0; i < structType.NumFields(); i++ {
+		if structType.Field(i) != field {
+			continue
+		}
+		tag := reflect.StructTag(structType.Tag(i))
+		if _, ok := tag.Lookup("protobuf_oneof"); ok {
+			return true
+		}
+		if value, ok := tag.Lookup("protobuf"); ok {
+			parts := strings.Split(value, ",")
+			has := func(marker string) bool {
+				for _, part := range parts {
+					if part == marker {
+						return true
+					}
+				}
+				return false
+			}
+			switch {
+			case has("oneof"), has("rep"):
+				return true
+			case has("req"):
+				return false
+			case has("opt"):
+				return !has("proto3")
+			}
+			return false
+		}
+		break
+	}
+
+	// In hand-written structs, optional message fields are sometimes **Type (double pointer)
+	if ptr, ok := field.Type().(*types.Pointer); ok {
+		if _, ok := ptr.Elem().(*types.Pointer); ok {
+			return true
+		}
+	}
+
+	return false // Conservative: assume required unless we can prove optional
+}
+
+// IsWellKnownType checks if a type is a Google well-known type, from the protobuf
+// runtime's types/known packages or the googleapis common types
+func (p *Policy) IsWellKnownType(t types.Type) bool {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+	pkgPath := named.Obj().Pkg().Path()
+	return strings.Contains(pkgPath, "google.golang.org/protobuf/types/known") ||
+		strings.Contains(pkgPath, "google.golang.org/genproto/googleapis/type")
+}
+
+// hiddenFieldPrefix starts the names of the fields protoc-gen-go's opaque API hides
+// behind accessors, e.g. xxx_hidden_User for GetUser and SetUser
+const hiddenFieldPrefix = "xxx_hidden_"
+
+// FieldName returns the name code sets a field by: its own for exported fields, and
+// that of its accessors for fields the opaque API hides. Other unexported fields, such
+// as the message state, are empty.
+func (p *Policy) FieldName(field *types.Var) string {
+	if field.Exported() {
+		return field.Name()
+	}
+	name, _ := strings.CutPrefix(field.Name(), hiddenFieldPrefix)
+	if name == field.Name() {
+		return ""
+	}
+	return name
+}
+
+// BuilderMessage returns the message type a builder of the opaque and hybrid APIs
+// builds, *X for X_builder with its Build() *X method, or nil when t isn't a builder
+func (p *Policy) BuilderMessage(t types.Type) types.Type {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return nil
+	}
+	message, ok := strings.CutSuffix(named.Obj().Name(), "_builder")
+	if !ok {
+		return nil
+	}
+	obj, _, _ := types.LookupFieldOrMethod(named, false, named.Obj().Pkg(), "Build")
+	build, ok := obj.(*types.Func)
+	if !ok {
+		return nil
+	}
+	sig := build.Type().(*types.Signature)
+	if sig.Params().Len() != 0 || sig.Results().Len() != 1 {
+		return nil
+	}
+	ptr, ok := sig.Results().At(0).Type().(*types.Pointer)
+	if !ok {
+		return nil
+	}
+	result, ok := ptr.Elem().(*types.Named)
+	if !ok || result.Obj().Name() != message || !p.IsMessage(result) {
+		return nil
+	}
+	return ptr
+}
+
+// IsResponseName checks if a message type name follows a response naming convention:
+// it ends with one of the ResponseSuffixes or matches ResponsePattern
+func (p *Policy) IsResponseName(typeName string) bool {
+	for _, suffix := range strings.Split(p.ResponseSuffixes, ",") {
+		if suffix = strings.TrimSpace(suffix); suffix != "" && strings.HasSuffix(typeName, suffix) {
+			return true
+		}
+	}
+	return p.ResponsePattern != nil && p.ResponsePattern.MatchString(typeName)
+}
+
+// IsRequestName checks if a message type name is that of a request, *Request
+func (p *Policy) IsRequestName(typeName string) bool {
+	return strings.HasSuffix(typeName, "Request")
+}
+
+// Tag is the parsed protobuf:"..." struct tag of a generated message field, e.g.
+// protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3"
+type Tag struct {
+	Number   int
+	Name     string
+	JSONName string
+}
+
+// ParseTag parses the protobuf tag of a struct field tag, if it has one
+func ParseTag(structTag string) (Tag, bool) {
+	value, ok := reflect.StructTag(structTag).Lookup("protobuf")
+	if !ok {
+		return Tag{}, false
+	}
+	var tag Tag
+	for i, part := range strings.Split(value, ",") {
+		switch {
+		case i == 1:
+			tag.Number, _ = strconv.Atoi(part)
+		case strings.HasPrefix(part, "name="):
+			tag.Name = strings.TrimPrefix(part, "name=")
+		case strings.HasPrefix(part, "json="):
+			tag.JSONName = strings.TrimPrefix(part, "json=")
+		}
+	}
+	// protoc-gen-go only writes json= when it differs from the field name
+	if tag.JSONName == "" {
+		tag.JSONName = tag.Name
+	}
+	return tag, true
+}
@@ -0,0 +1,66 @@
+package protopolicy
+
+import (
+	"go/token"
+	"go/types"
+	"regexp"
+	"testing"
+)
+
+func TestParseTag(t *testing.T) {
+	tag, ok := ParseTag(`protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`)
+	if !ok || tag != (Tag{Number: 3, Name: "created_at", JSONName: "createdAt"}) {
+		t.Errorf("ParseTag = %+v, %v", tag, ok)
+	}
+	if tag, _ := ParseTag(`protobuf:"bytes,1,opt,name=user,proto3"`); tag.JSONName != "user" {
+		t.Errorf("JSONName = %q, want the field name without json=", tag.JSONName)
+	}
+	if _, ok := ParseTag(`json:"user"`); ok {
+		t.Error("a field without a protobuf tag should not parse")
+	}
+}
+
+func TestIsOptionalField(t *testing.T) {
+	pkg := types.NewPackage("example.com/pb", "pb")
+	msg := types.NewPointer(types.NewNamed(types.NewTypeName(token.NoPos, pkg, "User", nil), types.NewStruct(nil, nil), nil))
+	tests := []struct {
+		tag  string
+		typ  types.Type
+		want bool
+	}{
+		{`protobuf:"bytes,1,opt,name=user,proto3"`, msg, false},
+		{`protobuf:"bytes,1,opt,name=user,proto3,oneof"`, msg, true},
+		{`protobuf:"bytes,1,opt,name=user"`, msg, true},
+		{`protobuf:"bytes,1,req,name=user"`, msg, false},
+		{`protobuf:"bytes,1,rep,name=users,proto3"`, types.NewSlice(msg), true},
+		{`protobuf_oneof:"result"`, msg, true},
+		{``, types.NewPointer(msg), true},
+		{``, msg, false},
+	}
+	var p Policy
+	for _, tt := range tests {
+		field := types.NewField(token.NoPos, pkg, "User", tt.typ, false)
+		structType := types.NewStruct([]*types.Var{field}, []string{tt.tag})
+		if got := p.IsOptionalField(structType, field); got != tt.want {
+			t.Errorf("IsOptionalField(%s %s) = %v, want %v", tt.typ, tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestIsResponseName(t *testing.T) {
+	p := Policy{ResponseSuffixes: "Response, Reply", ResponsePattern: regexp.MustCompile(`Out$`)}
+	for name, want := range map[string]bool{
+		"GetUserResponse": true,
+		"GetUserReply":    true,
+		"ListUsersOut":    true,
+		"GetUserRequest":  false,
+		"User":            false,
+	} {
+		if got := p.IsResponseName(name); got != want {
+			t.Errorf("IsResponseName(%q) = %v, want %v", name, got, want)
+		}
+	}
+	if (&Policy{}).IsResponseName("GetUserResponse") {
+		t.Error("the zero Policy should treat no message as a response")
+	}
+}
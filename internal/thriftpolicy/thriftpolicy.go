@@ -0,0 +1,98 @@
+// Package thriftpolicy classifies the Go types the Apache Thrift compiler generates for
+// .thrift files, as an experimental schema next to protobuf's; see protopolicy.
+//
+// Thrift structs, exceptions and unions are Go structs whose fields are tagged with
+// their name, id and requiredness:
+//
+//	type GetUserResponse struct {
+//		User    *User `thrift:"user,1,required" db:"user" json:"user"`
+//		Manager *User `thrift:"manager,2" db:"manager" json:"manager,omitempty"`
+//	}
+//
+// Only fields declared required in the IDL must hold a struct. Default and optional
+// fields may be nil; the generated code checks them with IsSet methods.
+package thriftpolicy
+
+import (
+	"go/types"
+	"reflect"
+	"strings"
+)
+
+// Policy is the Thrift schema. It has no settings; messages are named by the Go types
+// the compiler writes.
+type Policy struct{}
+
+// IsMessage checks if a named type is a Thrift struct: a struct with thrift:"..." field
+// tags
+func (p *Policy) IsMessage(t *types.Named) bool {
+	structType, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return false
+	}
+	for i := 0; i < structType.NumFields(); i++ {
+		if _, ok := reflect.StructTag(structType.Tag(i)).Lookup("thrift"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// IsMessageField checks if a struct field holds a single Thrift struct. The compiler
+// writes struct fields as pointers; lists, sets and maps hold theirs in elements.
+func (p *Policy) IsMessageField(field *types.Var) bool {
+	ptr, ok := field.Type().(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	return ok && p.IsMessage(named)
+}
+
+// IsOptionalField checks if a field may be left nil: any field but those tagged
+// required, which the IDL declares as such
+func (p *Policy) IsOptionalField(structType *types.Struct, field *types.Var) bool {
+	for i := 0; i < structType.NumFields(); i++ {
+		if structType.Field(i) != field {
+			continue
+		}
+		value, ok := reflect.StructTag(structType.Tag(i)).Lookup("thrift")
+		if !ok {
+			return true
+		}
+		parts := strings.Split(value, ",")
+		return len(parts) < 3 || parts[2] != "required"
+	}
+	return true
+}
+
+// FieldName returns the name code sets a field by. The compiler exports every field
+// it generates.
+func (p *Policy) FieldName(field *types.Var) string {
+	if field.Exported() {
+		return field.Name()
+	}
+	return ""
+}
+
+// BuilderMessage returns nil: the compiler generates no builders
+func (p *Policy) BuilderMessage(t types.Type) types.Type {
+	return nil
+}
+
+// IsWellKnownType returns false: Thrift has no standard structs
+func (p *Policy) IsWellKnownType(t types.Type) bool {
+	return false
+}
+
+// IsResponseName checks if a struct type name is that of a response, *Response. The
+// *Result structs the compiler generates for each service method are wrappers whose
+// fields are all optional.
+func (p *Policy) IsResponseName(typeName string) bool {
+	return strings.HasSuffix(typeName, "Response")
+}
+
+// IsRequestName checks if a struct type name is that of a request, *Request
+func (p *Policy) IsRequestName(typeName string) bool {
+	return strings.HasSuffix(typeName, "Request")
+}
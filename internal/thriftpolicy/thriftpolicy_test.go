@@ -0,0 +1,47 @@
+package thriftpolicy
+
+import (
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+func TestIsOptionalField(t *testing.T) {
+	pkg := types.NewPackage("example.com/gen-go/user", "user")
+	tests := []struct {
+		tag  string
+		want bool
+	}{
+		{`thrift:"user,1,required" db:"user" json:"user"`, false},
+		{`thrift:"user,1" db:"user" json:"user"`, true},
+		{`thrift:"user,1,optional" db:"user" json:"user,omitempty"`, true},
+		{`json:"user"`, true},
+	}
+	var p Policy
+	for _, tt := range tests {
+		field := types.NewField(token.NoPos, pkg, "User", types.NewPointer(types.Typ[types.String]), false)
+		structType := types.NewStruct([]*types.Var{field}, []string{tt.tag})
+		if got := p.IsOptionalField(structType, field); got != tt.want {
+			t.Errorf("IsOptionalField(%s) = %v, want %v", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestIsMessageField(t *testing.T) {
+	pkg := types.NewPackage("example.com/gen-go/user", "user")
+	id := types.NewField(token.NoPos, pkg, "ID", types.Typ[types.String], false)
+	user := types.NewNamed(types.NewTypeName(token.NoPos, pkg, "User", nil), types.NewStruct([]*types.Var{id}, []string{`thrift:"id,1"`}), nil)
+	plain := types.NewNamed(types.NewTypeName(token.NoPos, pkg, "Plain", nil), types.NewStruct(nil, nil), nil)
+
+	var p Policy
+	for typ, want := range map[types.Type]bool{
+		types.NewPointer(user):                 true,
+		user:                                   false,
+		types.NewSlice(types.NewPointer(user)): false,
+		types.NewPointer(plain):                false,
+	} {
+		if got := p.IsMessageField(types.NewField(token.NoPos, pkg, "F", typ, false)); got != want {
+			t.Errorf("IsMessageField(%s) = %v, want %v", typ, got, want)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Package protodeprecated defines an Analyzer that reports uses of protobuf message
+// fields marked deprecated.
+//
+// protoc-gen-go copies `[deprecated = true]` into a "Deprecated:" comment on the struct
+// field and its getter. The analyzer records those as facts while analyzing the
+// generated package, so uses are found in every package that imports it: selecting the
+// field, calling the getter or setting the field in a message literal.
+package protodeprecated
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Analyzer reports uses of deprecated protobuf fields and their getters
+var Analyzer = &analysis.Analyzer{
+	Name:      "protodeprecated",
+	Doc:       "reports uses of protobuf message fields marked deprecated",
+	Run:       run,
+	FactTypes: []analysis.Fact{new(deprecatedFact)},
+}
+
+// deprecatedFact marks a deprecated message field or getter
+type deprecatedFact struct {
+	// Name is the message and field, e.g. "User.Nickname"
+	Name string
+
+	// Note is the text of the Deprecated: paragraph
+	Note string
+}
+
+func (*deprecatedFact) AFact() {}
+
+func (f *deprecatedFact) String() string { return "deprecated " + f.Name }
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	for _, file := range pass.Files {
+		exportDeprecated(file, pass)
+	}
+
+	for _, file := range pass.Files {
+		if ast.IsGenerated(file) {
+			continue
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			id, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			obj := pass.TypesInfo.Uses[id]
+			var fact deprecatedFact
+			if obj == nil || !pass.ImportObjectFact(obj, &fact) {
+				return true
+			}
+			what := "field"
+			if _, ok := obj.(*types.Func); ok {
+				what = "getter of field"
+			}
+			message := fmt.Sprintf("use of deprecated protobuf %s '%s'", what, fact.Name)
+			if fact.Note != "" {
+				message += ": " + fact.Note
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:      id.Pos(),
+				End:      id.End(),
+				Category: "deprecated-field",
+				Message:  message,
+			})
+			return true
+		})
+	}
+	return nil, nil
+}
+
+// exportDeprecated records the deprecated fields of the messages declared in a file,
+// and their getters
+func exportDeprecated(file *ast.File, pass *analysis.Pass) {
+	fields := make(map[string]*deprecatedFact)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			for _, field := range st.Fields.List {
+				note, deprecated := deprecation(field.Doc)
+				if !deprecated || field.Tag == nil || !isProtobufTag(field.Tag.Value) {
+					continue
+				}
+				for _, name := range field.Names {
+					fact := &deprecatedFact{Name: ts.Name.Name + "." + name.Name, Note: note}
+					pass.ExportObjectFact(pass.TypesInfo.Defs[name], fact)
+					fields[fact.Name] = fact
+				}
+			}
+		}
+	}
+
+	// Getters are named Get<Field>; the field's note covers them
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || len(fn.Recv.List) != 1 || !strings.HasPrefix(fn.Name.Name, "Get") {
+			continue
+		}
+		recv := types.ExprString(fn.Recv.List[0].Type)
+		fact, ok := fields[strings.TrimPrefix(recv, "*")+"."+strings.TrimPrefix(fn.Name.Name, "Get")]
+		if ok {
+			pass.ExportObjectFact(pass.TypesInfo.Defs[fn.Name], fact)
+		}
+	}
+}
+
+// deprecation returns the Deprecated: paragraph of a doc comment, without the marker
+func deprecation(doc *ast.CommentGroup) (string, bool) {
+	if doc == nil {
+		return "", false
+	}
+	for _, paragraph := range strings.Split(doc.Text(), "\n\n") {
+		if note, ok := strings.CutPrefix(paragraph, "Deprecated:"); ok {
+			return strings.Join(strings.Fields(note), " "), true
+		}
+	}
+	return "", false
+}
+
+// isProtobufTag reports whether a struct tag literal has a protobuf key, as on the
+// fields protoc-gen-go generates
+func isProtobufTag(literal string) bool {
+	tag := strings.Trim(literal, "`")
+	_, ok := reflect.StructTag(tag).Lookup("protobuf")
+	return ok
+}
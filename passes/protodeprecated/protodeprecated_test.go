@@ -0,0 +1,12 @@
+package protodeprecated_test
+
+import (
+	"testing"
+
+	"github.com/nickheyer/go_no_nil_linter/passes/protodeprecated"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestProtoDeprecated(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), protodeprecated.Analyzer, "deprecated")
+}
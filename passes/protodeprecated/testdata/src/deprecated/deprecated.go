@@ -0,0 +1,17 @@
+package deprecated
+
+import "pb"
+
+func build(id string) *pb.User {
+	return &pb.User{
+		Id:       id,
+		Nickname: id, // want `use of deprecated protobuf field 'User.Nickname': Marked as deprecated in user.proto.`
+	}
+}
+
+func read(u *pb.User) string {
+	if u.Legacy != nil { // want `use of deprecated protobuf field 'User.Legacy'`
+		return u.GetNickname() // want `use of deprecated protobuf getter of field 'User.Nickname'`
+	}
+	return u.GetId() + u.Id
+}
@@ -0,0 +1,132 @@
+// Package protooneof defines an Analyzer that reports misuse of protobuf oneof fields
+// in generated Go code.
+//
+// protoc-gen-go represents a oneof as an interface field, such as
+// isUserResponse_Contact, holding a pointer to one wrapper struct per case. Two
+// mistakes compile but fail at run time:
+//
+//   - A single-value type assertion on the oneof, resp.Contact.(*pb.UserResponse_Email),
+//     panics when another case or no case is set.
+//   - A typed nil wrapper, resp.Contact = (*pb.UserResponse_Email)(nil), makes the oneof
+//     look set, and marshaling it fails. Assign nil to clear the oneof instead.
+package protooneof
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// Analyzer reports unchecked type assertions on oneof fields and typed nil wrappers
+// assigned to them
+var Analyzer = &analysis.Analyzer{
+	Name:     "protooneof",
+	Doc:      "reports unchecked type assertions on protobuf oneof fields and typed nil oneof wrappers",
+	Run:      run,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodeFilter := []ast.Node{
+		(*ast.TypeAssertExpr)(nil),
+		(*ast.AssignStmt)(nil),
+		(*ast.KeyValueExpr)(nil),
+	}
+	inspect.WithStack(nodeFilter, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push || ast.IsGenerated(stack[0].(*ast.File)) {
+			return true
+		}
+		switch node := n.(type) {
+		case *ast.TypeAssertExpr:
+			checkTypeAssertion(node, stack, pass)
+		case *ast.AssignStmt:
+			if len(node.Lhs) != len(node.Rhs) {
+				return true
+			}
+			for i, lhs := range node.Lhs {
+				checkWrapperValue(pass.TypesInfo.TypeOf(lhs), types.ExprString(lhs), node.Rhs[i], pass)
+			}
+		case *ast.KeyValueExpr:
+			if key, ok := node.Key.(*ast.Ident); ok {
+				if field, ok := pass.TypesInfo.ObjectOf(key).(*types.Var); ok && field.IsField() {
+					checkWrapperValue(field.Type(), key.Name, node.Value, pass)
+				}
+			}
+		}
+		return true
+	})
+	return nil, nil
+}
+
+// checkTypeAssertion reports x.(*T) on a oneof unless its ok result is checked. Type
+// switches (x.(type)) are how oneofs are meant to be read.
+func checkTypeAssertion(assert *ast.TypeAssertExpr, stack []ast.Node, pass *analysis.Pass) {
+	if assert.Type == nil || !isOneof(pass.TypesInfo.TypeOf(assert.X)) {
+		return
+	}
+
+	// The comma-ok forms: v, ok := x.(*T) and var v, ok = x.(*T)
+	var parent ast.Node
+	for i := len(stack) - 2; i >= 0; i-- {
+		if _, ok := stack[i].(*ast.ParenExpr); !ok {
+			parent = stack[i]
+			break
+		}
+	}
+	switch p := parent.(type) {
+	case *ast.AssignStmt:
+		if len(p.Lhs) == 2 && len(p.Rhs) == 1 {
+			return
+		}
+	case *ast.ValueSpec:
+		if len(p.Names) == 2 && len(p.Values) == 1 {
+			return
+		}
+	}
+
+	pass.Report(analysis.Diagnostic{
+		Pos:      assert.Pos(),
+		End:      assert.End(),
+		Category: "oneof-assertion",
+		Message: fmt.Sprintf("unchecked type assertion on oneof %s panics when another case or no case is set; use the comma-ok form, a type switch or the generated getter",
+			types.ExprString(assert.X)),
+	})
+}
+
+// checkWrapperValue reports a typed nil wrapper, (*T)(nil), stored in a oneof
+func checkWrapperValue(dst types.Type, name string, value ast.Expr, pass *analysis.Pass) {
+	if !isOneof(dst) {
+		return
+	}
+	conv, ok := ast.Unparen(value).(*ast.CallExpr)
+	if !ok || len(conv.Args) != 1 || !pass.TypesInfo.Types[conv.Fun].IsType() || !pass.TypesInfo.Types[conv.Args[0]].IsNil() {
+		return
+	}
+
+	pass.Report(analysis.Diagnostic{
+		Pos:      value.Pos(),
+		End:      value.End(),
+		Category: "oneof-nil-wrapper",
+		Message: fmt.Sprintf("typed nil %s stored in oneof %s makes the oneof look set and fails to marshal; assign nil to clear it",
+			types.TypeString(pass.TypesInfo.TypeOf(value), types.RelativeTo(pass.Pkg)), name),
+	})
+}
+
+// isOneof reports whether t is the interface protoc-gen-go generates for a oneof: a
+// named interface isM_Name whose only method is named after the type
+func isOneof(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	iface, ok := named.Underlying().(*types.Interface)
+	name := named.Obj().Name()
+	return ok && strings.HasPrefix(name, "is") && iface.NumMethods() == 1 && iface.Method(0).Name() == name
+}
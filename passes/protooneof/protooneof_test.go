@@ -0,0 +1,12 @@
+package protooneof_test
+
+import (
+	"testing"
+
+	"github.com/nickheyer/go_no_nil_linter/passes/protooneof"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestProtoOneof(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), protooneof.Analyzer, "oneof", "pb")
+}
@@ -0,0 +1,41 @@
+package oneof
+
+import (
+	"fmt"
+
+	"pb"
+)
+
+func assertions(resp *pb.UserResponse) string {
+	email := resp.Contact.(*pb.UserResponse_Email).Email // want `unchecked type assertion on oneof resp.Contact panics when another case or no case is set`
+	phone := (resp.GetContact().(*pb.UserResponse_Phone)) // want `unchecked type assertion on oneof resp.GetContact\(\)`
+	return email + phone.Phone
+}
+
+func checked(resp *pb.UserResponse) string {
+	if e, ok := resp.Contact.(*pb.UserResponse_Email); ok {
+		return e.Email
+	}
+	var p, ok = resp.GetContact().(*pb.UserResponse_Phone)
+	if ok {
+		return p.Phone
+	}
+	switch c := resp.Contact.(type) {
+	case *pb.UserResponse_Email:
+		return c.Email
+	}
+	return resp.GetEmail()
+}
+
+func otherInterfaces(v any) string {
+	return v.(fmt.Stringer).String()
+}
+
+func nilWrappers(resp *pb.UserResponse) *pb.UserResponse {
+	resp.Contact = (*pb.UserResponse_Email)(nil) // want `typed nil \*pb.UserResponse_Email stored in oneof resp.Contact makes the oneof look set and fails to marshal; assign nil to clear it`
+	resp.Contact = nil
+	resp.Contact = &pb.UserResponse_Email{Email: "a@example.com"}
+	return &pb.UserResponse{
+		Contact: (*pb.UserResponse_Phone)(nil), // want `typed nil \*pb.UserResponse_Phone stored in oneof Contact`
+	}
+}
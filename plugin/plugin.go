@@ -0,0 +1,136 @@
+// Package plugin runs nonillinter inside golangci-lint through its module plugin
+// system. golangci-lint's custom build imports this package, whose init registers the
+// linter as "nonillinter". It is part of the linter's module, so it builds against the
+// analyzer of the same release:
+//
+//	# .custom-gcl.yml
+//	version: v1.64.8
+//	plugins:
+//	  - module: github.com/nickheyer/go_no_nil_linter
+//	    import: github.com/nickheyer/go_no_nil_linter/plugin
+//	    version: latest
+//
+// Its settings in .golangci.yml are the analyzer's flag names, as in a -config file:
+//
+//	linters-settings:
+//	  custom:
+//	    nonillinter:
+//	      type: module
+//	      settings:
+//	        response-suffixes: [Response, Reply]
+//	        include-packages: [services/...]
+//	        map-lookup: error
+package plugin
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golangci/plugin-module-register/register"
+	"github.com/nickheyer/go_no_nil_linter/analyzer"
+	"golang.org/x/tools/go/analysis"
+)
+
+func init() {
+	register.Plugin(analyzer.Analyzer.Name, newPlugin)
+}
+
+// New configures the analyzer from golangci-lint settings and returns it. conf is the
+// settings map from .golangci.yml, or nil for the defaults.
+func New(conf any) ([]*analysis.Analyzer, error) {
+	settings, err := flagSettings(conf)
+	if err != nil {
+		return nil, err
+	}
+	if err := analyzer.Configure(settings); err != nil {
+		return nil, fmt.Errorf("nonillinter settings: %v", err)
+	}
+	return []*analysis.Analyzer{analyzer.Analyzer}, nil
+}
+
+// linterPlugin is the register.LinterPlugin golangci-lint builds the analyzers from
+type linterPlugin struct {
+	analyzers []*analysis.Analyzer
+}
+
+func newPlugin(conf any) (register.LinterPlugin, error) {
+	analyzers, err := New(conf)
+	if err != nil {
+		return nil, err
+	}
+	return &linterPlugin{analyzers: analyzers}, nil
+}
+
+func (p *linterPlugin) BuildAnalyzers() ([]*analysis.Analyzer, error) {
+	return p.analyzers, nil
+}
+
+// GetLoadMode asks for type information, which every check needs
+func (p *linterPlugin) GetLoadMode() string {
+	return register.LoadModeTypesInfo
+}
+
+// flagSettings turns golangci-lint settings into analyzer flag values. Lists are
+// joined with commas; nested settings are rejected.
+func flagSettings(conf any) (map[string]string, error) {
+	if conf == nil {
+		return nil, nil
+	}
+	raw, ok := conf.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("nonillinter settings: expected a map of settings, got %T", conf)
+	}
+	settings := make(map[string]string, len(raw))
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, err := flagValue(raw[key])
+		if err != nil {
+			return nil, fmt.Errorf("nonillinter settings: %s: %v", key, err)
+		}
+		settings[key] = value
+	}
+	return settings, nil
+}
+
+// flagValue renders a scalar or a list of scalars as a flag value
+func flagValue(value any) (string, error) {
+	switch v := value.(type) {
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			s, err := scalarValue(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return strings.Join(items, ","), nil
+	case []string:
+		return strings.Join(v, ","), nil
+	}
+	return scalarValue(value)
+}
+
+func scalarValue(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case nil:
+		return "", nil
+	}
+	return "", fmt.Errorf("nested settings are not supported, got %T", value)
+}
@@ -0,0 +1,60 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/golangci/plugin-module-register/register"
+	"github.com/nickheyer/go_no_nil_linter/analyzer"
+)
+
+func TestNew(t *testing.T) {
+	defer analyzer.Analyzer.Flags.Set("response-suffixes", "Response,Reply,Result")
+	defer analyzer.Analyzer.Flags.Set("map-lookup", "advisory")
+	defer analyzer.Analyzer.Flags.Set("tagged-structs", "false")
+
+	analyzers, err := New(map[string]any{
+		"response-suffixes": []any{"Response", "Output"},
+		"map_lookup":        "error",
+		"tagged-structs":    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(analyzers) != 1 || analyzers[0] != analyzer.Analyzer {
+		t.Fatalf("Expected the nonillinter analyzer, got %v", analyzers)
+	}
+	for name, want := range map[string]string{"response-suffixes": "Response,Output", "map-lookup": "error", "tagged-structs": "true"} {
+		if got := analyzer.Analyzer.Flags.Lookup(name).Value.String(); got != want {
+			t.Errorf("-%s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestNewRejectsBadSettings(t *testing.T) {
+	for _, conf := range []any{
+		"response-suffixes=Output",
+		map[string]any{"no-such-flag": true},
+		map[string]any{"map-lookup": map[string]any{"mode": "error"}},
+	} {
+		if _, err := New(conf); err == nil {
+			t.Errorf("New(%v) should fail", conf)
+		}
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	newPlugin, err := register.GetPlugin("nonillinter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := newPlugin(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.GetLoadMode() != register.LoadModeTypesInfo {
+		t.Errorf("Expected load mode %q, got %q", register.LoadModeTypesInfo, p.GetLoadMode())
+	}
+	if analyzers, err := p.BuildAnalyzers(); err != nil || len(analyzers) != 1 {
+		t.Errorf("BuildAnalyzers() = %v, %v", analyzers, err)
+	}
+}